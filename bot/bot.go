@@ -0,0 +1,396 @@
+// Package bot is a framework for writing BroChat bots. Chat messages are routed to command handlers by a prefix
+// ("!roll 2d6") or a mention of the bot ("@dicebot roll 2d6"), and handlers reply through a Context.
+//
+// The bot is given feed messages by the caller from whatever connection the application uses, such as a
+// chat.FeedClient, either one at a time with HandleFeedMessage or as a channel with Run.
+//
+// Usage:
+//
+//	b := bot.NewBot(session.UserId, bot.ClientSender(client, session.AccessToken), bot.BotOption_Username("dicebot"))
+//	err := b.Handle("flip", "Flips a coin.", func(c *bot.Context) error {
+//		_, err := c.Reply("heads")
+//		return err
+//	})
+//	b.Run(ctx, feedMessages)
+package bot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+const (
+	// The default prefix which triggers a command.
+	DEFAULT_COMMAND_PREFIX = "!"
+	// The default number of messages Run handles concurrently.
+	DEFAULT_CONCURRENCY = 16
+)
+
+var (
+	// ErrInvalidCommandName is returned by Handle when the command name is empty or contains whitespace.
+	ErrInvalidCommandName = errors.New("bot: invalid command name")
+)
+
+// A HandlerFunc handles a command. A returned error is passed to the bot's error callback.
+type HandlerFunc func(c *Context) error
+
+// A Sender sends chat messages on behalf of the bot.
+type Sender interface {
+	SendChatMessage(request chat.ChatMessageRequest) (chat.ChatMessage, error)
+}
+
+// SenderFunc adapts a function to the Sender interface.
+type SenderFunc func(request chat.ChatMessageRequest) (chat.ChatMessage, error)
+
+// SendChatMessage implements the Sender interface.
+func (f SenderFunc) SendChatMessage(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+	return f(request)
+}
+
+// ClientSender returns a Sender which sends messages with the client using the access token. The access token may be
+// empty if the client was created with chat.NewTokenProviderHttpClient.
+func ClientSender(client *chat.BroChatClient, accessToken string) Sender {
+	return SenderFunc(func(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+		result := client.SendChatMessage(accessToken, request)
+
+		return result.Content, result.Err()
+	})
+}
+
+// SigningSender returns a Sender which signs every message with the Ed25519 private key of the signing key with the ID
+// before sending it with the sender, so recipients can verify the message came from the bot. Register the public key
+// with chat.BroChatClient.AddSigningKey to get the key ID.
+func SigningSender(sender Sender, keyId string, key ed25519.PrivateKey) Sender {
+	return SenderFunc(func(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+		if err := chat.SignChatMessageRequest(&request, keyId, key, time.Now()); err != nil {
+			return chat.ChatMessage{}, err
+		}
+
+		return sender.SendChatMessage(request)
+	})
+}
+
+// A Command is a registered command of a bot.
+type Command struct {
+	// The name of the command. Always lower case.
+	Name string
+	// A short description of the command, shown by Help.
+	Description string
+
+	handler HandlerFunc
+}
+
+// Bot routes chat messages to command handlers. Handlers for different messages run concurrently.
+type Bot struct {
+	mu          sync.RWMutex
+	userId      string
+	username    string
+	prefix      string
+	sender      Sender
+	commands    map[string]Command
+	notFound    HandlerFunc
+	limiter     Limiter
+	concurrency int
+	codec       chat.Codec
+	onError     func(error)
+}
+
+// BotOption is a type for the options that can be passed to NewBot.
+type BotOption func(*Bot)
+
+// Sets the prefix which triggers a command. Defaults to DEFAULT_COMMAND_PREFIX. An empty prefix disables prefix triggers.
+func BotOption_Prefix(prefix string) BotOption {
+	return func(b *Bot) {
+		b.prefix = prefix
+	}
+}
+
+// Sets the username of the bot, enabling mention triggers. Example: "@dicebot roll 2d6"
+func BotOption_Username(username string) BotOption {
+	return func(b *Bot) {
+		b.username = username
+	}
+}
+
+// Limits each user to triggering requests commands per period, in bursts of up to requests commands. Commands over the
+// limit are ignored. Replaces any limiter set with BotOption_Limiter.
+func BotOption_RateLimit(requests int, per time.Duration) BotOption {
+	return func(b *Bot) {
+		b.limiter = NewMemoryLimiter(requests, per)
+	}
+}
+
+// Sets the limiter deciding whether a user may trigger a command. Commands the limiter does not allow are ignored.
+// Useful to share limits between several instances of a bot, see Limiter.
+func BotOption_Limiter(limiter Limiter) BotOption {
+	return func(b *Bot) {
+		b.limiter = limiter
+	}
+}
+
+// Sets the number of messages Run handles concurrently. Run stops receiving messages while that many are being
+// handled. Defaults to DEFAULT_CONCURRENCY.
+func BotOption_Concurrency(concurrency int) BotOption {
+	return func(b *Bot) {
+		if concurrency > 0 {
+			b.concurrency = concurrency
+		}
+	}
+}
+
+// Sets the codec used to decode the content of feed messages. Defaults to chat.StdCodec.
+func BotOption_Codec(codec chat.Codec) BotOption {
+	return func(b *Bot) {
+		b.codec = codec
+	}
+}
+
+// Signs every message the bot sends with the signing key. See SigningSender.
+func BotOption_SigningKey(keyId string, key ed25519.PrivateKey) BotOption {
+	return func(b *Bot) {
+		b.sender = SigningSender(b.sender, keyId, key)
+	}
+}
+
+// Sets a callback which is invoked with the errors returned by handlers, panics recovered from handlers and errors
+// decoding feed messages. Must be safe for concurrent use. Errors are discarded by default.
+func BotOption_OnError(callback func(error)) BotOption {
+	return func(b *Bot) {
+		b.onError = callback
+	}
+}
+
+// NewBot creates a bot for the user the sender sends as. Messages sent by the user are ignored.
+func NewBot(userId string, sender Sender, options ...BotOption) *Bot {
+	b := &Bot{
+		userId:      userId,
+		prefix:      DEFAULT_COMMAND_PREFIX,
+		sender:      sender,
+		commands:    make(map[string]Command),
+		concurrency: DEFAULT_CONCURRENCY,
+		codec:       chat.StdCodec{},
+		onError:     func(error) {},
+	}
+
+	for _, opt := range options {
+		opt(b)
+	}
+
+	return b
+}
+
+// Handle registers the handler for a command, replacing any existing handler. Command names are case-insensitive and
+// cannot contain whitespace. Returns ErrInvalidCommandName if the name is empty or contains whitespace.
+func (b *Bot) Handle(name string, description string, handler HandlerFunc) error {
+	name = strings.ToLower(name)
+
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidCommandName, name)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.commands[name] = Command{Name: name, Description: description, handler: handler}
+
+	return nil
+}
+
+// NotFound registers the handler for triggered commands which have not been registered. Unknown commands are ignored by default.
+func (b *Bot) NotFound(handler HandlerFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.notFound = handler
+}
+
+// Commands returns the registered commands ordered by name.
+func (b *Bot) Commands() []Command {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	commands := make([]Command, 0, len(b.commands))
+
+	for _, c := range b.commands {
+		commands = append(commands, c)
+	}
+
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+
+	return commands
+}
+
+// Help returns a list of the registered commands and their descriptions, one per line. Suitable as the reply of a help command.
+func (b *Bot) Help() string {
+	var help strings.Builder
+
+	for i, c := range b.Commands() {
+		if i > 0 {
+			help.WriteString("\n")
+		}
+
+		help.WriteString(b.prefix + c.Name)
+
+		if c.Description != "" {
+			help.WriteString(" - " + c.Description)
+		}
+	}
+
+	return help.String()
+}
+
+// Run handles the feed messages received on the channel until it is closed or the context is cancelled, then waits for
+// running handlers to return. Up to the concurrency of the bot messages are handled at once; further messages wait in
+// the channel until a handler returns.
+func (b *Bot) Run(ctx context.Context, messages <-chan *chat.FeedMessage) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, b.concurrency)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				if err := b.HandleFeedMessage(ctx, message); err != nil {
+					b.onError(err)
+				}
+			}()
+		}
+	}
+}
+
+// HandleFeedMessage routes a feed message to its command handler. Feed messages other than chat messages, messages sent
+// by the bot, messages which do not trigger a command and rate limited messages are ignored. Returns the handler's error.
+func (b *Bot) HandleFeedMessage(ctx context.Context, message *chat.FeedMessage) error {
+	if message.Type != chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE {
+		return nil
+	}
+
+	var chatMessage chat.ChatMessage
+
+	if err := message.DecodeContent(b.codec, &chatMessage); err != nil {
+		return fmt.Errorf("decoding chat message: %w", err)
+	}
+
+	return b.HandleMessage(ctx, chatMessage)
+}
+
+// HandleMessage routes a chat message to its command handler. See HandleFeedMessage.
+func (b *Bot) HandleMessage(ctx context.Context, message chat.ChatMessage) error {
+	if message.SenderUserId == b.userId {
+		return nil
+	}
+
+	text, ok := b.trigger(message.Content)
+
+	if !ok {
+		return nil
+	}
+
+	name, argText := text, ""
+
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		name, argText = text[:i], text[i:]
+	}
+
+	name = strings.ToLower(name)
+
+	b.mu.RLock()
+	command, found := b.commands[name]
+	handler := command.handler
+
+	if !found {
+		handler = b.notFound
+	}
+
+	b.mu.RUnlock()
+
+	if handler == nil {
+		return nil
+	}
+
+	if b.limiter != nil {
+		allowed, err := b.limiter.Allow(ctx, message.SenderUserId)
+
+		if err != nil || !allowed {
+			return err
+		}
+	}
+
+	argText = strings.TrimSpace(argText)
+
+	return b.call(handler, &Context{
+		ctx:     ctx,
+		bot:     b,
+		Message: message,
+		Command: name,
+		Args:    strings.Fields(argText),
+		ArgText: argText,
+	})
+}
+
+// trigger returns the text following the prefix or mention which triggered a command.
+func (b *Bot) trigger(content string) (string, bool) {
+	content = strings.TrimSpace(content)
+
+	if b.prefix != "" && strings.HasPrefix(content, b.prefix) {
+		text := strings.TrimSpace(strings.TrimPrefix(content, b.prefix))
+
+		return text, text != ""
+	}
+
+	if b.username != "" {
+		mention := "@" + b.username
+
+		if len(content) > len(mention) && strings.EqualFold(content[:len(mention)], mention) {
+			rest := content[len(mention):]
+
+			// The mention must be a whole word. Example: "@dicebot, roll" but not "@dicebotx roll".
+			if r := rune(rest[0]); unicode.IsSpace(r) || r == ',' || r == ':' {
+				text := strings.TrimSpace(strings.TrimLeft(rest, ",:"))
+
+				return text, text != ""
+			}
+		}
+	}
+
+	return "", false
+}
+
+// call invokes the handler, converting a panic into an error.
+func (b *Bot) call(handler HandlerFunc, c *Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("command %s panicked: %v", c.Command, r)
+		}
+	}()
+
+	return handler(c)
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// discardSender is a Sender which drops every message.
+var discardSender = SenderFunc(func(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+	return chat.ChatMessage{}, nil
+})
+
+func TestBot_Handle_InvalidName(t *testing.T) {
+	b := NewBot("bot", discardSender)
+
+	for _, name := range []string{"", "two words", "tab\tname"} {
+		if err := b.Handle(name, "", func(*Context) error { return nil }); !errors.Is(err, ErrInvalidCommandName) {
+			t.Errorf("Handle(%q) error = %v, want ErrInvalidCommandName", name, err)
+		}
+	}
+
+	if err := b.Handle("Roll", "", func(*Context) error { return nil }); err != nil {
+		t.Errorf("Handle(Roll) error = %v", err)
+	}
+
+	if commands := b.Commands(); len(commands) != 1 || commands[0].Name != "roll" {
+		t.Errorf("Commands() = %+v, want only roll", commands)
+	}
+}
+
+func TestBot_Run_BoundsConcurrency(t *testing.T) {
+	const concurrency = 3
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+
+	b := NewBot("bot", discardSender, BotOption_Concurrency(concurrency))
+	b.Handle("wait", "", func(*Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		<-release
+		return nil
+	})
+
+	messages := make(chan *chat.FeedMessage, 10)
+
+	for i := 0; i < cap(messages); i++ {
+		message, err := chat.NewFeedMessageJSON(chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE, chat.ChatMessage{SenderUserId: "user", Content: "!wait"})
+
+		if err != nil {
+			t.Fatalf("NewFeedMessageJSON() error = %v", err)
+		}
+
+		messages <- message
+	}
+
+	close(messages)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		b.Run(context.Background(), messages)
+	}()
+
+	// Let the handlers pile up, then release them all
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if p := peak.Load(); p != concurrency {
+		t.Errorf("peak concurrent handlers = %d, want %d", p, concurrency)
+	}
+}
+
+func TestBot_RateLimit(t *testing.T) {
+	var calls atomic.Int32
+
+	b := NewBot("bot", discardSender, BotOption_RateLimit(2, time.Hour))
+	b.Handle("ping", "", func(*Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		if err := b.HandleMessage(context.Background(), chat.ChatMessage{SenderUserId: "user", Content: "!ping"}); err != nil {
+			t.Fatalf("HandleMessage() error = %v", err)
+		}
+	}
+
+	if err := b.HandleMessage(context.Background(), chat.ChatMessage{SenderUserId: "other", Content: "!ping"}); err != nil {
+		t.Fatalf("HandleMessage() error = %v", err)
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("handler called %d times, want 2 for the limited user and 1 for the other", n)
+	}
+}
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// A Context is passed to a command handler. It describes the message which triggered the command and sends replies.
+type Context struct {
+	ctx context.Context
+	bot *Bot
+
+	// The message which triggered the command.
+	Message chat.ChatMessage
+	// The lower case name of the command.
+	Command string
+	// The whitespace separated arguments following the command name.
+	Args []string
+	// The text following the command name, with surrounding whitespace removed.
+	ArgText string
+}
+
+// Context returns the context the message is being handled under.
+func (c *Context) Context() context.Context {
+	return c.ctx
+}
+
+// Arg returns the argument at the index or an empty string if there are not enough arguments.
+func (c *Context) Arg(index int) string {
+	if index < 0 || index >= len(c.Args) {
+		return ""
+	}
+
+	return c.Args[index]
+}
+
+// Reply sends a message to the channel of the triggering message as a reply to it.
+func (c *Context) Reply(content string) (chat.ChatMessage, error) {
+	return c.SendRequest(chat.NewChatMessageRequest(c.Message.ChannelId, content, chat.ChatMessageRequestOption_ReplyTo(c.Message.Id)))
+}
+
+// Replyf formats the content and sends it as a reply. See Reply.
+func (c *Context) Replyf(format string, args ...any) (chat.ChatMessage, error) {
+	return c.Reply(fmt.Sprintf(format, args...))
+}
+
+// Send sends a message to the channel of the triggering message without replying to it.
+func (c *Context) Send(content string) (chat.ChatMessage, error) {
+	return c.SendRequest(chat.NewChatMessageRequest(c.Message.ChannelId, content))
+}
+
+// SendRequest sends a message request as the bot. Useful for embeds or for messages to other channels.
+func (c *Context) SendRequest(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+	if errs := request.Validate(); len(errs) > 0 {
+		return chat.ChatMessage{}, errs
+	}
+
+	return c.bot.sender.SendChatMessage(request)
+}
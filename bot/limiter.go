@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// A Limiter decides whether a user may trigger another command. Implementations must be safe for concurrent use.
+//
+// A limit shared by several instances of a bot can be kept in a serverutil.RateLimitStore by adapting it:
+//
+//	bot.LimiterFunc(func(ctx context.Context, userId string) (bool, error) {
+//		decision, err := store.Take(ctx, userId, policy, time.Now())
+//		return decision.Allowed, err
+//	})
+type Limiter interface {
+	// Allow returns true if the user may trigger a command, using up one of their requests.
+	Allow(ctx context.Context, userId string) (bool, error)
+}
+
+// LimiterFunc adapts a function to the Limiter interface.
+type LimiterFunc func(ctx context.Context, userId string) (bool, error)
+
+// Allow implements the Limiter interface.
+func (f LimiterFunc) Allow(ctx context.Context, userId string) (bool, error) {
+	return f(ctx, userId)
+}
+
+// MemoryLimiter is a Limiter which keeps a token bucket per user in memory. Each bucket holds up to requests tokens and
+// refills completely over per, allowing bursts of requests commands and a sustained rate of requests per per.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	requests  float64
+	per       time.Duration
+	buckets   map[string]*limiterBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// limiterBucket is the state of the bucket of a single user.
+type limiterBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryLimiter creates a limiter allowing each user requests commands per period.
+func NewMemoryLimiter(requests int, per time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{
+		requests: float64(requests),
+		per:      per,
+		buckets:  make(map[string]*limiterBucket),
+		now:      time.Now,
+	}
+}
+
+// Allow implements the Limiter interface.
+func (l *MemoryLimiter) Allow(_ context.Context, userId string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := l.requests / l.per.Seconds()
+
+	l.sweep(now, rate)
+
+	bucket, ok := l.buckets[userId]
+
+	if !ok {
+		bucket = &limiterBucket{tokens: l.requests, updatedAt: now}
+		l.buckets[userId] = bucket
+	}
+
+	if elapsed := now.Sub(bucket.updatedAt).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(l.requests, bucket.tokens+elapsed*rate)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens < 1 {
+		return false, nil
+	}
+
+	bucket.tokens--
+
+	return true, nil
+}
+
+// sweep removes the buckets which have refilled completely, as they are indistinguishable from new buckets.
+// Runs at most once per period. Must be called with the lock held.
+func (l *MemoryLimiter) sweep(now time.Time, rate float64) {
+	if now.Sub(l.lastSweep) < l.per {
+		return
+	}
+
+	l.lastSweep = now
+
+	for userId, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate >= l.requests {
+			delete(l.buckets, userId)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package chat
+
+import (
+	"testing"
+)
+
+// assertAllocs fails the test if f allocates more than budget times on average. Budgets guard the hot paths of the
+// client and the feed against regressions; raise one only together with the change which needs it.
+func assertAllocs(t *testing.T, budget float64, f func()) {
+	t.Helper()
+
+	if raceEnabled {
+		t.Skip("allocation budgets are not checked with the race detector")
+	}
+
+	if allocs := testing.AllocsPerRun(100, f); allocs > budget {
+		t.Errorf("allocs = %v, budget %v", allocs, budget)
+	}
+}
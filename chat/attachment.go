@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// An Attachment represents a file that has been uploaded to a channel.
+type Attachment struct {
+	// The Id of the attachment.
+	Id string `json:"id"`
+	// The ID of the channel that the attachment was uploaded to.
+	ChannelId string `json:"channel_id"`
+	// The kind of attachment. An empty value should be treated as ATTACHMENT_KIND_FILE.
+	Kind AttachmentKind `json:"kind,omitempty"`
+	// The original name of the file.
+	FileName string `json:"file_name"`
+	// The MIME type of the file. Example: image/png
+	ContentType string `json:"content_type"`
+	// The size of the file in bytes.
+	SizeBytes int64 `json:"size_bytes"`
+	// The URL the attachment can be downloaded from.
+	Url string `json:"url"`
+	// The width of the image in pixels. Will be 0 if the attachment is not an image.
+	Width int `json:"width,omitempty"`
+	// The height of the image in pixels. Will be 0 if the attachment is not an image.
+	Height int `json:"height,omitempty"`
+	// The duration of the recording in milliseconds. Only populated for voice notes.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// The available thumbnails for the attachment. Will be empty if the attachment is not an image.
+	Thumbnails []AttachmentThumbnail `json:"thumbnails,omitempty"`
+	// The ID of the user that uploaded the attachment.
+	UploadedByUserId string `json:"uploaded_by_user_id"`
+	// CreatedAtUtc is when the attachment was uploaded.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+// Duration returns the duration of a voice note attachment.
+func (a Attachment) Duration() time.Duration {
+	return time.Duration(a.DurationMs) * time.Millisecond
+}
+
+// An AttachmentThumbnail is a scaled down rendition of an image attachment.
+type AttachmentThumbnail struct {
+	// The URL the thumbnail can be downloaded from.
+	Url string `json:"url"`
+	// The width of the thumbnail in pixels.
+	Width int `json:"width"`
+	// The height of the thumbnail in pixels.
+	Height int `json:"height"`
+}
+
+// IsImage returns true if the attachment is an image.
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+// BestThumbnail returns the smallest thumbnail that covers the requested bounding box. If no thumbnail is large enough
+// the largest available thumbnail is returned. The second return value will be false if the attachment has no thumbnails.
+func (a Attachment) BestThumbnail(maxWidth int, maxHeight int) (AttachmentThumbnail, bool) {
+	if len(a.Thumbnails) == 0 {
+		return AttachmentThumbnail{}, false
+	}
+
+	var best, largest *AttachmentThumbnail
+
+	for i := range a.Thumbnails {
+		t := &a.Thumbnails[i]
+
+		if largest == nil || t.Width*t.Height > largest.Width*largest.Height {
+			largest = t
+		}
+
+		if t.Width >= maxWidth || t.Height >= maxHeight {
+			if best == nil || t.Width*t.Height < best.Width*best.Height {
+				best = t
+			}
+		}
+	}
+
+	if best == nil {
+		return *largest, true
+	}
+
+	return *best, true
+}
+
+// UploadProgressFunc is called periodically during an attachment upload with the number of bytes sent so far.
+// The total will be -1 if the size of the upload is unknown.
+type UploadProgressFunc func(sent int64, total int64)
+
+// The name of the multipart form field that contains the uploaded file.
+const attachmentFormFieldName = "file"
+
+// progressReader wraps a reader and reports the number of bytes read to a progress callback.
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+
+	if n > 0 {
+		r.sent += int64(n)
+
+		if r.progress != nil {
+			r.progress(r.sent, r.total)
+		}
+	}
+
+	return n, err
+}
+
+// detectAttachmentContentType determines the MIME type of a file based on its extension.
+func detectAttachmentContentType(fileName string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(fileName)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
+
+// escapeQuotes escapes quotes and backslashes for use in a Content-Disposition header value.
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
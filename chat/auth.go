@@ -0,0 +1,150 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// A TokenProvider supplies the access token used to authorize requests to the BroChat API.
+// Implementations must be safe for concurrent use.
+type TokenProvider interface {
+	// Token returns the current access token.
+	Token() (string, error)
+	// Refresh obtains a new access token after the server rejected the stale token.
+	// Implementations should only refresh once when called concurrently with the same stale token.
+	Refresh(staleToken string) (string, error)
+}
+
+// NewTokenProviderHttpClient creates an http client which authorizes every request with the token from the provider.
+// If the server responds with 401 Unauthorized the token is refreshed and the request is retried once.
+// The access token passed to the BroChatClient methods is ignored when using this client, so an empty string may be passed.
+// The base client may be nil, in which case http.DefaultClient is used as the base. A base client without a transport of
+// its own uses a transport created by NewTransport with the default settings.
+func NewTokenProviderHttpClient(base *http.Client, provider TokenProvider) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+
+	transport := base.Transport
+
+	if transport == nil {
+		transport = NewTransport(DefaultTransportConfig())
+	}
+
+	client := *base
+	client.Transport = &tokenRefreshTransport{base: transport, provider: provider}
+
+	if client.CheckRedirect == nil {
+		client.CheckRedirect = dropCredentialsOnCrossOriginRedirect
+	}
+
+	return &client
+}
+
+// credentialsContextKey marks the context of a request which must not be sent credentials.
+type credentialsContextKey struct{}
+
+// withoutCredentials returns a copy of the request which a tokenRefreshTransport sends without authorizing it, such as
+// a request to a host other than the API.
+func withoutCredentials(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), credentialsContextKey{}, false))
+}
+
+// mayAuthorize returns true if the request may be sent credentials: it has not been marked by withoutCredentials and,
+// if it follows a redirect, it goes to the scheme and host of the request which was redirected.
+func mayAuthorize(req *http.Request) bool {
+	if allowed, ok := req.Context().Value(credentialsContextKey{}).(bool); ok && !allowed {
+		return false
+	}
+
+	origin := req
+
+	for origin.Response != nil && origin.Response.Request != nil {
+		origin = origin.Response.Request
+	}
+
+	return isSameOrigin(origin.URL, req.URL)
+}
+
+// dropCredentialsOnCrossOriginRedirect is the redirect policy of the http clients created by the library. It keeps the
+// limit of ten redirects of the default policy, and removes the authorization header from redirects to another scheme
+// or host, including subdomains which the default policy would still send it to.
+func dropCredentialsOnCrossOriginRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	if !isSameOrigin(via[0].URL, req.URL) {
+		req.Header.Del("Authorization")
+	}
+
+	return nil
+}
+
+// tokenRefreshTransport is an http.RoundTripper which authorizes requests using a TokenProvider.
+type tokenRefreshTransport struct {
+	base     http.RoundTripper
+	provider TokenProvider
+}
+
+func (t *tokenRefreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !mayAuthorize(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	token, err := t.provider.Token()
+
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(withAuthorization(req, token))
+
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	// The request can only be retried if its body can be replayed
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	refreshed, err := t.provider.Refresh(token)
+
+	if err != nil || refreshed == token {
+		return res, nil
+	}
+
+	retry := withAuthorization(req, refreshed)
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+
+		if err != nil {
+			return res, nil
+		}
+
+		retry.Body = body
+	}
+
+	res.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+// withAuthorization clones the request with the authorization header set to the token.
+func withAuthorization(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, token))
+	return clone
+}
+
+// closeRequestBody closes the request body. A RoundTripper must always close the body, even on errors.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
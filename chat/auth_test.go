@@ -0,0 +1,314 @@
+package chat
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// authorizationRecorder records the authorization header of each request it serves.
+type authorizationRecorder struct {
+	mu      sync.Mutex
+	headers []string
+}
+
+func (r *authorizationRecorder) record(req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = append(r.headers, req.Header.Get("Authorization"))
+}
+
+func (r *authorizationRecorder) authorized() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, h := range r.headers {
+		if h != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestDownloadAttachmentThumbnail_Credentials(t *testing.T) {
+	var cdnRequests authorizationRecorder
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cdnRequests.record(r)
+		io.WriteString(w, "thumbnail")
+	}))
+	defer cdn.Close()
+
+	var apiRequests authorizationRecorder
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiRequests.record(r)
+
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, cdn.URL+"/thumb.png", http.StatusFound)
+			return
+		}
+
+		io.WriteString(w, "thumbnail")
+	}))
+	defer api.Close()
+
+	tests := []struct {
+		name          string
+		url           string
+		apiAuthorized bool
+	}{
+		{name: "same host", url: "/thumb.png", apiAuthorized: true},
+		{name: "other host", url: cdn.URL + "/thumb.png"},
+		{name: "redirect to other host", url: "/redirect", apiAuthorized: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cdnRequests = authorizationRecorder{}
+			apiRequests = authorizationRecorder{}
+
+			client := NewBroChatClient(nil, api.URL)
+			attachment := Attachment{Id: "a", Thumbnails: []AttachmentThumbnail{{Url: tt.url, Width: 10, Height: 10}}}
+
+			result := client.DownloadAttachmentThumbnail("token", attachment, 10, 10, io.Discard)
+
+			if err := result.Err(); err != nil {
+				t.Fatalf("DownloadAttachmentThumbnail() error = %v", err)
+			}
+
+			if cdnRequests.authorized() {
+				t.Errorf("the other host received the access token")
+			}
+
+			if got := apiRequests.authorized(); got != tt.apiAuthorized {
+				t.Errorf("API host authorized = %v, want %v", got, tt.apiAuthorized)
+			}
+		})
+	}
+}
+
+func TestTokenProviderHttpClient_CrossOriginRedirect(t *testing.T) {
+	var otherRequests authorizationRecorder
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherRequests.record(r)
+	}))
+	defer other.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer api.Close()
+
+	client := NewTokenProviderHttpClient(nil, staticTokenProvider("token"))
+
+	res, err := client.Get(api.URL + "/start")
+
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	res.Body.Close()
+
+	if otherRequests.authorized() {
+		t.Errorf("the redirect target received the access token")
+	}
+}
+
+// staticTokenProvider is a TokenProvider which always returns the same token.
+type staticTokenProvider string
+
+func (p staticTokenProvider) Token() (string, error) {
+	return string(p), nil
+}
+
+func (p staticTokenProvider) Refresh(string) (string, error) {
+	return string(p), nil
+}
+
+// rotatingTokenProvider is a TokenProvider which issues a new token when refreshed with the current one, collapsing
+// concurrent refreshes of the same stale token into one.
+type rotatingTokenProvider struct {
+	mu        sync.Mutex
+	token     string
+	refreshes int
+}
+
+func (p *rotatingTokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.token, nil
+}
+
+func (p *rotatingTokenProvider) Refresh(staleToken string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if staleToken == p.token {
+		p.refreshes++
+		p.token = fmt.Sprintf("token-%d", p.refreshes)
+	}
+
+	return p.token, nil
+}
+
+// newTokenServer starts a server which responds 401 Unauthorized unless the request carries the accepted token, and
+// records the authorization header and body of each request.
+func newTokenServer(t *testing.T, accepted func() string) (*httptest.Server, *[]string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		requests = append(requests, r.Header.Get("Authorization")+" "+string(body))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+accepted() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestTokenProviderHttpClient_Refresh(t *testing.T) {
+	tests := []struct {
+		name          string
+		accepted      string
+		body          func() io.Reader
+		wantStatus    int
+		wantRequests  []string
+		wantRefreshes int
+	}{
+		{
+			name:          "retried once after refreshing",
+			accepted:      "token-1",
+			wantStatus:    http.StatusOK,
+			wantRequests:  []string{"Bearer stale ", "Bearer token-1 "},
+			wantRefreshes: 1,
+		},
+		{
+			name:          "replayable body is sent again",
+			accepted:      "token-1",
+			body:          func() io.Reader { return strings.NewReader("payload") },
+			wantStatus:    http.StatusOK,
+			wantRequests:  []string{"Bearer stale payload", "Bearer token-1 payload"},
+			wantRefreshes: 1,
+		},
+		{
+			name:          "second 401 is not retried",
+			accepted:      "never",
+			wantStatus:    http.StatusUnauthorized,
+			wantRequests:  []string{"Bearer stale ", "Bearer token-1 "},
+			wantRefreshes: 1,
+		},
+		{
+			// A reader other than bytes.Reader, bytes.Buffer or strings.Reader leaves GetBody unset
+			name:          "body without GetBody is not retried",
+			accepted:      "token-1",
+			body:          func() io.Reader { return io.MultiReader(strings.NewReader("payload")) },
+			wantStatus:    http.StatusUnauthorized,
+			wantRequests:  []string{"Bearer stale payload"},
+			wantRefreshes: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTokenServer(t, func() string { return tt.accepted })
+			provider := &rotatingTokenProvider{token: "stale"}
+			client := NewTokenProviderHttpClient(server.Client(), provider)
+
+			var body io.Reader
+
+			if tt.body != nil {
+				body = tt.body()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, body)
+
+			if err != nil {
+				t.Fatalf("NewRequest() error = %v", err)
+			}
+
+			res, err := client.Do(req)
+
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			res.Body.Close()
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+
+			if !slices.Equal(*requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", *requests, tt.wantRequests)
+			}
+
+			if provider.refreshes != tt.wantRefreshes {
+				t.Errorf("refreshes = %d, want %d", provider.refreshes, tt.wantRefreshes)
+			}
+		})
+	}
+}
+
+func TestTokenProviderHttpClient_ConcurrentRefresh(t *testing.T) {
+	const n = 20
+
+	provider := &rotatingTokenProvider{token: "stale"}
+	server, _ := newTokenServer(t, func() string { return "token-1" })
+	client := NewTokenProviderHttpClient(server.Client(), provider)
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := client.Get(server.URL)
+
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+
+			res.Body.Close()
+			statuses <- res.StatusCode
+		}()
+	}
+
+	wg.Wait()
+	close(statuses)
+
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("status = %d, want every request to succeed after the refresh", status)
+		}
+	}
+
+	if provider.refreshes != 1 {
+		t.Errorf("refreshes = %d, want the stale token refreshed once", provider.refreshes)
+	}
+}
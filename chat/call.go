@@ -0,0 +1,198 @@
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Call signaling lets the two participants of a direct message channel set up a WebRTC call through the feed. The
+// server relays each signal to the other participant of the channel, setting SenderUserId, and does not inspect the
+// session descriptions or candidates. A call goes:
+//
+//  1. The caller sends a CallOffer with a new call ID from NewCallId and its SDP offer.
+//  2. The callee sends a CallAnswer with its SDP answer, or a CallEnded with CALL_END_REASON_DECLINED or
+//     CALL_END_REASON_BUSY.
+//  3. Both participants send a CallIceCandidate for each local candidate as it is gathered, which may start before the
+//     answer arrives.
+//  4. Either participant sends a CallEnded to hang up. The server sends a CallEnded with CALL_END_REASON_MISSED to both
+//     participants if an offer is not answered in time.
+//
+// Calls start with audio and, if the offer sets Video, video. A participant upgrades a voice call to video by sending a
+// CallOffer with the ID of the active call, Video set and an SDP offer adding the video track. Such an offer renegotiates
+// the call rather than ringing, and is answered with a CallAnswer as usual; an answer without Video accepts the upgrade
+// without sending video back. Either participant may renegotiate, and participants turn their camera and microphone on
+// and off without renegotiating by sending a CallMediaStateChanged.
+//
+// A participant shares their screen by sending a CallScreenShareStarted describing the stream, then a CallOffer adding
+// the stream's video track. The announcement comes first so the other participant can tell the screen from the camera
+// when the track arrives. To stop, the participant sends a CallScreenShareStopped and renegotiates without the track.
+
+// Describes why a call ended.
+type CallEndReason string
+
+const (
+	// A participant hung up.
+	CALL_END_REASON_HANGUP CallEndReason = "hangup"
+	// The callee declined the call.
+	CALL_END_REASON_DECLINED CallEndReason = "declined"
+	// The callee is already in another call.
+	CALL_END_REASON_BUSY CallEndReason = "busy"
+	// The callee did not answer in time. Sent by the server.
+	CALL_END_REASON_MISSED CallEndReason = "missed"
+	// The connection could not be established or was lost.
+	CALL_END_REASON_FAILED CallEndReason = "failed"
+)
+
+// IsValid returns true if the reason is one of the defined reasons.
+func (r CallEndReason) IsValid() bool {
+	switch r {
+	case CALL_END_REASON_HANGUP, CALL_END_REASON_DECLINED, CALL_END_REASON_BUSY, CALL_END_REASON_MISSED, CALL_END_REASON_FAILED:
+		return true
+	default:
+		return false
+	}
+}
+
+// Represents an invitation to a call, or a renegotiation of the media of an active call such as an upgrade to video.
+// Sent with the FEED_MESSAGE_TYPE_CALL_OFFER message type.
+type CallOffer struct {
+	// The ID of the call, generated by the caller with NewCallId.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The SDP offer of the caller.
+	Sdp string `json:"sdp"`
+	// If true the offer includes a video track as well as audio.
+	Video bool `json:"video,omitempty"`
+}
+
+// Represents the acceptance of a call or of a renegotiation. Sent with the FEED_MESSAGE_TYPE_CALL_ANSWER message type.
+type CallAnswer struct {
+	// The ID of the call being answered.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The SDP answer of the callee.
+	Sdp string `json:"sdp"`
+	// If true the answer includes a video track of the callee. An answer without video to an offer with video still
+	// receives the video of the other participant.
+	Video bool `json:"video,omitempty"`
+}
+
+// Represents an ICE candidate gathered by a participant. Sent with the FEED_MESSAGE_TYPE_CALL_ICE_CANDIDATE message type.
+// The fields match the RTCIceCandidateInit dictionary of WebRTC.
+type CallIceCandidate struct {
+	// The ID of the call.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The candidate attribute. An empty candidate signals the end of candidates.
+	Candidate string `json:"candidate"`
+	// The media stream identification tag of the media the candidate belongs to.
+	SdpMid string `json:"sdp_mid,omitempty"`
+	// The index of the media description the candidate belongs to. Will be nil if SdpMid is set instead.
+	SdpMLineIndex *uint16 `json:"sdp_m_line_index,omitempty"`
+	// The ICE username fragment the candidate belongs to.
+	UsernameFragment string `json:"username_fragment,omitempty"`
+}
+
+// Represents the end of a call, or the refusal of an offer. Sent with the FEED_MESSAGE_TYPE_CALL_ENDED message type.
+type CallEnded struct {
+	// The ID of the call that ended.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying and empty when sent by the server itself;
+	// ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// Why the call ended.
+	Reason CallEndReason `json:"reason"`
+}
+
+// Represents a change to whether a participant is sending video or audio during a call. Sent with the
+// FEED_MESSAGE_TYPE_CALL_MEDIA_STATE_CHANGED message type. Turning the camera on in a call without video requires a
+// renegotiation first; see CallOffer.
+type CallMediaStateChanged struct {
+	// The ID of the call.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// If true the participant's camera is on. Clients should show a placeholder for the participant while it is off.
+	CameraOn bool `json:"camera_on"`
+	// If true the participant's microphone is muted.
+	Muted bool `json:"muted"`
+}
+
+// Describes what a screen share shows.
+type ScreenShareSource string
+
+const (
+	// A whole display.
+	SCREEN_SHARE_SOURCE_SCREEN ScreenShareSource = "screen"
+	// A single application window.
+	SCREEN_SHARE_SOURCE_WINDOW ScreenShareSource = "window"
+	// A single browser tab.
+	SCREEN_SHARE_SOURCE_TAB ScreenShareSource = "tab"
+)
+
+// IsValid returns true if the source is one of the defined sources.
+func (s ScreenShareSource) IsValid() bool {
+	return s == SCREEN_SHARE_SOURCE_SCREEN || s == SCREEN_SHARE_SOURCE_WINDOW || s == SCREEN_SHARE_SOURCE_TAB
+}
+
+// Represents a participant starting to share their screen during a call. Sent with the
+// FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STARTED message type.
+type CallScreenShareStarted struct {
+	// The ID of the call.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The ID of the WebRTC media stream carrying the screen, as given in the msid attribute of the SDP.
+	StreamId string `json:"stream_id"`
+	// What is being shared. May be empty if the client cannot tell.
+	Source ScreenShareSource `json:"source,omitempty"`
+	// A name for what is being shared, such as the title of the window. May be empty.
+	Label string `json:"label,omitempty"`
+	// The width of the shared video in pixels. Zero if unknown.
+	Width int `json:"width,omitempty"`
+	// The height of the shared video in pixels. Zero if unknown.
+	Height int `json:"height,omitempty"`
+	// The frame rate of the shared video in frames per second. Zero if unknown.
+	FrameRate int `json:"frame_rate,omitempty"`
+	// If true the stream carries the audio of what is being shared as well as the video.
+	Audio bool `json:"audio,omitempty"`
+}
+
+// Represents a participant no longer sharing their screen. Sent with the FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STOPPED
+// message type. Screen shares also stop when the call ends.
+type CallScreenShareStopped struct {
+	// The ID of the call.
+	CallId string `json:"call_id"`
+	// The ID of the direct message channel the call is in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the signal. Set by the server when relaying; ignored when sending.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The ID of the media stream that carried the screen.
+	StreamId string `json:"stream_id"`
+}
+
+// NewCallId generates a random ID for a new call.
+func NewCallId() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
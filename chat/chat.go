@@ -1,8 +1,22 @@
 package chat
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // A ChatMessage represents a text message sent in to chat channel.
+//
+// The time a message was sent has historically been serialized under the misspelled "recieved_at_utc" key. The key is
+// being migrated to "received_at_utc":
+//
+//  1. Now: messages are written with both keys and read from either, preferring "received_at_utc". Servers and clients
+//     can be upgraded in any order.
+//  2. Once no supported client reads "recieved_at_utc", the key is no longer written.
+//  3. The deprecated RecievedAtUtc field is removed.
+//
+// Types which embed ChatMessage must implement json.Marshaler and json.Unmarshaler themselves, as ExportedMessage does,
+// since the methods of ChatMessage would otherwise be promoted and drop their other fields.
 type ChatMessage struct {
 	// The Id of the message.
 	Id string `json:"id"`
@@ -12,8 +26,168 @@ type ChatMessage struct {
 	SenderUserId string `json:"sender_user_id"`
 	// The content of the message.
 	Content string `json:"content"`
+	// The type of the message content. An empty value should be treated as MESSAGE_CONTENT_TYPE_TEXT.
+	ContentType MessageContentType `json:"content_type,omitempty"`
+	// The structured embed content. Only populated when the content type is MESSAGE_CONTENT_TYPE_EMBED.
+	Embed *Embed `json:"embed,omitempty"`
 	// The time that the message was sent.
+	ReceivedAtUtc time.Time `json:"received_at_utc"`
+	// The time that the message was sent, under the misspelled key.
+	//
+	// Deprecated: Use ReceivedAtUtc or ReceivedAt. Kept in sync with ReceivedAtUtc when encoding and decoding JSON.
 	RecievedAtUtc time.Time `json:"recieved_at_utc"`
+	// A reference to the message that this message is a reply to. Will be nil if the message is not a reply.
+	ReplyTo *MessageReference `json:"reply_to,omitempty"`
+	// Previews of the URLs contained in the message. Populated asynchronously by the server after the message is sent.
+	LinkPreviews []LinkPreview `json:"link_previews,omitempty"`
+	// The files attached to the message.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// When the message expires and should no longer be displayed. Will be nil if the message does not expire.
+	ExpiresAtUtc *time.Time `json:"expires_at_utc,omitempty"`
+	// When the message was last edited. Will be nil if the message has never been edited.
+	EditedAtUtc *time.Time `json:"edited_at_utc,omitempty"`
+	// The overall delivery state of the message. Only populated for messages sent by the requesting user.
+	DeliveryState MessageDeliveryState `json:"delivery_state,omitempty"`
+	// The delivery state of the message for each recipient. Only populated for messages sent by the requesting user.
+	RecipientStates []RecipientDeliveryState `json:"recipient_states,omitempty"`
+	// The aggregated reactions on the message.
+	Reactions []ReactionSummary `json:"reactions,omitempty"`
+	// The sender's signature of the message. Will be nil if the message was not signed. See VerifyMessageSignature.
+	Signature *MessageSignature `json:"signature,omitempty"`
+}
+
+// chatMessageJSON has the fields of ChatMessage without its methods, so it can be encoded without recursion.
+type chatMessageJSON ChatMessage
+
+// ReceivedAt returns the time that the message was sent, falling back to the deprecated RecievedAtUtc field for messages
+// built by code which only sets the old field.
+func (m ChatMessage) ReceivedAt() time.Time {
+	if m.ReceivedAtUtc.IsZero() {
+		return m.RecievedAtUtc
+	}
+
+	return m.ReceivedAtUtc
+}
+
+// syncReceivedAt sets both sent time fields to the sent time.
+func (m *ChatMessage) syncReceivedAt() {
+	m.ReceivedAtUtc = m.ReceivedAt()
+	m.RecievedAtUtc = m.ReceivedAtUtc
+}
+
+// MarshalJSON implements the json.Marshaler interface, writing the sent time under both keys.
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	m.syncReceivedAt()
+	return json.Marshal(chatMessageJSON(m))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, reading the sent time from either key.
+func (m *ChatMessage) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*chatMessageJSON)(m)); err != nil {
+		return err
+	}
+
+	m.syncReceivedAt()
+
+	return nil
+}
+
+// IsEdited returns true if the message has been edited since it was sent.
+func (m ChatMessage) IsEdited() bool {
+	return m.EditedAtUtc != nil
+}
+
+// IsExpired returns true if the message is an expiring message and its expiry time has passed.
+func (m ChatMessage) IsExpired(now time.Time) bool {
+	return m.ExpiresAtUtc != nil && !now.Before(*m.ExpiresAtUtc)
+}
+
+// A LinkPreview represents the unfurled metadata of a URL contained in a chat message.
+type LinkPreview struct {
+	// The URL that was unfurled.
+	Url string `json:"url"`
+	// The title of the linked page.
+	Title string `json:"title"`
+	// A short description of the linked page.
+	Description string `json:"description"`
+	// The URL of the preview image for the linked page. May be empty.
+	ImageUrl string `json:"image_url"`
+	// The name of the site the linked page belongs to. May be empty.
+	SiteName string `json:"site_name"`
+}
+
+type EditMessageRequest struct {
+	// The new content of the message.
+	Content string `json:"content"`
+}
+
+// A MessageSearchResult is a single message matched by a message search.
+type MessageSearchResult struct {
+	// The matched message.
+	Message ChatMessage `json:"message"`
+	// The ranges of the message content which matched the search query.
+	Highlights []HighlightRange `json:"highlights"`
+}
+
+// A HighlightRange identifies a matched section of message content. Offsets are byte offsets into the content.
+type HighlightRange struct {
+	// The offset of the first byte of the match.
+	Start int `json:"start"`
+	// The offset of the byte following the last byte of the match.
+	End int `json:"end"`
+}
+
+// A ReactionSummary is the aggregated count of a single reaction on a message.
+type ReactionSummary struct {
+	// The reaction emoji. Either a unicode emoji or a custom emoji shortcode.
+	Emoji string `json:"emoji"`
+	// The number of users that reacted with the emoji.
+	Count int `json:"count"`
+	// The IDs of the users that reacted with the emoji.
+	UserIds []string `json:"user_ids"`
+	// True if the requesting user is one of the users that reacted with the emoji.
+	ReactedByMe bool `json:"reacted_by_me"`
+}
+
+type AddReactionRequest struct {
+	// The reaction emoji. Either a unicode emoji or a custom emoji shortcode.
+	Emoji string `json:"emoji"`
+}
+
+// A MessageRevision is a previous version of an edited message.
+type MessageRevision struct {
+	// The ID of the message the revision belongs to.
+	MessageId string `json:"message_id"`
+	// The content of the message before the edit was made.
+	Content string `json:"content"`
+	// The ID of the user that made the edit which replaced this revision.
+	EditedByUserId string `json:"edited_by_user_id"`
+	// When this revision was replaced.
+	RevisedAtUtc time.Time `json:"revised_at_utc"`
+}
+
+type MuteChannelRequest struct {
+	// When the mute should end. Leave nil to mute the channel indefinitely.
+	MutedUntilUtc *time.Time `json:"muted_until_utc,omitempty"`
+}
+
+// A MessageDraft is a partially written message saved against a channel so it can be resumed on another device.
+type MessageDraft struct {
+	// The ID of the channel that the draft belongs to.
+	ChannelId string `json:"channel_id"`
+	// The content of the draft.
+	Content string `json:"content"`
+	// The ID of the message the draft is replying to. Empty if the draft is not a reply.
+	ReplyToMessageId string `json:"reply_to_message_id,omitempty"`
+	// When the draft was last saved.
+	UpdatedAtUtc time.Time `json:"updated_at_utc"`
+}
+
+type SaveMessageDraftRequest struct {
+	// The content of the draft.
+	Content string `json:"content"`
+	// The ID of the message the draft is replying to. Leave empty if the draft is not a reply.
+	ReplyToMessageId string `json:"reply_to_message_id,omitempty"`
 }
 
 type UserRelationship struct {
@@ -27,8 +201,41 @@ type UserRelationship struct {
 	Username string `json:"username"`
 	// When the user was last online
 	LastOnlineUtc time.Time `json:"last_online_utc"`
-	// IsOnline is true if the user is online
-	IsOnline bool `json:"is_online"`
+	// The presence state of the user. Will be empty if the user has hidden their presence.
+	Presence PresenceState `json:"presence,omitempty"`
+	// The custom status of the user. Will be nil if the user has not set a status.
+	Status *UserStatus `json:"status,omitempty"`
+}
+
+// userRelationshipJSON is a UserRelationship without its JSON methods.
+type userRelationshipJSON UserRelationship
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Older servers send the is_online flag instead of the
+// presence, so the flag is decoded into Presence when the presence is absent.
+func (r *UserRelationship) UnmarshalJSON(data []byte) error {
+	decoded := struct {
+		*userRelationshipJSON
+		IsOnline *bool `json:"is_online"`
+	}{userRelationshipJSON: (*userRelationshipJSON)(r)}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if r.Presence == "" && decoded.IsOnline != nil {
+		r.Presence = PRESENCE_STATE_OFFLINE
+
+		if *decoded.IsOnline {
+			r.Presence = PRESENCE_STATE_ONLINE
+		}
+	}
+
+	return nil
+}
+
+// IsOnline returns true if the user the relationship is with is visibly online.
+func (r UserRelationship) IsOnline() bool {
+	return r.Presence.IsOnline()
 }
 
 type User struct {
@@ -44,6 +251,39 @@ type User struct {
 	LastOnlineUtc time.Time `json:"last_online_utc"`
 	// CreatedAtUtc is when the user was created
 	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// The custom status of the user. Will be nil if the user has not set a status.
+	Status *UserStatus `json:"status,omitempty"`
+	// The user's privacy settings
+	PrivacySettings PrivacySettings `json:"privacy_settings"`
+}
+
+// A UserStatus is a custom status message displayed next to a user's name. Example: "🎮 playing Elden Ring"
+type UserStatus struct {
+	// An optional emoji displayed before the status text.
+	Emoji string `json:"emoji"`
+	// The status text.
+	Text string `json:"text"`
+	// When the status should be cleared. Will be nil if the status does not expire.
+	ExpiresAtUtc *time.Time `json:"expires_at_utc,omitempty"`
+}
+
+// IsActive returns true if the status has not expired.
+func (s UserStatus) IsActive(now time.Time) bool {
+	return s.ExpiresAtUtc == nil || now.Before(*s.ExpiresAtUtc)
+}
+
+type SetPresenceRequest struct {
+	// The presence state to set. PRESENCE_STATE_OFFLINE cannot be set explicitly; use PRESENCE_STATE_INVISIBLE instead.
+	Presence PresenceState `json:"presence"`
+}
+
+type SetStatusRequest struct {
+	// An optional emoji displayed before the status text.
+	Emoji string `json:"emoji"`
+	// The status text.
+	Text string `json:"text"`
+	// When the status should be cleared. Leave nil if the status should not expire.
+	ExpiresAtUtc *time.Time `json:"expires_at_utc,omitempty"`
 }
 
 type UserInfo struct {
@@ -53,6 +293,13 @@ type UserInfo struct {
 	Username string `json:"username"`
 	// When the user was last online
 	LastOnlineUtc time.Time `json:"last_online_utc"`
+	// The presence state of the user. Will be empty if unknown or the user has hidden their presence.
+	Presence PresenceState `json:"presence,omitempty"`
+}
+
+type GetUsersByIdsRequest struct {
+	// The IDs of the users to resolve.
+	UserIds []string `json:"user_ids"`
 }
 
 // A Channel represents a communication channel between two or more users.
@@ -61,8 +308,44 @@ type Channel struct {
 	Id string `json:"id"`
 	// The type of the channel.
 	Type ChannelType `json:"type"`
+	// The display name of the channel. Only used by group direct message channels and may be empty.
+	Name string `json:"name,omitempty"`
 	// The users that are members of the channel. This is a list of user info.
 	Users []UserInfo `json:"users"`
+	// IsArchived is true if the channel is read-only. Example: a direct message channel between users who are no longer friends.
+	IsArchived bool `json:"is_archived"`
+	// The posting mode of the channel. An empty mode is treated as CHANNEL_MODE_DEFAULT.
+	Mode ChannelMode `json:"mode,omitempty"`
+}
+
+// An UnreadState describes the unread messages in a channel for the requesting user.
+type UnreadState struct {
+	// The ID of the channel.
+	ChannelId string `json:"channel_id"`
+	// The number of messages the user has not read.
+	UnreadCount uint64 `json:"unread_count"`
+	// The number of unread messages which mention the user.
+	MentionCount uint64 `json:"mention_count"`
+	// The ID of the last message the user has read. Empty if the user has never read the channel.
+	LastReadMessageId string `json:"last_read_message_id"`
+	// When the most recent message in the channel was sent.
+	LastMessageAtUtc time.Time `json:"last_message_at_utc"`
+}
+
+// A ReadPointer marks how far the user has read a channel. It is kept by the server so every device the user logs into
+// shows the "new messages" divider in the same place; see FirstUnreadIndex.
+type ReadPointer struct {
+	// The ID of the channel.
+	ChannelId string `json:"channel_id"`
+	// The ID of the last message the user has read. Empty if the user has never read the channel.
+	LastReadMessageId string `json:"last_read_message_id"`
+	// When the pointer was last moved. Will be nil if the user has never read the channel.
+	UpdatedAtUtc *time.Time `json:"updated_at_utc,omitempty"`
+}
+
+type MarkChannelReadRequest struct {
+	// The ID of the last message that has been read. Leave empty to mark the whole channel as read.
+	LastReadMessageId string `json:"last_read_message_id,omitempty"`
 }
 
 type Room struct {
@@ -74,10 +357,40 @@ type Room struct {
 	ChannelId string `json:"channel_id"`
 	// ID of the user who owns the room
 	Owner UserInfo `json:"owner"`
+	// The users who moderate the room
+	Moderators []UserInfo `json:"moderators"`
 	// Membership Model
 	MembershipModel RoomMembershipModel `json:"membership_model"`
 	// CreatedAtUtc is when the room was created
 	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// The custom emoji available in the room
+	CustomEmojis []CustomEmoji `json:"custom_emojis"`
+	// If true, messages sent in the room are passed through the content filter.
+	ContentFilterEnabled bool `json:"content_filter_enabled"`
+	// If true, a password is required to join the room.
+	IsPasswordProtected bool `json:"is_password_protected"`
+	// The maximum number of members the room can have, including the owner. Zero means the default capacity of MAX_ROOM_MEMBERS.
+	MaxMembers uint64 `json:"max_members"`
+	// The number of members the room currently has, including the owner.
+	MemberCount uint64 `json:"member_count"`
+	// Tags describing the interests of the room. Used to filter room discovery.
+	Tags []string `json:"tags"`
+	// The IDs of the voice channels of the room.
+	VoiceChannelIds []string `json:"voice_channel_ids,omitempty"`
+}
+
+// Capacity returns the effective maximum number of members of the room.
+func (r Room) Capacity() uint64 {
+	if r.MaxMembers == 0 || r.MaxMembers > MAX_ROOM_MEMBERS {
+		return MAX_ROOM_MEMBERS
+	}
+
+	return r.MaxMembers
+}
+
+// IsFull returns true if the room has reached its member capacity.
+func (r Room) IsFull() bool {
+	return r.MemberCount >= r.Capacity()
 }
 
 type CreateRoomRequest struct {
@@ -85,6 +398,160 @@ type CreateRoomRequest struct {
 	Name string `json:"name"`
 	// The membership model that the room uses
 	MembershipModel string `json:"membership_model"`
+	// If true, messages sent in the room are passed through the content filter.
+	ContentFilterEnabled bool `json:"content_filter_enabled"`
+	// An optional password required to join the room. The server only ever stores a hash of the password.
+	JoinPassword string `json:"join_password,omitempty"`
+	// The maximum number of members the room can have, including the owner. Leave as zero for the default capacity.
+	MaxMembers uint64 `json:"max_members,omitempty"`
+	// Tags describing the interests of the room.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// UpdateRoomRequest is a partial update of a room. Nil fields are omitted from the request body and left unchanged.
+type UpdateRoomRequest struct {
+	// The new name of the room
+	Name *string `json:"name,omitempty"`
+	// The new membership model of the room
+	MembershipModel *string `json:"membership_model,omitempty"`
+	// Enables or disables the content filter for the room
+	ContentFilterEnabled *bool `json:"content_filter_enabled,omitempty"`
+	// The new join password of the room. An empty password removes the password protection.
+	JoinPassword *string `json:"join_password,omitempty"`
+	// The new member capacity of the room. Cannot be lowered below the current member count.
+	MaxMembers *uint64 `json:"max_members,omitempty"`
+	// The new tags of the room. Replaces all existing tags; an empty list removes all tags.
+	Tags *[]string `json:"tags,omitempty"`
+	// The new posting mode of the room's channel.
+	ChannelMode *ChannelMode `json:"channel_mode,omitempty"`
+}
+
+// UpdateRoomOption is a type for the options that can be passed to NewUpdateRoomRequest.
+type UpdateRoomOption func(*UpdateRoomRequest)
+
+// An option which renames the room.
+func UpdateRoomOption_Name(name string) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		r.Name = &name
+	}
+}
+
+// An option which changes the membership model of the room.
+func UpdateRoomOption_MembershipModel(model RoomMembershipModel) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		value := string(model)
+		r.MembershipModel = &value
+	}
+}
+
+// An option which enables or disables the content filter for the room.
+func UpdateRoomOption_ContentFilterEnabled(enabled bool) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		r.ContentFilterEnabled = &enabled
+	}
+}
+
+// An option which sets the join password of the room. An empty password removes the password protection.
+func UpdateRoomOption_JoinPassword(password string) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		r.JoinPassword = &password
+	}
+}
+
+// An option which changes the member capacity of the room.
+func UpdateRoomOption_MaxMembers(maxMembers uint64) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		r.MaxMembers = &maxMembers
+	}
+}
+
+// An option which replaces the tags of the room.
+func UpdateRoomOption_Tags(tags ...string) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		if tags == nil {
+			tags = make([]string, 0)
+		}
+
+		r.Tags = &tags
+	}
+}
+
+// An option which changes the posting mode of the room's channel. Example: CHANNEL_MODE_ANNOUNCEMENT
+func UpdateRoomOption_ChannelMode(mode ChannelMode) UpdateRoomOption {
+	return func(r *UpdateRoomRequest) {
+		r.ChannelMode = &mode
+	}
+}
+
+// Creates a new UpdateRoomRequest which only changes the fields set by the given options.
+func NewUpdateRoomRequest(options ...UpdateRoomOption) UpdateRoomRequest {
+	var request UpdateRoomRequest
+
+	for _, opt := range options {
+		opt(&request)
+	}
+
+	return request
+}
+
+type JoinRoomRequest struct {
+	// The password of a password protected room.
+	Password string `json:"password,omitempty"`
+}
+
+// A RoomJoinRequest is a request by a user to join a non-public room.
+type RoomJoinRequest struct {
+	// The Id of the join request.
+	Id string `json:"id"`
+	// The ID of the room the user wants to join.
+	RoomId string `json:"room_id"`
+	// The user that wants to join the room.
+	User UserInfo `json:"user"`
+	// An optional message from the user to the room owner.
+	Message string `json:"message"`
+	// The status of the join request.
+	Status JoinRequestStatus `json:"status"`
+	// CreatedAtUtc is when the join request was made.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+type RequestToJoinRoomRequest struct {
+	// An optional message to the room owner.
+	Message string `json:"message,omitempty"`
+}
+
+// An AuditEntry records a single administrative change to a room.
+type AuditEntry struct {
+	// The Id of the audit entry.
+	Id string `json:"id"`
+	// The ID of the room the change was made to.
+	RoomId string `json:"room_id"`
+	// The action that was performed.
+	Action AuditAction `json:"action"`
+	// The user that performed the action.
+	Actor UserInfo `json:"actor"`
+	// The user the action was performed on. Will be nil if the action did not target a user.
+	Target *UserInfo `json:"target,omitempty"`
+	// The value before the change. Example: the previous room name. May be empty.
+	OldValue string `json:"old_value,omitempty"`
+	// The value after the change. Example: the new room name. May be empty.
+	NewValue string `json:"new_value,omitempty"`
+	// The reason given for the action. May be empty.
+	Reason string `json:"reason,omitempty"`
+	// CreatedAtUtc is when the action was performed.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+type KickUserFromRoomRequest struct {
+	// The reason the user is being kicked. Shared with the kicked user and the other room members.
+	Reason string `json:"reason,omitempty"`
+}
+
+type CreateGroupDirectMessageRequest struct {
+	// The IDs of the users to include in the group. The requesting user is included automatically.
+	UserIds []string `json:"user_ids"`
+	// An optional display name for the group.
+	Name string `json:"name,omitempty"`
 }
 
 type InviteUserToRoomRequest struct {
@@ -99,6 +566,35 @@ type AcceptRoomInviteRequest struct {
 	RoomId string `json:"room_id"`
 }
 
+// A Report is an abuse report filed against a user or message.
+type Report struct {
+	// The Id of the report.
+	Id string `json:"id"`
+	// The ID of the user that filed the report.
+	ReporterUserId string `json:"reporter_user_id"`
+	// The ID of the user being reported. For message reports this is the author of the message.
+	ReportedUserId string `json:"reported_user_id"`
+	// The ID of the channel the reported message was sent in. Empty for user reports.
+	ChannelId string `json:"channel_id,omitempty"`
+	// The ID of the reported message. Empty for user reports.
+	MessageId string `json:"message_id,omitempty"`
+	// The reason for the report.
+	Reason ReportReason `json:"reason"`
+	// Additional details provided by the reporter.
+	Details string `json:"details"`
+	// The review status of the report.
+	Status ReportStatus `json:"status"`
+	// CreatedAtUtc is when the report was filed.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+type ReportRequest struct {
+	// The reason for the report.
+	Reason ReportReason `json:"reason"`
+	// Additional details describing the problem. Required when the reason is REPORT_REASON_OTHER.
+	Details string `json:"details,omitempty"`
+}
+
 type SendFriendRequestRequest struct {
 	// The ID of the user that the friend request is being sent to.
 	RequestedUserId string `json:"requested_user_id"`
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUserRelationship_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want PresenceState
+	}{
+		{name: "presence", data: `{"user_id":"u","presence":"away"}`, want: PRESENCE_STATE_AWAY},
+		{name: "presence and is_online", data: `{"user_id":"u","presence":"do_not_disturb","is_online":false}`, want: PRESENCE_STATE_DO_NOT_DISTURB},
+		{name: "online on an older server", data: `{"user_id":"u","is_online":true}`, want: PRESENCE_STATE_ONLINE},
+		{name: "offline on an older server", data: `{"user_id":"u","is_online":false}`, want: PRESENCE_STATE_OFFLINE},
+		{name: "hidden", data: `{"user_id":"u"}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r UserRelationship
+
+			if err := json.Unmarshal([]byte(tt.data), &r); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if r.UserId != "u" {
+				t.Errorf("UserId = %q, want %q", r.UserId, "u")
+			}
+
+			if r.Presence != tt.want {
+				t.Errorf("Presence = %q, want %q", r.Presence, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserRelationship_MarshalJSON_OmitsHiddenPresence(t *testing.T) {
+	data, err := json.Marshal(UserRelationship{UserId: "u"})
+
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if _, ok := fields["presence"]; ok {
+		t.Errorf("hidden presence was encoded: %s", data)
+	}
+}
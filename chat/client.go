@@ -2,13 +2,19 @@ package chat
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // BroChatClientResult is the result of a requsted operation to the BroChat API via the BroChatClient.
@@ -17,6 +23,13 @@ type BroChatClientResult struct {
 	ResponseCode BroChatResponseCode `json:"response_code"`
 	// Error details. Will be empty if the response code is a success code.
 	ErrorDetails []string `json:"error_details"`
+	// The ID the server assigned to a failed request. Include it when reporting errors. May be empty.
+	RequestId string `json:"request_id,omitempty"`
+	// How long the server asked the client to wait before retrying a failed request, from the Retry-After header.
+	// Zero if the server did not say.
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+	// The rate limit of a failed request, from the X-RateLimit-* headers.
+	RateLimit RateLimit `json:"rate_limit"`
 }
 
 // makeBroChatClientResult creates a BroChatClientResult with the given code and message.
@@ -48,6 +61,14 @@ func (c BroChatClientResult) Err() error {
 		return fmt.Errorf("data conflict")
 	case BROCHAT_RESPONSE_CODE_INVALID_OPERATION:
 		return fmt.Errorf("invalid operation")
+	case BROCHAT_RESPONSE_CODE_SPAM_DETECTED_ERROR:
+		return fmt.Errorf("message rejected as spam")
+	case BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR:
+		return fmt.Errorf("invalid room password")
+	case BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR:
+		return fmt.Errorf("room is full")
+	case BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR:
+		return fmt.Errorf("rate limited")
 	case BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS:
 		return fmt.Errorf("invalid host address")
 	case BROCHAT_RESPONSE_CODE_CONNECTION_TIMEOUT_ERROR:
@@ -101,6 +122,14 @@ const (
 	BROCHAT_RESPONSE_CODE_INVALID_OPERATION
 	// Indicates an unauthorized operation error. This means the user is not authorized to perform the requested operation.
 	BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR
+	// Indicates the message was rejected by the server's spam detection.
+	BROCHAT_RESPONSE_CODE_SPAM_DETECTED_ERROR
+	// Indicates the password supplied when joining a password protected room was wrong or missing.
+	BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR
+	// Indicates the room has reached its member capacity and cannot be joined.
+	BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR
+	// Indicates the client has sent too many requests and must wait before retrying.
+	BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR
 )
 
 // Client side error codes
@@ -129,16 +158,75 @@ const (
 
 // BroChatClient is a client for the BroChat API.
 type BroChatClient struct {
-	httpClient *http.Client
-	baseUrl    string
+	httpClient      *http.Client
+	baseUrl         string
+	codec           Codec
+	responseDecoder ResponseDecoder
+	transport       TransportConfig
 }
 
-// NewBroChatClient creates a new BroChatClient with the given http client and base url.
-func NewBroChatClient(httpClient *http.Client, baseUrl string) *BroChatClient {
-	return &BroChatClient{
-		httpClient: httpClient,
-		baseUrl:    baseUrl,
+// BroChatClientOption is a type for the options that can be passed to NewBroChatClient.
+type BroChatClientOption func(*BroChatClient)
+
+// Sets the codec used to encode request bodies and decode response bodies. Defaults to StdCodec.
+func BroChatClientOption_Codec(codec Codec) BroChatClientOption {
+	return func(c *BroChatClient) {
+		c.codec = codec
+	}
+}
+
+// Sets the decoder of successful response bodies, such as to unwrap responses from deployments which wrap them in an
+// envelope. Defaults to DefaultResponseDecoder.
+func BroChatClientOption_ResponseDecoder(decoder ResponseDecoder) BroChatClientOption {
+	return func(c *BroChatClient) {
+		c.responseDecoder = decoder
+	}
+}
+
+// Sets the number of idle connections kept open to the API host. Defaults to DEFAULT_MAX_IDLE_CONNS_PER_HOST.
+// Only applies when the http client has no transport of its own, see NewBroChatClient, or to the transport of a ClientFactory.
+func BroChatClientOption_MaxIdleConnsPerHost(maxIdleConnsPerHost int) BroChatClientOption {
+	return func(c *BroChatClient) {
+		c.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+}
+
+// Sets how long an idle connection is kept open. Defaults to DEFAULT_IDLE_CONN_TIMEOUT.
+// Only applies when the http client has no transport of its own, see NewBroChatClient, or to the transport of a ClientFactory.
+func BroChatClientOption_IdleConnTimeout(idleConnTimeout time.Duration) BroChatClientOption {
+	return func(c *BroChatClient) {
+		c.transport.IdleConnTimeout = idleConnTimeout
+	}
+}
+
+// Sets the number of TLS sessions cached for resumption. Zero disables session resumption. Defaults to DEFAULT_TLS_SESSION_CACHE_SIZE.
+// Only applies when the http client has no transport of its own, see NewBroChatClient, or to the transport of a ClientFactory.
+func BroChatClientOption_TLSSessionCacheSize(size int) BroChatClientOption {
+	return func(c *BroChatClient) {
+		c.transport.TLSSessionCacheSize = size
+	}
+}
+
+// NewBroChatClient creates a new BroChatClient with the given http client and base url. The base url may include a path
+// the API is served under, such as https://example.com/tenants/acme.
+// If the http client is nil or has no transport of its own, such as http.DefaultClient, the client uses a transport
+// tuned for the API, keeping idle connections open and resuming TLS sessions. The transport is shared by every client
+// created this way with the same transport options, see WithTunedTransport.
+func NewBroChatClient(httpClient *http.Client, baseUrl string, options ...BroChatClientOption) *BroChatClient {
+	client := &BroChatClient{
+		baseUrl:         baseUrl,
+		codec:           StdCodec{},
+		responseDecoder: DefaultResponseDecoder,
+		transport:       DefaultTransportConfig(),
 	}
+
+	for _, opt := range options {
+		opt(client)
+	}
+
+	client.httpClient = WithTunedTransport(httpClient, client.transport)
+
+	return client
 }
 
 // GetUser returns a user by their ID.
@@ -163,254 +251,3749 @@ func (c *BroChatClient) GetUser(accessToken string, userId string) BroChatClient
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, User{})
+		return handleHttpRequestErrorWithContent(err, User{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, User{})
+	}
+
+	var user User
+
+	err = c.decodeResponse(res.Body, &user)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, User{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, user)
+}
+
+// SetStatus sets the user's custom status message. The status is shared with the user's friends.
+func (c *BroChatClient) SetStatus(accessToken string, request SetStatusRequest) BroChatClientContentResult[UserStatus] {
+	url, err := buildUrl(c.baseUrl, USER_STATUS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, UserStatus{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, UserStatus{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, UserStatus{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, UserStatus{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, UserStatus{})
+	}
+
+	var status UserStatus
+
+	err = c.decodeResponse(res.Body, &status)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, UserStatus{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, status)
+}
+
+// ClearStatus removes the user's custom status message.
+func (c *BroChatClient) ClearStatus(accessToken string) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, USER_STATUS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// SetPresence sets the user's presence state. Setting PRESENCE_STATE_INVISIBLE makes the user appear offline to other users.
+func (c *BroChatClient) SetPresence(accessToken string, request SetPresenceRequest) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, USER_PRESENCE_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetNotificationPreferences returns the user's notification preferences.
+func (c *BroChatClient) GetNotificationPreferences(accessToken string) BroChatClientContentResult[NotificationPreferences] {
+	url, err := buildUrl(c.baseUrl, NOTIFICATION_PREFERENCES_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, NotificationPreferences{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, NotificationPreferences{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, NotificationPreferences{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, NotificationPreferences{})
+	}
+
+	var preferences NotificationPreferences
+
+	err = c.decodeResponse(res.Body, &preferences)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, NotificationPreferences{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, preferences)
+}
+
+// UpdateNotificationPreferences replaces the user's notification preferences.
+// The updated preferences are returned as the content of the result.
+func (c *BroChatClient) UpdateNotificationPreferences(accessToken string, preferences NotificationPreferences) BroChatClientContentResult[NotificationPreferences] {
+	url, err := buildUrl(c.baseUrl, NOTIFICATION_PREFERENCES_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, NotificationPreferences{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, preferences)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, NotificationPreferences{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, NotificationPreferences{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, NotificationPreferences{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, NotificationPreferences{})
+	}
+
+	var updated NotificationPreferences
+
+	err = c.decodeResponse(res.Body, &updated)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, NotificationPreferences{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, updated)
+}
+
+// GetPrivacySettings returns the user's privacy settings.
+func (c *BroChatClient) GetPrivacySettings(accessToken string) BroChatClientContentResult[PrivacySettings] {
+	url, err := buildUrl(c.baseUrl, PRIVACY_SETTINGS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, PrivacySettings{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, PrivacySettings{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, PrivacySettings{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, PrivacySettings{})
+	}
+
+	var settings PrivacySettings
+
+	err = c.decodeResponse(res.Body, &settings)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, PrivacySettings{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, settings)
+}
+
+// UpdatePrivacySettings replaces the user's privacy settings.
+// The updated settings are returned as the content of the result.
+func (c *BroChatClient) UpdatePrivacySettings(accessToken string, settings PrivacySettings) BroChatClientContentResult[PrivacySettings] {
+	url, err := buildUrl(c.baseUrl, PRIVACY_SETTINGS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, PrivacySettings{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, settings)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, PrivacySettings{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, PrivacySettings{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, PrivacySettings{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, PrivacySettings{})
+	}
+
+	var updated PrivacySettings
+
+	err = c.decodeResponse(res.Body, &updated)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, PrivacySettings{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, updated)
+}
+
+// GetUsersOption is a type for the options that can be passed to the GetUsers method.
+type GetUsersOption func(*option)
+
+// An option for the GetUsers method which will exclude the user making the request from the list of users returned.
+func GetUsersOption_ExcludeSelf() GetUsersOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "exclude-self", value: "true"})
+	}
+}
+
+// An option for the GetUsers method which will exclude the friends of the user making the request from the list of users returned.
+func GetUsersOption_ExcludeFriends() GetUsersOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "exclude-friends", value: "true"})
+	}
+}
+
+// An option for the GetUsers method which will only return users who are visibly online.
+func GetUsersOption_OnlineOnly() GetUsersOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "online-only", value: "true"})
+	}
+}
+
+// An option for the GetUsers method which sets the order of the users returned. An unknown order is rejected with a validation error.
+func GetUsersOption_SortBy(order UserSortOrder) GetUsersOption {
+	return func(o *option) {
+		if !order.IsValid() {
+			o.errs.add("sort", "unknown sort order %q", order)
+			return
+		}
+
+		o.values = append(o.values, queryParam{key: "sort", value: string(order)})
+	}
+}
+
+// An option for the GetUsers method which will filter the list of users returned by the given username.
+// An empty filter is rejected with a validation error.
+func GetUsersOption_UsernameFilter(value string) GetUsersOption {
+	return func(o *option) {
+		o.setFilter("username-filter", value)
+	}
+}
+
+// Sets the page option. This will determine which page to start the channel message query from.
+func GetUsersOption_Page(page uint64) GetUsersOption {
+	return func(o *option) {
+		o.setPage(page)
+	}
+}
+
+// Sets the pageSize option. This will determine the size of each page. Anything over MAX_PAGE_SIZE is clamped to MAX_PAGE_SIZE and
+// a page size of zero is rejected with a validation error.
+func GetUsersOption_PageSize(pageSize uint64) GetUsersOption {
+	return func(o *option) {
+		o.setPageSize(pageSize)
+	}
+}
+
+// GetUsers returns a list of users.
+func (c *BroChatClient) GetUsers(accessToken string, options ...GetUsersOption) BroChatClientContentResult[[]UserInfo] {
+
+	// Default options
+	opts := option{values: make([]queryParam, 0)}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, make([]UserInfo, 0), opts.errs.Details()...)
+	}
+
+	url, err := buildUrl(c.baseUrl, GET_USERS_URL_SUFFIX, opts.values...)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]UserInfo, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UserInfo, 0))
+	}
+
+	// add authorization header to the req
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]UserInfo, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]UserInfo, 0))
+	}
+
+	var users = make([]UserInfo, 0)
+
+	err = c.decodeResponse(res.Body, &users)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]UserInfo, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+}
+
+// GetUsersByIds resolves many users by their IDs in as few round trips as possible. Duplicate and empty IDs are ignored.
+// IDs that do not match a user are omitted from the result. Requests for more than 200 users are split into multiple batches.
+func (c *BroChatClient) GetUsersByIds(accessToken string, ids []string) BroChatClientContentResult[[]UserInfo] {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	users := make([]UserInfo, 0, len(unique))
+
+	for start := 0; start < len(unique); start += MAX_USERS_PER_LOOKUP {
+		end := min(start+MAX_USERS_PER_LOOKUP, len(unique))
+
+		result := c.getUsersByIdsBatch(accessToken, unique[start:end])
+
+		if result.Err() != nil {
+			return makeBroChatClientContentResult(result.ResponseCode, make([]UserInfo, 0), result.ErrorDetails...)
+		}
+
+		users = append(users, result.Content...)
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+}
+
+// getUsersByIdsBatch resolves a single batch of user IDs.
+func (c *BroChatClient) getUsersByIdsBatch(accessToken string, ids []string) BroChatClientContentResult[[]UserInfo] {
+	url, err := buildUrl(c.baseUrl, GET_USERS_BY_IDS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]UserInfo, 0))
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, GetUsersByIdsRequest{UserIds: ids})
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UserInfo, 0))
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UserInfo, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]UserInfo, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]UserInfo, 0))
+	}
+
+	var users = make([]UserInfo, 0)
+
+	err = c.decodeResponse(res.Body, &users)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]UserInfo, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+}
+
+// GetChannel returns a channel by its ID.
+func (c *BroChatClient) GetChannel(accessToken string, channelId string) BroChatClientContentResult[Channel] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(GET_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Channel{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	// add authorization header to the req
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Channel{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Channel{})
+	}
+
+	var channel Channel
+
+	err = c.decodeResponse(res.Body, &channel)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Channel{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channel)
+}
+
+// GetDirectMessageChannel returns the direct message channel between the requesting user and the given user.
+// The channel is created if it does not already exist, so the request is idempotent.
+func (c *BroChatClient) GetDirectMessageChannel(accessToken string, userId string) BroChatClientContentResult[Channel] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(GET_DIRECT_MESSAGE_CHANNEL_URL_SUFFIX, ":userId", userId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Channel{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPut, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Channel{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Channel{})
+	}
+
+	var channel Channel
+
+	err = c.decodeResponse(res.Body, &channel)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Channel{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channel)
+}
+
+// CreateGroupDirectMessage creates a group direct message channel between the requesting user and the given users.
+// Note: A group direct message cannot have more than 10 participants including the creator.
+func (c *BroChatClient) CreateGroupDirectMessage(accessToken string, request CreateGroupDirectMessageRequest) BroChatClientContentResult[Channel] {
+	url, err := buildUrl(c.baseUrl, CREATE_GROUP_DIRECT_MESSAGE_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Channel{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Channel{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Channel{})
+	}
+
+	var channel Channel
+
+	err = c.decodeResponse(res.Body, &channel)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Channel{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channel)
+}
+
+// AddGroupDirectMessageParticipant adds a user to a group direct message channel. Any participant may add users.
+func (c *BroChatClient) AddGroupDirectMessageParticipant(accessToken string, channelId string, userId string) BroChatClientResult {
+	suffix := strings.Replace(GROUP_DIRECT_MESSAGE_PARTICIPANT_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":userId", userId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPut, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// RemoveGroupDirectMessageParticipant removes a user from a group direct message channel.
+// Participants may remove themselves to leave the conversation.
+func (c *BroChatClient) RemoveGroupDirectMessageParticipant(accessToken string, channelId string, userId string) BroChatClientResult {
+	suffix := strings.Replace(GROUP_DIRECT_MESSAGE_PARTICIPANT_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":userId", userId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetUnreadCounts returns the unread and mention counts for every channel the user is a member of.
+// Channels with no unread messages may be omitted.
+func (c *BroChatClient) GetUnreadCounts(accessToken string) BroChatClientContentResult[[]UnreadState] {
+	url, err := buildUrl(c.baseUrl, GET_UNREAD_COUNTS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]UnreadState, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UnreadState, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]UnreadState, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]UnreadState, 0))
+	}
+
+	var states = make([]UnreadState, 0)
+
+	err = c.decodeResponse(res.Body, &states)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]UnreadState, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, states)
+}
+
+// GetChannelReadPointer returns how far the user has read a channel.
+func (c *BroChatClient) GetChannelReadPointer(accessToken string, channelId string) BroChatClientContentResult[ReadPointer] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MARK_CHANNEL_READ_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ReadPointer{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ReadPointer{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, ReadPointer{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ReadPointer{})
+	}
+
+	var pointer ReadPointer
+
+	err = c.decodeResponse(res.Body, &pointer)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ReadPointer{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, pointer)
+}
+
+// MarkChannelRead marks the messages in a channel as read up to and including the given message.
+// If the request does not specify a message, every message in the channel is marked as read. The read pointer only
+// moves forward: marking an older message as read has no effect. When it moves, every session of the user receives a
+// FEED_MESSAGE_TYPE_READ_POINTER_UPDATED feed message.
+func (c *BroChatClient) MarkChannelRead(accessToken string, channelId string, request MarkChannelReadRequest) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MARK_CHANNEL_READ_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// MuteChannel suppresses ChatNotification feed messages for a channel. Messages are still delivered when the channel is active.
+func (c *BroChatClient) MuteChannel(accessToken string, channelId string, request MuteChannelRequest) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MUTE_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// UnmuteChannel restores notifications for a previously muted channel.
+func (c *BroChatClient) UnmuteChannel(accessToken string, channelId string) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MUTE_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetChannelMessagesOption is a type for the options that can be passed to the GetChannelMessages method.
+// Example usage: GetChannelMessages_Page(1), GetChannelMessages_PageSize(10)... etc.
+type GetChannelMessagesOption func(*option)
+
+// An option for the GetChannelMessages method which will pull the messages before the given chat message ID.
+func GetChannelMessages_BeforeMessage(value string) GetChannelMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "before-msg", value: value})
+	}
+}
+
+// An option for the GetChannelMessages method which will only pull the messages sent after the given chat message ID.
+// Useful for filling the gap in a channel's history after reconnecting. Pages are still ordered newest first, so keep
+// requesting pages until a short page is returned. May be combined with GetChannelMessages_BeforeMessage.
+func GetChannelMessages_AfterMessage(value string) GetChannelMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "after-msg", value: value})
+	}
+}
+
+// An option for the GetChannelMessages method which will only pull the messages sent at or after from and before to.
+// A zero time leaves that end of the range open. A range which ends before it starts is rejected with a validation error.
+func GetChannelMessages_Between(from time.Time, to time.Time) GetChannelMessagesOption {
+	return func(o *option) {
+		if !from.IsZero() && !to.IsZero() && to.Before(from) {
+			o.errs.add("to", "must not be before from")
+			return
+		}
+
+		if !from.IsZero() {
+			o.values = append(o.values, queryParam{key: "from", value: from.UTC().Format(time.RFC3339Nano)})
+		}
+
+		if !to.IsZero() {
+			o.values = append(o.values, queryParam{key: "to", value: to.UTC().Format(time.RFC3339Nano)})
+		}
+	}
+}
+
+// Sets the page option. This will determine which page to start the channel message query from.
+func GetChannelMessages_Page(page uint64) GetChannelMessagesOption {
+	return func(o *option) {
+		o.setPage(page)
+	}
+}
+
+// Sets the pageSize option. This will determine the size of each page. Anything over MAX_PAGE_SIZE is clamped to MAX_PAGE_SIZE and
+// a page size of zero is rejected with a validation error.
+func GetChannelMessages_PageSize(pageSize uint64) GetChannelMessagesOption {
+	return func(o *option) {
+		o.setPageSize(pageSize)
+	}
+}
+
+// GetChannelMessages returns a list of messages in a channel.
+func (c *BroChatClient) GetChannelMessages(accessToken string, channelId string, options ...GetChannelMessagesOption) BroChatClientContentResult[[]ChatMessage] {
+	return c.getChannelMessages(context.Background(), accessToken, channelId, options...)
+}
+
+// GetChannelMessagesRange returns the messages of pages fromPage to toPage (inclusive) of a channel, with each page
+// holding MAX_PAGE_SIZE messages. Up to parallelism pages are fetched concurrently and the results are stitched together
+// in page order, as if the pages had been fetched one after another. Pages after the end of the history are not requested.
+// Pass math.MaxUint64 as toPage to fetch to the end of the history; pages are only held once they have been fetched.
+// Useful for loading full channel histories for export or search indexing.
+// If any page fails the remaining requests are cancelled and the error of the failed page is returned.
+func (c *BroChatClient) GetChannelMessagesRange(ctx context.Context, accessToken string, channelId string, fromPage uint64, toPage uint64, parallelism int) BroChatClientContentResult[[]ChatMessage] {
+	fromPage = max(fromPage, 1)
+
+	if toPage < fromPage {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, make([]ChatMessage, 0))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		pages       = make(map[uint64][]ChatMessage)
+		pagesMu     sync.Mutex
+		pageNumbers = make(chan uint64)
+		lastPage    atomic.Uint64
+		failure     *BroChatClientResult
+		failureOnce sync.Once
+		wg          sync.WaitGroup
+	)
+
+	lastPage.Store(toPage)
+
+	workers := max(parallelism, 1)
+
+	// No more workers than pages, without computing the page count which overflows for a toPage of math.MaxUint64
+	if span := toPage - fromPage; span < uint64(workers) {
+		workers = int(span) + 1
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for page := range pageNumbers {
+				result := c.getChannelMessages(ctx, accessToken, channelId, GetChannelMessages_Page(page), GetChannelMessages_PageSize(MAX_PAGE_SIZE))
+
+				if result.Err() != nil {
+					failureOnce.Do(func() {
+						failure = &result.BroChatClientResult
+						cancel()
+					})
+
+					continue
+				}
+
+				pagesMu.Lock()
+				pages[page] = result.Content
+				pagesMu.Unlock()
+
+				// A short page is the end of the history, so later pages do not need to be requested.
+				if len(result.Content) < MAX_PAGE_SIZE {
+					for last := lastPage.Load(); page < last && !lastPage.CompareAndSwap(last, page); last = lastPage.Load() {
+					}
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for page := fromPage; page <= lastPage.Load(); page++ {
+		select {
+		case pageNumbers <- page:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		if page == toPage {
+			break
+		}
+	}
+
+	close(pageNumbers)
+	wg.Wait()
+
+	if failure != nil {
+		result := makeBroChatClientContentResult(failure.ResponseCode, make([]ChatMessage, 0), failure.ErrorDetails...)
+		result.RequestId = failure.RequestId
+
+		return result
+	}
+
+	if err := ctx.Err(); err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]ChatMessage, 0))
+	}
+
+	count := 0
+
+	for _, page := range pages {
+		count += len(page)
+	}
+
+	messages := make([]ChatMessage, 0, count)
+
+	// The fetched pages are consecutive from fromPage
+	for page := fromPage; len(pages) > 0; page++ {
+		messages = append(messages, pages[page]...)
+		delete(pages, page)
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, messages)
+}
+
+// getChannelMessages returns a list of messages in a channel. The request is cancelled with the context.
+func (c *BroChatClient) getChannelMessages(ctx context.Context, accessToken string, channelId string, options ...GetChannelMessagesOption) BroChatClientContentResult[[]ChatMessage] {
+	// Default options
+	opts := option{values: make([]queryParam, 0)}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, make([]ChatMessage, 0), opts.errs.Details()...)
+	}
+
+	url, err := buildUrl(c.baseUrl, strings.Replace(GET_CHANNEL_MESSAGES_URL_SUFFIX, ":channelId", channelId, 1), opts.values...)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]ChatMessage, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]ChatMessage, 0))
+	}
+
+	// add authorization header to the req
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]ChatMessage, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]ChatMessage, 0))
+	}
+
+	var channels = make([]ChatMessage, 0)
+
+	err = c.decodeResponse(res.Body, &channels)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]ChatMessage, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channels)
+}
+
+// GetChannelMessage returns a single message from a channel. Useful for resolving the message a reply refers to.
+func (c *BroChatClient) GetChannelMessage(accessToken string, channelId string, messageId string) BroChatClientContentResult[ChatMessage] {
+	suffix := strings.Replace(GET_CHANNEL_MESSAGE_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ChatMessage{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ChatMessage{})
+	}
+
+	// add authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, ChatMessage{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ChatMessage{})
+	}
+
+	var message ChatMessage
+
+	err = c.decodeResponse(res.Body, &message)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ChatMessage{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, message)
+}
+
+// GetReplyReference fetches the message that the given message is replying to and returns a fresh reference to it.
+// Useful when the reply was received without a populated snippet or the original message has since changed.
+func (c *BroChatClient) GetReplyReference(accessToken string, message ChatMessage) BroChatClientContentResult[MessageReference] {
+	if message.ReplyTo == nil || message.ReplyTo.MessageId == "" {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_OPERATION, MessageReference{}, "the message is not a reply")
+	}
+
+	channelId := message.ReplyTo.ChannelId
+
+	if channelId == "" {
+		channelId = message.ChannelId
+	}
+
+	result := c.GetChannelMessage(accessToken, channelId, message.ReplyTo.MessageId)
+
+	if result.Err() != nil {
+		return makeBroChatClientContentResult(result.ResponseCode, MessageReference{}, result.ErrorDetails...)
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, NewMessageReference(result.Content))
+}
+
+// SendChatMessage sends a chat message to a channel without requiring a feed connection. Useful for bots, webhooks and scripts.
+// The created message is returned as the content of the result.
+func (c *BroChatClient) SendChatMessage(accessToken string, request ChatMessageRequest) BroChatClientContentResult[ChatMessage] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(SEND_CHAT_MESSAGE_URL_SUFFIX, ":channelId", request.ChannelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ChatMessage{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ChatMessage{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ChatMessage{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, ChatMessage{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ChatMessage{})
+	}
+
+	var message ChatMessage
+
+	err = c.decodeResponse(res.Body, &message)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ChatMessage{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, message)
+}
+
+// EditMessage replaces the content of a previously sent message. Only the author of the message may edit it.
+// The updated message is returned as the content of the result.
+func (c *BroChatClient) EditMessage(accessToken string, channelId string, messageId string, request EditMessageRequest) BroChatClientContentResult[ChatMessage] {
+	suffix := strings.Replace(EDIT_MESSAGE_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ChatMessage{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ChatMessage{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ChatMessage{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, ChatMessage{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ChatMessage{})
+	}
+
+	var message ChatMessage
+
+	err = c.decodeResponse(res.Body, &message)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ChatMessage{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, message)
+}
+
+// DeleteMessage deletes a previously sent message. The author of the message, the room owner and room moderators may delete a message.
+func (c *BroChatClient) DeleteMessage(accessToken string, channelId string, messageId string) BroChatClientResult {
+	suffix := strings.Replace(DELETE_MESSAGE_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// SearchMessagesOption is a type for the options that can be passed to the SearchMessages method.
+type SearchMessagesOption func(*option)
+
+// An option for the SearchMessages method which will limit the search to the given channel.
+func SearchMessagesOption_Channel(channelId string) SearchMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "channel-id", value: channelId})
+	}
+}
+
+// An option for the SearchMessages method which will limit the search to messages sent by the given user.
+func SearchMessagesOption_Sender(userId string) SearchMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "sender-id", value: userId})
+	}
+}
+
+// An option for the SearchMessages method which will limit the search to messages sent at or after the given time.
+func SearchMessagesOption_After(t time.Time) SearchMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "from", value: t.UTC().Format(time.RFC3339)})
+	}
+}
+
+// An option for the SearchMessages method which will limit the search to messages sent before the given time.
+func SearchMessagesOption_Before(t time.Time) SearchMessagesOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "to", value: t.UTC().Format(time.RFC3339)})
+	}
+}
+
+// Sets the page option. This will determine which page of search results is returned.
+func SearchMessagesOption_Page(page uint64) SearchMessagesOption {
+	return func(o *option) {
+		o.setPage(page)
+	}
+}
+
+// Sets the pageSize option. This will determine the size of each page. Anything over MAX_PAGE_SIZE is clamped to MAX_PAGE_SIZE and
+// a page size of zero is rejected with a validation error.
+func SearchMessagesOption_PageSize(pageSize uint64) SearchMessagesOption {
+	return func(o *option) {
+		o.setPageSize(pageSize)
+	}
+}
+
+// SearchMessages performs a full-text search over the messages in the channels the user is a member of.
+func (c *BroChatClient) SearchMessages(accessToken string, query string, options ...SearchMessagesOption) BroChatClientContentResult[[]MessageSearchResult] {
+	// Default options
+	opts := option{values: []queryParam{{key: "q", value: query}}}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, make([]MessageSearchResult, 0), opts.errs.Details()...)
+	}
+
+	url, err := buildUrl(c.baseUrl, SEARCH_MESSAGES_URL_SUFFIX, opts.values...)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]MessageSearchResult, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]MessageSearchResult, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]MessageSearchResult, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]MessageSearchResult, 0))
+	}
+
+	var results = make([]MessageSearchResult, 0)
+
+	err = c.decodeResponse(res.Body, &results)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]MessageSearchResult, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, results)
+}
+
+// GetReactions returns the aggregated reactions on a message.
+func (c *BroChatClient) GetReactions(accessToken string, channelId string, messageId string) BroChatClientContentResult[[]ReactionSummary] {
+	suffix := strings.Replace(GET_REACTIONS_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]ReactionSummary, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]ReactionSummary, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]ReactionSummary, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]ReactionSummary, 0))
+	}
+
+	var reactions = make([]ReactionSummary, 0)
+
+	err = c.decodeResponse(res.Body, &reactions)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]ReactionSummary, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, reactions)
+}
+
+// AddReaction adds the user's reaction to a message. Adding a reaction the user has already made has no effect.
+func (c *BroChatClient) AddReaction(accessToken string, channelId string, messageId string, request AddReactionRequest) BroChatClientResult {
+	suffix := strings.Replace(ADD_REACTION_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// RemoveReaction removes the user's reaction from a message.
+func (c *BroChatClient) RemoveReaction(accessToken string, channelId string, messageId string, emoji string) BroChatClientResult {
+	suffix := strings.Replace(REMOVE_REACTION_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+	suffix = strings.Replace(suffix, ":emoji", url.PathEscape(emoji), 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// ImportChannelMessages bulk imports historical messages into a channel. Only the channel owner may import messages.
+// The server decides whether the original timestamps are preserved; the result reports whether they were.
+func (c *BroChatClient) ImportChannelMessages(accessToken string, channelId string, request ImportMessagesRequest) BroChatClientContentResult[ImportMessagesResult] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(IMPORT_CHANNEL_MESSAGES_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ImportMessagesResult{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ImportMessagesResult{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ImportMessagesResult{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, ImportMessagesResult{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ImportMessagesResult{})
+	}
+
+	var importResult ImportMessagesResult
+
+	err = c.decodeResponse(res.Body, &importResult)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ImportMessagesResult{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, importResult)
+}
+
+// SendFriendRequest sends a friend request to a user.
+func (c *BroChatClient) SendFriendRequest(accessToken string, request SendFriendRequestRequest) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, SEND_FRIEND_REQUEST_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// add authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// AcceptFriendRequest accepts a friend request from a user.
+func (c *BroChatClient) AcceptFriendRequest(accessToken string, request AcceptFriendRequestRequest) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, ACCEPT_FRIEND_REQUEST_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// add authorization header to the req
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// RemoveFriendOption is a type for the options that can be passed to the RemoveFriend method.
+type RemoveFriendOption func(*option)
+
+// An option for the RemoveFriend method which keeps the direct message channel between the users open instead of archiving it.
+func RemoveFriendOption_RetainDirectMessageChannel() RemoveFriendOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "dm-channel", value: string(DIRECT_MESSAGE_CHANNEL_DISPOSITION_RETAIN)})
+	}
+}
+
+// RemoveFriend dissolves the friendship between the requesting user and the given user. Both users' relationships revert to the default type.
+// By default the direct message channel between the users is archived: its history remains readable but no new messages can be sent.
+// Use RemoveFriendOption_RetainDirectMessageChannel to keep the channel open.
+func (c *BroChatClient) RemoveFriend(accessToken string, userId string, options ...RemoveFriendOption) BroChatClientResult {
+	// Default options
+	opts := option{values: make([]queryParam, 0)}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	url, err := buildUrl(c.baseUrl, strings.Replace(REMOVE_FRIEND_URL_SUFFIX, ":userId", userId, 1), opts.values...)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetMutualFriends returns the friends that the requesting user and the given user have in common.
+func (c *BroChatClient) GetMutualFriends(accessToken string, userId string) BroChatClientContentResult[[]UserInfo] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(GET_MUTUAL_FRIENDS_URL_SUFFIX, ":userId", userId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]UserInfo, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UserInfo, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]UserInfo, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]UserInfo, 0))
+	}
+
+	var friends = make([]UserInfo, 0)
+
+	err = c.decodeResponse(res.Body, &friends)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]UserInfo, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, friends)
+}
+
+// GetRooms returns a list of rooms.
+func (c *BroChatClient) GetRooms(accessToken string) BroChatClientContentResult[[]Room] {
+	url, err := buildUrl(c.baseUrl, GET_ROOMS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]Room, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]Room, 0))
+	}
+
+	// add authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]Room, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]Room, 0))
+	}
+
+	var rooms []Room = make([]Room, 0)
+
+	err = c.decodeResponse(res.Body, &rooms)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]Room, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, rooms)
+}
+
+// DiscoverRoomsOption is a type for the options that can be passed to the DiscoverRooms method.
+type DiscoverRoomsOption func(*option)
+
+// An option for the DiscoverRooms method which will only return rooms that have all of the given tags.
+func DiscoverRoomsOption_Tags(tags ...string) DiscoverRoomsOption {
+	return func(o *option) {
+		normalized := make([]string, 0, len(tags))
+
+		for _, tag := range tags {
+			if tag = NormalizeRoomTag(tag); tag != "" {
+				normalized = append(normalized, tag)
+			}
+		}
+
+		o.values = append(o.values, queryParam{key: "tags", value: strings.Join(normalized, ",")})
+	}
+}
+
+// An option for the DiscoverRooms method which will filter the list of rooms returned by the given name.
+// An empty filter is rejected with a validation error.
+func DiscoverRoomsOption_NameFilter(value string) DiscoverRoomsOption {
+	return func(o *option) {
+		o.setFilter("name-filter", value)
+	}
+}
+
+// Sets the page option. This will determine which page of rooms is returned.
+func DiscoverRoomsOption_Page(page uint64) DiscoverRoomsOption {
+	return func(o *option) {
+		o.setPage(page)
+	}
+}
+
+// Sets the pageSize option. This will determine the size of each page. Anything over MAX_PAGE_SIZE is clamped to MAX_PAGE_SIZE and
+// a page size of zero is rejected with a validation error.
+func DiscoverRoomsOption_PageSize(pageSize uint64) DiscoverRoomsOption {
+	return func(o *option) {
+		o.setPageSize(pageSize)
+	}
+}
+
+// DiscoverRooms returns a list of public rooms that the user can join.
+func (c *BroChatClient) DiscoverRooms(accessToken string, options ...DiscoverRoomsOption) BroChatClientContentResult[[]Room] {
+	// Default options
+	opts := option{values: make([]queryParam, 0)}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, make([]Room, 0), opts.errs.Details()...)
+	}
+
+	url, err := buildUrl(c.baseUrl, DISCOVER_ROOMS_URL_SUFFIX, opts.values...)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]Room, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]Room, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]Room, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]Room, 0))
+	}
+
+	var rooms = make([]Room, 0)
+
+	err = c.decodeResponse(res.Body, &rooms)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]Room, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, rooms)
+}
+
+// CreateRoom creates a new room. Note: The user cannot create more than 20 rooms.
+func (c *BroChatClient) CreateRoom(accessToken string, request CreateRoomRequest) BroChatClientContentResult[Room] {
+	url, err := buildUrl(c.baseUrl, CREATE_ROOM_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Room{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Room{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Room{})
+	}
+
+	var room Room = Room{}
+
+	err = c.decodeResponse(res.Body, &room)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Room{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, room)
+}
+
+// UpdateRoom applies a partial update to a room. Only the fields set on the request are changed. Only the room owner may update a room.
+// The updated room is returned as the content of the result.
+func (c *BroChatClient) UpdateRoom(accessToken string, roomId string, request UpdateRoomRequest) BroChatClientContentResult[Room] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(UPDATE_ROOM_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Room{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPatch, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Room{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Room{})
+	}
+
+	var room Room
+
+	err = c.decodeResponse(res.Body, &room)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Room{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, room)
+}
+
+// JoinRoomOption is a type for the options that can be passed to the JoinRoom method.
+type JoinRoomOption func(*JoinRoomRequest)
+
+// An option for the JoinRoom method which supplies the password of a password protected room.
+func JoinRoomOption_Password(password string) JoinRoomOption {
+	return func(r *JoinRoomRequest) {
+		r.Password = password
+	}
+}
+
+// JoinRoom joins a user to a room. Password protected rooms require the JoinRoomOption_Password option.
+// A wrong or missing password results in BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR.
+// Joining a room that has reached its member capacity results in BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR.
+func (c *BroChatClient) JoinRoom(accessToken string, roomId string, options ...JoinRoomOption) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(JOIN_ROOM_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	var request JoinRoomRequest
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&request)
+	}
+
+	var body io.Reader
+
+	// Rooms without a password do not require a request body
+	if request != (JoinRoomRequest{}) {
+		requestBodyBytes, err := c.codec.Marshal(request)
+
+		if err != nil {
+			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+		}
+
+		body = bytes.NewReader(requestBodyBytes)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPut, url, body)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	if body != nil {
+		// Set the content type header
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		if err, ok := err.(net.Error); ok && err.Timeout() {
+			// If it was a timeout error
+			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_CONNECTION_TIMEOUT_ERROR)
+		}
+
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_GENERIC_CONNECTION_ERROR)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// RequestToJoinRoom asks the owner of a non-public room for permission to join it.
+// The created join request is returned as the content of the result.
+func (c *BroChatClient) RequestToJoinRoom(accessToken string, roomId string, request RequestToJoinRoomRequest) BroChatClientContentResult[RoomJoinRequest] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_JOIN_REQUESTS_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, RoomJoinRequest{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, RoomJoinRequest{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, RoomJoinRequest{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, RoomJoinRequest{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, RoomJoinRequest{})
+	}
+
+	var joinRequest RoomJoinRequest
+
+	err = c.decodeResponse(res.Body, &joinRequest)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, RoomJoinRequest{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, joinRequest)
+}
+
+// GetRoomJoinRequests returns the pending join requests for a room. Only the room owner may list join requests.
+func (c *BroChatClient) GetRoomJoinRequests(accessToken string, roomId string) BroChatClientContentResult[[]RoomJoinRequest] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_JOIN_REQUESTS_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]RoomJoinRequest, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]RoomJoinRequest, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]RoomJoinRequest, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]RoomJoinRequest, 0))
+	}
+
+	var joinRequests = make([]RoomJoinRequest, 0)
+
+	err = c.decodeResponse(res.Body, &joinRequests)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]RoomJoinRequest, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, joinRequests)
+}
+
+// ApproveJoinRequest approves a pending join request, making the requesting user a member of the room.
+func (c *BroChatClient) ApproveJoinRequest(accessToken string, roomId string, requestId string) BroChatClientResult {
+	suffix := strings.Replace(APPROVE_JOIN_REQUEST_URL_SUFFIX, ":roomId", roomId, 1)
+	suffix = strings.Replace(suffix, ":requestId", requestId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPut, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// DenyJoinRequest denies a pending join request.
+func (c *BroChatClient) DenyJoinRequest(accessToken string, roomId string, requestId string) BroChatClientResult {
+	suffix := strings.Replace(DENY_JOIN_REQUEST_URL_SUFFIX, ":roomId", roomId, 1)
+	suffix = strings.Replace(suffix, ":requestId", requestId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPut, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetRoomAuditLogOption is a type for the options that can be passed to the GetRoomAuditLog method.
+type GetRoomAuditLogOption func(*option)
+
+// An option for the GetRoomAuditLog method which will only return entries of the given action.
+func GetRoomAuditLogOption_Action(action AuditAction) GetRoomAuditLogOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "action", value: string(action)})
+	}
+}
+
+// An option for the GetRoomAuditLog method which will only return entries where the given user performed the action.
+func GetRoomAuditLogOption_Actor(userId string) GetRoomAuditLogOption {
+	return func(o *option) {
+		o.values = append(o.values, queryParam{key: "actor-id", value: userId})
+	}
+}
+
+// Sets the page option. This will determine which page to start the audit log query from.
+func GetRoomAuditLogOption_Page(page uint64) GetRoomAuditLogOption {
+	return func(o *option) {
+		o.setPage(page)
+	}
+}
+
+// Sets the pageSize option. This will determine the size of each page. Anything over MAX_PAGE_SIZE is clamped to MAX_PAGE_SIZE and
+// a page size of zero is rejected with a validation error.
+func GetRoomAuditLogOption_PageSize(pageSize uint64) GetRoomAuditLogOption {
+	return func(o *option) {
+		o.setPageSize(pageSize)
+	}
+}
+
+// GetRoomAuditLog returns the audit log of a room, most recent entries first. Only the room owner may view the audit log.
+func (c *BroChatClient) GetRoomAuditLog(accessToken string, roomId string, options ...GetRoomAuditLogOption) BroChatClientContentResult[[]AuditEntry] {
+	// Default options
+	opts := option{values: make([]queryParam, 0)}
+
+	// Apply user-defined options
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, make([]AuditEntry, 0), opts.errs.Details()...)
+	}
+
+	url, err := buildUrl(c.baseUrl, strings.Replace(GET_ROOM_AUDIT_LOG_URL_SUFFIX, ":roomId", roomId, 1), opts.values...)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]AuditEntry, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]AuditEntry, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]AuditEntry, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]AuditEntry, 0))
+	}
+
+	var entries = make([]AuditEntry, 0)
+
+	err = c.decodeResponse(res.Body, &entries)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]AuditEntry, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, entries)
+}
+
+// GetRoomInvites returns the usable invites of a room. Only the room owner and moderators may list invites.
+func (c *BroChatClient) GetRoomInvites(accessToken string, roomId string) BroChatClientContentResult[[]RoomInvite] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_INVITES_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]RoomInvite, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]RoomInvite, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]RoomInvite, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]RoomInvite, 0))
+	}
+
+	var invites = make([]RoomInvite, 0)
+
+	err = c.decodeResponse(res.Body, &invites)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]RoomInvite, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, invites)
+}
+
+// CreateRoomInvite creates an invite to a room. Only the room owner and moderators may create invites and a room cannot
+// have more than MAX_INVITES_PER_ROOM usable invites. The created invite is returned as the content of the result; share
+// its code directly or as a link made with InviteLink.
+func (c *BroChatClient) CreateRoomInvite(accessToken string, roomId string, request CreateRoomInviteRequest) BroChatClientContentResult[RoomInvite] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_INVITES_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, RoomInvite{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, RoomInvite{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, RoomInvite{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, RoomInvite{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, RoomInvite{})
+	}
+
+	var invite RoomInvite
+
+	err = c.decodeResponse(res.Body, &invite)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, RoomInvite{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, invite)
+}
+
+// RevokeRoomInvite revokes an invite to a room so it can no longer be used. Users who already joined with the invite
+// remain members. Only the room owner and moderators may revoke invites.
+func (c *BroChatClient) RevokeRoomInvite(accessToken string, roomId string, code string) BroChatClientResult {
+	suffix := strings.Replace(ROOM_INVITE_URL_SUFFIX, ":roomId", roomId, 1)
+	suffix = strings.Replace(suffix, ":code", url.PathEscape(code), 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// JoinRoomByInvite joins the user to the room of an invite, bypassing the room's password and join approval. The code may
+// be parsed from a shared link with ParseInviteCode. The joined room is returned as the content of the result. An invite
+// which has expired, been used up or been revoked results in BROCHAT_RESPONSE_CODE_NOT_FOUND, and a room that has reached
+// its member capacity results in BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR.
+func (c *BroChatClient) JoinRoomByInvite(accessToken string, code string) BroChatClientContentResult[Room] {
+	suffix := strings.Replace(JOIN_ROOM_BY_INVITE_URL_SUFFIX, ":code", url.PathEscape(code), 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Room{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Room{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Room{})
+	}
+
+	var room Room
+
+	err = c.decodeResponse(res.Body, &room)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Room{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, room)
+}
+
+// UploadAttachment uploads a file to a channel as a multipart form. The returned attachment ID can then be included in a ChatMessageRequest.
+// The size is used for progress reporting and may be -1 if unknown. The progress callback is optional.
+func (c *BroChatClient) UploadAttachment(accessToken string, channelId string, fileName string, content io.Reader, size int64, progress UploadProgressFunc) BroChatClientContentResult[Attachment] {
+	return c.uploadAttachment(accessToken, channelId, fileName, content, size, progress)
+}
+
+// UploadVoiceNote uploads recorded audio to a channel as a voice note attachment. The duration is used by clients to render an inline audio player.
+// The size is used for progress reporting and may be -1 if unknown. The progress callback is optional.
+func (c *BroChatClient) UploadVoiceNote(accessToken string, channelId string, fileName string, content io.Reader, size int64, duration time.Duration, progress UploadProgressFunc) BroChatClientContentResult[Attachment] {
+	return c.uploadAttachment(accessToken, channelId, fileName, content, size, progress,
+		queryParam{key: "kind", value: string(ATTACHMENT_KIND_VOICE_NOTE)},
+		queryParam{key: "duration_ms", value: strconv.FormatInt(duration.Milliseconds(), 10)})
+}
+
+// uploadAttachment streams a multipart upload to the given channel. The form fields are written ahead of the file part.
+func (c *BroChatClient) uploadAttachment(accessToken string, channelId string, fileName string, content io.Reader, size int64, progress UploadProgressFunc, formFields ...queryParam) BroChatClientContentResult[Attachment] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(UPLOAD_ATTACHMENT_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Attachment{})
+	}
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	// Stream the multipart body so the whole file never has to be held in memory
+	go func() {
+		for _, field := range formFields {
+			if err := form.WriteField(field.key, field.value); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		partHeader := make(textproto.MIMEHeader)
+		partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, attachmentFormFieldName, escapeQuotes(fileName)))
+		partHeader.Set("Content-Type", detectAttachmentContentType(fileName))
+
+		part, err := form.CreatePart(partHeader)
+
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		_, err = io.Copy(part, &progressReader{reader: content, total: size, progress: progress})
+
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(form.Close())
+	}()
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, pr)
+
+	if err != nil {
+		pr.Close()
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Attachment{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		pr.Close()
+		return handleHttpRequestErrorWithContent(err, Attachment{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Attachment{})
+	}
+
+	var attachment Attachment
+
+	err = c.decodeResponse(res.Body, &attachment)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Attachment{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, attachment)
+}
+
+// DownloadAttachment streams the content of an attachment to the given writer.
+func (c *BroChatClient) DownloadAttachment(accessToken string, attachmentId string, w io.Writer) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(DOWNLOAD_ATTACHMENT_URL_SUFFIX, ":attachmentId", attachmentId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	_, err = io.Copy(w, res.Body)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// DownloadAttachmentThumbnail streams the most appropriately sized thumbnail for the given bounding box to the writer.
+// If the attachment has no thumbnails the full attachment is downloaded instead.
+func (c *BroChatClient) DownloadAttachmentThumbnail(accessToken string, attachment Attachment, maxWidth int, maxHeight int, w io.Writer) BroChatClientResult {
+	thumbnail, ok := attachment.BestThumbnail(maxWidth, maxHeight)
+
+	if !ok {
+		return c.DownloadAttachment(accessToken, attachment.Id, w)
+	}
+
+	// Thumbnails may be served from another host, such as a CDN, which must not receive the access token
+	thumbnailUrl, sameOrigin, err := resolveServerUrl(c.baseUrl, thumbnail.Url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, thumbnailUrl, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	if sameOrigin {
+		// Set authorization header to the req
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+	} else {
+		req = withoutCredentials(req)
+	}
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	_, err = io.Copy(w, res.Body)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// RequestDataExport requests an archive of everything the server holds about the user: their profile, relationships
+// and messages. The archive is prepared in the background; the pending export is returned as the content of the result.
+// Wait for it with WaitForDataExport, then download it with DownloadDataExport.
+func (c *BroChatClient) RequestDataExport(accessToken string) BroChatClientContentResult[DataExport] {
+	url, err := buildUrl(c.baseUrl, DATA_EXPORTS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, DataExport{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, DataExport{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, DataExport{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, DataExport{})
+	}
+
+	var export DataExport
+
+	err = c.decodeResponse(res.Body, &export)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, DataExport{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, export)
+}
+
+// GetDataExport returns the current state of a data export requested by the user.
+func (c *BroChatClient) GetDataExport(accessToken string, exportId string) BroChatClientContentResult[DataExport] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(DATA_EXPORT_URL_SUFFIX, ":exportId", exportId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, DataExport{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, DataExport{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, DataExport{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, DataExport{})
+	}
+
+	var export DataExport
+
+	err = c.decodeResponse(res.Body, &export)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, DataExport{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, export)
+}
+
+// DownloadDataExport streams the zip archive of a ready data export to the given writer. Read the archive with
+// OpenDataExportArchive. Downloading an export which is not ready fails with BROCHAT_RESPONSE_CODE_INVALID_OPERATION.
+func (c *BroChatClient) DownloadDataExport(accessToken string, exportId string, w io.Writer) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(DOWNLOAD_DATA_EXPORT_URL_SUFFIX, ":exportId", exportId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	_, err = io.Copy(w, res.Body)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetMessageDrafts returns all of the user's saved message drafts across every channel.
+func (c *BroChatClient) GetMessageDrafts(accessToken string) BroChatClientContentResult[[]MessageDraft] {
+	url, err := buildUrl(c.baseUrl, GET_MESSAGE_DRAFTS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]MessageDraft, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]MessageDraft, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]MessageDraft, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]MessageDraft, 0))
+	}
+
+	var drafts = make([]MessageDraft, 0)
+
+	err = c.decodeResponse(res.Body, &drafts)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]MessageDraft, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, drafts)
+}
+
+// GetMessageDraft returns the user's saved message draft for a channel.
+// Returns a not found result if there is no draft for the channel.
+func (c *BroChatClient) GetMessageDraft(accessToken string, channelId string) BroChatClientContentResult[MessageDraft] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MESSAGE_DRAFT_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, MessageDraft{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, MessageDraft{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, MessageDraft{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, MessageDraft{})
+	}
+
+	var draft MessageDraft
+
+	err = c.decodeResponse(res.Body, &draft)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, MessageDraft{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, draft)
+}
+
+// SaveMessageDraft saves (or replaces) the user's message draft for a channel.
+func (c *BroChatClient) SaveMessageDraft(accessToken string, channelId string, request SaveMessageDraftRequest) BroChatClientContentResult[MessageDraft] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MESSAGE_DRAFT_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, MessageDraft{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, MessageDraft{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, MessageDraft{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, MessageDraft{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, MessageDraft{})
+	}
+
+	var draft MessageDraft
+
+	err = c.decodeResponse(res.Body, &draft)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, MessageDraft{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, draft)
+}
+
+// DeleteMessageDraft discards the user's message draft for a channel. Typically called after the message has been sent.
+func (c *BroChatClient) DeleteMessageDraft(accessToken string, channelId string) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(MESSAGE_DRAFT_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetMessageEditHistory returns the prior revisions of a message, ordered from oldest to newest.
+// The current content of the message is not included.
+func (c *BroChatClient) GetMessageEditHistory(accessToken string, channelId string, messageId string) BroChatClientContentResult[[]MessageRevision] {
+	suffix := strings.Replace(GET_MESSAGE_EDIT_HISTORY_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]MessageRevision, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]MessageRevision, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]MessageRevision, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]MessageRevision, 0))
+	}
+
+	var revisions = make([]MessageRevision, 0)
+
+	err = c.decodeResponse(res.Body, &revisions)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]MessageRevision, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, revisions)
+}
+
+// KickUserFromRoom removes a member from a room. Only the room owner and room moderators may kick users.
+// The room owner cannot be kicked.
+func (c *BroChatClient) KickUserFromRoom(accessToken string, roomId string, userId string, request KickUserFromRoomRequest) BroChatClientResult {
+	suffix := strings.Replace(KICK_USER_FROM_ROOM_URL_SUFFIX, ":roomId", roomId, 1)
+	suffix = strings.Replace(suffix, ":userId", userId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// GetWebhooks returns the webhooks of a room. Only the room owner may list webhooks. Secrets are not included.
+func (c *BroChatClient) GetWebhooks(accessToken string, roomId string) BroChatClientContentResult[[]Webhook] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_WEBHOOKS_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]Webhook, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]Webhook, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]Webhook, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]Webhook, 0))
+	}
+
+	var webhooks = make([]Webhook, 0)
+
+	err = c.decodeResponse(res.Body, &webhooks)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]Webhook, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, webhooks)
+}
+
+// CreateWebhook registers a URL to receive the events of a room. Only the room owner may create webhooks and a room cannot
+// have more than 10 webhooks. The created webhook is returned as the content of the result, including the secret used
+// to sign deliveries. The secret cannot be retrieved again.
+func (c *BroChatClient) CreateWebhook(accessToken string, roomId string, request CreateWebhookRequest) BroChatClientContentResult[Webhook] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_WEBHOOKS_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Webhook{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Webhook{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Webhook{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Webhook{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Webhook{})
+	}
+
+	var webhook Webhook
+
+	err = c.decodeResponse(res.Body, &webhook)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Webhook{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, webhook)
+}
+
+// DeleteWebhook removes a webhook from a room. Only the room owner may delete webhooks.
+// Deliveries already in progress may still be attempted.
+func (c *BroChatClient) DeleteWebhook(accessToken string, roomId string, webhookId string) BroChatClientResult {
+	suffix := strings.Replace(ROOM_WEBHOOK_URL_SUFFIX, ":roomId", roomId, 1)
+	suffix = strings.Replace(suffix, ":webhookId", webhookId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// CreateVoiceChannel creates a voice channel in a room. Only the room owner may create voice channels. The created
+// channel is returned as the content of the result and its ID is added to the VoiceChannelIds of the room.
+func (c *BroChatClient) CreateVoiceChannel(accessToken string, roomId string, request CreateVoiceChannelRequest) BroChatClientContentResult[Channel] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(ROOM_VOICE_CHANNELS_URL_SUFFIX, ":roomId", roomId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Channel{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Channel{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Channel{})
+	}
+
+	var channel Channel
+
+	err = c.decodeResponse(res.Body, &channel)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Channel{})
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channel)
+}
+
+// GetVoiceParticipants returns the users connected to a voice channel, in the order they joined. The user must be a member
+// of the room the voice channel belongs to.
+func (c *BroChatClient) GetVoiceParticipants(accessToken string, channelId string) BroChatClientContentResult[[]VoiceParticipant] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(VOICE_PARTICIPANTS_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]VoiceParticipant, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]VoiceParticipant, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]VoiceParticipant, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]VoiceParticipant, 0))
+	}
+
+	var participants = make([]VoiceParticipant, 0)
+
+	err = c.decodeResponse(res.Body, &participants)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]VoiceParticipant, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, participants)
+}
+
+// JoinVoiceChannel connects the user to a voice channel, leaving any other voice channel they are connected to. The
+// participants of the channel, including the user, are returned as the content of the result. Joining a channel which is
+// not a voice channel fails with BROCHAT_RESPONSE_CODE_INVALID_OPERATION.
+func (c *BroChatClient) JoinVoiceChannel(accessToken string, channelId string, request JoinVoiceChannelRequest) BroChatClientContentResult[[]VoiceParticipant] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(JOIN_VOICE_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]VoiceParticipant, 0))
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]VoiceParticipant, 0))
+	}
+
+	defer requestBody.release()
+
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPost, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]VoiceParticipant, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]VoiceParticipant, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]VoiceParticipant, 0))
+	}
+
+	var participants = make([]VoiceParticipant, 0)
+
+	err = c.decodeResponse(res.Body, &participants)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]VoiceParticipant, 0))
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, participants)
+}
+
+// LeaveVoiceChannel disconnects the user from a voice channel. Leaving a voice channel the user is not connected to succeeds.
+func (c *BroChatClient) LeaveVoiceChannel(accessToken string, channelId string) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(LEAVE_VOICE_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return handleUnsuccessfulStatusCodeWithContent(res, User{})
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
 	}
 
-	var user User
+	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
+}
 
-	err = json.NewDecoder(res.Body).Decode(&user)
+// UpdateVoiceState changes the muted, deafened or speaking state of the user in a voice channel they are connected to.
+// The updated participant is returned as the content of the result. Updating the state in a voice channel the user is
+// not connected to fails with BROCHAT_RESPONSE_CODE_INVALID_OPERATION.
+func (c *BroChatClient) UpdateVoiceState(accessToken string, channelId string, request UpdateVoiceStateRequest) BroChatClientContentResult[VoiceParticipant] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(VOICE_STATE_URL_SUFFIX, ":channelId", channelId, 1))
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, User{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, VoiceParticipant{})
 	}
 
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, user)
-}
-
-// GetUsersOption is a type for the options that can be passed to the GetUsers method.
-type GetUsersOption func(*option)
+	requestBody, err := encodeRequestBody(c.codec, request)
 
-// An option for the GetUsers method which will exclude the user making the request from the list of users returned.
-func GetUsersOption_ExcludeSelf() GetUsersOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "exclude-self", value: "true"})
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, VoiceParticipant{})
 	}
-}
 
-// An option for the GetUsers method which will exclude the friends of the user making the request from the list of users returned.
-func GetUsersOption_ExcludeFriends() GetUsersOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "before-msg", value: "true"})
-	}
-}
+	defer requestBody.release()
 
-// An option for the GetUsers method which will filter the list of users returned by the given username.
-func GetUsersOption_UsernameFilter(value string) GetUsersOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "username-filter", value: value})
+	// Create a new request using http
+	req, err := requestBody.newRequest(http.MethodPut, url)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, VoiceParticipant{})
 	}
-}
 
-// Sets the page option. This will determine which page to start the channel message query from.
-func GetUsersOption_Page(page uint64) GetUsersOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "page", value: strconv.FormatUint(page, 10)})
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, VoiceParticipant{})
 	}
-}
 
-// Sets the pageSize option. This will determine the size of each page. Anything over 100 will just be set to 100.
-func GetUsersOption_PageSize(pageSize uint64) GetUsersOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "page-size", value: strconv.FormatUint(pageSize, 10)})
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, VoiceParticipant{})
 	}
-}
 
-// GetUsers returns a list of users.
-func (c *BroChatClient) GetUsers(accessToken string, options ...GetUsersOption) BroChatClientContentResult[[]UserInfo] {
+	var participant VoiceParticipant
 
-	// Default options
-	opts := option{values: make([]queryParam, 0)}
+	err = c.decodeResponse(res.Body, &participant)
 
-	// Apply user-defined options
-	for _, opt := range options {
-		opt(&opts)
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, VoiceParticipant{})
 	}
 
-	url, err := buildUrl(c.baseUrl, GET_USERS_URL_SUFFIX, opts.values...)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, participant)
+}
+
+// GetServerHealth returns the health of the server. Does not require authentication. A server which reports itself
+// unavailable responds with 503 Service Unavailable and its health, which is returned as the content of a successful
+// result, so check ServerHealth.Status rather than the error of the result to tell if the server is healthy.
+func (c *BroChatClient) GetServerHealth() BroChatClientContentResult[ServerHealth] {
+	url, err := buildUrl(c.baseUrl, SERVER_HEALTH_URL_SUFFIX)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]UserInfo, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ServerHealth{})
 	}
 
 	// Create a new request using http
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]UserInfo, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ServerHealth{})
 	}
 
-	// add authorization header to the req
-	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
-
 	// Send req using http Client
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, make([]UserInfo, 0))
+		return handleHttpRequestErrorWithContent(err, ServerHealth{})
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return handleUnsuccessfulStatusCodeWithContent(res, make([]UserInfo, 0))
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusServiceUnavailable {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ServerHealth{})
 	}
 
-	var users = make([]UserInfo, 0)
+	var health ServerHealth
 
-	err = json.NewDecoder(res.Body).Decode(&users)
+	err = c.decodeResponse(res.Body, &health)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]UserInfo, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ServerHealth{})
 	}
 
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, health)
 }
 
-// GetChannel returns a channel by its ID.
-func (c *BroChatClient) GetChannel(accessToken string, channelId string) BroChatClientContentResult[Channel] {
-	url, err := buildUrl(c.baseUrl, strings.Replace(GET_CHANNEL_URL_SUFFIX, ":channelId", channelId, 1))
+// GetServerStats returns the usage statistics of the server: uptime, connected users and message throughput.
+func (c *BroChatClient) GetServerStats(accessToken string) BroChatClientContentResult[ServerStats] {
+	url, err := buildUrl(c.baseUrl, SERVER_STATS_URL_SUFFIX)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Channel{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, ServerStats{})
 	}
 
 	// Create a new request using http
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Channel{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, ServerStats{})
 	}
 
-	// add authorization header to the req
-	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
 
 	// Send req using http Client
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, Channel{})
+		return handleHttpRequestErrorWithContent(err, ServerStats{})
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return handleUnsuccessfulStatusCodeWithContent(res, Channel{})
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, ServerStats{})
 	}
 
-	var channel Channel
+	var stats ServerStats
 
-	err = json.NewDecoder(res.Body).Decode(&channel)
+	err = c.decodeResponse(res.Body, &stats)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Channel{})
-	}
-
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channel)
-}
-
-// GetChannelMessagesOption is a type for the options that can be passed to the GetChannelMessages method.
-// Example usage: GetChannelMessages_Page(1), GetChannelMessages_PageSize(10)... etc.
-type GetChannelMessagesOption func(*option)
-
-// An option for the GetChannelMessages method which will pull the messages before the given chat message ID.
-func GetChannelMessages_BeforeMessage(value string) GetChannelMessagesOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "before-msg", value: value})
-	}
-}
-
-// Sets the page option. This will determine which page to start the channel message query from.
-func GetChannelMessages_Page(page uint64) GetChannelMessagesOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "page", value: strconv.FormatUint(page, 10)})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, ServerStats{})
 	}
-}
 
-// Sets the pageSize option. This will determine the size of each page. Anything over 100 will just be set to 100.
-func GetChannelMessages_PageSize(pageSize uint64) GetChannelMessagesOption {
-	return func(o *option) {
-		o.values = append(o.values, queryParam{key: "page-size", value: strconv.FormatUint(pageSize, 10)})
-	}
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, stats)
 }
 
-// GetChannelMessages returns a list of messages in a channel.
-func (c *BroChatClient) GetChannelMessages(accessToken string, channelId string, options ...GetChannelMessagesOption) BroChatClientContentResult[[]ChatMessage] {
-	// Default options
-	opts := option{values: make([]queryParam, 0)}
-
-	// Apply user-defined options
-	for _, opt := range options {
-		opt(&opts)
-	}
-
-	url, err := buildUrl(c.baseUrl, strings.Replace(GET_CHANNEL_MESSAGES_URL_SUFFIX, ":channelId", channelId, 1), opts.values...)
+// GetSigningKeys returns the signing keys of a user, including revoked keys. Used to verify the signatures of the user's
+// messages, see MessageVerifier.
+func (c *BroChatClient) GetSigningKeys(accessToken string, userId string) BroChatClientContentResult[[]SigningKey] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(USER_SIGNING_KEYS_URL_SUFFIX, ":userId", userId, 1))
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]ChatMessage, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]SigningKey, 0))
 	}
 
 	// Create a new request using http
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]ChatMessage, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]SigningKey, 0))
 	}
 
-	// add authorization header to the req
-	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
 
 	// Send req using http Client
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, make([]ChatMessage, 0))
+		return handleHttpRequestErrorWithContent(err, make([]SigningKey, 0))
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return handleUnsuccessfulStatusCodeWithContent(res, make([]ChatMessage, 0))
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]SigningKey, 0))
 	}
 
-	var channels = make([]ChatMessage, 0)
+	var keys = make([]SigningKey, 0)
 
-	err = json.NewDecoder(res.Body).Decode(&channels)
+	err = c.decodeResponse(res.Body, &keys)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]ChatMessage, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]SigningKey, 0))
 	}
 
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, channels)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, keys)
 }
 
-// SendFriendRequest sends a friend request to a user.
-func (c *BroChatClient) SendFriendRequest(accessToken string, request SendFriendRequestRequest) BroChatClientResult {
-	url, err := buildUrl(c.baseUrl, SEND_FRIEND_REQUEST_URL_SUFFIX)
+// AddSigningKey registers an Ed25519 public key for the user to sign their messages with.
+// The registered key, including the ID to sign with, is returned as the content of the result.
+func (c *BroChatClient) AddSigningKey(accessToken string, request AddSigningKeyRequest) BroChatClientContentResult[SigningKey] {
+	url, err := buildUrl(c.baseUrl, SIGNING_KEYS_URL_SUFFIX)
 
 	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, SigningKey{})
 	}
 
-	requestBodyBytes, err := json.Marshal(request)
+	requestBody, err := encodeRequestBody(c.codec, request)
 
 	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, SigningKey{})
 	}
 
+	defer requestBody.release()
+
 	// Create a new request using http
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(requestBodyBytes))
+	req, err := requestBody.newRequest(http.MethodPost, url)
 
 	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, SigningKey{})
 	}
 
-	// add authorization header to the req
+	// Set authorization header to the req
 	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
 
 	// Set the content type header
@@ -420,44 +4003,43 @@ func (c *BroChatClient) SendFriendRequest(accessToken string, request SendFriend
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestError(err)
+		return handleHttpRequestErrorWithContent(err, SigningKey{})
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusNoContent {
-		return handleUnsuccessfulStatusCode(res)
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, SigningKey{})
 	}
 
-	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
-}
+	var key SigningKey
 
-// AcceptFriendRequest accepts a friend request from a user.
-func (c *BroChatClient) AcceptFriendRequest(accessToken string, request AcceptFriendRequestRequest) BroChatClientResult {
-	url, err := buildUrl(c.baseUrl, ACCEPT_FRIEND_REQUEST_URL_SUFFIX)
+	err = c.decodeResponse(res.Body, &key)
 
 	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, SigningKey{})
 	}
 
-	requestBodyBytes, err := json.Marshal(request)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, key)
+}
+
+// RevokeSigningKey revokes one of the user's signing keys. Messages signed with the key before it was revoked remain valid.
+func (c *BroChatClient) RevokeSigningKey(accessToken string, keyId string) BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(SIGNING_KEY_URL_SUFFIX, ":keyId", keyId, 1))
 
 	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
 	}
 
 	// Create a new request using http
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(requestBodyBytes))
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
 
 	if err != nil {
 		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
 	}
 
-	// add authorization header to the req
-	req.Header.Add("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
-
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
 
 	// Send req using http Client
 	res, err := c.httpClient.Do(req)
@@ -469,73 +4051,91 @@ func (c *BroChatClient) AcceptFriendRequest(accessToken string, request AcceptFr
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusNoContent {
-		return handleUnsuccessfulStatusCode(res)
+		return handleUnsuccessfulStatusCode(c.codec, res)
 	}
 
 	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
 }
 
-// GetRooms returns a list of rooms.
-func (c *BroChatClient) GetRooms(accessToken string) BroChatClientContentResult[[]Room] {
-	url, err := buildUrl(c.baseUrl, GET_ROOMS_URL_SUFFIX)
+// ReportUser reports a user to the BroChat moderators.
+// The created report is returned as the content of the result.
+func (c *BroChatClient) ReportUser(accessToken string, userId string, request ReportRequest) BroChatClientContentResult[Report] {
+	url, err := buildUrl(c.baseUrl, strings.Replace(REPORT_USER_URL_SUFFIX, ":userId", userId, 1))
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]Room, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Report{})
+	}
+
+	requestBody, err := encodeRequestBody(c.codec, request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Report{})
 	}
 
+	defer requestBody.release()
+
 	// Create a new request using http
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := requestBody.newRequest(http.MethodPost, url)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]Room, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Report{})
 	}
 
-	// add authorization header to the req
+	// Set authorization header to the req
 	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
 
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
 	// Send req using http Client
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, make([]Room, 0))
+		return handleHttpRequestErrorWithContent(err, Report{})
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return handleUnsuccessfulStatusCodeWithContent(res, make([]Room, 0))
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Report{})
 	}
 
-	var rooms []Room = make([]Room, 0)
+	var report Report
 
-	err = json.NewDecoder(res.Body).Decode(&rooms)
+	err = c.decodeResponse(res.Body, &report)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]Room, 0))
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Report{})
 	}
 
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, rooms)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, report)
 }
 
-// CreateRoom creates a new room. Note: The user cannot create more than 20 rooms.
-func (c *BroChatClient) CreateRoom(accessToken string, request CreateRoomRequest) BroChatClientContentResult[Room] {
-	url, err := buildUrl(c.baseUrl, CREATE_ROOM_URL_SUFFIX)
+// ReportMessage reports a message to the BroChat moderators.
+// The created report is returned as the content of the result.
+func (c *BroChatClient) ReportMessage(accessToken string, channelId string, messageId string, request ReportRequest) BroChatClientContentResult[Report] {
+	suffix := strings.Replace(REPORT_MESSAGE_URL_SUFFIX, ":channelId", channelId, 1)
+	suffix = strings.Replace(suffix, ":messageId", messageId, 1)
+
+	url, err := buildUrl(c.baseUrl, suffix)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Room{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Report{})
 	}
 
-	requestBodyBytes, err := json.Marshal(request)
+	requestBody, err := encodeRequestBody(c.codec, request)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Report{})
 	}
 
+	defer requestBody.release()
+
 	// Create a new request using http
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	req, err := requestBody.newRequest(http.MethodPost, url)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Room{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Report{})
 	}
 
 	// Set authorization header to the req
@@ -548,68 +4148,61 @@ func (c *BroChatClient) CreateRoom(accessToken string, request CreateRoomRequest
 	res, err := c.httpClient.Do(req)
 
 	if err != nil {
-		return handleHttpRequestErrorWithContent(err, Room{})
+		return handleHttpRequestErrorWithContent(err, Report{})
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusCreated {
-		return handleUnsuccessfulStatusCodeWithContent(res, Room{})
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Report{})
 	}
 
-	var room Room = Room{}
+	var report Report
 
-	err = json.NewDecoder(res.Body).Decode(&room)
+	err = c.decodeResponse(res.Body, &report)
 
 	if err != nil {
-		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Room{})
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Report{})
 	}
 
-	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, room)
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, report)
 }
 
-// JoinRoom joins a user to a room.
-func (c *BroChatClient) JoinRoom(accessToken string, roomId string) BroChatClientResult {
-	url, err := buildUrl(c.baseUrl, strings.Replace(JOIN_ROOM_URL_SUFFIX, ":roomId", roomId, 1))
-
-	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
-	}
-
-	// Create a new request using http
-	req, err := http.NewRequest(http.MethodPut, url, nil)
+// option is a type for the options that can be passed to the GetChannelMessages method.
+type option struct {
+	values []queryParam
+	// Invalid option values, reported as a validation error instead of making the request.
+	errs ValidationErrors
+}
 
-	if err != nil {
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+// setPage sets the page query parameter. Pages start at 1.
+func (o *option) setPage(page uint64) {
+	if page < 1 {
+		o.errs.add("page", "must be at least 1")
+		return
 	}
 
-	// Set authorization header to the req
-	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
-
-	// Send req using http Client
-	res, err := c.httpClient.Do(req)
-
-	if err != nil {
-		if err, ok := err.(net.Error); ok && err.Timeout() {
-			// If it was a timeout error
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_CONNECTION_TIMEOUT_ERROR)
-		}
+	o.values = append(o.values, queryParam{key: "page", value: strconv.FormatUint(page, 10)})
+}
 
-		return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_GENERIC_CONNECTION_ERROR)
+// setPageSize sets the page-size query parameter, clamped to MAX_PAGE_SIZE.
+func (o *option) setPageSize(pageSize uint64) {
+	if pageSize < 1 {
+		o.errs.add("page-size", "must be at least 1")
+		return
 	}
 
-	defer res.Body.Close()
+	o.values = append(o.values, queryParam{key: "page-size", value: strconv.FormatUint(min(pageSize, MAX_PAGE_SIZE), 10)})
+}
 
-	if res.StatusCode != http.StatusNoContent {
-		return handleUnsuccessfulStatusCode(res)
+// setFilter sets a text filter query parameter, which cannot be blank.
+func (o *option) setFilter(key string, value string) {
+	if strings.TrimSpace(value) == "" {
+		o.errs.add(key, "cannot be empty")
+		return
 	}
 
-	return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_SUCCESS)
-}
-
-// option is a type for the options that can be passed to the GetChannelMessages method.
-type option struct {
-	values []queryParam
+	o.values = append(o.values, queryParam{key: key, value: value})
 }
 
 // The default token type used for authorization.
@@ -621,7 +4214,9 @@ type queryParam struct {
 	value string
 }
 
-// buildUrl is a helper function that builds a url from a base url, a suffix and query parameters.
+// buildUrl is a helper function that builds a url from a base url, an endpoint path and query parameters. The endpoint
+// path is always joined to the path of the base url, which may be a path the API is served under.
+// Example: https://example.com/tenants/acme and /api/brochat/user build https://example.com/tenants/acme/api/brochat/user
 func buildUrl(baseUrl, suffix string, queryParams ...queryParam) (string, error) {
 	base, err := url.Parse(baseUrl)
 
@@ -635,7 +4230,16 @@ func buildUrl(baseUrl, suffix string, queryParams ...queryParam) (string, error)
 		return "", err
 	}
 
-	resolvedUrl := base.ResolveReference(suffixUrl)
+	resolvedUrl := *base
+	resolvedUrl.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.TrimPrefix(suffixUrl.Path, "/")
+	resolvedUrl.RawPath = ""
+	resolvedUrl.RawQuery = suffixUrl.RawQuery
+	resolvedUrl.Fragment = ""
+
+	// Keep escaped characters, such as an escaped slash in an emoji or invite code
+	if base.RawPath != "" || suffixUrl.RawPath != "" {
+		resolvedUrl.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + "/" + strings.TrimPrefix(suffixUrl.EscapedPath(), "/")
+	}
 
 	if len(queryParams) > 0 {
 		q := resolvedUrl.Query()
@@ -674,32 +4278,81 @@ func handleHttpRequestError(err error) BroChatClientResult {
 
 // handleUnsuccessfulStatusCodeWithContent is a helper function that handles the response from the server when the response is not successful.
 // It returns a BroChatClientContentResult with the given content and a BroChatClientResult with the given response code and error details.
-func handleUnsuccessfulStatusCodeWithContent[T any](res *http.Response, content T) BroChatClientContentResult[T] {
+func handleUnsuccessfulStatusCodeWithContent[T any](codec Codec, res *http.Response, content T) BroChatClientContentResult[T] {
 	return BroChatClientContentResult[T]{Content: content,
-		BroChatClientResult: handleUnsuccessfulStatusCode(res),
+		BroChatClientResult: handleUnsuccessfulStatusCode(codec, res),
+	}
+}
+
+// decodeResponse reads the body of a successful response to the end into a pooled buffer and decodes it into v with
+// the response decoder.
+func (c *BroChatClient) decodeResponse(body io.Reader, v any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := buf.ReadFrom(body); err != nil {
+		return err
+	}
+
+	return c.responseDecoder(c.codec, buf.Bytes(), v)
+}
+
+// resolveServerUrl resolves a url supplied by the server, such as the url of a thumbnail, against the base url. Returns
+// true if the resolved url has the scheme and host of the base url, meaning it may be sent credentials.
+func resolveServerUrl(baseUrl string, ref string) (string, bool, error) {
+	base, err := url.Parse(baseUrl)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	refUrl, err := url.Parse(ref)
+
+	if err != nil {
+		return "", false, err
 	}
+
+	resolved := base.ResolveReference(refUrl)
+
+	return resolved.String(), isSameOrigin(base, resolved), nil
+}
+
+// isSameOrigin returns true if the urls have the same scheme and host, including the port.
+func isSameOrigin(a *url.URL, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
 }
 
 // handleUnsuccessfulStatusCode is a helper function that handles the response from the server when the response is not successful.
-func handleUnsuccessfulStatusCode(res *http.Response) BroChatClientResult {
+// The error body is decoded with the codec of the client. Error responses are never wrapped in an envelope, so the
+// ResponseDecoder is not used.
+func handleUnsuccessfulStatusCode(codec Codec, res *http.Response) BroChatClientResult {
 	var serverSideErr BroChatError
 
-	err := json.NewDecoder(res.Body).Decode(&serverSideErr)
+	err := DecodeReader(codec, res.Body, &serverSideErr)
+
+	var result BroChatClientResult
 
 	if err != nil {
-		switch res.StatusCode {
-		case http.StatusUnauthorized:
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR)
-		case http.StatusForbidden:
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR)
-		case http.StatusNotFound:
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR)
-		case http.StatusBadRequest:
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR)
-		default:
-			return makeBroChatClientResult(BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR)
+		code := ResponseCodeFromHttpStatus(res.StatusCode)
+
+		// An unexpected success status is still a failure of the operation
+		if code >= BROCHAT_RESPONSE_CODE_SUCCESS {
+			code = BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR
 		}
+
+		result = makeBroChatClientResult(code)
+	} else {
+		result = makeBroChatClientResult(serverSideErr.Code, serverSideErr.AllDetails()...)
 	}
 
-	return makeBroChatClientResult(serverSideErr.Code, serverSideErr.ErrorDetails...)
+	result.RequestId = serverSideErr.RequestId
+
+	if result.RequestId == "" {
+		result.RequestId = res.Header.Get(REQUEST_ID_HEADER)
+	}
+
+	result.RetryAfter = ParseRetryAfter(res.Header, time.Now())
+	result.RateLimit = ParseRateLimit(res.Header)
+
+	return result
 }
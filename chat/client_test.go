@@ -0,0 +1,177 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBuildUrl_Allocs(t *testing.T) {
+	assertAllocs(t, 11, func() {
+		buildUrl("https://example.com", SEARCH_MESSAGES_URL_SUFFIX, queryParam{"page", "2"}, queryParam{"page_size", "50"})
+	})
+}
+
+func BenchmarkBuildUrl(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buildUrl("https://example.com", SEARCH_MESSAGES_URL_SUFFIX, queryParam{"page", "2"}, queryParam{"page_size", "50"})
+	}
+}
+
+func TestBroChatClientResult_Allocs(t *testing.T) {
+	assertAllocs(t, 0, func() {
+		result := makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, ChatMessage{Id: "message"})
+		_ = result.Err()
+	})
+}
+
+func BenchmarkBroChatClientResult(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		result := makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, ChatMessage{Id: "message"})
+		_ = result.Err()
+	}
+}
+
+func TestBuildUrl(t *testing.T) {
+	tests := []struct {
+		baseUrl string
+		suffix  string
+		params  []queryParam
+		want    string
+	}{
+		{baseUrl: "https://example.com", suffix: "/api/brochat/user", want: "https://example.com/api/brochat/user"},
+		{baseUrl: "https://example.com/", suffix: "/api/brochat/user", want: "https://example.com/api/brochat/user"},
+		{baseUrl: "https://example.com/tenants/acme", suffix: "/api/brochat/user", want: "https://example.com/tenants/acme/api/brochat/user"},
+		{baseUrl: "https://example.com/tenants/acme/", suffix: "/api/brochat/user", want: "https://example.com/tenants/acme/api/brochat/user"},
+		// A base path which is also the start of the endpoint path is still joined.
+		{baseUrl: "https://example.com/api", suffix: "/api/brochat/user", want: "https://example.com/api/api/brochat/user"},
+		{baseUrl: "https://example.com/api", suffix: "/api/brochat/rooms/invites/a%2Fb", want: "https://example.com/api/api/brochat/rooms/invites/a%2Fb"},
+		{baseUrl: "https://example.com", suffix: "/api/brochat/messages/search", params: []queryParam{{"q", "hi there"}, {"empty", ""}}, want: "https://example.com/api/brochat/messages/search?q=hi+there"},
+	}
+
+	for _, tt := range tests {
+		got, err := buildUrl(tt.baseUrl, tt.suffix, tt.params...)
+
+		if err != nil {
+			t.Fatalf("buildUrl(%q, %q) error = %v", tt.baseUrl, tt.suffix, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("buildUrl(%q, %q) = %q, want %q", tt.baseUrl, tt.suffix, got, tt.want)
+		}
+	}
+}
+
+func TestGetChannelMessagesRange_HugeToPage(t *testing.T) {
+	const total = 2*MAX_PAGE_SIZE + 5
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		messages := make([]ChatMessage, 0, MAX_PAGE_SIZE)
+
+		for i := (page - 1) * MAX_PAGE_SIZE; i < min(page*MAX_PAGE_SIZE, total); i++ {
+			messages = append(messages, ChatMessage{Id: strconv.Itoa(i)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(messages)
+	}))
+	defer server.Close()
+
+	client := NewBroChatClient(server.Client(), server.URL)
+
+	for _, toPage := range []uint64{math.MaxUint64, math.MaxUint64 - 1, 1 << 40} {
+		requests.Store(0)
+
+		result := client.GetChannelMessagesRange(context.Background(), "token", "c", 1, toPage, 4)
+
+		if err := result.Err(); err != nil {
+			t.Fatalf("GetChannelMessagesRange(toPage=%d) error = %v", toPage, err)
+		}
+
+		if len(result.Content) != total {
+			t.Fatalf("GetChannelMessagesRange(toPage=%d) returned %d messages, want %d", toPage, len(result.Content), total)
+		}
+
+		for i, m := range result.Content {
+			if m.Id != strconv.Itoa(i) {
+				t.Fatalf("message %d has id %s, want the pages in order", i, m.Id)
+			}
+		}
+
+		// The pages being fetched when the short page arrives are requested too
+		if n := requests.Load(); n > 3+4 {
+			t.Errorf("GetChannelMessagesRange(toPage=%d) made %d requests, want it to stop after the short page", toPage, n)
+		}
+	}
+}
+
+func TestHandleUnsuccessfulStatusCode_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RATE_LIMIT_LIMIT_HEADER, "60")
+		w.Header().Set(RATE_LIMIT_REMAINING_HEADER, "0")
+		w.Header().Set(RATE_LIMIT_RESET_HEADER, "30")
+		w.Header().Set(RETRY_AFTER_HEADER, "2")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(NewRateLimitedError(2 * time.Second))
+	}))
+	defer server.Close()
+
+	result := NewBroChatClient(server.Client(), server.URL).GetChannelMessages("token", "c")
+
+	if result.ResponseCode != BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR {
+		t.Fatalf("ResponseCode = %v, want BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR", result.ResponseCode)
+	}
+
+	if result.RetryAfter != 2*time.Second {
+		t.Errorf("RetryAfter = %v, want 2s", result.RetryAfter)
+	}
+
+	if want := (RateLimit{Limit: 60, Remaining: 0, Reset: 30 * time.Second}); result.RateLimit != want {
+		t.Errorf("RateLimit = %+v, want %+v", result.RateLimit, want)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "120", want: 2 * time.Minute},
+		{value: "-5", want: 0},
+		{value: "soon", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "99999999999999999", want: time.Duration(math.MaxInt64 / int64(time.Second) * int64(time.Second))},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+
+		if tt.value != "" {
+			header.Set(RETRY_AFTER_HEADER, tt.value)
+		}
+
+		if got := ParseRetryAfter(header, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
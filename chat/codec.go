@@ -0,0 +1,98 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Returned by an EnvelopeResponseDecoder when a response is not an envelope holding the content field.
+var ErrResponseEnvelopeMissingField = errors.New("response envelope is missing the content field")
+
+// A Codec encodes and decodes the JSON bodies of API requests and responses and the content of feed messages.
+//
+// The default StdCodec uses encoding/json. Large responses such as pages of channel history spend most of their time in
+// reflection based decoding, so applications may plug in a faster JSON library such as sonic or go-json by implementing
+// this interface. Implementations must be compatible with encoding/json, honouring struct tags and the json.Marshaler
+// and json.Unmarshaler interfaces, and must be safe for concurrent use.
+//
+// Response bodies are read into pooled buffers, so Unmarshal must not retain data after it returns. Libraries which
+// reference the input when decoding strings must be configured to copy them.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// A StreamCodec is a Codec which can encode directly to a writer. The clients encode request bodies of a StreamCodec
+// into pooled buffers instead of allocating a new slice for every request.
+type StreamCodec interface {
+	Codec
+	Encode(w io.Writer, v any) error
+}
+
+// StdCodec is a Codec backed by encoding/json.
+type StdCodec struct{}
+
+// Marshal implements the Codec interface.
+func (StdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements the Codec interface.
+func (StdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Encode implements the StreamCodec interface. The encoded value is followed by a newline.
+func (StdCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// DecodeReader reads r to the end into a pooled buffer and decodes it with the codec. A nil codec uses StdCodec.
+func DecodeReader(codec Codec, r io.Reader, v any) error {
+	if codec == nil {
+		codec = StdCodec{}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+
+	return codec.Unmarshal(buf.Bytes(), v)
+}
+
+// A ResponseDecoder decodes the body of a successful API response into v with the codec. Deployments which wrap
+// responses in an envelope, such as {"data": ..., "meta": ...}, unwrap them by setting a ResponseDecoder with
+// BroChatClientOption_ResponseDecoder. Error responses are decoded as usual. The body is a pooled buffer, so decoders
+// must not retain it after returning.
+type ResponseDecoder func(codec Codec, body []byte, v any) error
+
+// DefaultResponseDecoder decodes the body as is. It is the ResponseDecoder of clients which do not set one.
+func DefaultResponseDecoder(codec Codec, body []byte, v any) error {
+	return codec.Unmarshal(body, v)
+}
+
+// EnvelopeResponseDecoder returns a ResponseDecoder for responses which are an object holding the content under the
+// given field. The other fields of the envelope are ignored; wrap the decoder to read them.
+// Usage: chat.BroChatClientOption_ResponseDecoder(chat.EnvelopeResponseDecoder("data"))
+func EnvelopeResponseDecoder(field string) ResponseDecoder {
+	return func(codec Codec, body []byte, v any) error {
+		var envelope map[string]json.RawMessage
+
+		if err := codec.Unmarshal(body, &envelope); err != nil {
+			return err
+		}
+
+		content, ok := envelope[field]
+
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrResponseEnvelopeMissingField, field)
+		}
+
+		return codec.Unmarshal(content, v)
+	}
+}
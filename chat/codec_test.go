@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCodec is a StdCodec which counts the values it decodes.
+type countingCodec struct {
+	StdCodec
+	unmarshals atomic.Int32
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals.Add(1)
+	return c.StdCodec.Unmarshal(data, v)
+}
+
+func TestHandleUnsuccessfulStatusCode_Codec(t *testing.T) {
+	codec := &countingCodec{}
+
+	res := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"error_code":3,"error_details":["missing"],"request_id":"request"}`)),
+	}
+
+	result := handleUnsuccessfulStatusCode(codec, res)
+
+	if codec.unmarshals.Load() != 1 {
+		t.Errorf("codec decoded %d values, want 1", codec.unmarshals.Load())
+	}
+
+	if result.RequestId != "request" {
+		t.Errorf("RequestId = %q, want %q", result.RequestId, "request")
+	}
+}
+
+// historyPage returns the JSON of a full page of channel history, the largest response the client decodes routinely.
+func historyPage(t testing.TB) []byte {
+	messages := make([]ChatMessage, MAX_PAGE_SIZE)
+
+	for i := range messages {
+		messages[i] = ChatMessage{
+			Id:            fmt.Sprintf("message-%d", i),
+			ChannelId:     "channel",
+			SenderUserId:  "user",
+			Content:       strings.Repeat("hello there ", 10),
+			ReceivedAtUtc: time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+		}
+	}
+
+	data, err := json.Marshal(messages)
+
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	return data
+}
+
+// BenchmarkDecodeHistoryPage compares decoding a page of history through the client codec with streaming it through
+// a json.Decoder. Add a case for a candidate Codec to compare it against StdCodec.
+func BenchmarkDecodeHistoryPage(b *testing.B) {
+	data := historyPage(b)
+
+	b.Run("json.Decoder", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			var messages []ChatMessage
+
+			if err := json.NewDecoder(bytes.NewReader(data)).Decode(&messages); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	codecs := map[string]Codec{
+		"StdCodec": StdCodec{},
+	}
+
+	for name, codec := range codecs {
+		client := NewBroChatClient(nil, "https://example.com", BroChatClientOption_Codec(codec))
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+
+			for i := 0; i < b.N; i++ {
+				var messages []ChatMessage
+
+				if err := client.decodeResponse(bytes.NewReader(data), &messages); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeFeedContent compares marshaling the content of a feed message with each codec.
+func BenchmarkEncodeFeedContent(b *testing.B) {
+	message := ChatMessage{Id: "message", ChannelId: "channel", SenderUserId: "user", Content: "hello there"}
+
+	codecs := map[string]Codec{
+		"StdCodec": StdCodec{},
+	}
+
+	for name, codec := range codecs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := NewFeedMessageJSONWithCodec(codec, FEED_MESSAGE_TYPE_CHAT_MESSAGE, message); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,15 +1,149 @@
 package chat
 
 const (
-	GET_USER_URL_SUFFIX              = "/api/brochat/user"
-	GET_USERS_URL_SUFFIX             = "/api/brochat/users"
-	GET_CHANNEL_URL_SUFFIX           = "/api/brochat/channels/:channelId"
-	GET_CHANNEL_MESSAGES_URL_SUFFIX  = "/api/brochat/channels/:channelId/messages"
-	SEND_FRIEND_REQUEST_URL_SUFFIX   = "/api/brochat/friends/send-friend-request"
-	ACCEPT_FRIEND_REQUEST_URL_SUFFIX = "/api/brochat/friends/accept-friend-request"
-	GET_ROOMS_URL_SUFFIX             = "/api/brochat/rooms"
-	CREATE_ROOM_URL_SUFFIX           = "/api/brochat/rooms"
-	JOIN_ROOM_URL_SUFFIX             = "/api/brochat/rooms/:roomId/join"
+	GET_USER_URL_SUFFIX                         = "/api/brochat/user"
+	USER_STATUS_URL_SUFFIX                      = "/api/brochat/user/status"
+	USER_PRESENCE_URL_SUFFIX                    = "/api/brochat/user/presence"
+	NOTIFICATION_PREFERENCES_URL_SUFFIX         = "/api/brochat/user/notification-preferences"
+	PRIVACY_SETTINGS_URL_SUFFIX                 = "/api/brochat/user/privacy"
+	SIGNING_KEYS_URL_SUFFIX                     = "/api/brochat/user/signing-keys"
+	SIGNING_KEY_URL_SUFFIX                      = "/api/brochat/user/signing-keys/:keyId"
+	USER_SIGNING_KEYS_URL_SUFFIX                = "/api/brochat/users/:userId/signing-keys"
+	DATA_EXPORTS_URL_SUFFIX                     = "/api/brochat/user/data-exports"
+	DATA_EXPORT_URL_SUFFIX                      = "/api/brochat/user/data-exports/:exportId"
+	DOWNLOAD_DATA_EXPORT_URL_SUFFIX             = "/api/brochat/user/data-exports/:exportId/download"
+	SERVER_HEALTH_URL_SUFFIX                    = "/api/brochat/health"
+	SERVER_STATS_URL_SUFFIX                     = "/api/brochat/stats"
+	GET_USERS_BY_IDS_URL_SUFFIX                 = "/api/brochat/users/lookup"
+	GET_USERS_URL_SUFFIX                        = "/api/brochat/users"
+	GET_DIRECT_MESSAGE_CHANNEL_URL_SUFFIX       = "/api/brochat/users/:userId/direct-message-channel"
+	CREATE_GROUP_DIRECT_MESSAGE_URL_SUFFIX      = "/api/brochat/channels/group-direct-messages"
+	GROUP_DIRECT_MESSAGE_PARTICIPANT_URL_SUFFIX = "/api/brochat/channels/:channelId/participants/:userId"
+	GET_UNREAD_COUNTS_URL_SUFFIX                = "/api/brochat/channels/unread"
+	MARK_CHANNEL_READ_URL_SUFFIX                = "/api/brochat/channels/:channelId/read"
+	MUTE_CHANNEL_URL_SUFFIX                     = "/api/brochat/channels/:channelId/mute"
+	GET_CHANNEL_URL_SUFFIX                      = "/api/brochat/channels/:channelId"
+	GET_CHANNEL_MESSAGES_URL_SUFFIX             = "/api/brochat/channels/:channelId/messages"
+	GET_CHANNEL_MESSAGE_URL_SUFFIX              = "/api/brochat/channels/:channelId/messages/:messageId"
+	SEND_CHAT_MESSAGE_URL_SUFFIX                = "/api/brochat/channels/:channelId/messages"
+	EDIT_MESSAGE_URL_SUFFIX                     = "/api/brochat/channels/:channelId/messages/:messageId"
+	DELETE_MESSAGE_URL_SUFFIX                   = "/api/brochat/channels/:channelId/messages/:messageId"
+	IMPORT_CHANNEL_MESSAGES_URL_SUFFIX          = "/api/brochat/channels/:channelId/messages/import"
+	SEARCH_MESSAGES_URL_SUFFIX                  = "/api/brochat/messages/search"
+	GET_REACTIONS_URL_SUFFIX                    = "/api/brochat/channels/:channelId/messages/:messageId/reactions"
+	ADD_REACTION_URL_SUFFIX                     = "/api/brochat/channels/:channelId/messages/:messageId/reactions"
+	REMOVE_REACTION_URL_SUFFIX                  = "/api/brochat/channels/:channelId/messages/:messageId/reactions/:emoji"
+	SEND_FRIEND_REQUEST_URL_SUFFIX              = "/api/brochat/friends/send-friend-request"
+	ACCEPT_FRIEND_REQUEST_URL_SUFFIX            = "/api/brochat/friends/accept-friend-request"
+	GET_MUTUAL_FRIENDS_URL_SUFFIX               = "/api/brochat/users/:userId/mutual-friends"
+	REMOVE_FRIEND_URL_SUFFIX                    = "/api/brochat/friends/:userId"
+	GET_ROOMS_URL_SUFFIX                        = "/api/brochat/rooms"
+	DISCOVER_ROOMS_URL_SUFFIX                   = "/api/brochat/rooms/discover"
+	CREATE_ROOM_URL_SUFFIX                      = "/api/brochat/rooms"
+	UPDATE_ROOM_URL_SUFFIX                      = "/api/brochat/rooms/:roomId"
+	JOIN_ROOM_URL_SUFFIX                        = "/api/brochat/rooms/:roomId/join"
+	ROOM_JOIN_REQUESTS_URL_SUFFIX               = "/api/brochat/rooms/:roomId/join-requests"
+	APPROVE_JOIN_REQUEST_URL_SUFFIX             = "/api/brochat/rooms/:roomId/join-requests/:requestId/approve"
+	DENY_JOIN_REQUEST_URL_SUFFIX                = "/api/brochat/rooms/:roomId/join-requests/:requestId/deny"
+	GET_ROOM_AUDIT_LOG_URL_SUFFIX               = "/api/brochat/rooms/:roomId/audit-log"
+	KICK_USER_FROM_ROOM_URL_SUFFIX              = "/api/brochat/rooms/:roomId/members/:userId/kick"
+	REPORT_USER_URL_SUFFIX                      = "/api/brochat/users/:userId/report"
+	REPORT_MESSAGE_URL_SUFFIX                   = "/api/brochat/channels/:channelId/messages/:messageId/report"
+	UPLOAD_ATTACHMENT_URL_SUFFIX                = "/api/brochat/channels/:channelId/attachments"
+	DOWNLOAD_ATTACHMENT_URL_SUFFIX              = "/api/brochat/attachments/:attachmentId"
+	GET_MESSAGE_DRAFTS_URL_SUFFIX               = "/api/brochat/drafts"
+	GET_MESSAGE_EDIT_HISTORY_URL_SUFFIX         = "/api/brochat/channels/:channelId/messages/:messageId/history"
+	MESSAGE_DRAFT_URL_SUFFIX                    = "/api/brochat/channels/:channelId/draft"
+	ROOM_WEBHOOKS_URL_SUFFIX                    = "/api/brochat/rooms/:roomId/webhooks"
+	ROOM_WEBHOOK_URL_SUFFIX                     = "/api/brochat/rooms/:roomId/webhooks/:webhookId"
+	ROOM_VOICE_CHANNELS_URL_SUFFIX              = "/api/brochat/rooms/:roomId/voice-channels"
+	VOICE_PARTICIPANTS_URL_SUFFIX               = "/api/brochat/channels/:channelId/voice/participants"
+	JOIN_VOICE_CHANNEL_URL_SUFFIX               = "/api/brochat/channels/:channelId/voice/join"
+	LEAVE_VOICE_CHANNEL_URL_SUFFIX              = "/api/brochat/channels/:channelId/voice/leave"
+	VOICE_STATE_URL_SUFFIX                      = "/api/brochat/channels/:channelId/voice/state"
+	ROOM_INVITES_URL_SUFFIX                     = "/api/brochat/rooms/:roomId/invites"
+	ROOM_INVITE_URL_SUFFIX                      = "/api/brochat/rooms/:roomId/invites/:code"
+	JOIN_ROOM_BY_INVITE_URL_SUFFIX              = "/api/brochat/invites/:code/join"
+	FEED_URL_SUFFIX                             = "/api/brochat/feed"
+)
+
+// Shared limits enforced by the BroChat API. Clients can use these to reject invalid input before making a request.
+const (
+	// The maximum number of characters allowed in a chat message.
+	MAX_MESSAGE_LENGTH = 2000
+	// The minimum number of characters allowed in a room name.
+	MIN_ROOM_NAME_LENGTH = 3
+	// The maximum number of characters allowed in a room name.
+	MAX_ROOM_NAME_LENGTH = 50
+	// The minimum number of characters allowed in a room join password.
+	MIN_ROOM_PASSWORD_LENGTH = 4
+	// The maximum number of characters allowed in a room join password.
+	MAX_ROOM_PASSWORD_LENGTH = 128
+	// The minimum member capacity of a room, including the owner.
+	MIN_ROOM_MEMBERS = 2
+	// The maximum (and default) member capacity of a room, including the owner.
+	MAX_ROOM_MEMBERS = 1000
+	// The maximum number of tags a room can have.
+	MAX_ROOM_TAGS = 5
+	// The maximum number of characters allowed in a room tag.
+	MAX_ROOM_TAG_LENGTH = 24
+	// The maximum number of characters allowed in the message attached to a room join request.
+	MAX_JOIN_REQUEST_MESSAGE_LENGTH = 280
+	// The maximum number of rooms a user can own.
+	MAX_ROOMS_PER_USER = 20
+	// The maximum number of participants in a group direct message, including the creator.
+	MAX_GROUP_DIRECT_MESSAGE_PARTICIPANTS = 10
+	// The maximum number of attachments that can be included in a single chat message.
+	MAX_ATTACHMENTS_PER_MESSAGE = 10
+	// The maximum number of characters allowed in an embed title.
+	MAX_EMBED_TITLE_LENGTH = 256
+	// The maximum number of characters allowed in an embed description.
+	MAX_EMBED_DESCRIPTION_LENGTH = 4096
+	// The maximum number of fields allowed in an embed.
+	MAX_EMBED_FIELDS = 25
+	// The maximum time to live of an expiring message in seconds. (7 days)
+	MAX_MESSAGE_TTL_SECONDS = 7 * 24 * 60 * 60
+	// The maximum number of characters allowed in a custom status message.
+	MAX_STATUS_TEXT_LENGTH = 128
+	// The maximum number of users that can be resolved by a single user lookup request.
+	MAX_USERS_PER_LOOKUP = 200
+	// The maximum number of characters allowed in the details of a report.
+	MAX_REPORT_DETAILS_LENGTH = 1000
+	// The maximum number of messages that can be imported by a single import request.
+	MAX_IMPORT_BATCH_SIZE = 500
+	// The maximum number of characters allowed in the reason given for a moderation action such as a kick.
+	MAX_MODERATION_REASON_LENGTH = 512
+	// The maximum number of bytes allowed in a reaction emoji or custom emoji shortcode.
+	MAX_REACTION_EMOJI_LENGTH = 64
+	// The maximum number of webhooks a room can have.
+	MAX_WEBHOOKS_PER_ROOM = 10
+	// The maximum number of characters allowed in a webhook URL.
+	MAX_WEBHOOK_URL_LENGTH = 2048
+	// The maximum number of usable invites a room can have.
+	MAX_INVITES_PER_ROOM = 50
+	// The maximum time an invite works for in seconds. (30 days)
+	MAX_ROOM_INVITE_TTL_SECONDS = 30 * 24 * 60 * 60
+	// The maximum number of times an invite can be used.
+	MAX_ROOM_INVITE_USES = 1000
+	// The maximum number of characters allowed in the nonce of a feed request.
+	MAX_REPLAY_NONCE_LENGTH = 64
+	// The maximum page size for paginated queries. Anything larger will be set to this value.
+	MAX_PAGE_SIZE = 100
+)
+
+// The header used to carry the ID of a request for tracing.
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// Rate limit response headers.
+const (
+	// The number of requests allowed in a burst.
+	RATE_LIMIT_LIMIT_HEADER = "X-RateLimit-Limit"
+	// The number of requests left in the current burst.
+	RATE_LIMIT_REMAINING_HEADER = "X-RateLimit-Remaining"
+	// The number of seconds until the full burst is available again.
+	RATE_LIMIT_RESET_HEADER = "X-RateLimit-Reset"
+	// The number of seconds, or the HTTP date, after which a rejected request may be retried.
+	RETRY_AFTER_HEADER = "Retry-After"
 )
 
 type RelationshipType uint8
@@ -25,6 +159,82 @@ const (
 	RELATIONSHIP_TYPE_FRIENDSHIP_REQUESTED
 )
 
+type PresenceState string
+
+const (
+	// The user is online and active.
+	PRESENCE_STATE_ONLINE PresenceState = "online"
+	// The user is online but has been idle.
+	PRESENCE_STATE_AWAY PresenceState = "away"
+	// The user is online but does not want to be disturbed. Notifications should be suppressed.
+	PRESENCE_STATE_DO_NOT_DISTURB PresenceState = "do_not_disturb"
+	// The user is online but appears offline to other users. Only ever visible to the user themselves.
+	PRESENCE_STATE_INVISIBLE PresenceState = "invisible"
+	// The user is offline.
+	PRESENCE_STATE_OFFLINE PresenceState = "offline"
+)
+
+// IsOnline returns true if the presence state indicates that the user is visibly online.
+func (p PresenceState) IsOnline() bool {
+	return p == PRESENCE_STATE_ONLINE || p == PRESENCE_STATE_AWAY || p == PRESENCE_STATE_DO_NOT_DISTURB
+}
+
+type NotificationLevel string
+
+const (
+	// Notify for every message.
+	NOTIFICATION_LEVEL_ALL NotificationLevel = "all"
+	// Only notify for messages which mention the user.
+	NOTIFICATION_LEVEL_MENTIONS_ONLY NotificationLevel = "mentions_only"
+	// Never notify.
+	NOTIFICATION_LEVEL_NONE NotificationLevel = "none"
+)
+
+type JoinRequestStatus string
+
+const (
+	// The join request is awaiting a decision from the room owner.
+	JOIN_REQUEST_STATUS_PENDING JoinRequestStatus = "pending"
+	// The join request was approved and the user is now a member of the room.
+	JOIN_REQUEST_STATUS_APPROVED JoinRequestStatus = "approved"
+	// The join request was denied.
+	JOIN_REQUEST_STATUS_DENIED JoinRequestStatus = "denied"
+)
+
+type AuditAction string
+
+const (
+	// A user joined the room.
+	AUDIT_ACTION_MEMBER_JOINED AuditAction = "member_joined"
+	// A user left the room.
+	AUDIT_ACTION_MEMBER_LEFT AuditAction = "member_left"
+	// A user was kicked from the room.
+	AUDIT_ACTION_MEMBER_KICKED AuditAction = "member_kicked"
+	// The room was renamed.
+	AUDIT_ACTION_ROOM_RENAMED AuditAction = "room_renamed"
+	// The room settings (membership model, capacity, password, tags...) were changed.
+	AUDIT_ACTION_ROOM_SETTINGS_CHANGED AuditAction = "room_settings_changed"
+	// A member's role in the room was changed.
+	AUDIT_ACTION_ROLE_CHANGED AuditAction = "role_changed"
+	// A join request was approved.
+	AUDIT_ACTION_JOIN_REQUEST_APPROVED AuditAction = "join_request_approved"
+	// A join request was denied.
+	AUDIT_ACTION_JOIN_REQUEST_DENIED AuditAction = "join_request_denied"
+	// A message was deleted by a moderator.
+	AUDIT_ACTION_MESSAGE_DELETED AuditAction = "message_deleted"
+)
+
+type LastSeenVisibility string
+
+const (
+	// Everyone can see when the user was last online and their presence state.
+	LAST_SEEN_VISIBILITY_EVERYONE LastSeenVisibility = "everyone"
+	// Only the user's friends can see when the user was last online and their presence state.
+	LAST_SEEN_VISIBILITY_FRIENDS LastSeenVisibility = "friends"
+	// Nobody can see when the user was last online or their presence state.
+	LAST_SEEN_VISIBILITY_NOBODY LastSeenVisibility = "nobody"
+)
+
 type ChannelType uint8
 
 const (
@@ -32,6 +242,39 @@ const (
 	CHANNEL_TYPE_DIRECT_MESSAGE ChannelType = iota
 	// A channel that is used for group messages in a room.
 	CHANNEL_TYPE_ROOM
+	// A channel that is used for direct messaging between a small group of users outside of a room.
+	CHANNEL_TYPE_GROUP_DM
+	// A voice channel of a room. Members of the room join it to talk; chat messages cannot be sent to it.
+	CHANNEL_TYPE_VOICE
+)
+
+type DirectMessageChannelDisposition string
+
+const (
+	// The direct message channel is archived. Its history remains readable but no new messages can be sent.
+	DIRECT_MESSAGE_CHANNEL_DISPOSITION_ARCHIVE DirectMessageChannelDisposition = "archive"
+	// The direct message channel remains open.
+	DIRECT_MESSAGE_CHANNEL_DISPOSITION_RETAIN DirectMessageChannelDisposition = "retain"
+)
+
+type ChannelMode string
+
+const (
+	// Every member of the channel can post messages. An empty mode is treated as the default mode.
+	CHANNEL_MODE_DEFAULT ChannelMode = "default"
+	// Only the room owner and room moderators can post messages. Other members can read and react.
+	CHANNEL_MODE_ANNOUNCEMENT ChannelMode = "announcement"
+)
+
+type RoomRole string
+
+const (
+	// The owner of the room.
+	ROOM_ROLE_OWNER RoomRole = "owner"
+	// A moderator of the room. Moderators can kick members, delete messages and post in announcement channels.
+	ROOM_ROLE_MODERATOR RoomRole = "moderator"
+	// A regular member of the room.
+	ROOM_ROLE_MEMBER RoomRole = "member"
 )
 
 type RoomMembershipModel string
@@ -43,6 +286,86 @@ const (
 	PUBLIC_MEMBERSHIP_MODEL RoomMembershipModel = "public"
 )
 
+type MessageContentType string
+
+const (
+	// The message content is plain text. This is the default when no content type is provided.
+	MESSAGE_CONTENT_TYPE_TEXT MessageContentType = "text"
+	// The message content is a structured embed. The plain text content is used as a fallback for clients that cannot render embeds.
+	MESSAGE_CONTENT_TYPE_EMBED MessageContentType = "embed"
+	// The message is a voice note. The message will have a single attachment of kind ATTACHMENT_KIND_VOICE_NOTE.
+	MESSAGE_CONTENT_TYPE_VOICE_NOTE MessageContentType = "voice_note"
+)
+
+type MessageDeliveryState uint8
+
+const (
+	// The delivery state of the message is unknown.
+	DELIVERY_STATE_UNKNOWN MessageDeliveryState = iota
+	// The message has been accepted by the server.
+	DELIVERY_STATE_SENT
+	// The message has been delivered to the recipient's client.
+	DELIVERY_STATE_DELIVERED
+	// The message has been read by the recipient.
+	DELIVERY_STATE_READ
+)
+
+type AttachmentKind string
+
+const (
+	// A generic file attachment.
+	ATTACHMENT_KIND_FILE AttachmentKind = "file"
+	// An image attachment. Image attachments may include dimensions and thumbnails.
+	ATTACHMENT_KIND_IMAGE AttachmentKind = "image"
+	// A recorded audio clip. Voice note attachments include the duration of the recording.
+	ATTACHMENT_KIND_VOICE_NOTE AttachmentKind = "voice_note"
+)
+
+type ReportReason string
+
+const (
+	// Unsolicited or repetitive content.
+	REPORT_REASON_SPAM ReportReason = "spam"
+	// Targeted abuse or bullying of a user.
+	REPORT_REASON_HARASSMENT ReportReason = "harassment"
+	// Content attacking people based on a protected characteristic.
+	REPORT_REASON_HATE_SPEECH ReportReason = "hate_speech"
+	// Sexual, violent or otherwise inappropriate content.
+	REPORT_REASON_INAPPROPRIATE_CONTENT ReportReason = "inappropriate_content"
+	// A user pretending to be someone else.
+	REPORT_REASON_IMPERSONATION ReportReason = "impersonation"
+	// Content indicating a risk of self harm.
+	REPORT_REASON_SELF_HARM ReportReason = "self_harm"
+	// Any other reason. The report details should describe the problem.
+	REPORT_REASON_OTHER ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	// The report has been submitted and is awaiting review.
+	REPORT_STATUS_OPEN ReportStatus = "open"
+	// The report has been reviewed and action was taken.
+	REPORT_STATUS_ACTIONED ReportStatus = "actioned"
+	// The report has been reviewed and no action was required.
+	REPORT_STATUS_DISMISSED ReportStatus = "dismissed"
+)
+
+// UserSortOrder is the order of the users returned by GetUsers.
+type UserSortOrder string
+
+const (
+	// Users are sorted alphabetically by username.
+	USER_SORT_ORDER_USERNAME UserSortOrder = "username"
+	// Users are sorted by when they were last online, most recent first.
+	USER_SORT_ORDER_LAST_ONLINE UserSortOrder = "last_online"
+)
+
+// IsValid returns true if the sort order is one of the defined orders.
+func (o UserSortOrder) IsValid() bool {
+	return o == USER_SORT_ORDER_USERNAME || o == USER_SORT_ORDER_LAST_ONLINE
+}
+
 type FeedMessageType string
 
 const (
@@ -50,9 +373,9 @@ const (
 	FEED_MESSAGE_TYPE_CHAT_MESSAGE_REQUEST FeedMessageType = "brochat:feed_message_type:chat_message_request"
 	// Set active channel message type
 	FEED_MESSAGE_TYPE_SET_ACTIVE_CHANNEL_REQUEST FeedMessageType = "brochat:feed_message_type:set_active_channel_request"
-	// User online message type
+	// User online message type. Also sent when an online user changes between visible presence states. Carries a UserPresenceEvent.
 	FEED_MESSAGE_TYPE_USER_ONLINE_EVENT FeedMessageType = "brochat:feed_message_type:user_online_event"
-	// User offline message type
+	// User offline message type. Also sent when a user becomes invisible. Carries a UserPresenceEvent.
 	FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT FeedMessageType = "brochat:feed_message_type:user_offline_event"
 	// Chat notification message type
 	FEED_MESSAGE_TYPE_CHAT_NOTIFICATION FeedMessageType = "brochat:feed_message_type:chat_notification"
@@ -62,23 +385,79 @@ const (
 	FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED FeedMessageType = "brochat:feed_message_type:friend_request_recieved"
 	// Friend Request accepted type
 	FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED FeedMessageType = "brochat:feed_message_type:friend_request_accepted"
+	// Friend removed type
+	FEED_MESSAGE_TYPE_FRIEND_REMOVED FeedMessageType = "brochat:feed_message_type:friend_removed"
 	// Room created message type
 	FEED_MESSAGE_TYPE_ROOM_CREATED FeedMessageType = "brochat:feed_message_type:room_created"
 	// User joined a room message type
 	FEED_MESSAGE_TYPE_USER_JOINED_ROOM FeedMessageType = "brochat:feed_message_type:user_joined_room"
+	// A user has requested to join a room message type. Sent to the room owner.
+	FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RECEIVED FeedMessageType = "brochat:feed_message_type:room_join_request_received"
+	// A room join request has been approved or denied message type. Sent to the requesting user.
+	FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RESOLVED FeedMessageType = "brochat:feed_message_type:room_join_request_resolved"
+	// A user has been invited to a room message type. Sent to the invited user. Carries a RoomInviteReceivedEvent.
+	FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED FeedMessageType = "brochat:feed_message_type:room_invite_received"
+	// User was kicked from a room message type
+	FEED_MESSAGE_TYPE_USER_KICKED_FROM_ROOM FeedMessageType = "brochat:feed_message_type:user_kicked_from_room"
+	// A user's custom status message has changed.
+	FEED_MESSAGE_TYPE_USER_STATUS_CHANGED FeedMessageType = "brochat:feed_message_type:user_status_changed"
 	// The users profile has been updated. This indicates that the user should refresh their profile in thier local state.
 	FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED FeedMessageType = "brochat:feed_message_type:user_profile_updated"
+	// One of the user's relationships has been created, changed or removed. Carries a RelationshipChangedEvent which can be
+	// applied to the local state with ApplyRelationshipChange instead of refreshing the whole user.
+	FEED_MESSAGE_TYPE_RELATIONSHIP_CHANGED FeedMessageType = "brochat:feed_message_type:relationship_changed"
+	// Rooms have been added to, updated in or removed from the user's rooms. Carries a RoomsChangedEvent which can be
+	// applied to the local state with ApplyRoomsChange instead of refreshing the whole user.
+	FEED_MESSAGE_TYPE_ROOMS_CHANGED FeedMessageType = "brochat:feed_message_type:rooms_changed"
 	// The feed message indicating that a channel has been updated.
 	FEED_MESSAGE_TYPE_CHANNEL_UPDATED FeedMessageType = "brochat:feed_message_type:channel_updated"
+	// The feed message indicating that the user has read a channel on one of their devices. Sent to every session of the
+	// user so the other devices move their "new messages" divider. Carries a ReadPointer.
+	FEED_MESSAGE_TYPE_READ_POINTER_UPDATED FeedMessageType = "brochat:feed_message_type:read_pointer_updated"
 	// The feed message that represents a macro request
 	FEED_MESSAGE_TYPE_MACRO_REQUEST FeedMessageType = "brochat:feed_message_type:macro_request"
+	// The feed message indicating that the server has finished enriching a message. Example: unfurling link previews.
+	FEED_MESSAGE_TYPE_MESSAGE_ENRICHED FeedMessageType = "brochat:feed_message_type:message_enriched"
+	// The feed message indicating that a voice note has been posted to a channel.
+	FEED_MESSAGE_TYPE_VOICE_NOTE FeedMessageType = "brochat:feed_message_type:voice_note"
+	// The feed message indicating that an expiring message has expired and should be removed.
+	FEED_MESSAGE_TYPE_MESSAGE_EXPIRED FeedMessageType = "brochat:feed_message_type:message_expired"
+	// The feed message indicating that the delivery state of a message has changed for one of its recipients.
+	FEED_MESSAGE_TYPE_MESSAGE_DELIVERY_STATE_UPDATED FeedMessageType = "brochat:feed_message_type:message_delivery_state_updated"
+	// The feed message indicating that a user reacted to a message.
+	FEED_MESSAGE_TYPE_REACTION_ADDED FeedMessageType = "brochat:feed_message_type:reaction_added"
+	// The feed message indicating that a user removed their reaction from a message.
+	FEED_MESSAGE_TYPE_REACTION_REMOVED FeedMessageType = "brochat:feed_message_type:reaction_removed"
+	// A call signal offering a call to the other participant of a direct message channel, or renegotiating an active
+	// call. Carries a CallOffer.
+	FEED_MESSAGE_TYPE_CALL_OFFER FeedMessageType = "brochat:feed_message_type:call_offer"
+	// A call signal accepting a call offer. Carries a CallAnswer.
+	FEED_MESSAGE_TYPE_CALL_ANSWER FeedMessageType = "brochat:feed_message_type:call_answer"
+	// A call signal exchanging an ICE candidate. Carries a CallIceCandidate.
+	FEED_MESSAGE_TYPE_CALL_ICE_CANDIDATE FeedMessageType = "brochat:feed_message_type:call_ice_candidate"
+	// A call signal ending a call or refusing an offer. Carries a CallEnded.
+	FEED_MESSAGE_TYPE_CALL_ENDED FeedMessageType = "brochat:feed_message_type:call_ended"
+	// A call signal turning a participant's camera or microphone on or off. Carries a CallMediaStateChanged.
+	FEED_MESSAGE_TYPE_CALL_MEDIA_STATE_CHANGED FeedMessageType = "brochat:feed_message_type:call_media_state_changed"
+	// A call signal announcing that a participant started sharing their screen. Carries a CallScreenShareStarted.
+	FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STARTED FeedMessageType = "brochat:feed_message_type:call_screen_share_started"
+	// A call signal announcing that a participant stopped sharing their screen. Carries a CallScreenShareStopped.
+	FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STOPPED FeedMessageType = "brochat:feed_message_type:call_screen_share_stopped"
+	// A user joined a voice channel. Carries a VoiceParticipantEvent.
+	FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_JOINED FeedMessageType = "brochat:feed_message_type:voice_participant_joined"
+	// A user left a voice channel. Carries a VoiceParticipantEvent.
+	FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_LEFT FeedMessageType = "brochat:feed_message_type:voice_participant_left"
+	// A user in a voice channel started or stopped speaking, or was muted, unmuted, deafened or undeafened. Carries a
+	// VoiceParticipantEvent.
+	FEED_MESSAGE_TYPE_VOICE_STATE_UPDATED FeedMessageType = "brochat:feed_message_type:voice_state_updated"
 )
 
 type UserProfileUpdateCode uint8
 
 const (
-	// The users rooms have been updated.
+	// The users rooms have been updated. Servers which send FEED_MESSAGE_TYPE_ROOMS_CHANGED no longer send this.
 	USER_PROFILE_UPDATE_CODE_ROOM_UPDATE UserProfileUpdateCode = 0x1
-	// The users relationships have been updated.
+	// The users relationships have been updated. Servers which send FEED_MESSAGE_TYPE_RELATIONSHIP_CHANGED only send this
+	// for changes to several relationships at once.
 	USER_PROFILE_UPDATE_REASON_RELATIONSHIP_UPDATE UserProfileUpdateCode = 0x2
 )
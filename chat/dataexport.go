@@ -0,0 +1,166 @@
+package chat
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The default time WaitForDataExport waits between checks of an export.
+const DEFAULT_DATA_EXPORT_POLL_INTERVAL = 5 * time.Second
+
+// The entries of a data export archive.
+const (
+	// The JSON encoded User, including their relationships and rooms.
+	DATA_EXPORT_PROFILE_ENTRY = "profile.json"
+	// The directory holding the messages of each channel the user has been a member of. Each channel is a file named
+	// after the channel ID with the .ndjson extension, holding one ExportedMessage per line oldest first.
+	DATA_EXPORT_MESSAGES_DIRECTORY = "messages/"
+)
+
+type DataExportStatus string
+
+const (
+	// The export has been requested and is being prepared.
+	DATA_EXPORT_STATUS_PENDING DataExportStatus = "pending"
+	// The archive is ready to download.
+	DATA_EXPORT_STATUS_READY DataExportStatus = "ready"
+	// The export could not be prepared. See DataExport.FailureReason.
+	DATA_EXPORT_STATUS_FAILED DataExportStatus = "failed"
+	// The archive was ready but has been deleted. Request a new export.
+	DATA_EXPORT_STATUS_EXPIRED DataExportStatus = "expired"
+)
+
+// A DataExport is a request for an archive of everything the server holds about the user: their profile,
+// relationships and messages.
+type DataExport struct {
+	// The ID of the export.
+	Id string `json:"id"`
+	// The status of the export.
+	Status DataExportStatus `json:"status"`
+	// When the export was requested.
+	RequestedAtUtc time.Time `json:"requested_at_utc"`
+	// When the archive was ready. Will be nil until the export is ready.
+	CompletedAtUtc *time.Time `json:"completed_at_utc,omitempty"`
+	// When the archive will be deleted. Will be nil until the export is ready.
+	ExpiresAtUtc *time.Time `json:"expires_at_utc,omitempty"`
+	// The size of the archive in bytes. Zero until the export is ready.
+	SizeBytes int64 `json:"size_bytes"`
+	// Why the export failed. Only set when the status is DATA_EXPORT_STATUS_FAILED.
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// IsDone returns true if the export is no longer being prepared.
+func (e DataExport) IsDone() bool {
+	return e.Status != DATA_EXPORT_STATUS_PENDING
+}
+
+// WaitForDataExport checks an export every interval until it is no longer pending or the context is done, and returns
+// the last state of the export. Check the status of the content before downloading: only ready exports can be downloaded.
+func (c *BroChatClient) WaitForDataExport(ctx context.Context, accessToken string, exportId string, interval time.Duration) BroChatClientContentResult[DataExport] {
+	if interval <= 0 {
+		interval = DEFAULT_DATA_EXPORT_POLL_INTERVAL
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result := c.GetDataExport(accessToken, exportId)
+
+		if result.Err() != nil || result.Content.IsDone() {
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			return handleHttpRequestErrorWithContent(ctx.Err(), result.Content)
+		case <-ticker.C:
+		}
+	}
+}
+
+// A DataExportArchive reads a downloaded data export archive.
+type DataExportArchive struct {
+	reader *zip.Reader
+}
+
+// OpenDataExportArchive opens a data export archive of the given size, such as an *os.File written by DownloadDataExport.
+func OpenDataExportArchive(r io.ReaderAt, size int64) (*DataExportArchive, error) {
+	reader, err := zip.NewReader(r, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataExportArchive{reader: reader}, nil
+}
+
+// Profile returns the exported user, including their relationships and rooms.
+func (a *DataExportArchive) Profile() (User, error) {
+	var user User
+
+	file, err := a.reader.Open(DATA_EXPORT_PROFILE_ENTRY)
+
+	if err != nil {
+		return user, err
+	}
+
+	defer file.Close()
+
+	return user, json.NewDecoder(file).Decode(&user)
+}
+
+// ChannelIds returns the IDs of the channels with exported messages, sorted.
+func (a *DataExportArchive) ChannelIds() []string {
+	ids := make([]string, 0)
+
+	for _, file := range a.reader.File {
+		if dir, name := path.Split(file.Name); dir == DATA_EXPORT_MESSAGES_DIRECTORY && strings.HasSuffix(name, ".ndjson") {
+			ids = append(ids, strings.TrimSuffix(name, ".ndjson"))
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Messages calls fn with each exported message of a channel, oldest first, stopping at the first error fn returns.
+func (a *DataExportArchive) Messages(channelId string, fn func(ExportedMessage) error) error {
+	file, err := a.reader.Open(DATA_EXPORT_MESSAGES_DIRECTORY + channelId + ".ndjson")
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var message ExportedMessage
+
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return fmt.Errorf("decoding message on line %d of channel %s: %w", line, channelId, err)
+		}
+
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,83 @@
+package chat
+
+import "time"
+
+// A RecipientDeliveryState describes how far a message has progressed for a single recipient.
+type RecipientDeliveryState struct {
+	// The ID of the recipient.
+	UserId string `json:"user_id"`
+	// The delivery state of the message for the recipient.
+	State MessageDeliveryState `json:"state"`
+	// When the state was last updated.
+	UpdatedAtUtc time.Time `json:"updated_at_utc"`
+}
+
+// AggregateDeliveryState computes the overall delivery state of a message from its per-recipient states.
+// The overall state is the least advanced state of any recipient, so a DM shows "read" only once every recipient has read it.
+// The sender is excluded from the aggregation. If there are no recipients the message is considered sent.
+func AggregateDeliveryState(senderUserId string, states []RecipientDeliveryState) MessageDeliveryState {
+	aggregate := DELIVERY_STATE_READ
+	counted := false
+
+	for _, s := range states {
+		if s.UserId == senderUserId {
+			continue
+		}
+
+		counted = true
+
+		if s.State < aggregate {
+			aggregate = s.State
+		}
+	}
+
+	if !counted || aggregate < DELIVERY_STATE_SENT {
+		return DELIVERY_STATE_SENT
+	}
+
+	return aggregate
+}
+
+// ApplyDeliveryStateUpdate applies a delivery state update event to the message and recomputes its aggregate delivery state.
+// States only ever move forward, so a late "delivered" event will not overwrite a "read" state.
+// Returns false if the event does not apply to the message or did not change it.
+func ApplyDeliveryStateUpdate(message *ChatMessage, event MessageDeliveryStateUpdatedEvent) bool {
+	if message == nil || message.Id != event.MessageId {
+		return false
+	}
+
+	changed := false
+	found := false
+
+	for i := range message.RecipientStates {
+		s := &message.RecipientStates[i]
+
+		if s.UserId != event.UserId {
+			continue
+		}
+
+		found = true
+
+		if event.State > s.State {
+			s.State = event.State
+			s.UpdatedAtUtc = event.UpdatedAtUtc
+			changed = true
+		}
+	}
+
+	if !found {
+		message.RecipientStates = append(message.RecipientStates, RecipientDeliveryState{
+			UserId:       event.UserId,
+			State:        event.State,
+			UpdatedAtUtc: event.UpdatedAtUtc,
+		})
+
+		changed = true
+	}
+
+	if changed {
+		message.DeliveryState = AggregateDeliveryState(message.SenderUserId, message.RecipientStates)
+	}
+
+	return changed
+}
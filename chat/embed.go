@@ -0,0 +1,59 @@
+package chat
+
+// An Embed is a structured card that can be posted in place of plain text. Typically used by bots.
+type Embed struct {
+	// The title of the embed.
+	Title string `json:"title"`
+	// The main body text of the embed.
+	Description string `json:"description,omitempty"`
+	// An optional URL that the title links to.
+	Url string `json:"url,omitempty"`
+	// The accent color of the embed as a 24 bit RGB value. Example: 0xFF5733
+	Color uint32 `json:"color,omitempty"`
+	// The fields displayed in the body of the embed.
+	Fields []EmbedField `json:"fields,omitempty"`
+	// The footer of the embed. Will be nil if there is no footer.
+	Footer *EmbedFooter `json:"footer,omitempty"`
+}
+
+// An EmbedField is a name/value pair displayed in the body of an embed.
+type EmbedField struct {
+	// The name of the field.
+	Name string `json:"name"`
+	// The value of the field.
+	Value string `json:"value"`
+	// If true the field may be displayed on the same line as neighbouring inline fields.
+	Inline bool `json:"inline,omitempty"`
+}
+
+// An EmbedFooter is the small text displayed at the bottom of an embed.
+type EmbedFooter struct {
+	// The footer text.
+	Text string `json:"text"`
+	// An optional URL of an icon displayed next to the footer text.
+	IconUrl string `json:"icon_url,omitempty"`
+}
+
+// NewEmbedChatMessageRequest creates a ChatMessageRequest which posts the given embed.
+// The fallback content is displayed by clients that are unable to render embeds.
+func NewEmbedChatMessageRequest(channelId string, embed Embed, fallbackContent string) ChatMessageRequest {
+	if fallbackContent == "" {
+		fallbackContent = embed.Title
+	}
+
+	return ChatMessageRequest{
+		ChannelId:   channelId,
+		Content:     fallbackContent,
+		ContentType: MESSAGE_CONTENT_TYPE_EMBED,
+		Embed:       &embed,
+	}
+}
+
+// ResolveContentType returns the effective content type of the given value. An empty content type resolves to MESSAGE_CONTENT_TYPE_TEXT.
+func ResolveContentType(contentType MessageContentType) MessageContentType {
+	if contentType == "" {
+		return MESSAGE_CONTENT_TYPE_TEXT
+	}
+
+	return contentType
+}
@@ -0,0 +1,280 @@
+package chat
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// A CustomEmoji represents a room specific emoji that is rendered as an image rather than a unicode character.
+type CustomEmoji struct {
+	// The Id of the custom emoji.
+	Id string `json:"id"`
+	// The ID of the room that the custom emoji belongs to.
+	RoomId string `json:"room_id"`
+	// The shortcode used to reference the emoji without the surrounding colons. Example: "partyparrot"
+	Shortcode string `json:"shortcode"`
+	// The URL of the image that is used to render the emoji.
+	ImageUrl string `json:"image_url"`
+	// The ID of the user that added the custom emoji.
+	CreatedByUserId string `json:"created_by_user_id"`
+	// CreatedAtUtc is when the custom emoji was added.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+// emojiShortcode maps a shortcode to its unicode representation.
+type emojiShortcode struct {
+	shortcode string
+	unicode   string
+}
+
+// The known emoji shortcodes. When several shortcodes map to the same emoji the first one listed is the canonical
+// shortcode which is used when converting unicode back to shortcodes.
+var emojiShortcodes = []emojiShortcode{
+	{"smile", "😄"},
+	{"smiley", "😃"},
+	{"grin", "😁"},
+	{"grinning", "😀"},
+	{"joy", "😂"},
+	{"rofl", "🤣"},
+	{"laughing", "😆"},
+	{"sweat_smile", "😅"},
+	{"wink", "😉"},
+	{"blush", "😊"},
+	{"slightly_smiling_face", "🙂"},
+	{"upside_down_face", "🙃"},
+	{"heart_eyes", "😍"},
+	{"kissing_heart", "😘"},
+	{"yum", "😋"},
+	{"stuck_out_tongue", "😛"},
+	{"stuck_out_tongue_winking_eye", "😜"},
+	{"sunglasses", "😎"},
+	{"nerd_face", "🤓"},
+	{"thinking", "🤔"},
+	{"neutral_face", "😐"},
+	{"expressionless", "😑"},
+	{"unamused", "😒"},
+	{"roll_eyes", "🙄"},
+	{"smirk", "😏"},
+	{"grimacing", "😬"},
+	{"relieved", "😌"},
+	{"pensive", "😔"},
+	{"sleepy", "😪"},
+	{"sleeping", "😴"},
+	{"mask", "😷"},
+	{"nauseated_face", "🤢"},
+	{"exploding_head", "🤯"},
+	{"cowboy_hat_face", "🤠"},
+	{"partying_face", "🥳"},
+	{"confused", "😕"},
+	{"worried", "😟"},
+	{"frowning_face", "☹️"},
+	{"open_mouth", "😮"},
+	{"astonished", "😲"},
+	{"flushed", "😳"},
+	{"pleading_face", "🥺"},
+	{"cry", "😢"},
+	{"sob", "😭"},
+	{"scream", "😱"},
+	{"angry", "😠"},
+	{"rage", "😡"},
+	{"skull", "💀"},
+	{"poop", "💩"},
+	{"clown_face", "🤡"},
+	{"ghost", "👻"},
+	{"alien", "👽"},
+	{"robot", "🤖"},
+	{"see_no_evil", "🙈"},
+	{"wave", "👋"},
+	{"ok_hand", "👌"},
+	{"v", "✌️"},
+	{"crossed_fingers", "🤞"},
+	{"point_up", "☝️"},
+	{"thumbsup", "👍"},
+	{"+1", "👍"},
+	{"thumbsdown", "👎"},
+	{"-1", "👎"},
+	{"fist", "✊"},
+	{"facepunch", "👊"},
+	{"clap", "👏"},
+	{"raised_hands", "🙌"},
+	{"pray", "🙏"},
+	{"muscle", "💪"},
+	{"eyes", "👀"},
+	{"brain", "🧠"},
+	{"heart", "❤️"},
+	{"broken_heart", "💔"},
+	{"sparkling_heart", "💖"},
+	{"100", "💯"},
+	{"boom", "💥"},
+	{"zzz", "💤"},
+	{"fire", "🔥"},
+	{"sparkles", "✨"},
+	{"star", "⭐"},
+	{"zap", "⚡"},
+	{"rainbow", "🌈"},
+	{"sunny", "☀️"},
+	{"snowflake", "❄️"},
+	{"dog", "🐶"},
+	{"cat", "🐱"},
+	{"goat", "🐐"},
+	{"pizza", "🍕"},
+	{"hamburger", "🍔"},
+	{"taco", "🌮"},
+	{"coffee", "☕"},
+	{"beer", "🍺"},
+	{"beers", "🍻"},
+	{"tada", "🎉"},
+	{"gift", "🎁"},
+	{"trophy", "🏆"},
+	{"video_game", "🎮"},
+	{"game_die", "🎲"},
+	{"coin", "🪙"},
+	{"rocket", "🚀"},
+	{"moneybag", "💰"},
+	{"bulb", "💡"},
+	{"lock", "🔒"},
+	{"bell", "🔔"},
+	{"warning", "⚠️"},
+	{"x", "❌"},
+	{"white_check_mark", "✅"},
+	{"heavy_check_mark", "✔️"},
+	{"question", "❓"},
+	{"exclamation", "❗"},
+}
+
+var (
+	// Lookup table of shortcode to unicode.
+	emojiByShortcode map[string]string
+	// Replacer used to convert unicode emoji back to their canonical shortcodes.
+	emojiToShortcodeReplacer *strings.Replacer
+)
+
+func init() {
+	emojiByShortcode = make(map[string]string, len(emojiShortcodes))
+	canonical := make(map[string]string, len(emojiShortcodes))
+
+	for _, e := range emojiShortcodes {
+		emojiByShortcode[e.shortcode] = e.unicode
+
+		if _, ok := canonical[e.unicode]; !ok {
+			canonical[e.unicode] = e.shortcode
+		}
+	}
+
+	// The replacer compares candidates in argument order, so the longest sequences must come first
+	// to prevent an emoji with a variation selector from only being partially matched.
+	unicodes := make([]string, 0, len(canonical))
+
+	for u := range canonical {
+		unicodes = append(unicodes, u)
+	}
+
+	sort.Slice(unicodes, func(i, j int) bool {
+		if len(unicodes[i]) != len(unicodes[j]) {
+			return len(unicodes[i]) > len(unicodes[j])
+		}
+
+		return unicodes[i] < unicodes[j]
+	})
+
+	pairs := make([]string, 0, len(unicodes)*2)
+
+	for _, u := range unicodes {
+		pairs = append(pairs, u, ":"+canonical[u]+":")
+	}
+
+	emojiToShortcodeReplacer = strings.NewReplacer(pairs...)
+}
+
+// LookupEmojiShortcode returns the unicode representation of the given shortcode. The shortcode may be provided with or without the surrounding colons.
+// The second return value will be false if the shortcode is not recognized.
+func LookupEmojiShortcode(shortcode string) (string, bool) {
+	shortcode = strings.ToLower(strings.Trim(shortcode, ":"))
+	val, ok := emojiByShortcode[shortcode]
+	return val, ok
+}
+
+// ReplaceEmojiShortcodes converts all recognized :shortcode: sequences in the given content to their unicode representation.
+// Unrecognized shortcodes (including room custom emoji) are left untouched.
+// Usage: ReplaceEmojiShortcodes("gg :fire:") // "gg 🔥"
+func ReplaceEmojiShortcodes(content string) string {
+	if strings.IndexByte(content, ':') < 0 {
+		return content
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(content))
+
+	for {
+		start := strings.IndexByte(content, ':')
+
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(content[start+1:], ':')
+
+		if end < 0 {
+			break
+		}
+
+		end += start + 1
+		name := content[start+1 : end]
+
+		if isShortcodeName(name) {
+			if val, ok := emojiByShortcode[strings.ToLower(name)]; ok {
+				sb.WriteString(content[:start])
+				sb.WriteString(val)
+				content = content[end+1:]
+				continue
+			}
+		}
+
+		// The closing colon could be the start of the next shortcode.
+		sb.WriteString(content[:end])
+		content = content[end:]
+	}
+
+	sb.WriteString(content)
+
+	return sb.String()
+}
+
+// ReplaceEmojiWithShortcodes converts all recognized unicode emoji in the given content to their canonical :shortcode: representation.
+// This is useful for clients which cannot render unicode emoji.
+// Usage: ReplaceEmojiWithShortcodes("gg 🔥") // "gg :fire:"
+func ReplaceEmojiWithShortcodes(content string) string {
+	return emojiToShortcodeReplacer.Replace(content)
+}
+
+// FindCustomEmoji returns the custom emoji with the given shortcode from the provided list.
+// The shortcode may be provided with or without the surrounding colons. The second return value will be false if no match is found.
+func FindCustomEmoji(emojis []CustomEmoji, shortcode string) (CustomEmoji, bool) {
+	shortcode = strings.ToLower(strings.Trim(shortcode, ":"))
+
+	for _, e := range emojis {
+		if strings.ToLower(e.Shortcode) == shortcode {
+			return e, true
+		}
+	}
+
+	return CustomEmoji{}, false
+}
+
+// isShortcodeName reports whether the given value is a syntactically valid shortcode name.
+func isShortcodeName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '+', r == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,122 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// The readable names of the numeric enum types. Values without a name are encoded as numbers so unknown values survive a round trip.
+var (
+	relationshipTypeNames = map[RelationshipType]string{
+		RELATIONSHIP_TYPE_DEFAULT:                 "default",
+		RELATIONSHIP_TYPE_FRIEND:                  "friend",
+		RELATIONSHIP_TYPE_FRIEND_REQUEST_RECIEVED: "friend_request_received",
+		RELATIONSHIP_TYPE_FRIENDSHIP_REQUESTED:    "friendship_requested",
+	}
+	channelTypeNames = map[ChannelType]string{
+		CHANNEL_TYPE_DIRECT_MESSAGE: "direct_message",
+		CHANNEL_TYPE_ROOM:           "room",
+		CHANNEL_TYPE_GROUP_DM:       "group_dm",
+		CHANNEL_TYPE_VOICE:          "voice",
+	}
+	userProfileUpdateCodeNames = map[UserProfileUpdateCode]string{
+		USER_PROFILE_UPDATE_CODE_ROOM_UPDATE:           "room_update",
+		USER_PROFILE_UPDATE_REASON_RELATIONSHIP_UPDATE: "relationship_update",
+	}
+)
+
+// String returns the readable name of the relationship type.
+func (t RelationshipType) String() string {
+	return enumString(relationshipTypeNames, t)
+}
+
+// MarshalJSON encodes the relationship type as its readable name.
+func (t RelationshipType) MarshalJSON() ([]byte, error) {
+	return marshalEnum(relationshipTypeNames, t)
+}
+
+// UnmarshalJSON decodes the relationship type from either its readable name or its numeric value.
+func (t *RelationshipType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(relationshipTypeNames, data, t)
+}
+
+// String returns the readable name of the channel type.
+func (t ChannelType) String() string {
+	return enumString(channelTypeNames, t)
+}
+
+// MarshalJSON encodes the channel type as its readable name.
+func (t ChannelType) MarshalJSON() ([]byte, error) {
+	return marshalEnum(channelTypeNames, t)
+}
+
+// UnmarshalJSON decodes the channel type from either its readable name or its numeric value.
+func (t *ChannelType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(channelTypeNames, data, t)
+}
+
+// String returns the readable name of the update code.
+func (c UserProfileUpdateCode) String() string {
+	return enumString(userProfileUpdateCodeNames, c)
+}
+
+// MarshalJSON encodes the update code as its readable name.
+func (c UserProfileUpdateCode) MarshalJSON() ([]byte, error) {
+	return marshalEnum(userProfileUpdateCodeNames, c)
+}
+
+// UnmarshalJSON decodes the update code from either its readable name or its numeric value.
+func (c *UserProfileUpdateCode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(userProfileUpdateCodeNames, data, c)
+}
+
+// enumString returns the readable name of the value or its number if the value has no name.
+func enumString[T ~uint8](names map[T]string, value T) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+// marshalEnum encodes the value as a JSON string if it has a name and as a JSON number otherwise.
+func marshalEnum[T ~uint8](names map[T]string, value T) ([]byte, error) {
+	if name, ok := names[value]; ok {
+		return json.Marshal(name)
+	}
+
+	return json.Marshal(uint8(value))
+}
+
+// unmarshalEnum decodes a value from either a JSON string containing its name or a JSON number.
+func unmarshalEnum[T ~uint8](names map[T]string, data []byte, value *T) error {
+	var name string
+
+	if err := json.Unmarshal(data, &name); err == nil {
+		for v, n := range names {
+			if n == name {
+				*value = v
+				return nil
+			}
+		}
+
+		// Numbers encoded as strings are accepted for compatibility with loosely typed clients
+		if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+			*value = T(n)
+			return nil
+		}
+
+		return fmt.Errorf("unrecognized %T value %q", *value, name)
+	}
+
+	var number uint8
+
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("invalid %T value %s", *value, data)
+	}
+
+	*value = T(number)
+
+	return nil
+}
@@ -1,26 +1,185 @@
 package chat
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BroChatError is the response returned by the BroChat API when an error is encountered while processing a request.
 type BroChatError struct {
 	// The error code
-	Code BroChatResponseCode `json:"error_code"`
-	// The error message
-	ErrorDetails []string `json:"error_details"`
+	Code BroChatResponseCode
+	// A human readable summary of the error. May be empty.
+	Message string
+	// Additional details about the error. Example: the individual validation failures.
+	Details []string
+	// The ID of the request that failed. Used to trace a client reported error on the server. May be empty.
+	RequestId string
+}
+
+// brochatErrorJSON is the wire representation of a BroChatError. The legacy error_details field holds the message
+// followed by the details, so clients which predate the message and details fields still receive all of the information.
+type brochatErrorJSON struct {
+	Code         BroChatResponseCode `json:"error_code"`
+	Message      *string             `json:"message,omitempty"`
+	Details      []string            `json:"details,omitempty"`
+	ErrorDetails []string            `json:"error_details"`
+	RequestId    string              `json:"request_id,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e BroChatError) MarshalJSON() ([]byte, error) {
+	wire := brochatErrorJSON{
+		Code:         e.Code,
+		Details:      e.Details,
+		ErrorDetails: e.AllDetails(),
+		RequestId:    e.RequestId,
+	}
+
+	if e.Message != "" {
+		wire.Message = &e.Message
+	}
+
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Responses which only carry the legacy error_details field
+// are decoded into Details.
+func (e *BroChatError) UnmarshalJSON(data []byte) error {
+	var wire brochatErrorJSON
+
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	e.Code = wire.Code
+	e.Message = ""
+	e.Details = wire.Details
+	e.RequestId = wire.RequestId
+
+	if wire.Message != nil {
+		e.Message = *wire.Message
+	}
+
+	if wire.Message == nil && wire.Details == nil {
+		e.Details = wire.ErrorDetails
+	}
+
+	return nil
+}
+
+// AllDetails returns the message followed by the details.
+func (e BroChatError) AllDetails() []string {
+	details := make([]string, 0, len(e.Details)+1)
+
+	if e.Message != "" {
+		details = append(details, e.Message)
+	}
+
+	return append(details, e.Details...)
+}
+
+// Error implements the error interface.
+func (e *BroChatError) Error() string {
+	details := e.AllDetails()
+
+	if len(details) == 0 {
+		return fmt.Sprintf("brochat error %d", e.Code)
+	}
+
+	return fmt.Sprintf("brochat error %d: %s", e.Code, strings.Join(details, "; "))
 }
 
 // NewErrorResponse creates an ErrorResponse with the given code and message.
 // Usage: NewErrorResponse(0, "An error occured during validation")
 func NewErrorResponse(code BroChatResponseCode, message string) *BroChatError {
 	return &BroChatError{
-		Code:         code,
-		ErrorDetails: []string{message},
+		Code:    code,
+		Message: message,
 	}
 }
 
-// NewErrorResponseWithDetails creates an ErrorResponse with the given code and message.
+// NewErrorResponseWithDetails creates an ErrorResponse with the given code and details.
 // Usage: NewErrorResponseWithDetails(0, "An error occured during validation", "The username field is required")
 func NewErrorResponseWithDetails(code BroChatResponseCode, details ...string) *BroChatError {
 	return &BroChatError{
-		Code:         code,
-		ErrorDetails: details,
+		Code:    code,
+		Details: details,
 	}
 }
+
+// NewErrorResponseWithMessageAndDetails creates an ErrorResponse with the given code, message and details.
+// Usage: NewErrorResponseWithMessageAndDetails(0, "An error occured during validation", "The username field is required")
+func NewErrorResponseWithMessageAndDetails(code BroChatResponseCode, message string, details ...string) *BroChatError {
+	return &BroChatError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// NewUnhandledError creates the error response returned when an unexpected error occurs while processing a request.
+func NewUnhandledError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR, details...)
+}
+
+// NewForbiddenError creates the error response returned when the user does not have permission to perform the operation.
+func NewForbiddenError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR, details...)
+}
+
+// NewValidationError creates the error response returned when the request parameters are invalid.
+// Usage: NewValidationError("The username field is required")
+func NewValidationError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, details...)
+}
+
+// NewValidationErrorFromFields creates the error response for the validation errors produced by a Validate method.
+// Usage: NewValidationErrorFromFields(request.Validate())
+func NewValidationErrorFromFields(errs ValidationErrors) *BroChatError {
+	return NewErrorResponseWithMessageAndDetails(BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, "the request is invalid", errs.Details()...)
+}
+
+// NewRequestParseError creates the error response returned when the request body could not be parsed.
+func NewRequestParseError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_REQUEST_PARSE_ERROR, details...)
+}
+
+// NewNotFoundError creates the error response returned when the requested resource does not exist.
+// Usage: NewNotFoundError("room not found")
+func NewNotFoundError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR, details...)
+}
+
+// NewDataConflictError creates the error response returned when the request conflicts with the current state of the resource.
+func NewDataConflictError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_DATA_CONFLICT_ERROR, details...)
+}
+
+// NewInvalidOperationError creates the error response returned when the requested operation is invalid.
+// Usage: NewInvalidOperationError("you cannot send a friend request to yourself")
+func NewInvalidOperationError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_INVALID_OPERATION, details...)
+}
+
+// NewUnauthorizedError creates the error response returned when the request is missing valid credentials.
+func NewUnauthorizedError(details ...string) *BroChatError {
+	return NewErrorResponseWithDetails(BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR, details...)
+}
+
+// NewRoomFullError creates the error response returned to a client that tried to join a room at capacity.
+func NewRoomFullError() *BroChatError {
+	return NewErrorResponse(BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR, "the room is full")
+}
+
+// NewRateLimitedError creates the error response returned to a client that has sent too many requests.
+// A retryAfter of 0 omits the retry hint.
+func NewRateLimitedError(retryAfter time.Duration) *BroChatError {
+	if retryAfter <= 0 {
+		return NewErrorResponse(BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR, "too many requests")
+	}
+
+	return NewErrorResponse(BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR, fmt.Sprintf("too many requests, retry after %s", retryAfter.Round(time.Second)))
+}
@@ -0,0 +1,241 @@
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+)
+
+// Describes the output format of a channel export.
+type ExportFormat string
+
+const (
+	// Newline delimited JSON. Each line is an ExportedMessage, newest message first.
+	EXPORT_FORMAT_JSON ExportFormat = "ndjson"
+	// A self contained HTML transcript.
+	EXPORT_FORMAT_HTML ExportFormat = "html"
+)
+
+// An ExportedMessage is a single message in a channel export.
+type ExportedMessage struct {
+	ChatMessage
+	// The username of the user that sent the message. Empty if the user could not be resolved.
+	SenderUsername string `json:"sender_username"`
+}
+
+// exportedMessageJSON has the fields of ExportedMessage without the methods promoted from ChatMessage.
+type exportedMessageJSON struct {
+	chatMessageJSON
+	SenderUsername string `json:"sender_username"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. See ChatMessage.MarshalJSON.
+func (m ExportedMessage) MarshalJSON() ([]byte, error) {
+	m.syncReceivedAt()
+	return json.Marshal(exportedMessageJSON{chatMessageJSON(m.ChatMessage), m.SenderUsername})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. See ChatMessage.UnmarshalJSON.
+func (m *ExportedMessage) UnmarshalJSON(data []byte) error {
+	var decoded exportedMessageJSON
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	m.ChatMessage, m.SenderUsername = ChatMessage(decoded.chatMessageJSON), decoded.SenderUsername
+	m.syncReceivedAt()
+
+	return nil
+}
+
+// ChannelExporter writes the full history of a channel to a writer.
+type ChannelExporter struct {
+	client   *BroChatClient
+	pageSize uint64
+}
+
+// ChannelExporterOption is a type for the options that can be passed to NewChannelExporter.
+type ChannelExporterOption func(*ChannelExporter)
+
+// Sets the number of messages fetched per request. Defaults to MAX_PAGE_SIZE.
+func ChannelExporterOption_PageSize(pageSize uint64) ChannelExporterOption {
+	return func(e *ChannelExporter) {
+		if pageSize > 0 && pageSize <= MAX_PAGE_SIZE {
+			e.pageSize = pageSize
+		}
+	}
+}
+
+// NewChannelExporter creates a new ChannelExporter which uses the given client to fetch channel history.
+func NewChannelExporter(client *BroChatClient, options ...ChannelExporterOption) *ChannelExporter {
+	exporter := &ChannelExporter{
+		client:   client,
+		pageSize: MAX_PAGE_SIZE,
+	}
+
+	for _, opt := range options {
+		opt(exporter)
+	}
+
+	return exporter
+}
+
+// Export fetches the full history of a channel, resolves the sender usernames and writes it to the writer in the given format.
+// The history is paged backwards from the most recent message so that messages sent during the export do not shift the pages.
+// Each page is written as soon as it is fetched, so only one page is held in memory and messages are written newest first.
+// The HTML transcript is laid out so that browsers still show the oldest message at the top. If a request fails part way
+// through, the messages already written are left in the writer.
+func (e *ChannelExporter) Export(accessToken string, channelId string, format ExportFormat, w io.Writer) error {
+	if format != EXPORT_FORMAT_JSON && format != EXPORT_FORMAT_HTML {
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	writeMessage := json.NewEncoder(bw).Encode
+
+	if format == EXPORT_FORMAT_HTML {
+		if err := htmlTranscriptTemplate.ExecuteTemplate(bw, "header", channelId); err != nil {
+			return err
+		}
+
+		writeMessage = func(m any) error {
+			return htmlTranscriptTemplate.ExecuteTemplate(bw, "message", m)
+		}
+	}
+
+	usernames := make(map[string]string)
+
+	err := e.fetchHistory(accessToken, channelId, func(page []ChatMessage) error {
+		if err := e.resolveUsernames(accessToken, page, usernames); err != nil {
+			return err
+		}
+
+		for _, m := range page {
+			if err := writeMessage(ExportedMessage{ChatMessage: m, SenderUsername: usernames[m.SenderUserId]}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if format == EXPORT_FORMAT_HTML {
+		if err := htmlTranscriptTemplate.ExecuteTemplate(bw, "footer", nil); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// fetchHistory pages backwards through the channel history, passing each page to fn newest message first.
+// Messages already passed in the previous page are left out, as pages can overlap when messages share a timestamp.
+func (e *ChannelExporter) fetchHistory(accessToken string, channelId string, fn func(page []ChatMessage) error) error {
+	previous := make(map[string]struct{})
+	before := ""
+
+	for {
+		options := []GetChannelMessagesOption{GetChannelMessages_PageSize(e.pageSize)}
+
+		if before != "" {
+			options = append(options, GetChannelMessages_BeforeMessage(before))
+		}
+
+		result := e.client.GetChannelMessages(accessToken, channelId, options...)
+
+		if err := result.Err(); err != nil {
+			return err
+		}
+
+		page := make([]ChatMessage, 0, len(result.Content))
+		current := make(map[string]struct{}, len(result.Content))
+
+		for _, m := range result.Content {
+			current[m.Id] = struct{}{}
+
+			if _, ok := previous[m.Id]; !ok {
+				page = append(page, m)
+			}
+		}
+
+		if len(page) == 0 {
+			return nil
+		}
+
+		SortMessages(page)
+		slices.Reverse(page)
+
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		if uint64(len(result.Content)) < e.pageSize {
+			return nil
+		}
+
+		previous = current
+		before = page[len(page)-1].Id
+	}
+}
+
+// resolveUsernames looks up the usernames of the senders in the messages which are not already in usernames and adds
+// them. Senders which could not be resolved are added with an empty username so they are not looked up again.
+func (e *ChannelExporter) resolveUsernames(accessToken string, messages []ChatMessage, usernames map[string]string) error {
+	ids := make([]string, 0)
+
+	for _, m := range messages {
+		if _, ok := usernames[m.SenderUserId]; !ok {
+			usernames[m.SenderUserId] = ""
+			ids = append(ids, m.SenderUserId)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := e.client.GetUsersByIds(accessToken, ids)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	for _, u := range result.Content {
+		usernames[u.Id] = u.Username
+	}
+
+	return nil
+}
+
+// htmlTranscriptTemplate renders a transcript in parts, so messages can be written as their page is fetched. Messages
+// are written newest first and displayed in reverse, oldest at the top.
+var htmlTranscriptTemplate = template.Must(template.New("transcript").Parse(`{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>BroChat transcript - {{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.messages { display: flex; flex-direction: column-reverse; }
+.message { margin: 0.25em 0; }
+.time { color: #888; font-size: 0.85em; }
+.sender { font-weight: bold; }
+.content { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Channel {{.}}</h1>
+<div class="messages">
+{{end}}{{define "message"}}<div class="message" id="{{.Id}}"><span class="time">{{.ReceivedAt.UTC.Format "2006-01-02 15:04:05"}}</span> <span class="sender">{{if .SenderUsername}}{{.SenderUsername}}{{else}}{{.SenderUserId}}{{end}}</span>: <span class="content">{{.Content}}</span></div>
+{{end}}{{define "footer"}}</div>
+</body>
+</html>
+{{end}}`))
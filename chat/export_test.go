@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newExportServer serves the messages of a channel newest first, paged with before-msg, and resolves user IDs to
+// usernames. Messages with the same timestamp as the before message are returned again, as pages can overlap.
+func newExportServer(t *testing.T, messages []ChatMessage) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/brochat/channels/{channelId}/messages", func(w http.ResponseWriter, r *http.Request) {
+		page := slices.Clone(messages)
+
+		if before := r.URL.Query().Get("before-msg"); before != "" {
+			i := slices.IndexFunc(page, func(m ChatMessage) bool { return m.Id == before })
+			cutoff := page[i].ReceivedAt()
+			page = slices.DeleteFunc(page, func(m ChatMessage) bool { return m.Id == before || m.ReceivedAt().After(cutoff) })
+		}
+
+		slices.Reverse(page)
+		size, _ := strconv.Atoi(r.URL.Query().Get("page-size"))
+		page = page[:min(size, len(page))]
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+
+	mux.HandleFunc("POST "+GET_USERS_BY_IDS_URL_SUFFIX, func(w http.ResponseWriter, r *http.Request) {
+		var request GetUsersByIdsRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		users := make([]UserInfo, 0, len(request.UserIds))
+
+		for _, id := range request.UserIds {
+			users = append(users, UserInfo{Id: id, Username: "name-" + id})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestChannelExporter_Export(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	messages := make([]ChatMessage, 0)
+
+	for i := 1; i <= 7; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+
+		// Messages 3 and 4 share a timestamp, so they are on both sides of a page boundary
+		if i == 4 {
+			at = messages[2].ReceivedAtUtc
+		}
+
+		messages = append(messages, ChatMessage{Id: fmt.Sprintf("m%d", i), SenderUserId: fmt.Sprintf("u%d", i%2), Content: fmt.Sprintf("message %d", i), ReceivedAtUtc: at})
+	}
+
+	server := newExportServer(t, messages)
+	exporter := NewChannelExporter(NewBroChatClient(server.Client(), server.URL), ChannelExporterOption_PageSize(3))
+
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := exporter.Export("token", "c", EXPORT_FORMAT_JSON, &buf); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+
+		var ids []string
+		scanner := bufio.NewScanner(&buf)
+
+		for scanner.Scan() {
+			var exported ExportedMessage
+
+			if err := json.Unmarshal(scanner.Bytes(), &exported); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if exported.SenderUsername != "name-"+exported.SenderUserId {
+				t.Errorf("message %s has sender username %q", exported.Id, exported.SenderUsername)
+			}
+
+			ids = append(ids, exported.Id)
+		}
+
+		if want := []string{"m7", "m6", "m5", "m4", "m3", "m2", "m1"}; !slices.Equal(ids, want) {
+			t.Errorf("exported ids = %v, want %v", ids, want)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := exporter.Export("token", "c", EXPORT_FORMAT_HTML, &buf); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+
+		html := buf.String()
+
+		if !strings.HasPrefix(html, "<!DOCTYPE html>") || !strings.HasSuffix(html, "</html>\n") {
+			t.Errorf("transcript is not a complete document:\n%s", html)
+		}
+
+		if n := strings.Count(html, `<div class="message"`); n != len(messages) {
+			t.Errorf("transcript has %d messages, want %d", n, len(messages))
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// A Tenant is a BroChat workspace served to a ClientFactory.
+type Tenant struct {
+	// Identifies the tenant within the factory.
+	Id string
+	// The base url of the tenant's API, including any path it is served under. Example: https://example.com/tenants/acme
+	BaseUrl string
+	// Supplies the access tokens of the tenant. May be nil, in which case the access token passed to each method is used.
+	TokenProvider TokenProvider
+}
+
+// A RequestObserver is called after every request made by the clients of a ClientFactory, such as to record metrics
+// in the registry of the host. The response is nil if the request failed. The response body must not be read.
+// Observers are called concurrently.
+type RequestObserver func(tenantId string, req *http.Request, res *http.Response, err error, duration time.Duration)
+
+// ClientFactory creates a BroChatClient for each tenant of a host serving many BroChat workspaces from one binary.
+// The clients share one transport, so connections and TLS sessions are pooled across tenants, and one RequestObserver.
+// Each tenant's client is created once and reused along with its TokenProvider, so the tokens it caches are kept.
+// ClientFactory is safe for concurrent use.
+type ClientFactory struct {
+	httpClient    *http.Client
+	observer      RequestObserver
+	clientOptions []BroChatClientOption
+
+	mu      sync.Mutex
+	clients map[string]tenantClient
+}
+
+// tenantClient is a client created for a tenant, along with the base url it was created for.
+type tenantClient struct {
+	baseUrl string
+	client  *BroChatClient
+}
+
+// ClientFactoryOption is a type for the options that can be passed to NewClientFactory.
+type ClientFactoryOption func(*ClientFactory)
+
+// Sets the http client whose settings and transport are shared by the clients. A client without a transport of its
+// own gets a tuned one, see WithTunedTransport. Defaults to a client with a tuned transport.
+func ClientFactoryOption_HttpClient(httpClient *http.Client) ClientFactoryOption {
+	return func(f *ClientFactory) {
+		f.httpClient = httpClient
+	}
+}
+
+// Sets a function called after every request made by the clients.
+func ClientFactoryOption_RequestObserver(observer RequestObserver) ClientFactoryOption {
+	return func(f *ClientFactory) {
+		f.observer = observer
+	}
+}
+
+// Sets the options applied to every client, such as BroChatClientOption_Codec. The transport options, such as
+// BroChatClientOption_MaxIdleConnsPerHost, tune the transport the factory creates and shares between the clients. They
+// have no effect if the http client set with ClientFactoryOption_HttpClient has a transport of its own.
+func ClientFactoryOption_ClientOptions(options ...BroChatClientOption) ClientFactoryOption {
+	return func(f *ClientFactory) {
+		f.clientOptions = append(f.clientOptions, options...)
+	}
+}
+
+// NewClientFactory creates a factory with no tenants.
+func NewClientFactory(options ...ClientFactoryOption) *ClientFactory {
+	f := &ClientFactory{
+		clients: make(map[string]tenantClient),
+	}
+
+	for _, opt := range options {
+		opt(f)
+	}
+
+	// The clients are given the shared transport, so the transport options are applied to it instead
+	config := &BroChatClient{transport: DefaultTransportConfig()}
+
+	for _, opt := range f.clientOptions {
+		opt(config)
+	}
+
+	f.httpClient = WithTunedTransport(f.httpClient, config.transport)
+
+	return f
+}
+
+// Client returns the client of the tenant, creating it on first use. A client is created again if the base url of the
+// tenant has changed. To replace the TokenProvider of a tenant, Remove the tenant first.
+func (f *ClientFactory) Client(tenant Tenant) *BroChatClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if cached, ok := f.clients[tenant.Id]; ok && cached.baseUrl == tenant.BaseUrl {
+		return cached.client
+	}
+
+	httpClient := *f.httpClient
+
+	if f.observer != nil {
+		httpClient.Transport = &observingTransport{base: httpClient.Transport, tenantId: tenant.Id, observer: f.observer}
+	}
+
+	var client *BroChatClient
+
+	if tenant.TokenProvider != nil {
+		client = NewBroChatClient(NewTokenProviderHttpClient(&httpClient, tenant.TokenProvider), tenant.BaseUrl, f.clientOptions...)
+	} else {
+		client = NewBroChatClient(&httpClient, tenant.BaseUrl, f.clientOptions...)
+	}
+
+	f.clients[tenant.Id] = tenantClient{baseUrl: tenant.BaseUrl, client: client}
+
+	return client
+}
+
+// Remove forgets the client of the tenant, such as when the tenant is deprovisioned. Clients already handed out keep working.
+func (f *ClientFactory) Remove(tenantId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.clients, tenantId)
+}
+
+// Tenants returns the IDs of the tenants with a client, sorted.
+func (f *ClientFactory) Tenants() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := make([]string, 0, len(f.clients))
+
+	for id := range f.clients {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+// observingTransport is an http.RoundTripper which reports every request of a tenant to a RequestObserver.
+type observingTransport struct {
+	base     http.RoundTripper
+	tenantId string
+	observer RequestObserver
+}
+
+func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	res, err := t.base.RoundTrip(req)
+
+	t.observer(t.tenantId, req, res, err, time.Since(start))
+
+	return res, err
+}
@@ -0,0 +1,34 @@
+package chat
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientFactory_TransportOptions(t *testing.T) {
+	f := NewClientFactory(ClientFactoryOption_ClientOptions(
+		BroChatClientOption_MaxIdleConnsPerHost(7),
+		BroChatClientOption_IdleConnTimeout(time.Minute),
+	))
+
+	transport, ok := f.httpClient.Transport.(*http.Transport)
+
+	if !ok {
+		t.Fatalf("shared transport is %T, want *http.Transport", f.httpClient.Transport)
+	}
+
+	if transport.MaxIdleConnsPerHost != 7 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 7", transport.MaxIdleConnsPerHost)
+	}
+
+	if transport.IdleConnTimeout != time.Minute {
+		t.Errorf("IdleConnTimeout = %v, want %v", transport.IdleConnTimeout, time.Minute)
+	}
+
+	client := f.Client(Tenant{Id: "acme", BaseUrl: "https://example.com/tenants/acme"})
+
+	if client.httpClient.Transport != transport {
+		t.Errorf("tenant client does not use the shared transport")
+	}
+}
@@ -1,7 +1,8 @@
 package chat
 
 import (
-	"encoding/json"
+	"slices"
+	"time"
 )
 
 // Acts as an envelope for broadcasted messages
@@ -16,7 +17,12 @@ type FeedMessage struct {
 
 // Creates a new FeedMessage. Sets the content as marshaled json bytes and sets the appropriate JSON content type.
 func NewFeedMessageJSON(messageType FeedMessageType, content interface{}) (*FeedMessage, error) {
-	contentBytes, err := json.Marshal(content)
+	return NewFeedMessageJSONWithCodec(StdCodec{}, messageType, content)
+}
+
+// Creates a new FeedMessage like NewFeedMessageJSON, marshaling the content with the codec.
+func NewFeedMessageJSONWithCodec(codec Codec, messageType FeedMessageType, content interface{}) (*FeedMessage, error) {
+	contentBytes, err := codec.Marshal(content)
 
 	if err != nil {
 		return nil, err
@@ -29,6 +35,16 @@ func NewFeedMessageJSON(messageType FeedMessageType, content interface{}) (*Feed
 	}, nil
 }
 
+// DecodeContent unmarshals the JSON content of the message into v with the codec. A nil codec uses StdCodec.
+// Usage: var message ChatMessage; err := feedMessage.DecodeContent(nil, &message)
+func (m *FeedMessage) DecodeContent(codec Codec, v any) error {
+	if codec == nil {
+		codec = StdCodec{}
+	}
+
+	return codec.Unmarshal(m.Content, v)
+}
+
 // A notification that a chat message has been recieved.
 // Sent to the user when a chat message is recieved but the user is not actively listening to the relvant channel.
 type ChatNotification struct {
@@ -42,6 +58,60 @@ type ChatMessageRequest struct {
 	ChannelId string `json:"channel_id"`
 	// The content of the message.
 	Content string `json:"content"`
+	// The type of the message content. An empty value will be treated as MESSAGE_CONTENT_TYPE_TEXT.
+	ContentType MessageContentType `json:"content_type,omitempty"`
+	// The structured embed content. Only used when the content type is MESSAGE_CONTENT_TYPE_EMBED.
+	Embed *Embed `json:"embed,omitempty"`
+	// The ID of the message being replied to. Leave empty if the message is not a reply.
+	ReplyToMessageId string `json:"reply_to_message_id,omitempty"`
+	// The IDs of previously uploaded attachments to include with the message.
+	AttachmentIds []string `json:"attachment_ids,omitempty"`
+	// How long the message should live for, in seconds, before it expires. Zero means the message does not expire.
+	TtlSeconds uint64 `json:"ttl_seconds,omitempty"`
+	// The sender's signature of the message. Set by SignChatMessageRequest. Leave nil to send an unsigned message.
+	Signature *MessageSignature `json:"signature,omitempty"`
+	// Protects the request against being sent again if intercepted. Not covered by the signature.
+	ReplayProtection
+}
+
+// ChatMessageRequestOption is a type for the options that can be passed to NewChatMessageRequest.
+type ChatMessageRequestOption func(*ChatMessageRequest)
+
+// An option which makes the message disappear after the given duration. The duration is truncated to whole seconds.
+func ChatMessageRequestOption_TTL(ttl time.Duration) ChatMessageRequestOption {
+	return func(r *ChatMessageRequest) {
+		if ttl > 0 {
+			r.TtlSeconds = uint64(ttl / time.Second)
+		}
+	}
+}
+
+// An option which marks the message as a reply to the message with the given ID.
+func ChatMessageRequestOption_ReplyTo(messageId string) ChatMessageRequestOption {
+	return func(r *ChatMessageRequest) {
+		r.ReplyToMessageId = messageId
+	}
+}
+
+// An option which stamps the request with a new nonce and the current time, so the server accepts it only once.
+func ChatMessageRequestOption_ReplayProtection() ChatMessageRequestOption {
+	return func(r *ChatMessageRequest) {
+		r.ReplayProtection = NewReplayProtection(time.Now())
+	}
+}
+
+// Creates a new plain text ChatMessageRequest with the given options applied.
+func NewChatMessageRequest(channelId string, content string, options ...ChatMessageRequestOption) ChatMessageRequest {
+	request := ChatMessageRequest{
+		ChannelId: channelId,
+		Content:   content,
+	}
+
+	for _, opt := range options {
+		opt(&request)
+	}
+
+	return request
 }
 
 // A request to set the users active channel.
@@ -68,13 +138,219 @@ type FriendRequestAcceptedEvent struct {
 	DirectMessageChannel string `json:"direct_message_channel"`
 }
 
+// Represents an event where a user has removed another user from their friends. Sent to both users.
+type FriendRemovedEvent struct {
+	// The user that removed the friend.
+	InitiatingUser UserInfo `json:"initiating_user"`
+	// The user that was removed.
+	RemovedUser UserInfo `json:"removed_user"`
+	// The ID of the channel for direct message communication between the users.
+	DirectMessageChannel string `json:"direct_message_channel"`
+	// What happened to the direct message channel.
+	DirectMessageChannelDisposition DirectMessageChannelDisposition `json:"direct_message_channel_disposition"`
+}
+
+// Represents an event where a user has come online, gone offline or changed their presence state.
+// Sent with the FEED_MESSAGE_TYPE_USER_ONLINE_EVENT and FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT message types.
+type UserPresenceEvent struct {
+	// The ID of the user whose presence changed.
+	UserId string `json:"user_id"`
+	// The new presence state of the user. Invisible users are reported as PRESENCE_STATE_OFFLINE.
+	Presence PresenceState `json:"presence"`
+	// When the user was last online
+	LastOnlineUtc time.Time `json:"last_online_utc"`
+}
+
+// Represents an event where one of the user's friends has changed their custom status.
+type UserStatusChangedEvent struct {
+	// The ID of the user whose status changed.
+	UserId string `json:"user_id"`
+	// The new status of the user. Will be nil if the status was cleared.
+	Status *UserStatus `json:"status"`
+}
+
 // Represents an event when the user's profile has been updated. This indicates that the user should refresh their profile in thier local state.
 type UserProfileUpdatedEvent struct {
 	// The reason for the update.
 	UpdateCode UserProfileUpdateCode `json:"reason"`
 }
 
+// Represents a change to one of the user's relationships. Sent to the user whose relationship changed.
+type RelationshipChangedEvent struct {
+	// The ID of the other user of the relationship.
+	UserId string `json:"user_id"`
+	// The relationship after the change. Will be nil if the relationship was removed.
+	Relationship *UserRelationship `json:"relationship,omitempty"`
+}
+
+// ApplyRelationshipChange patches the user's relationships with the event: the relationship with the other user is
+// added, replaced or, if the event has no relationship, removed. Returns false if there was no relationship to remove.
+func ApplyRelationshipChange(user *User, event RelationshipChangedEvent) bool {
+	if user == nil {
+		return false
+	}
+
+	i := slices.IndexFunc(user.Relationships, func(r UserRelationship) bool { return r.UserId == event.UserId })
+
+	if event.Relationship == nil {
+		if i < 0 {
+			return false
+		}
+
+		user.Relationships = slices.Delete(user.Relationships, i, i+1)
+
+		return true
+	}
+
+	relationship := *event.Relationship
+	relationship.UserId = event.UserId
+
+	if i < 0 {
+		user.Relationships = append(user.Relationships, relationship)
+	} else {
+		user.Relationships[i] = relationship
+	}
+
+	return true
+}
+
+// Represents changes to the rooms the user owns or is a member of, such as joining, leaving or being kicked from a room,
+// or a room being renamed. Sent to the user whose rooms changed.
+type RoomsChangedEvent struct {
+	// The rooms the user has joined or created.
+	Added []Room `json:"added,omitempty"`
+	// The rooms of the user which have changed, in their state after the change.
+	Updated []Room `json:"updated,omitempty"`
+	// The IDs of the rooms the user is no longer a member of.
+	RemovedRoomIds []string `json:"removed_room_ids,omitempty"`
+}
+
+// ApplyRoomsChange patches the user's rooms with the event. Added and updated rooms replace the user's copy, or are
+// appended if the user does not have one, so applying an event twice has no further effect.
+func ApplyRoomsChange(user *User, event RoomsChangedEvent) {
+	if user == nil {
+		return
+	}
+
+	for _, room := range slices.Concat(event.Added, event.Updated) {
+		if i := slices.IndexFunc(user.Rooms, func(r Room) bool { return r.Id == room.Id }); i >= 0 {
+			user.Rooms[i] = room
+		} else {
+			user.Rooms = append(user.Rooms, room)
+		}
+	}
+
+	user.Rooms = slices.DeleteFunc(user.Rooms, func(r Room) bool { return slices.Contains(event.RemovedRoomIds, r.Id) })
+}
+
 type ChannelUpdatedEvent struct {
 	// The ID of the channel that was updated.
 	ChannelId string `json:"channel_id"`
 }
+
+// Represents an event where the server has finished enriching a previously sent message.
+// Clients should attach the link previews to the message with the matching ID in their local state.
+type MessageEnrichedEvent struct {
+	// The ID of the message that was enriched.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the message was sent in.
+	ChannelId string `json:"channel_id"`
+	// The link previews generated for the message.
+	LinkPreviews []LinkPreview `json:"link_previews"`
+}
+
+// Represents a voice note posted to a channel. Contains everything a client needs to render an inline audio player.
+type VoiceNoteEvent struct {
+	// The ID of the message that carries the voice note.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the voice note was posted in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that recorded the voice note.
+	SenderUserId string `json:"sender_user_id"`
+	// The ID of the voice note attachment.
+	AttachmentId string `json:"attachment_id"`
+	// The URL the audio can be streamed from.
+	Url string `json:"url"`
+	// The MIME type of the audio. Example: audio/ogg
+	ContentType string `json:"content_type"`
+	// The duration of the recording in milliseconds.
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// Represents an event where an expiring message has reached its expiry time.
+// Clients should remove the message with the matching ID from their local state.
+type MessageExpiredEvent struct {
+	// The ID of the message that expired.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the message was sent in.
+	ChannelId string `json:"channel_id"`
+}
+
+// Represents an event where the delivery state of a message has changed for one of its recipients.
+// Sent to the author of the message.
+type MessageDeliveryStateUpdatedEvent struct {
+	// The ID of the message.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the message was sent in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the recipient whose delivery state changed.
+	UserId string `json:"user_id"`
+	// The new delivery state for the recipient.
+	State MessageDeliveryState `json:"state"`
+	// When the state changed.
+	UpdatedAtUtc time.Time `json:"updated_at_utc"`
+}
+
+// Represents an event where a reaction was added to or removed from a message.
+// Sent with the FEED_MESSAGE_TYPE_REACTION_ADDED and FEED_MESSAGE_TYPE_REACTION_REMOVED message types.
+type ReactionEvent struct {
+	// The ID of the message that was reacted to.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the message was sent in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that reacted.
+	UserId string `json:"user_id"`
+	// The reaction emoji.
+	Emoji string `json:"emoji"`
+}
+
+// Represents an event where a user has been kicked from a room. Sent to the kicked user and the remaining room members.
+type UserKickedFromRoomEvent struct {
+	// The ID of the room the user was kicked from.
+	RoomId string `json:"room_id"`
+	// The user that was kicked.
+	KickedUser UserInfo `json:"kicked_user"`
+	// The owner or moderator that kicked the user.
+	KickedByUser UserInfo `json:"kicked_by_user"`
+	// The reason the user was kicked. May be empty.
+	Reason string `json:"reason"`
+}
+
+// Represents an event where a user has requested to join a room. Sent to the room owner.
+type RoomJoinRequestReceivedEvent struct {
+	// The join request.
+	Request RoomJoinRequest `json:"request"`
+}
+
+// Represents an event where a room join request has been approved or denied. Sent to the requesting user.
+type RoomJoinRequestResolvedEvent struct {
+	// The ID of the join request.
+	RequestId string `json:"request_id"`
+	// The ID of the room.
+	RoomId string `json:"room_id"`
+	// The name of the room.
+	RoomName string `json:"room_name"`
+	// The outcome of the join request. Either JOIN_REQUEST_STATUS_APPROVED or JOIN_REQUEST_STATUS_DENIED.
+	Status JoinRequestStatus `json:"status"`
+}
+
+// Represents an event where a user has been invited to a room. Sent to the invited user, who accepts with an
+// AcceptRoomInviteRequest.
+type RoomInviteReceivedEvent struct {
+	// The ID of the room.
+	RoomId string `json:"room_id"`
+	// The name of the room.
+	RoomName string `json:"room_name"`
+	// The member that sent the invite.
+	InvitingUser UserInfo `json:"inviting_user"`
+}
@@ -0,0 +1,300 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FeedClient defaults.
+const (
+	// The default time a FeedClient waits before reconnecting after the connection fails or drops.
+	DEFAULT_FEED_RECONNECT_INTERVAL = 5 * time.Second
+	// The default size in bytes of the largest feed message a FeedClient accepts.
+	DEFAULT_FEED_MAX_MESSAGE_SIZE = 1 << 20
+)
+
+var (
+	// ErrFeedNotConnected is returned when sending a feed message while the FeedClient is not connected.
+	ErrFeedNotConnected = errors.New("the feed is not connected")
+	// ErrFeedClientRunning is returned when Run is called on a FeedClient which is already running.
+	ErrFeedClientRunning = errors.New("the feed client is already running")
+)
+
+// FeedClient keeps a websocket connection to the feed of the BroChat API, passing every feed message received to a
+// handler and reconnecting whenever the connection fails or drops. Messages sent while the connection was down are
+// not delivered again, so use the OnConnect callback to catch up, such as by calling Resync on a ChannelWatcher.
+//
+// The handshake is sent with the http client, so create it with NewTokenProviderHttpClient to authorize every
+// reconnection with a fresh access token. FeedClient is safe for concurrent use.
+//
+// Usage:
+//
+//	feed := chat.NewFeedClient(chat.NewTokenProviderHttpClient(nil, provider), baseUrl, chat.FeedClientOption_OnConnect(watcher.Resync))
+//	go feed.Run(ctx, "", watcher.HandleFeedMessage)
+type FeedClient struct {
+	httpClient        *http.Client
+	baseUrl           string
+	codec             Codec
+	framings          []FeedFraming
+	reconnectInterval time.Duration
+	maxMessageSize    int64
+	onConnect         func()
+	onError           func(error)
+
+	mu      sync.Mutex
+	conn    *websocketConn
+	framing FeedFraming
+	running bool
+	closed  bool
+	stop    func()
+}
+
+// FeedClientOption is a type for the options that can be passed to NewFeedClient.
+type FeedClientOption func(*FeedClient)
+
+// Sets the codec used to encode and decode JSON feed frames. Defaults to StdCodec.
+func FeedClientOption_Codec(codec Codec) FeedClientOption {
+	return func(f *FeedClient) {
+		f.codec = codec
+	}
+}
+
+// Sets the framings offered to the server, most preferred first. Defaults to FEED_FRAMING_BINARY, then FEED_FRAMING_JSON.
+func FeedClientOption_Framings(framings ...FeedFraming) FeedClientOption {
+	return func(f *FeedClient) {
+		if len(framings) > 0 {
+			f.framings = framings
+		}
+	}
+}
+
+// Sets how long the client waits before reconnecting. Defaults to DEFAULT_FEED_RECONNECT_INTERVAL.
+func FeedClientOption_ReconnectInterval(reconnectInterval time.Duration) FeedClientOption {
+	return func(f *FeedClient) {
+		if reconnectInterval > 0 {
+			f.reconnectInterval = reconnectInterval
+		}
+	}
+}
+
+// Sets the size in bytes of the largest feed message accepted. A larger message drops the connection, which is then
+// reconnected. Defaults to DEFAULT_FEED_MAX_MESSAGE_SIZE.
+func FeedClientOption_MaxMessageSize(size int64) FeedClientOption {
+	return func(f *FeedClient) {
+		if size > 0 {
+			f.maxMessageSize = size
+		}
+	}
+}
+
+// Sets a function called every time the connection is established, including reconnections.
+func FeedClientOption_OnConnect(onConnect func()) FeedClientOption {
+	return func(f *FeedClient) {
+		f.onConnect = onConnect
+	}
+}
+
+// Sets a function called with the error of each failed connection, dropped connection and failed handler, such as to
+// show that the feed is reconnecting.
+func FeedClientOption_OnError(onError func(error)) FeedClientOption {
+	return func(f *FeedClient) {
+		f.onError = onError
+	}
+}
+
+// NewFeedClient creates a client of the feed served under the base url. Call Run to connect. The timeout of the http
+// client is not applied, as it would end the connection; the handshake ends with the context passed to Run.
+func NewFeedClient(httpClient *http.Client, baseUrl string, options ...FeedClientOption) *FeedClient {
+	client := *WithTunedTransport(httpClient, DefaultTransportConfig())
+	client.Timeout = 0
+
+	f := &FeedClient{
+		httpClient:        &client,
+		baseUrl:           baseUrl,
+		codec:             StdCodec{},
+		framings:          []FeedFraming{FEED_FRAMING_BINARY, FEED_FRAMING_JSON},
+		reconnectInterval: DEFAULT_FEED_RECONNECT_INTERVAL,
+		maxMessageSize:    DEFAULT_FEED_MAX_MESSAGE_SIZE,
+	}
+
+	for _, opt := range options {
+		opt(f)
+	}
+
+	return f
+}
+
+// Run connects to the feed and passes every message received to the handler until the context is done or Close is
+// called, reconnecting after the reconnect interval whenever the connection fails or drops. The handler is called from
+// the goroutine running Run, one message at a time; its errors are passed to the OnError callback. The access token
+// authorizes the handshake and is ignored if the http client is from NewTokenProviderHttpClient, in which case an
+// empty string may be passed. Returns the error of the context, nil once the client is closed, or ErrFeedClientRunning if the client
+// is already running.
+func (f *FeedClient) Run(ctx context.Context, accessToken string, handler func(*FeedMessage) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	f.mu.Lock()
+
+	if f.running {
+		f.mu.Unlock()
+		return ErrFeedClientRunning
+	}
+
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+
+	f.running, f.stop = true, func() { cancel(errFeedClientClosed) }
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.running, f.stop = false, nil
+		f.mu.Unlock()
+	}()
+
+	for {
+		err := f.connect(ctx, accessToken, handler)
+
+		if ctx.Err() != nil {
+			if errors.Is(context.Cause(ctx), errFeedClientClosed) {
+				return nil
+			}
+
+			return ctx.Err()
+		}
+
+		f.reportError(err)
+
+		timer := time.NewTimer(f.reconnectInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// errFeedClientClosed is the cause of the context of Run when the client is closed.
+var errFeedClientClosed = errors.New("the feed client was closed")
+
+// Close disconnects from the feed and stops Run. A closed client cannot be run again; cancel the context passed to Run
+// to stop a client which will be run later.
+func (f *FeedClient) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+
+	if f.stop != nil {
+		f.stop()
+	}
+}
+
+// Connected returns true if the client is connected to the feed.
+func (f *FeedClient) Connected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.conn != nil
+}
+
+// Send sends a feed message, such as a FEED_MESSAGE_TYPE_CHAT_MESSAGE_REQUEST, on the connection. Returns
+// ErrFeedNotConnected if the client is not connected; the message is not queued.
+func (f *FeedClient) Send(message *FeedMessage) error {
+	f.mu.Lock()
+	conn, framing := f.conn, f.framing
+	f.mu.Unlock()
+
+	if conn == nil {
+		return ErrFeedNotConnected
+	}
+
+	data, err := EncodeFeedFrame(framing, f.codec, message)
+
+	if err != nil {
+		return err
+	}
+
+	opcode := byte(websocketOpText)
+
+	if framing == FEED_FRAMING_BINARY {
+		opcode = websocketOpBinary
+	}
+
+	return conn.WriteMessage(opcode, data)
+}
+
+// connect establishes one connection and reads from it until it fails or the context is done.
+func (f *FeedClient) connect(ctx context.Context, accessToken string, handler func(*FeedMessage) error) error {
+	url, err := buildUrl(f.baseUrl, FEED_URL_SUFFIX)
+
+	if err != nil {
+		return err
+	}
+
+	protocols := make([]string, len(f.framings))
+
+	for i, framing := range f.framings {
+		protocols[i] = framing.Subprotocol()
+	}
+
+	conn, err := dialWebsocket(ctx, f.httpClient, url, accessToken, protocols, f.maxMessageSize)
+
+	if err != nil {
+		return err
+	}
+
+	// Unblock the read when the context is done
+	stopClosing := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopClosing()
+	defer conn.Close()
+
+	framing := NegotiateFeedFraming([]string{conn.protocol})
+
+	f.mu.Lock()
+	f.conn, f.framing = conn, framing
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.conn = nil
+		f.mu.Unlock()
+	}()
+
+	if f.onConnect != nil {
+		f.onConnect()
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+
+		if err != nil {
+			return err
+		}
+
+		message, err := DecodeFeedFrame(framing, f.codec, data)
+
+		if err != nil {
+			f.reportError(err)
+			continue
+		}
+
+		if err := handler(message); err != nil {
+			f.reportError(err)
+		}
+	}
+}
+
+// reportError passes the error to the OnError callback, if set.
+func (f *FeedClient) reportError(err error) {
+	if err != nil && f.onError != nil {
+		f.onError(err)
+	}
+}
@@ -0,0 +1,175 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFeedServer starts a server accepting the feed handshake and passing each connection, with the framing it
+// selected, to serve. The connection is closed when serve returns.
+func newFeedServer(t *testing.T, serve func(conn *websocketConn, framing FeedFraming)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != FEED_URL_SUFFIX || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		framing := NegotiateFeedFraming(strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ", "))
+
+		rwc, _, err := http.NewResponseController(w).Hijack()
+
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+
+		fmt.Fprintf(rwc, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: %s\r\n\r\n",
+			websocketAccept(r.Header.Get("Sec-WebSocket-Key")), framing.Subprotocol())
+
+		conn := &websocketConn{rwc: rwc, reader: bufio.NewReader(rwc), maxMessageSize: DEFAULT_FEED_MAX_MESSAGE_SIZE}
+		defer conn.Close()
+
+		serve(conn, framing)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFeedClient_ReceivesAndReconnects(t *testing.T) {
+	var connections atomic.Int32
+
+	server := newFeedServer(t, func(conn *websocketConn, framing FeedFraming) {
+		n := connections.Add(1)
+		data, _ := EncodeFeedFrame(framing, StdCodec{}, &FeedMessage{Type: FEED_MESSAGE_TYPE_CHAT_MESSAGE, Content: []byte(fmt.Sprint(n))})
+		conn.WriteMessage(websocketOpBinary, data)
+		// Dropping the connection makes the client reconnect
+	})
+
+	var connects atomic.Int32
+	received := make(chan string, 4)
+
+	feed := NewFeedClient(server.Client(), server.URL,
+		FeedClientOption_ReconnectInterval(time.Millisecond),
+		FeedClientOption_OnConnect(func() { connects.Add(1) }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- feed.Run(ctx, "token", func(message *FeedMessage) error {
+			received <- string(message.Content)
+			return nil
+		})
+	}()
+
+	for _, want := range []string{"1", "2"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("received %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %q", want)
+		}
+	}
+
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	if connects.Load() < 2 {
+		t.Errorf("OnConnect called %d times, want once per connection", connects.Load())
+	}
+}
+
+func TestFeedClient_Send(t *testing.T) {
+	echoed := make(chan *FeedMessage, 1)
+
+	server := newFeedServer(t, func(conn *websocketConn, framing FeedFraming) {
+		_, data, err := conn.ReadMessage()
+
+		if err != nil {
+			return
+		}
+
+		message, err := DecodeFeedFrame(framing, StdCodec{}, data)
+
+		if err != nil {
+			t.Errorf("DecodeFeedFrame() error = %v", err)
+			return
+		}
+
+		echoed <- message
+	})
+
+	connected := make(chan struct{}, 1)
+	feed := NewFeedClient(server.Client(), server.URL,
+		FeedClientOption_Framings(FEED_FRAMING_JSON),
+		FeedClientOption_OnConnect(func() { connected <- struct{}{} }))
+
+	if err := feed.Send(&FeedMessage{}); !errors.Is(err, ErrFeedNotConnected) {
+		t.Errorf("Send() before connecting error = %v, want ErrFeedNotConnected", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- feed.Run(context.Background(), "token", func(*FeedMessage) error { return nil }) }()
+
+	<-connected
+
+	if err := feed.Send(&FeedMessage{Type: FEED_MESSAGE_TYPE_CHAT_MESSAGE, ContentType: "text/plain", Content: []byte("hi")}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case message := <-echoed:
+		if message.Type != FEED_MESSAGE_TYPE_CHAT_MESSAGE || string(message.Content) != "hi" {
+			t.Errorf("server received %+v", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the server to receive the message")
+	}
+
+	feed.Close()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() after Close error = %v, want nil", err)
+	}
+}
+
+func TestFeedClient_HandshakeRejected(t *testing.T) {
+	server := newFeedServer(t, func(*websocketConn, FeedFraming) {})
+	errs := make(chan error, 1)
+
+	feed := NewFeedClient(server.Client(), server.URL, FeedClientOption_OnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go feed.Run(ctx, "wrong", func(*FeedMessage) error { return nil })
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrWebsocketHandshake) {
+			t.Errorf("error = %v, want ErrWebsocketHandshake", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the handshake error")
+	}
+}
@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// The prefix shared by the feed message types defined by this library.
+const feedMessageTypePrefix = "brochat:feed_message_type:"
+
+// feedMessageTypes is the registry of known feed message types.
+var (
+	feedMessageTypesMu sync.RWMutex
+	feedMessageTypes   = map[FeedMessageType]struct{}{
+		FEED_MESSAGE_TYPE_CHAT_MESSAGE_REQUEST:           {},
+		FEED_MESSAGE_TYPE_SET_ACTIVE_CHANNEL_REQUEST:     {},
+		FEED_MESSAGE_TYPE_USER_ONLINE_EVENT:              {},
+		FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT:             {},
+		FEED_MESSAGE_TYPE_CHAT_NOTIFICATION:              {},
+		FEED_MESSAGE_TYPE_CHAT_MESSAGE:                   {},
+		FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED:        {},
+		FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED:        {},
+		FEED_MESSAGE_TYPE_FRIEND_REMOVED:                 {},
+		FEED_MESSAGE_TYPE_ROOM_CREATED:                   {},
+		FEED_MESSAGE_TYPE_USER_JOINED_ROOM:               {},
+		FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RECEIVED:     {},
+		FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RESOLVED:     {},
+		FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED:           {},
+		FEED_MESSAGE_TYPE_USER_KICKED_FROM_ROOM:          {},
+		FEED_MESSAGE_TYPE_USER_STATUS_CHANGED:            {},
+		FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED:           {},
+		FEED_MESSAGE_TYPE_RELATIONSHIP_CHANGED:           {},
+		FEED_MESSAGE_TYPE_ROOMS_CHANGED:                  {},
+		FEED_MESSAGE_TYPE_CHANNEL_UPDATED:                {},
+		FEED_MESSAGE_TYPE_READ_POINTER_UPDATED:           {},
+		FEED_MESSAGE_TYPE_MACRO_REQUEST:                  {},
+		FEED_MESSAGE_TYPE_MESSAGE_ENRICHED:               {},
+		FEED_MESSAGE_TYPE_VOICE_NOTE:                     {},
+		FEED_MESSAGE_TYPE_MESSAGE_EXPIRED:                {},
+		FEED_MESSAGE_TYPE_MESSAGE_DELIVERY_STATE_UPDATED: {},
+		FEED_MESSAGE_TYPE_REACTION_ADDED:                 {},
+		FEED_MESSAGE_TYPE_REACTION_REMOVED:               {},
+		FEED_MESSAGE_TYPE_CALL_OFFER:                     {},
+		FEED_MESSAGE_TYPE_CALL_ANSWER:                    {},
+		FEED_MESSAGE_TYPE_CALL_ICE_CANDIDATE:             {},
+		FEED_MESSAGE_TYPE_CALL_ENDED:                     {},
+		FEED_MESSAGE_TYPE_CALL_MEDIA_STATE_CHANGED:       {},
+		FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STARTED:      {},
+		FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STOPPED:      {},
+		FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_JOINED:       {},
+		FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_LEFT:         {},
+		FEED_MESSAGE_TYPE_VOICE_STATE_UPDATED:            {},
+	}
+)
+
+// RegisterFeedMessageType adds an application defined feed message type to the registry of known types, so IsValid
+// reports it as valid. Registering a type twice has no effect.
+func RegisterFeedMessageType(messageType FeedMessageType) {
+	feedMessageTypesMu.Lock()
+	defer feedMessageTypesMu.Unlock()
+
+	feedMessageTypes[messageType] = struct{}{}
+}
+
+// KnownFeedMessageTypes returns the registered feed message types in sorted order.
+func KnownFeedMessageTypes() []FeedMessageType {
+	feedMessageTypesMu.RLock()
+	defer feedMessageTypesMu.RUnlock()
+
+	types := make([]FeedMessageType, 0, len(feedMessageTypes))
+
+	for t := range feedMessageTypes {
+		types = append(types, t)
+	}
+
+	slices.Sort(types)
+
+	return types
+}
+
+// IsValid returns true if the type is registered. Feed messages of unknown types are typically sent by a newer server
+// and should be ignored rather than treated as errors.
+func (t FeedMessageType) IsValid() bool {
+	feedMessageTypesMu.RLock()
+	defer feedMessageTypesMu.RUnlock()
+
+	_, ok := feedMessageTypes[t]
+
+	return ok
+}
+
+// String returns a short name for logging. Known types are returned without the common "brochat:feed_message_type:"
+// prefix. Example: "chat_message". Unknown types are quoted and marked as unknown.
+func (t FeedMessageType) String() string {
+	if !t.IsValid() {
+		return fmt.Sprintf("unknown(%q)", string(t))
+	}
+
+	return strings.TrimPrefix(string(t), feedMessageTypePrefix)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Unknown types are kept as they are so a message from a newer
+// server still decodes; check IsValid before dispatching on the type. Only values which are not JSON strings fail.
+func (t *FeedMessageType) UnmarshalJSON(data []byte) error {
+	var value string
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid feed message type %s", data)
+	}
+
+	*t = FeedMessageType(value)
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrContentRejected = errors.New("message content rejected by content filter")
+)
+
+// Describes what a content filter did with a message.
+type ContentFilterAction string
+
+const (
+	// The content was allowed through unchanged.
+	CONTENT_FILTER_ACTION_ALLOW ContentFilterAction = "allow"
+	// The content was allowed through with the offending words masked.
+	CONTENT_FILTER_ACTION_MASK ContentFilterAction = "mask"
+	// The content was rejected and should not be sent or displayed.
+	CONTENT_FILTER_ACTION_REJECT ContentFilterAction = "reject"
+)
+
+// The result of running message content through a ContentFilter.
+type ContentFilterResult struct {
+	// The action taken by the filter.
+	Action ContentFilterAction
+	// The filtered content. Will be the original content if the action is CONTENT_FILTER_ACTION_ALLOW.
+	Content string
+	// The words that triggered the filter.
+	Matches []string
+}
+
+// A ContentFilter inspects message content. Filters are invoked on outbound messages before they are sent (or stored by the server)
+// and on inbound messages before they are displayed. Implementations must be safe for concurrent use.
+type ContentFilter interface {
+	Filter(content string) ContentFilterResult
+}
+
+// WordListContentFilter is the default ContentFilter. It matches whole words case-insensitively against a list of blocked words.
+type WordListContentFilter struct {
+	words  map[string]struct{}
+	reject bool
+}
+
+// NewWordListContentFilter creates a filter which masks any of the given words with asterisks.
+func NewWordListContentFilter(words ...string) *WordListContentFilter {
+	filter := &WordListContentFilter{words: make(map[string]struct{}, len(words))}
+
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			filter.words[w] = struct{}{}
+		}
+	}
+
+	return filter
+}
+
+// NewRejectingWordListContentFilter creates a filter which rejects any content containing one of the given words.
+func NewRejectingWordListContentFilter(words ...string) *WordListContentFilter {
+	filter := NewWordListContentFilter(words...)
+	filter.reject = true
+	return filter
+}
+
+// Filter implements the ContentFilter interface.
+func (f *WordListContentFilter) Filter(content string) ContentFilterResult {
+	runes := []rune(content)
+	matches := make([]string, 0)
+
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+
+		end := start
+
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := string(runes[start:end])
+
+		if _, ok := f.words[strings.ToLower(word)]; ok {
+			matches = append(matches, word)
+
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+
+		start = end
+	}
+
+	switch {
+	case len(matches) == 0:
+		return ContentFilterResult{Action: CONTENT_FILTER_ACTION_ALLOW, Content: content, Matches: matches}
+	case f.reject:
+		return ContentFilterResult{Action: CONTENT_FILTER_ACTION_REJECT, Content: content, Matches: matches}
+	default:
+		return ContentFilterResult{Action: CONTENT_FILTER_ACTION_MASK, Content: string(runes), Matches: matches}
+	}
+}
+
+// FilterChatMessageRequest applies the filter to an outbound chat message request if the room has content filtering enabled.
+// A nil room indicates the message is not being sent to a room (Example: a direct message) in which case the filter is always applied.
+// Returns ErrContentRejected if the filter rejected the content.
+func FilterChatMessageRequest(filter ContentFilter, room *Room, request ChatMessageRequest) (ChatMessageRequest, error) {
+	if filter == nil || (room != nil && !room.ContentFilterEnabled) {
+		return request, nil
+	}
+
+	result := filter.Filter(request.Content)
+
+	if result.Action == CONTENT_FILTER_ACTION_REJECT {
+		return request, ErrContentRejected
+	}
+
+	request.Content = result.Content
+
+	return request, nil
+}
+
+// FilterChatMessage applies the filter to an inbound chat message if the room has content filtering enabled.
+// A nil room indicates the message was not sent in a room. Returns false if the message should not be displayed.
+func FilterChatMessage(filter ContentFilter, room *Room, message ChatMessage) (ChatMessage, bool) {
+	if filter == nil || (room != nil && !room.ContentFilterEnabled) {
+		return message, true
+	}
+
+	result := filter.Filter(message.Content)
+
+	if result.Action == CONTENT_FILTER_ACTION_REJECT {
+		return message, false
+	}
+
+	message.Content = result.Content
+
+	return message, true
+}
+
+// isWordRune reports whether the rune is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
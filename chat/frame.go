@@ -0,0 +1,142 @@
+package chat
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+)
+
+// The websocket subprotocols of the feed. Clients list the framings they support in the Sec-WebSocket-Protocol header
+// when connecting, most preferred first, and the server selects one with NegotiateFeedFraming.
+const (
+	// Each websocket text message is a FeedMessage encoded as JSON. Content is base64 encoded.
+	FEED_SUBPROTOCOL_JSON = "brochat.feed.json.v1"
+	// Each websocket binary message is a FeedMessage encoded with FeedMessage.MarshalBinary. Content is sent as is,
+	// avoiding the third larger payloads of base64. Intended for bandwidth sensitive clients such as mobile apps.
+	FEED_SUBPROTOCOL_BINARY = "brochat.feed.binary.v1"
+)
+
+// FeedFraming is how feed messages are framed on a feed connection.
+type FeedFraming uint8
+
+const (
+	// Feed messages are sent as JSON. Used when the client does not request a subprotocol.
+	FEED_FRAMING_JSON FeedFraming = iota
+	// Feed messages are sent as length prefixed binary frames.
+	FEED_FRAMING_BINARY
+)
+
+// Subprotocol returns the websocket subprotocol of the framing.
+func (f FeedFraming) Subprotocol() string {
+	if f == FEED_FRAMING_BINARY {
+		return FEED_SUBPROTOCOL_BINARY
+	}
+
+	return FEED_SUBPROTOCOL_JSON
+}
+
+// NegotiateFeedFraming returns the framing of the first subprotocol offered by a connecting client which the server
+// supports. Clients which offer no supported subprotocol get FEED_FRAMING_JSON, so existing clients keep working.
+func NegotiateFeedFraming(offered []string) FeedFraming {
+	for _, protocol := range offered {
+		switch protocol {
+		case FEED_SUBPROTOCOL_BINARY:
+			return FEED_FRAMING_BINARY
+		case FEED_SUBPROTOCOL_JSON:
+			return FEED_FRAMING_JSON
+		}
+	}
+
+	return FEED_FRAMING_JSON
+}
+
+// The version byte which starts every binary frame.
+const binaryFeedFrameVersion = 1
+
+// ErrInvalidFeedFrame is returned when a binary feed frame cannot be decoded.
+var ErrInvalidFeedFrame = errors.New("invalid binary feed frame")
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. See AppendBinary for the frame layout.
+func (m *FeedMessage) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, 1+2*binary.MaxVarintLen64+len(m.Type)+len(m.ContentType)+len(m.Content))), nil
+}
+
+// AppendBinary appends the binary frame of the message to b. The frame is a version byte, the uvarint length prefixed
+// type, the uvarint length prefixed content type and then the raw content, which runs to the end of the frame.
+func (m *FeedMessage) AppendBinary(b []byte) []byte {
+	b = append(b, binaryFeedFrameVersion)
+	b = binary.AppendUvarint(b, uint64(len(m.Type)))
+	b = append(b, m.Type...)
+	b = binary.AppendUvarint(b, uint64(len(m.ContentType)))
+	b = append(b, m.ContentType...)
+
+	return append(b, m.Content...)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The content is copied, so data may be reused.
+func (m *FeedMessage) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryFeedFrameVersion {
+		return ErrInvalidFeedFrame
+	}
+
+	data = data[1:]
+
+	messageType, data, ok := readFrameString(data)
+
+	if !ok {
+		return ErrInvalidFeedFrame
+	}
+
+	contentType, data, ok := readFrameString(data)
+
+	if !ok {
+		return ErrInvalidFeedFrame
+	}
+
+	m.Type = FeedMessageType(messageType)
+	m.ContentType = contentType
+	m.Content = slices.Clone(data)
+
+	return nil
+}
+
+// EncodeFeedFrame encodes the message in the framing. JSON frames are marshaled with the codec; a nil codec uses StdCodec.
+func EncodeFeedFrame(framing FeedFraming, codec Codec, message *FeedMessage) ([]byte, error) {
+	if framing == FEED_FRAMING_BINARY {
+		return message.MarshalBinary()
+	}
+
+	if codec == nil {
+		codec = StdCodec{}
+	}
+
+	return codec.Marshal(message)
+}
+
+// DecodeFeedFrame decodes a message received in the framing. JSON frames are unmarshaled with the codec; a nil codec uses StdCodec.
+func DecodeFeedFrame(framing FeedFraming, codec Codec, data []byte) (*FeedMessage, error) {
+	message := new(FeedMessage)
+
+	if framing == FEED_FRAMING_BINARY {
+		return message, message.UnmarshalBinary(data)
+	}
+
+	if codec == nil {
+		codec = StdCodec{}
+	}
+
+	return message, codec.Unmarshal(data, message)
+}
+
+// readFrameString reads a uvarint length prefixed string, returning the rest of the data.
+func readFrameString(data []byte) (string, []byte, bool) {
+	length, n := binary.Uvarint(data)
+
+	if n <= 0 || length > uint64(len(data)-n) {
+		return "", nil, false
+	}
+
+	data = data[n:]
+
+	return string(data[:length]), data[length:], true
+}
@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"testing"
+)
+
+func benchmarkFeedMessage(t testing.TB) *FeedMessage {
+	message, err := NewFeedMessageJSON(FEED_MESSAGE_TYPE_CHAT_MESSAGE, ChatMessage{
+		Id:           "message",
+		ChannelId:    "channel",
+		SenderUserId: "user",
+		Content:      "hello there",
+	})
+
+	if err != nil {
+		t.Fatalf("NewFeedMessageJSON() error = %v", err)
+	}
+
+	return message
+}
+
+func TestFeedFrame_RoundTrip(t *testing.T) {
+	message := benchmarkFeedMessage(t)
+
+	for _, framing := range []FeedFraming{FEED_FRAMING_JSON, FEED_FRAMING_BINARY} {
+		data, err := EncodeFeedFrame(framing, StdCodec{}, message)
+
+		if err != nil {
+			t.Fatalf("EncodeFeedFrame(%v) error = %v", framing, err)
+		}
+
+		decoded, err := DecodeFeedFrame(framing, StdCodec{}, data)
+
+		if err != nil {
+			t.Fatalf("DecodeFeedFrame(%v) error = %v", framing, err)
+		}
+
+		if decoded.Type != message.Type || decoded.ContentType != message.ContentType || string(decoded.Content) != string(message.Content) {
+			t.Errorf("DecodeFeedFrame(%v) = %+v, want %+v", framing, decoded, message)
+		}
+	}
+}
+
+func TestFeedFrame_Allocs(t *testing.T) {
+	budgets := map[FeedFraming]float64{
+		FEED_FRAMING_JSON:   4,
+		FEED_FRAMING_BINARY: 5,
+	}
+
+	message := benchmarkFeedMessage(t)
+
+	for framing, budget := range budgets {
+		assertAllocs(t, budget, func() {
+			data, _ := EncodeFeedFrame(framing, StdCodec{}, message)
+			DecodeFeedFrame(framing, StdCodec{}, data)
+		})
+	}
+}
+
+func BenchmarkFeedFrame(b *testing.B) {
+	message := benchmarkFeedMessage(b)
+
+	for _, framing := range []FeedFraming{FEED_FRAMING_JSON, FEED_FRAMING_BINARY} {
+		b.Run(framing.Subprotocol(), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				data, _ := EncodeFeedFrame(framing, StdCodec{}, message)
+				DecodeFeedFrame(framing, StdCodec{}, data)
+			}
+		})
+	}
+}
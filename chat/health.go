@@ -0,0 +1,67 @@
+package chat
+
+import "time"
+
+type HealthStatus string
+
+const (
+	// Everything is working.
+	HEALTH_STATUS_OK HealthStatus = "ok"
+	// The server is serving requests but a dependency is failing or slow. See ServerHealth.Checks.
+	HEALTH_STATUS_DEGRADED HealthStatus = "degraded"
+	// The server cannot serve requests.
+	HEALTH_STATUS_UNAVAILABLE HealthStatus = "unavailable"
+)
+
+// ServerHealth is the health of a BroChat deployment, as reported by GetServerHealth.
+type ServerHealth struct {
+	// The overall status of the server. The worst status of the checks.
+	Status HealthStatus `json:"status"`
+	// The version of the server.
+	Version string `json:"version"`
+	// How long the server has been running, in seconds.
+	UptimeSeconds uint64 `json:"uptime_seconds"`
+	// The status of each dependency of the server, such as the database.
+	Checks []HealthCheck `json:"checks"`
+}
+
+// A HealthCheck is the status of one dependency of the server.
+type HealthCheck struct {
+	// The name of the dependency. Example: "database"
+	Name string `json:"name"`
+	// The status of the dependency.
+	Status HealthStatus `json:"status"`
+	// What is wrong with the dependency. Empty when the status is HEALTH_STATUS_OK.
+	Message string `json:"message,omitempty"`
+}
+
+// IsHealthy returns true if the server reported HEALTH_STATUS_OK.
+func (h ServerHealth) IsHealthy() bool {
+	return h.Status == HEALTH_STATUS_OK
+}
+
+// Uptime returns how long the server has been running.
+func (h ServerHealth) Uptime() time.Duration {
+	return time.Duration(h.UptimeSeconds) * time.Second
+}
+
+// ServerStats are the usage statistics of a BroChat deployment, as reported by GetServerStats.
+type ServerStats struct {
+	// How long the server has been running, in seconds.
+	UptimeSeconds uint64 `json:"uptime_seconds"`
+	// The number of users with at least one open feed connection.
+	ConnectedUsers uint64 `json:"connected_users"`
+	// The number of open feed connections. Higher than ConnectedUsers when users are connected from several devices.
+	FeedConnections uint64 `json:"feed_connections"`
+	// The average number of chat messages sent per second over the last minute.
+	MessagesPerSecond float64 `json:"messages_per_second"`
+	// The number of chat messages sent since the server started.
+	MessagesSinceStart uint64 `json:"messages_since_start"`
+	// When the statistics were collected.
+	CollectedAtUtc time.Time `json:"collected_at_utc"`
+}
+
+// Uptime returns how long the server has been running.
+func (s ServerStats) Uptime() time.Duration {
+	return time.Duration(s.UptimeSeconds) * time.Second
+}
@@ -0,0 +1,338 @@
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrImportFormatUnknown = errors.New("unknown import format")
+)
+
+// Describes the format of a transcript being imported.
+type ImportFormat string
+
+const (
+	// A BroChat channel export in the EXPORT_FORMAT_JSON format.
+	IMPORT_FORMAT_BROCHAT ImportFormat = "brochat"
+	// A Discord channel export in the JSON format produced by DiscordChatExporter.
+	IMPORT_FORMAT_DISCORD ImportFormat = "discord"
+	// A single channel day file from a Slack workspace export.
+	IMPORT_FORMAT_SLACK ImportFormat = "slack"
+)
+
+// An ImportedMessage is a historical message to be replayed into a channel.
+type ImportedMessage struct {
+	// The ID of the message in the source system. Used by the server to skip messages that have already been imported.
+	ExternalId string `json:"external_id"`
+	// The BroChat user ID to attribute the message to. Leave empty to attribute the message to the importing user.
+	SenderUserId string `json:"sender_user_id,omitempty"`
+	// The name of the original author in the source system. Displayed when the message cannot be attributed to a BroChat user.
+	OriginalAuthor string `json:"original_author"`
+	// The content of the message.
+	Content string `json:"content"`
+	// When the message was originally sent.
+	SentAtUtc time.Time `json:"sent_at_utc"`
+}
+
+type ImportMessagesRequest struct {
+	// The messages to import, oldest first.
+	Messages []ImportedMessage `json:"messages"`
+	// If true, the server is asked to keep the original timestamps rather than the time of import.
+	PreserveTimestamps bool `json:"preserve_timestamps"`
+}
+
+// The result of a bulk message import.
+type ImportMessagesResult struct {
+	// The number of messages that were imported.
+	ImportedCount uint64 `json:"imported_count"`
+	// The number of messages that were skipped because they had already been imported.
+	SkippedCount uint64 `json:"skipped_count"`
+	// True if the server preserved the original timestamps.
+	TimestampsPreserved bool `json:"timestamps_preserved"`
+}
+
+// ParseTranscript reads the messages of a transcript in the given format. Messages are returned oldest first.
+func ParseTranscript(r io.Reader, format ImportFormat) ([]ImportedMessage, error) {
+	var (
+		messages []ImportedMessage
+		err      error
+	)
+
+	switch format {
+	case IMPORT_FORMAT_BROCHAT:
+		messages, err = parseBroChatTranscript(r)
+	case IMPORT_FORMAT_DISCORD:
+		messages, err = parseDiscordTranscript(r)
+	case IMPORT_FORMAT_SLACK:
+		messages, err = parseSlackTranscript(r)
+	default:
+		return nil, ErrImportFormatUnknown
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sortImportedMessages(messages)
+
+	return messages, nil
+}
+
+// ChannelImporter replays transcripts into a channel using the bulk import endpoint.
+type ChannelImporter struct {
+	client             *BroChatClient
+	batchSize          int
+	preserveTimestamps bool
+	userMapping        map[string]string
+}
+
+// ChannelImporterOption is a type for the options that can be passed to NewChannelImporter.
+type ChannelImporterOption func(*ChannelImporter)
+
+// Sets the number of messages sent per import request. Defaults to MAX_IMPORT_BATCH_SIZE.
+func ChannelImporterOption_BatchSize(batchSize int) ChannelImporterOption {
+	return func(i *ChannelImporter) {
+		if batchSize > 0 && batchSize <= MAX_IMPORT_BATCH_SIZE {
+			i.batchSize = batchSize
+		}
+	}
+}
+
+// Asks the server to keep the original timestamps of the imported messages.
+func ChannelImporterOption_PreserveTimestamps() ChannelImporterOption {
+	return func(i *ChannelImporter) {
+		i.preserveTimestamps = true
+	}
+}
+
+// Maps author IDs or names from the source system to BroChat user IDs so messages are attributed to the right users.
+func ChannelImporterOption_UserMapping(mapping map[string]string) ChannelImporterOption {
+	return func(i *ChannelImporter) {
+		i.userMapping = mapping
+	}
+}
+
+// NewChannelImporter creates a new ChannelImporter which uses the given client to import messages.
+func NewChannelImporter(client *BroChatClient, options ...ChannelImporterOption) *ChannelImporter {
+	importer := &ChannelImporter{
+		client:    client,
+		batchSize: MAX_IMPORT_BATCH_SIZE,
+	}
+
+	for _, opt := range options {
+		opt(importer)
+	}
+
+	return importer
+}
+
+// Import parses a transcript and replays it into the channel. The returned result is the total across every batch.
+// If a batch fails the totals of the batches imported so far are returned along with the error.
+func (i *ChannelImporter) Import(accessToken string, channelId string, r io.Reader, format ImportFormat) (ImportMessagesResult, error) {
+	messages, err := ParseTranscript(r, format)
+
+	if err != nil {
+		return ImportMessagesResult{}, err
+	}
+
+	return i.ImportMessages(accessToken, channelId, messages)
+}
+
+// ImportMessages replays already parsed messages into the channel in batches.
+func (i *ChannelImporter) ImportMessages(accessToken string, channelId string, messages []ImportedMessage) (ImportMessagesResult, error) {
+	total := ImportMessagesResult{TimestampsPreserved: i.preserveTimestamps}
+
+	for start := 0; start < len(messages); start += i.batchSize {
+		end := min(start+i.batchSize, len(messages))
+		batch := make([]ImportedMessage, 0, end-start)
+
+		for _, m := range messages[start:end] {
+			if id, ok := i.userMapping[m.OriginalAuthor]; ok && m.SenderUserId == "" {
+				m.SenderUserId = id
+			}
+
+			batch = append(batch, m)
+		}
+
+		result := i.client.ImportChannelMessages(accessToken, channelId, ImportMessagesRequest{
+			Messages:           batch,
+			PreserveTimestamps: i.preserveTimestamps,
+		})
+
+		if err := result.Err(); err != nil {
+			return total, err
+		}
+
+		total.ImportedCount += result.Content.ImportedCount
+		total.SkippedCount += result.Content.SkippedCount
+		total.TimestampsPreserved = total.TimestampsPreserved && result.Content.TimestampsPreserved
+	}
+
+	return total, nil
+}
+
+// parseBroChatTranscript reads a newline delimited JSON BroChat export.
+func parseBroChatTranscript(r io.Reader) ([]ImportedMessage, error) {
+	messages := make([]ImportedMessage, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var exported ExportedMessage
+
+		if err := json.Unmarshal(scanner.Bytes(), &exported); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		author := exported.SenderUsername
+
+		if author == "" {
+			author = exported.SenderUserId
+		}
+
+		messages = append(messages, ImportedMessage{
+			ExternalId:     exported.Id,
+			OriginalAuthor: author,
+			Content:        exported.Content,
+			SentAtUtc:      exported.ReceivedAt().UTC(),
+		})
+	}
+
+	return messages, scanner.Err()
+}
+
+// The subset of the DiscordChatExporter JSON format used when importing.
+type discordTranscript struct {
+	Messages []struct {
+		Id        string    `json:"id"`
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Content   string    `json:"content"`
+		Author    struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"messages"`
+}
+
+// parseDiscordTranscript reads a DiscordChatExporter JSON export. Only regular messages and replies are imported.
+func parseDiscordTranscript(r io.Reader) ([]ImportedMessage, error) {
+	var transcript discordTranscript
+
+	if err := json.NewDecoder(r).Decode(&transcript); err != nil {
+		return nil, err
+	}
+
+	messages := make([]ImportedMessage, 0, len(transcript.Messages))
+
+	for _, m := range transcript.Messages {
+		if m.Type != "" && m.Type != "Default" && m.Type != "Reply" {
+			continue
+		}
+
+		messages = append(messages, ImportedMessage{
+			ExternalId:     m.Id,
+			OriginalAuthor: m.Author.Name,
+			Content:        m.Content,
+			SentAtUtc:      m.Timestamp.UTC(),
+		})
+	}
+
+	return messages, nil
+}
+
+// The subset of a Slack export message used when importing.
+type slackMessage struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	Text        string `json:"text"`
+	Ts          string `json:"ts"`
+	UserProfile struct {
+		RealName string `json:"real_name"`
+		Name     string `json:"name"`
+	} `json:"user_profile"`
+}
+
+// parseSlackTranscript reads a Slack export channel day file. Channel join/leave and other system messages are skipped.
+func parseSlackTranscript(r io.Reader) ([]ImportedMessage, error) {
+	var export []slackMessage
+
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	messages := make([]ImportedMessage, 0, len(export))
+
+	for _, m := range export {
+		if m.Type != "message" || (m.Subtype != "" && m.Subtype != "thread_broadcast") {
+			continue
+		}
+
+		sentAt, err := parseSlackTimestamp(m.Ts)
+
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %w", m.Ts, err)
+		}
+
+		author := m.UserProfile.Name
+
+		if author == "" {
+			author = m.User
+		}
+
+		messages = append(messages, ImportedMessage{
+			ExternalId:     m.Ts,
+			OriginalAuthor: author,
+			Content:        m.Text,
+			SentAtUtc:      sentAt,
+		})
+	}
+
+	return messages, nil
+}
+
+// parseSlackTimestamp converts a Slack "seconds.micros" timestamp to a time.
+func parseSlackTimestamp(ts string) (time.Time, error) {
+	secondsPart, microsPart, _ := strings.Cut(ts, ".")
+
+	seconds, err := strconv.ParseInt(secondsPart, 10, 64)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid slack timestamp %q", ts)
+	}
+
+	var micros int64
+
+	if microsPart != "" {
+		microsPart = (microsPart + "000000")[:6]
+
+		if micros, err = strconv.ParseInt(microsPart, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid slack timestamp %q", ts)
+		}
+	}
+
+	return time.Unix(seconds, micros*int64(time.Microsecond)).UTC(), nil
+}
+
+// sortImportedMessages orders the messages oldest first, keeping the source order for identical timestamps.
+func sortImportedMessages(messages []ImportedMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].SentAtUtc.Before(messages[j].SentAtUtc)
+	})
+}
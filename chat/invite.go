@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The path of invite links on the BroChat web client, followed by the invite code.
+const INVITE_LINK_PATH = "/invite/"
+
+// A RoomInvite is a shareable code which lets anyone who has it join a room, including users who are not friends with
+// any member and rooms which would otherwise require a password or an approved join request.
+type RoomInvite struct {
+	// The code of the invite. Shared as is or as a link made by InviteLink.
+	Code string `json:"code"`
+	// The ID of the room the invite joins.
+	RoomId string `json:"room_id"`
+	// The owner or moderator that created the invite.
+	CreatedBy UserInfo `json:"created_by"`
+	// When the invite was created.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// When the invite stops working. Will be nil if the invite does not expire.
+	ExpiresAtUtc *time.Time `json:"expires_at_utc,omitempty"`
+	// The number of times the invite can be used. Zero if the invite can be used any number of times.
+	MaxUses uint64 `json:"max_uses,omitempty"`
+	// The number of users that have joined the room with the invite.
+	Uses uint64 `json:"uses"`
+}
+
+// IsUsable returns true if the invite has not expired or been used up at the given time. Revoked invites are not
+// returned by the server, so they need not be considered.
+func (i RoomInvite) IsUsable(now time.Time) bool {
+	if i.ExpiresAtUtc != nil && !now.Before(*i.ExpiresAtUtc) {
+		return false
+	}
+
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
+
+// A request to create an invite to a room.
+type CreateRoomInviteRequest struct {
+	// How long the invite works for, in seconds. Zero creates an invite which does not expire.
+	TtlSeconds uint64 `json:"ttl_seconds,omitempty"`
+	// The number of times the invite can be used. Zero creates an invite which can be used any number of times.
+	MaxUses uint64 `json:"max_uses,omitempty"`
+}
+
+// InviteLink returns the link to an invite on the BroChat web client at the given base URL.
+// Example: https://chat.example.com/invite/Xy12Ab
+func InviteLink(baseUrl string, code string) string {
+	return strings.TrimSuffix(baseUrl, "/") + INVITE_LINK_PATH + url.PathEscape(code)
+}
+
+// ParseInviteCode returns the invite code of an invite link made by InviteLink, or the input itself if it is a bare code,
+// so users can paste either. Returns false if the input is empty or a link which is not an invite link.
+func ParseInviteCode(linkOrCode string) (string, bool) {
+	linkOrCode = strings.TrimSpace(linkOrCode)
+
+	if !strings.Contains(linkOrCode, "/") {
+		return linkOrCode, linkOrCode != ""
+	}
+
+	u, err := url.Parse(linkOrCode)
+
+	if err != nil {
+		return "", false
+	}
+
+	i := strings.LastIndex(u.Path, INVITE_LINK_PATH)
+
+	if i < 0 {
+		return "", false
+	}
+
+	code := strings.Trim(u.Path[i+len(INVITE_LINK_PATH):], "/")
+
+	return code, code != "" && !strings.Contains(code, "/")
+}
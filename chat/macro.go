@@ -27,7 +27,7 @@ const (
 // If the string does not represent a Macro request, the MACRO_TYPE_UNKNOWN will be returned.
 func IsMacro(rawMacro string) (bool, MacroType) {
 	// Get the first word of the message
-	val := strings.Split(rawMacro, " ")[0]
+	val, _, _ := strings.Cut(rawMacro, " ")
 
 	// Check if the first word is a macro
 	if !strings.HasPrefix(val, "/") {
@@ -49,6 +49,8 @@ func IsMacro(rawMacro string) (bool, MacroType) {
 type MacroRequest struct {
 	Type MacroType
 	Body string
+	// Protects the request against being sent again if intercepted.
+	ReplayProtection
 }
 
 type MacroParsingError struct {
@@ -0,0 +1,41 @@
+package chat
+
+import (
+	"testing"
+)
+
+func TestIsMacro(t *testing.T) {
+	tests := []struct {
+		raw       string
+		isMacro   bool
+		macroType MacroType
+	}{
+		{raw: "/roll 2d6", isMacro: true, macroType: MACRO_TYPE_ROLL},
+		{raw: "/FLIP", isMacro: true, macroType: MACRO_TYPE_FLIP},
+		{raw: "/dance now", isMacro: true, macroType: MACRO_TYPE_UNRECOGNIZED},
+		{raw: "hello /roll", isMacro: false, macroType: MACRO_TYPE_NONE},
+		{raw: "", isMacro: false, macroType: MACRO_TYPE_NONE},
+	}
+
+	for _, tt := range tests {
+		isMacro, macroType := IsMacro(tt.raw)
+
+		if isMacro != tt.isMacro || macroType != tt.macroType {
+			t.Errorf("IsMacro(%q) = %v, %v, want %v, %v", tt.raw, isMacro, macroType, tt.isMacro, tt.macroType)
+		}
+	}
+}
+
+func TestIsMacro_Allocs(t *testing.T) {
+	assertAllocs(t, 0, func() {
+		IsMacro("/roll 2d6 for initiative against the goblins in the cave")
+	})
+}
+
+func BenchmarkIsMacro(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		IsMacro("/roll 2d6 for initiative against the goblins in the cave")
+	}
+}
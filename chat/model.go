@@ -0,0 +1,218 @@
+package chat
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Equal returns true if the users have the same field values. Times are compared with time.Time.Equal and nil slices
+// equal empty slices, as they do once encoded and decoded.
+func (u User) Equal(other User) bool {
+	return len(u.Diff(other)) == 0
+}
+
+// Clone returns a deep copy of the user which shares no slices or pointers with it.
+func (u User) Clone() User {
+	return cloneModel(u)
+}
+
+// Diff returns the JSON names of the top level fields which differ between the users, in field order. Example: after a
+// FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED event, Diff of the stored and refreshed user returns ["relationships"] if only
+// the relationships changed, so only the friends list needs to be updated.
+func (u User) Diff(other User) []string {
+	return diffModels(u, other)
+}
+
+// Equal returns true if the rooms have the same field values. See User.Equal.
+func (r Room) Equal(other Room) bool {
+	return len(r.Diff(other)) == 0
+}
+
+// Clone returns a deep copy of the room which shares no slices or pointers with it.
+func (r Room) Clone() Room {
+	return cloneModel(r)
+}
+
+// Diff returns the JSON names of the top level fields which differ between the rooms, in field order.
+func (r Room) Diff(other Room) []string {
+	return diffModels(r, other)
+}
+
+// Equal returns true if the channels have the same field values. See User.Equal.
+func (c Channel) Equal(other Channel) bool {
+	return len(c.Diff(other)) == 0
+}
+
+// Clone returns a deep copy of the channel which shares no slices or pointers with it.
+func (c Channel) Clone() Channel {
+	return cloneModel(c)
+}
+
+// Diff returns the JSON names of the top level fields which differ between the channels, in field order.
+func (c Channel) Diff(other Channel) []string {
+	return diffModels(c, other)
+}
+
+// Equal returns true if the messages have the same field values. See User.Equal.
+func (m ChatMessage) Equal(other ChatMessage) bool {
+	return len(m.Diff(other)) == 0
+}
+
+// Clone returns a deep copy of the message which shares no slices or pointers with it.
+func (m ChatMessage) Clone() ChatMessage {
+	return cloneModel(m)
+}
+
+// Diff returns the JSON names of the top level fields which differ between the messages, in field order. The sent time
+// is compared with ReceivedAt and reported as "received_at_utc" only.
+func (m ChatMessage) Diff(other ChatMessage) []string {
+	m.syncReceivedAt()
+	other.syncReceivedAt()
+	m.RecievedAtUtc, other.RecievedAtUtc = time.Time{}, time.Time{}
+
+	return diffModels(chatMessageJSON(m), chatMessageJSON(other))
+}
+
+// diffModels returns the JSON names of the top level fields of two structs of the same type which differ.
+func diffModels[T any](a T, b T) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	fields := make([]string, 0)
+
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if !equalValues(va.Field(i), vb.Field(i)) {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// equalValues compares two values of the same type deeply. Unlike reflect.DeepEqual, times are compared by instant
+// and nil slices and maps equal empty ones.
+func equalValues(a reflect.Value, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+
+		return equalValues(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for _, key := range a.MapKeys() {
+			value := b.MapIndex(key)
+
+			if !value.IsValid() || !equalValues(a.MapIndex(key), value) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
+
+// cloneModel returns a deep copy of a struct.
+func cloneModel[T any](model T) T {
+	return cloneValue(reflect.ValueOf(model)).Interface().(T)
+}
+
+// cloneValue returns a deep copy of the value. Times are copied as they are, since they are immutable.
+func cloneValue(v reflect.Value) reflect.Value {
+	clone := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(cloneValue(v.Elem()))
+			clone.Set(elem)
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			clone.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+
+			for i := 0; i < v.Len(); i++ {
+				clone.Index(i).Set(cloneValue(v.Index(i)))
+			}
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			clone.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+
+			for _, key := range v.MapKeys() {
+				clone.SetMapIndex(key, cloneValue(v.MapIndex(key)))
+			}
+		}
+	case reflect.Struct:
+		clone.Set(v)
+
+		if v.Type() != timeType {
+			for i := 0; i < v.NumField(); i++ {
+				if clone.Field(i).CanSet() {
+					clone.Field(i).Set(cloneValue(v.Field(i)))
+				}
+			}
+		}
+	default:
+		clone.Set(v)
+	}
+
+	return clone
+}
@@ -0,0 +1,116 @@
+package chat
+
+import "time"
+
+// NotificationPreferences control when the server emits ChatNotification feed messages for a user.
+type NotificationPreferences struct {
+	// The notification level used for channels without a channel specific preference. An empty value is treated as NOTIFICATION_LEVEL_ALL.
+	DefaultLevel NotificationLevel `json:"default_level"`
+	// Channel specific preferences which override the default level.
+	Channels []ChannelNotificationPreference `json:"channels"`
+	// The channels the user has muted. Muted channels never produce notifications.
+	MutedChannels []MutedChannel `json:"muted_channels"`
+	// The user's quiet hours. Will be nil if the user has no quiet hours.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// A ChannelNotificationPreference overrides the default notification level for a single channel.
+type ChannelNotificationPreference struct {
+	// The ID of the channel.
+	ChannelId string `json:"channel_id"`
+	// The notification level for the channel.
+	Level NotificationLevel `json:"level"`
+}
+
+// A MutedChannel is a channel the user has muted.
+type MutedChannel struct {
+	// The ID of the channel.
+	ChannelId string `json:"channel_id"`
+	// When the mute ends. Will be nil if the channel is muted indefinitely.
+	MutedUntilUtc *time.Time `json:"muted_until_utc,omitempty"`
+}
+
+// QuietHours is a recurring daily window during which no notifications are sent. Windows may span midnight.
+type QuietHours struct {
+	// The start of the window in minutes after midnight. Example: 22:30 is 1350
+	StartMinute int `json:"start_minute"`
+	// The end of the window in minutes after midnight. Example: 07:00 is 420
+	EndMinute int `json:"end_minute"`
+	// The IANA time zone the window is expressed in. Example: America/Chicago. An empty value is treated as UTC.
+	TimeZone string `json:"time_zone"`
+}
+
+// Contains returns true if the given time falls within the quiet hours window.
+// An unrecognized time zone is treated as UTC.
+func (q QuietHours) Contains(t time.Time) bool {
+	location, err := time.LoadLocation(q.TimeZone)
+
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	if q.StartMinute <= q.EndMinute {
+		return minute >= q.StartMinute && minute < q.EndMinute
+	}
+
+	// The window spans midnight
+	return minute >= q.StartMinute || minute < q.EndMinute
+}
+
+// IsChannelMuted returns true if the channel is muted at the given time.
+func (p NotificationPreferences) IsChannelMuted(channelId string, now time.Time) bool {
+	for _, m := range p.MutedChannels {
+		if m.ChannelId == channelId {
+			return m.MutedUntilUtc == nil || now.Before(*m.MutedUntilUtc)
+		}
+	}
+
+	return false
+}
+
+// ChannelLevel returns the effective notification level for a channel at the given time.
+func (p NotificationPreferences) ChannelLevel(channelId string, now time.Time) NotificationLevel {
+	if p.IsChannelMuted(channelId, now) {
+		return NOTIFICATION_LEVEL_NONE
+	}
+
+	level := p.DefaultLevel
+
+	for _, c := range p.Channels {
+		if c.ChannelId != channelId {
+			continue
+		}
+
+		if c.Level != "" {
+			level = c.Level
+		}
+
+		break
+	}
+
+	if level == "" {
+		return NOTIFICATION_LEVEL_ALL
+	}
+
+	return level
+}
+
+// ShouldNotify determines whether a notification should be emitted for a message in the given channel.
+// Servers should call this before sending a ChatNotification feed message.
+func (p NotificationPreferences) ShouldNotify(channelId string, isMention bool, now time.Time) bool {
+	if p.QuietHours != nil && p.QuietHours.Contains(now) {
+		return false
+	}
+
+	switch p.ChannelLevel(channelId, now) {
+	case NOTIFICATION_LEVEL_NONE:
+		return false
+	case NOTIFICATION_LEVEL_MENTIONS_ONLY:
+		return isMention
+	default:
+		return true
+	}
+}
@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+)
+
+// CompareMessages orders messages by the time they were sent, breaking ties by ID so the order is the same on every
+// client. Returns a negative number if a was sent before b, a positive number if after and zero if they are the same message.
+func CompareMessages(a ChatMessage, b ChatMessage) int {
+	if c := a.ReceivedAt().Compare(b.ReceivedAt()); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Id, b.Id)
+}
+
+// SortMessages sorts messages oldest first. The API returns pages newest first, so call SortMessages (or
+// slices.Reverse) before displaying a page top to bottom.
+func SortMessages(messages []ChatMessage) {
+	slices.SortStableFunc(messages, CompareMessages)
+}
+
+// MergeMessages merges pages of messages which may overlap into one slice sorted oldest first. A message which appears
+// in more than one page is kept once, using its copy from the last page it appears in, so passing pages in the order
+// they were fetched keeps the most recent version of edited messages. The pages are not modified.
+func MergeMessages(pages ...[]ChatMessage) []ChatMessage {
+	count := 0
+
+	for _, page := range pages {
+		count += len(page)
+	}
+
+	merged := make([]ChatMessage, 0, count)
+	positions := make(map[string]int, count)
+
+	for _, page := range pages {
+		for _, message := range page {
+			if i, ok := positions[message.Id]; ok {
+				merged[i] = message
+				continue
+			}
+
+			positions[message.Id] = len(merged)
+			merged = append(merged, message)
+		}
+	}
+
+	SortMessages(merged)
+
+	return merged
+}
+
+// InsertMessage adds a message, typically one received on the feed, to a history sorted oldest first and returns the
+// updated history. A message already in the history is replaced, moving it if its sent time changed. Messages usually
+// arrive in order, so appending is the fast path.
+func InsertMessage(history []ChatMessage, message ChatMessage) []ChatMessage {
+	if i := slices.IndexFunc(history, func(m ChatMessage) bool { return m.Id == message.Id }); i >= 0 {
+		if CompareMessages(history[i], message) == 0 {
+			history[i] = message
+			return history
+		}
+
+		history = slices.Delete(history, i, i+1)
+	}
+
+	if len(history) == 0 || CompareMessages(history[len(history)-1], message) < 0 {
+		return append(history, message)
+	}
+
+	i, _ := slices.BinarySearchFunc(history, message, CompareMessages)
+
+	return slices.Insert(history, i, message)
+}
+
+// FirstUnreadIndex returns the index of the first message after the read pointer in a history sorted oldest first,
+// which is where the "new messages" divider goes. Returns len(messages) if every message has been read, 0 if the
+// channel has never been read and -1 if the pointer is not in the history, in which case older messages must be
+// fetched to place the divider. Compute the index when the channel is opened and keep it while the channel is shown,
+// so the divider does not move as the user reads.
+func FirstUnreadIndex(messages []ChatMessage, lastReadMessageId string) int {
+	if lastReadMessageId == "" {
+		return 0
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Id == lastReadMessageId {
+			return i + 1
+		}
+	}
+
+	return -1
+}
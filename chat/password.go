@@ -0,0 +1,115 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrRoomPasswordHashMalformed = errors.New("malformed room password hash")
+)
+
+const (
+	// The identifier of the room password hashing scheme.
+	roomPasswordHashScheme = "pbkdf2-sha256"
+	// The number of PBKDF2 iterations used when hashing new room passwords.
+	roomPasswordHashIterations = 210000
+	// The size of the random salt in bytes.
+	roomPasswordSaltSize = 16
+	// The size of the derived key in bytes.
+	roomPasswordKeySize = 32
+)
+
+// HashRoomPassword hashes a room join password for storage. Intended for server implementations; the plain password must never be stored.
+// The returned value is self describing and can be verified with VerifyRoomPassword.
+func HashRoomPassword(password string) (string, error) {
+	salt := make([]byte, roomPasswordSaltSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2SHA256([]byte(password), salt, roomPasswordHashIterations, roomPasswordKeySize)
+
+	return fmt.Sprintf("%s$%d$%s$%s", roomPasswordHashScheme, roomPasswordHashIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyRoomPassword checks a password supplied to JoinRoom against a hash produced by HashRoomPassword.
+// Servers should respond with BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR when this returns false.
+func VerifyRoomPassword(password string, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+
+	if len(parts) != 4 || parts[0] != roomPasswordHashScheme {
+		return false, ErrRoomPasswordHashMalformed
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+
+	if err != nil || iterations <= 0 {
+		return false, ErrRoomPasswordHashMalformed
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return false, ErrRoomPasswordHashMalformed
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+
+	if err != nil || len(expected) == 0 {
+		return false, ErrRoomPasswordHashMalformed
+	}
+
+	key := pbkdf2SHA256([]byte(password), salt, iterations, len(expected))
+
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
+
+// NewInvalidRoomPasswordError creates the error response returned to a client that supplied a wrong or missing room password.
+func NewInvalidRoomPasswordError() *BroChatError {
+	return NewErrorResponse(BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR, "the room password is incorrect")
+}
+
+// pbkdf2SHA256 derives a key from the password using PBKDF2 (RFC 8018) with HMAC-SHA256.
+func pbkdf2SHA256(password []byte, salt []byte, iterations int, keyLength int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blockCount := (keyLength + prf.Size() - 1) / prf.Size()
+	key := make([]byte, 0, blockCount*prf.Size())
+	buf := make([]byte, 4)
+	u := make([]byte, 0, prf.Size())
+
+	for block := 1; block <= blockCount; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, len(u))
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLength]
+}
@@ -0,0 +1,67 @@
+package chat
+
+// RoleOf returns the role the given user has in the room. Returns ROOM_ROLE_MEMBER if the user is not the owner or a moderator;
+// whether such a user is actually a member of the room must be determined from the room's channel.
+func (r Room) RoleOf(userId string) RoomRole {
+	if r.Owner.Id == userId {
+		return ROOM_ROLE_OWNER
+	}
+
+	for _, m := range r.Moderators {
+		if m.Id == userId {
+			return ROOM_ROLE_MODERATOR
+		}
+	}
+
+	return ROOM_ROLE_MEMBER
+}
+
+// IsModerator returns true if the role is allowed to moderate the room.
+func (r RoomRole) IsModerator() bool {
+	return r == ROOM_ROLE_OWNER || r == ROOM_ROLE_MODERATOR
+}
+
+// IsMember returns true if the user is one of the channel's users.
+func (c Channel) IsMember(userId string) bool {
+	for _, u := range c.Users {
+		if u.Id == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanPostMessage determines whether a user may post a message in a channel. Messages cannot be posted in voice channels.
+// The room must be provided for room channels so the user's role can be determined; it is ignored for other channel types.
+func CanPostMessage(channel Channel, room *Room, userId string) bool {
+	if channel.IsArchived || channel.Type == CHANNEL_TYPE_VOICE || !channel.IsMember(userId) {
+		return false
+	}
+
+	if channel.Mode != CHANNEL_MODE_ANNOUNCEMENT || channel.Type != CHANNEL_TYPE_ROOM {
+		return true
+	}
+
+	return room != nil && room.RoleOf(userId).IsModerator()
+}
+
+// CanReactToMessage determines whether a user may react to messages in a channel. Members of announcement channels may react even though they cannot post.
+func CanReactToMessage(channel Channel, userId string) bool {
+	return !channel.IsArchived && channel.IsMember(userId)
+}
+
+// CanDeleteMessage determines whether a user may delete a message. Authors may always delete their own messages;
+// the owner and moderators of a room may delete any message in the room's channel.
+func CanDeleteMessage(message ChatMessage, room *Room, userId string) bool {
+	if message.SenderUserId == userId {
+		return true
+	}
+
+	return room != nil && room.ChannelId == message.ChannelId && room.RoleOf(userId).IsModerator()
+}
+
+// CanJoinVoiceChannel determines whether a user may join a voice channel. Any member of the room may join its voice channels.
+func CanJoinVoiceChannel(channel Channel, userId string) bool {
+	return channel.Type == CHANNEL_TYPE_VOICE && !channel.IsArchived && channel.IsMember(userId)
+}
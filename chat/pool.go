@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Buffers which have grown beyond this size are not returned to the pool, so a single large body does not pin memory.
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool holds the buffers used to encode request bodies and read response bodies.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
+}
+
+// putBuffer returns a buffer to the pool. The buffer must not be used afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
+
+// requestBody is an encoded JSON request body. When the codec can encode to a writer the body is held in a pooled buffer.
+//
+// The transport may read and close a request body after Client.Do returns, and may replay it with GetBody during
+// redirects and retries, so the buffer is reference counted: the caller holds one reference until release is called and
+// each body handed to the transport holds one until it is closed.
+type requestBody struct {
+	data []byte
+	buf  *bytes.Buffer
+	refs atomic.Int32
+}
+
+// encodeRequestBody encodes the value with the codec. Call release once the response has been handled.
+func encodeRequestBody(codec Codec, v any) (*requestBody, error) {
+	streamCodec, ok := codec.(StreamCodec)
+
+	if !ok {
+		data, err := codec.Marshal(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &requestBody{data: data}, nil
+	}
+
+	buf := getBuffer()
+
+	if err := streamCodec.Encode(buf, v); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+
+	body := &requestBody{data: buf.Bytes(), buf: buf}
+	body.refs.Store(1)
+
+	return body, nil
+}
+
+// newRequest creates a request with the body. The content length is set and the body can be replayed.
+func (b *requestBody) newRequest(method string, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.ContentLength = int64(len(b.data))
+	req.Body = b.reader()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return b.reader(), nil
+	}
+
+	return req, nil
+}
+
+// reader returns a new reader of the body which holds a reference until it is closed.
+func (b *requestBody) reader() io.ReadCloser {
+	if b.buf == nil {
+		return io.NopCloser(bytes.NewReader(b.data))
+	}
+
+	b.refs.Add(1)
+
+	return &requestBodyReader{Reader: bytes.NewReader(b.data), body: b}
+}
+
+// release drops a reference, returning the buffer to the pool when no references remain.
+func (b *requestBody) release() {
+	if b.buf != nil && b.refs.Add(-1) == 0 {
+		putBuffer(b.buf)
+	}
+}
+
+// requestBodyReader reads a requestBody and releases its reference when closed.
+type requestBodyReader struct {
+	*bytes.Reader
+	body *requestBody
+	once sync.Once
+}
+
+// Close implements the io.Closer interface.
+func (r *requestBodyReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
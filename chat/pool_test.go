@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+// marshalCodec is a StdCodec which cannot encode to a writer, so its request bodies are not pooled.
+type marshalCodec struct{}
+
+func (marshalCodec) Marshal(v any) ([]byte, error) {
+	return StdCodec{}.Marshal(v)
+}
+
+func (marshalCodec) Unmarshal(data []byte, v any) error {
+	return StdCodec{}.Unmarshal(data, v)
+}
+
+// sendRequestBody encodes a chat message request body and reads it the way the transport does.
+func sendRequestBody(t testing.TB, codec Codec) {
+	body, err := encodeRequestBody(codec, ChatMessageRequest{ChannelId: "channel", Content: "hello there"})
+
+	if err != nil {
+		t.Fatalf("encodeRequestBody() error = %v", err)
+	}
+
+	defer body.release()
+
+	req, err := body.newRequest(http.MethodPost, "https://example.com/api/brochat/messages")
+
+	if err != nil {
+		t.Fatalf("newRequest() error = %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, req.Body); err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+
+	req.Body.Close()
+}
+
+func TestEncodeRequestBody_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation budgets are not checked with the race detector")
+	}
+
+	pooled := testing.AllocsPerRun(100, func() { sendRequestBody(t, StdCodec{}) })
+	unpooled := testing.AllocsPerRun(100, func() { sendRequestBody(t, marshalCodec{}) })
+
+	if pooled >= unpooled {
+		t.Errorf("pooled allocs = %v, want fewer than unpooled allocs = %v", pooled, unpooled)
+	}
+
+	assertAllocs(t, 8, func() { sendRequestBody(t, StdCodec{}) })
+}
+
+// BenchmarkEncodeRequestBody compares encoding request bodies into pooled buffers with marshaling them into new slices.
+func BenchmarkEncodeRequestBody(b *testing.B) {
+	codecs := map[string]Codec{
+		"pooled":   StdCodec{},
+		"unpooled": marshalCodec{},
+	}
+
+	for name, codec := range codecs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				sendRequestBody(b, codec)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package chat
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessagePrefetcher defaults.
+const (
+	// The default number of prefetched pages held at once.
+	DEFAULT_PREFETCH_CAPACITY = 16
+	// The default time a prefetched page is served for before it is considered stale.
+	DEFAULT_PREFETCH_TTL = 30 * time.Second
+)
+
+// MessagePrefetcher makes scrolling back through channel history feel instant. After each GetChannelMessages call
+// that returns a full page, the next page is fetched in the background and held until it is requested, expires or
+// is invalidated. A request for a page which is still being prefetched waits for the prefetch instead of making a
+// second request. Each prefetched page is served once, after which the page following it is prefetched in turn.
+//
+// New messages shift the pages of a channel, so pass feed messages to HandleFeedMessage or call Invalidate when the
+// history of a channel changes. MessagePrefetcher is safe for concurrent use.
+type MessagePrefetcher struct {
+	mu       sync.Mutex
+	client   *BroChatClient
+	capacity int
+	ttl      time.Duration
+	codec    Codec
+	now      func() time.Time
+	order    *list.List
+	pages    map[string]*list.Element
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// prefetchedPage is a page which has been or is being prefetched.
+type prefetchedPage struct {
+	key       string
+	channelId string
+	ready     chan struct{}
+	result    BroChatClientContentResult[[]ChatMessage]
+	fetchedAt time.Time
+}
+
+// MessagePrefetcherOption is a type for the options that can be passed to NewMessagePrefetcher.
+type MessagePrefetcherOption func(*MessagePrefetcher)
+
+// Sets the maximum number of prefetched pages held at once. Defaults to DEFAULT_PREFETCH_CAPACITY.
+func MessagePrefetcherOption_Capacity(capacity int) MessagePrefetcherOption {
+	return func(p *MessagePrefetcher) {
+		p.capacity = capacity
+	}
+}
+
+// Sets how long a prefetched page is served for. Defaults to DEFAULT_PREFETCH_TTL.
+func MessagePrefetcherOption_TTL(ttl time.Duration) MessagePrefetcherOption {
+	return func(p *MessagePrefetcher) {
+		p.ttl = ttl
+	}
+}
+
+// Sets the codec used to decode the content of feed messages. Defaults to StdCodec.
+func MessagePrefetcherOption_Codec(codec Codec) MessagePrefetcherOption {
+	return func(p *MessagePrefetcher) {
+		p.codec = codec
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func MessagePrefetcherOption_Clock(now func() time.Time) MessagePrefetcherOption {
+	return func(p *MessagePrefetcher) {
+		p.now = now
+	}
+}
+
+// NewMessagePrefetcher creates a prefetcher which fetches pages with the client. Call Close to cancel running prefetches.
+func NewMessagePrefetcher(client *BroChatClient, options ...MessagePrefetcherOption) *MessagePrefetcher {
+	p := &MessagePrefetcher{
+		client:   client,
+		capacity: DEFAULT_PREFETCH_CAPACITY,
+		ttl:      DEFAULT_PREFETCH_TTL,
+		codec:    StdCodec{},
+		now:      time.Now,
+		order:    list.New(),
+		pages:    make(map[string]*list.Element),
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	p.capacity = max(p.capacity, 1)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	return p
+}
+
+// GetChannelMessages returns a list of messages in a channel, serving the page from the prefetched pages if possible,
+// and prefetches the next page. Accepts the same options as BroChatClient.GetChannelMessages.
+func (p *MessagePrefetcher) GetChannelMessages(accessToken string, channelId string, options ...GetChannelMessagesOption) BroChatClientContentResult[[]ChatMessage] {
+	query, ok := newPageQuery(options)
+
+	// The client reports invalid options, and there is no page to cache or prefetch after
+	if !ok {
+		return p.client.GetChannelMessages(accessToken, channelId, options...)
+	}
+
+	key := query.key(accessToken, channelId)
+
+	result, ok := p.take(key)
+
+	if !ok {
+		result = p.client.GetChannelMessages(accessToken, channelId, options...)
+	}
+
+	if result.Err() == nil && uint64(len(result.Content)) >= query.pageSize {
+		p.prefetch(accessToken, channelId, query.next())
+	}
+
+	return result
+}
+
+// Invalidate drops the prefetched pages of a channel.
+func (p *MessagePrefetcher) Invalidate(channelId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for element := p.order.Front(); element != nil; {
+		next := element.Next()
+
+		if page := element.Value.(*prefetchedPage); page.channelId == channelId {
+			p.remove(element)
+		}
+
+		element = next
+	}
+}
+
+// HandleFeedMessage invalidates the prefetched pages of the channel a feed message changes: a new chat message, an
+// expired message, a reaction or a delivery state update. Other feed messages are ignored.
+func (p *MessagePrefetcher) HandleFeedMessage(message *FeedMessage) error {
+	switch message.Type {
+	case FEED_MESSAGE_TYPE_CHAT_MESSAGE, FEED_MESSAGE_TYPE_MESSAGE_EXPIRED, FEED_MESSAGE_TYPE_REACTION_ADDED,
+		FEED_MESSAGE_TYPE_REACTION_REMOVED, FEED_MESSAGE_TYPE_MESSAGE_DELIVERY_STATE_UPDATED:
+		// The content of each of these types has the ID of the channel.
+		var content struct {
+			ChannelId string `json:"channel_id"`
+		}
+
+		if err := message.DecodeContent(p.codec, &content); err != nil {
+			return err
+		}
+
+		p.Invalidate(content.ChannelId)
+	}
+
+	return nil
+}
+
+// Close cancels running prefetches and drops all prefetched pages. GetChannelMessages keeps working after Close but
+// no longer prefetches.
+func (p *MessagePrefetcher) Close() {
+	p.cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.order.Init()
+	clear(p.pages)
+}
+
+// take removes a prefetched page, waiting for it if it is still being fetched. Returns false if the page has not been
+// prefetched, has expired or failed.
+func (p *MessagePrefetcher) take(key string) (BroChatClientContentResult[[]ChatMessage], bool) {
+	p.mu.Lock()
+	element, ok := p.pages[key]
+
+	if ok {
+		p.remove(element)
+	}
+
+	p.mu.Unlock()
+
+	if !ok {
+		return BroChatClientContentResult[[]ChatMessage]{}, false
+	}
+
+	page := element.Value.(*prefetchedPage)
+	<-page.ready
+
+	if page.result.Err() != nil || p.now().Sub(page.fetchedAt) > p.ttl {
+		return BroChatClientContentResult[[]ChatMessage]{}, false
+	}
+
+	return page.result, true
+}
+
+// prefetch fetches a page in the background unless it is already prefetched, evicting the oldest pages if full.
+func (p *MessagePrefetcher) prefetch(accessToken string, channelId string, query pageQuery) {
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	key := query.key(accessToken, channelId)
+	page := &prefetchedPage{key: key, channelId: channelId, ready: make(chan struct{})}
+
+	p.mu.Lock()
+
+	if _, ok := p.pages[key]; ok {
+		p.mu.Unlock()
+		return
+	}
+
+	p.pages[key] = p.order.PushFront(page)
+
+	for p.order.Len() > p.capacity {
+		p.remove(p.order.Back())
+	}
+
+	p.mu.Unlock()
+
+	go func() {
+		defer close(page.ready)
+
+		page.result = p.client.getChannelMessages(p.ctx, accessToken, channelId, query.options()...)
+		page.fetchedAt = p.now()
+	}()
+}
+
+// remove drops a page. The lock must be held.
+func (p *MessagePrefetcher) remove(element *list.Element) {
+	p.order.Remove(element)
+	delete(p.pages, element.Value.(*prefetchedPage).key)
+}
+
+// pageQuery is the page of channel history requested by a set of GetChannelMessagesOption values.
+type pageQuery struct {
+	page     uint64
+	pageSize uint64
+	// The other query parameters, such as before-msg, which select the messages being paged through.
+	filters []queryParam
+}
+
+// newPageQuery applies the options, filling in the server defaults for options which were not given. Returns false if
+// the options are invalid, such as a page size of zero, as BroChatClient.GetChannelMessages would reject them.
+func newPageQuery(options []GetChannelMessagesOption) (pageQuery, bool) {
+	opts := option{values: make([]queryParam, 0)}
+
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.errs) > 0 {
+		return pageQuery{}, false
+	}
+
+	query := pageQuery{page: 1, pageSize: MAX_PAGE_SIZE}
+
+	for _, value := range opts.values {
+		var err error
+
+		switch value.key {
+		case "page":
+			query.page, err = strconv.ParseUint(value.value, 10, 64)
+		case "page-size":
+			query.pageSize, err = strconv.ParseUint(value.value, 10, 64)
+		default:
+			query.filters = append(query.filters, value)
+		}
+
+		if err != nil {
+			return pageQuery{}, false
+		}
+	}
+
+	if query.page < 1 || query.pageSize < 1 || query.pageSize > MAX_PAGE_SIZE {
+		return pageQuery{}, false
+	}
+
+	slices.SortFunc(query.filters, func(a, b queryParam) int { return strings.Compare(a.key, b.key) })
+
+	return query, true
+}
+
+// next returns the query of the following page.
+func (q pageQuery) next() pageQuery {
+	q.page++
+	return q
+}
+
+// options returns the options requesting the page.
+func (q pageQuery) options() []GetChannelMessagesOption {
+	return []GetChannelMessagesOption{
+		GetChannelMessages_Page(q.page),
+		GetChannelMessages_PageSize(q.pageSize),
+		func(o *option) {
+			o.values = append(o.values, q.filters...)
+		},
+	}
+}
+
+// key identifies the page of a channel as seen by the holder of the access token.
+func (q pageQuery) key(accessToken string, channelId string) string {
+	parts := []string{accessToken, channelId, strconv.FormatUint(q.page, 10), strconv.FormatUint(q.pageSize, 10)}
+
+	for _, filter := range q.filters {
+		parts = append(parts, filter.key+"="+filter.value)
+	}
+
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newPagingServer serves pages of a channel holding the given number of messages and records the pages requested.
+func newPagingServer(t *testing.T, total int) (*httptest.Server, func() []string) {
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		page, _ := strconv.Atoi(query.Get("page"))
+		size, _ := strconv.Atoi(query.Get("page-size"))
+
+		mu.Lock()
+		requested = append(requested, query.Get("page"))
+		mu.Unlock()
+
+		messages := make([]ChatMessage, 0, size)
+
+		for i := (page - 1) * size; i < min(page*size, total); i++ {
+			messages = append(messages, ChatMessage{Id: fmt.Sprintf("m%d", i)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(messages)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string(nil), requested...)
+	}
+}
+
+func TestMessagePrefetcher_ServesPrefetchedPages(t *testing.T) {
+	server, requested := newPagingServer(t, 5)
+	p := NewMessagePrefetcher(NewBroChatClient(server.Client(), server.URL))
+	defer p.Close()
+
+	for page, want := range []int{2, 2, 1} {
+		result := p.GetChannelMessages("token", "c", GetChannelMessages_Page(uint64(page+1)), GetChannelMessages_PageSize(2))
+
+		if err := result.Err(); err != nil {
+			t.Fatalf("GetChannelMessages(page %d) error = %v", page+1, err)
+		}
+
+		if len(result.Content) != want {
+			t.Errorf("GetChannelMessages(page %d) returned %d messages, want %d", page+1, len(result.Content), want)
+		}
+	}
+
+	// Each page was requested once, the later ones by the prefetcher
+	if got := fmt.Sprint(requested()); got != "[1 2 3]" {
+		t.Errorf("requested pages = %s, want [1 2 3]", got)
+	}
+}
+
+func TestMessagePrefetcher_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []GetChannelMessagesOption
+	}{
+		{name: "zero page size", options: []GetChannelMessagesOption{GetChannelMessages_PageSize(0)}},
+		{name: "zero page", options: []GetChannelMessagesOption{GetChannelMessages_Page(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requested := newPagingServer(t, 500)
+			p := NewMessagePrefetcher(NewBroChatClient(server.Client(), server.URL))
+			defer p.Close()
+
+			result := p.GetChannelMessages("token", "c", tt.options...)
+
+			if result.ResponseCode != BROCHAT_RESPONSE_CODE_VALIDATION_ERROR {
+				t.Errorf("ResponseCode = %v, want BROCHAT_RESPONSE_CODE_VALIDATION_ERROR", result.ResponseCode)
+			}
+
+			p.mu.Lock()
+			cached := len(p.pages)
+			p.mu.Unlock()
+
+			if cached != 0 || len(requested()) != 0 {
+				t.Errorf("prefetched %d pages and requested %v, want the invalid query left alone", cached, requested())
+			}
+		})
+	}
+
+	if _, ok := newPageQuery([]GetChannelMessagesOption{func(o *option) { o.values = append(o.values, queryParam{"page-size", "many"}) }}); ok {
+		t.Errorf("newPageQuery() accepted a page size which is not a number")
+	}
+}
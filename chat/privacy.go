@@ -0,0 +1,77 @@
+package chat
+
+import "time"
+
+// PrivacySettings control which parts of a user's profile are shared with other users.
+type PrivacySettings struct {
+	// Who can see when the user was last online and their presence state. An empty value is treated as LAST_SEEN_VISIBILITY_EVERYONE.
+	LastSeenVisibility LastSeenVisibility `json:"last_seen_visibility"`
+}
+
+// CanViewLastSeen determines whether a viewer may see the last online time and presence state of the user these settings belong to.
+// Users can always see their own last seen information.
+func (s PrivacySettings) CanViewLastSeen(viewerIsSelf bool, viewerIsFriend bool) bool {
+	if viewerIsSelf {
+		return true
+	}
+
+	switch s.LastSeenVisibility {
+	case LAST_SEEN_VISIBILITY_NOBODY:
+		return false
+	case LAST_SEEN_VISIBILITY_FRIENDS:
+		return viewerIsFriend
+	default:
+		return true
+	}
+}
+
+// NewUserInfo creates the UserInfo payload describing the given user as seen by the viewer.
+// The last online time and presence state are omitted if the user's privacy settings do not allow the viewer to see them.
+// Invisible users are reported as offline to everyone but themselves.
+func NewUserInfo(user User, presence PresenceState, viewerUserId string) UserInfo {
+	info := UserInfo{
+		Id:            user.Id,
+		Username:      user.Username,
+		LastOnlineUtc: user.LastOnlineUtc,
+		Presence:      presence,
+	}
+
+	viewerIsSelf := viewerUserId == user.Id
+
+	if !viewerIsSelf && presence == PRESENCE_STATE_INVISIBLE {
+		info.Presence = PRESENCE_STATE_OFFLINE
+	}
+
+	if !user.PrivacySettings.CanViewLastSeen(viewerIsSelf, isFriendOf(user, viewerUserId)) {
+		info.LastOnlineUtc = time.Time{}
+		info.Presence = ""
+	}
+
+	return info
+}
+
+// RedactUserRelationship applies the privacy settings of the user a relationship is with to the relationship entry.
+// The settings must belong to the user identified by the relationship's UserId.
+func (s PrivacySettings) RedactUserRelationship(relationship UserRelationship) UserRelationship {
+	if relationship.Presence == PRESENCE_STATE_INVISIBLE {
+		relationship.Presence = PRESENCE_STATE_OFFLINE
+	}
+
+	if !s.CanViewLastSeen(false, relationship.Type == RELATIONSHIP_TYPE_FRIEND) {
+		relationship.LastOnlineUtc = time.Time{}
+		relationship.Presence = ""
+	}
+
+	return relationship
+}
+
+// isFriendOf reports whether the given user ID is a friend of the user.
+func isFriendOf(user User, userId string) bool {
+	for _, r := range user.Relationships {
+		if r.UserId == userId {
+			return r.Type == RELATIONSHIP_TYPE_FRIEND
+		}
+	}
+
+	return false
+}
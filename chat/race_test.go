@@ -0,0 +1,6 @@
+//go:build race
+
+package chat
+
+// The race detector adds allocations and drops pooled values at random, so allocation budgets are not checked.
+const raceEnabled = true
@@ -0,0 +1,38 @@
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// ReplayProtection lets the server reject feed requests which were intercepted and sent again, such as to spam a channel
+// with copies of a message. It is embedded in the requests clients send on the feed. A client stamps each request with
+// a new nonce and the time it was sent; the server accepts a stamped request once, and only within a window of the time
+// it was sent. Both fields are optional so clients which do not stamp their requests keep working, unless the server
+// requires them. The server side checks are in the server package.
+type ReplayProtection struct {
+	// A random value unique to the request. Set by NewReplayProtection.
+	Nonce string `json:"nonce,omitempty"`
+	// When the client sent the request.
+	SentAtUtc *time.Time `json:"sent_at_utc,omitempty"`
+}
+
+// NewReplayProtection creates the replay protection of a request sent at the given time, with a new random nonce.
+// Usage: request.ReplayProtection = chat.NewReplayProtection(time.Now())
+func NewReplayProtection(now time.Time) ReplayProtection {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	sentAt := now.UTC()
+
+	return ReplayProtection{Nonce: hex.EncodeToString(b), SentAtUtc: &sentAt}
+}
+
+// IsSet returns true if the request has been stamped with a nonce.
+func (p ReplayProtection) IsSet() bool {
+	return p.Nonce != ""
+}
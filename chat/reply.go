@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The default maximum length (in characters) of a quoted reply snippet.
+const DEFAULT_REPLY_SNIPPET_LENGTH = 80
+
+// A MessageReference is a lightweight reference to another message. Used to render quoted replies without fetching the full message.
+type MessageReference struct {
+	// The ID of the referenced message.
+	MessageId string `json:"message_id"`
+	// The ID of the channel that the referenced message was sent in.
+	ChannelId string `json:"channel_id"`
+	// The ID of the user that sent the referenced message.
+	SenderUserId string `json:"sender_user_id"`
+	// A truncated preview of the referenced message content.
+	Snippet string `json:"snippet"`
+}
+
+// NewMessageReference creates a MessageReference for the given message with a snippet of the default length.
+func NewMessageReference(message ChatMessage) MessageReference {
+	return MessageReference{
+		MessageId:    message.Id,
+		ChannelId:    message.ChannelId,
+		SenderUserId: message.SenderUserId,
+		Snippet:      QuoteSnippet(message.Content, DEFAULT_REPLY_SNIPPET_LENGTH),
+	}
+}
+
+// QuoteSnippet creates a single line preview of the given content which is no longer than maxLength characters.
+// Line breaks are collapsed into single spaces and an ellipsis is appended if the content was truncated.
+// A maxLength of 0 will use the DEFAULT_REPLY_SNIPPET_LENGTH.
+func QuoteSnippet(content string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = DEFAULT_REPLY_SNIPPET_LENGTH
+	}
+
+	snippet := []rune(strings.Join(strings.Fields(content), " "))
+
+	if len(snippet) <= maxLength {
+		return string(snippet)
+	}
+
+	if maxLength == 1 {
+		return "…"
+	}
+
+	return strings.TrimRight(string(snippet[:maxLength-1]), " ") + "…"
+}
+
+// FormatQuotedReply renders a quoted reply preview suitable for display above the reply content.
+// Usage: FormatQuotedReply("bro", ref) // "> bro: the original message"
+func FormatQuotedReply(senderUsername string, reference MessageReference) string {
+	if senderUsername == "" {
+		return fmt.Sprintf("> %s", reference.Snippet)
+	}
+
+	return fmt.Sprintf("> %s: %s", senderUsername, reference.Snippet)
+}
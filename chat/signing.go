@@ -0,0 +1,253 @@
+package chat
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The default maximum time between signing a message and the server receiving it accepted by VerifyMessageSignature.
+const DEFAULT_MESSAGE_SIGNATURE_TOLERANCE = 5 * time.Minute
+
+// messageSignatureDomain prefixes every signed payload so message signatures cannot be reused for anything else.
+const messageSignatureDomain = "brochat.message.signature.v1"
+
+var (
+	// ErrMessageNotSigned is returned when verifying a message which has no signature.
+	ErrMessageNotSigned = errors.New("message is not signed")
+	// ErrMessageSignatureInvalid is returned when a signature does not match the message, including messages edited after
+	// they were signed.
+	ErrMessageSignatureInvalid = errors.New("invalid message signature")
+	// ErrMessageSignatureExpired is returned when a message was received by the server outside the tolerance of when it
+	// was signed, which happens when a signed message is replayed.
+	ErrMessageSignatureExpired = errors.New("message signature timestamp is outside the tolerance")
+	// ErrSigningKeyNotFound is returned when the sender has no signing key with the ID of the signature.
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	// ErrSigningKeyRevoked is returned when a message was signed after its signing key was revoked.
+	ErrSigningKeyRevoked = errors.New("signing key has been revoked")
+)
+
+// A SigningKey is an Ed25519 public key registered by a user, typically a bot account, to sign their messages.
+type SigningKey struct {
+	// The ID of the key.
+	Id string `json:"id"`
+	// The ID of the user the key belongs to.
+	UserId string `json:"user_id"`
+	// The Ed25519 public key.
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	// When the key was registered.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// When the key was revoked. Will be nil if the key has not been revoked. Messages signed before the key was revoked
+	// remain valid.
+	RevokedAtUtc *time.Time `json:"revoked_at_utc,omitempty"`
+}
+
+type AddSigningKeyRequest struct {
+	// The Ed25519 public key to register.
+	PublicKey ed25519.PublicKey `json:"public_key"`
+}
+
+// A MessageSignature is an Ed25519 signature of a message by its sender.
+type MessageSignature struct {
+	// The ID of the SigningKey which made the signature.
+	KeyId string `json:"key_id"`
+	// When the message was signed.
+	SignedAtUtc time.Time `json:"signed_at_utc"`
+	// The Ed25519 signature of the message.
+	Signature []byte `json:"signature"`
+}
+
+// SignChatMessageRequest signs the request with the private key of the signing key with the ID. The signature covers the
+// channel, content type, content, embed, replied to message and attachments of the request, and the time it was signed.
+// Sign the request last, since changing any of those afterwards invalidates the signature.
+func SignChatMessageRequest(request *ChatMessageRequest, keyId string, key ed25519.PrivateKey, now time.Time) error {
+	signedAt := now.UTC()
+
+	payload, err := messageSigningPayload(request.ChannelId, request.ContentType, request.Content, request.Embed,
+		request.ReplyToMessageId, request.AttachmentIds, signedAt)
+
+	if err != nil {
+		return err
+	}
+
+	request.Signature = &MessageSignature{KeyId: keyId, SignedAtUtc: signedAt, Signature: ed25519.Sign(key, payload)}
+
+	return nil
+}
+
+// VerifyMessageSignature checks the signature of a received message against the signing key of its sender. Returns
+// ErrMessageSignatureExpired if the server received the message further than the tolerance from when it was signed.
+func VerifyMessageSignature(message ChatMessage, key SigningKey, tolerance time.Duration) error {
+	signature := message.Signature
+
+	if signature == nil {
+		return ErrMessageNotSigned
+	}
+
+	if signature.KeyId != key.Id || key.UserId != message.SenderUserId {
+		return ErrSigningKeyNotFound
+	}
+
+	if key.RevokedAtUtc != nil && !signature.SignedAtUtc.Before(*key.RevokedAtUtc) {
+		return ErrSigningKeyRevoked
+	}
+
+	if message.ReceivedAt().Sub(signature.SignedAtUtc).Abs() > tolerance {
+		return ErrMessageSignatureExpired
+	}
+
+	replyToMessageId := ""
+
+	if message.ReplyTo != nil {
+		replyToMessageId = message.ReplyTo.MessageId
+	}
+
+	attachmentIds := make([]string, 0, len(message.Attachments))
+
+	for _, attachment := range message.Attachments {
+		attachmentIds = append(attachmentIds, attachment.Id)
+	}
+
+	payload, err := messageSigningPayload(message.ChannelId, message.ContentType, message.Content, message.Embed,
+		replyToMessageId, attachmentIds, signature.SignedAtUtc)
+
+	if err != nil {
+		return err
+	}
+
+	if len(key.PublicKey) != ed25519.PublicKeySize || !ed25519.Verify(key.PublicKey, payload, signature.Signature) {
+		return ErrMessageSignatureInvalid
+	}
+
+	return nil
+}
+
+// messageSigningPayload returns the bytes signed for a message: the domain followed by each field prefixed with its
+// length as a uvarint. An empty content type is signed as MESSAGE_CONTENT_TYPE_TEXT.
+func messageSigningPayload(channelId string, contentType MessageContentType, content string, embed *Embed,
+	replyToMessageId string, attachmentIds []string, signedAt time.Time) ([]byte, error) {
+	if contentType == "" {
+		contentType = MESSAGE_CONTENT_TYPE_TEXT
+	}
+
+	var embedJSON []byte
+
+	if embed != nil {
+		var err error
+
+		if embedJSON, err = json.Marshal(embed); err != nil {
+			return nil, err
+		}
+	}
+
+	payload := []byte(messageSignatureDomain)
+
+	appendField := func(field []byte) {
+		payload = binary.AppendUvarint(payload, uint64(len(field)))
+		payload = append(payload, field...)
+	}
+
+	appendField([]byte(channelId))
+	appendField([]byte(contentType))
+	appendField([]byte(content))
+	appendField(embedJSON)
+	appendField([]byte(replyToMessageId))
+	payload = binary.AppendUvarint(payload, uint64(len(attachmentIds)))
+
+	for _, id := range attachmentIds {
+		appendField([]byte(id))
+	}
+
+	appendField([]byte(signedAt.UTC().Format(time.RFC3339Nano)))
+
+	return payload, nil
+}
+
+// MessageVerifier verifies the signatures of received messages, fetching the signing keys of senders from the server
+// and caching them. A signature made with a key which is not cached, such as a newly rotated key, refreshes the keys
+// of the sender once. MessageVerifier is safe for concurrent use.
+type MessageVerifier struct {
+	mu        sync.Mutex
+	client    *BroChatClient
+	tolerance time.Duration
+	keys      map[string][]SigningKey
+}
+
+// MessageVerifierOption is a type for the options that can be passed to NewMessageVerifier.
+type MessageVerifierOption func(*MessageVerifier)
+
+// Sets the maximum time between signing a message and the server receiving it. Defaults to DEFAULT_MESSAGE_SIGNATURE_TOLERANCE.
+func MessageVerifierOption_Tolerance(tolerance time.Duration) MessageVerifierOption {
+	return func(v *MessageVerifier) {
+		v.tolerance = tolerance
+	}
+}
+
+// NewMessageVerifier creates a verifier which fetches signing keys with the client.
+func NewMessageVerifier(client *BroChatClient, options ...MessageVerifierOption) *MessageVerifier {
+	v := &MessageVerifier{
+		client:    client,
+		tolerance: DEFAULT_MESSAGE_SIGNATURE_TOLERANCE,
+		keys:      make(map[string][]SigningKey),
+	}
+
+	for _, opt := range options {
+		opt(v)
+	}
+
+	return v
+}
+
+// Verify checks the signature of a message, fetching the signing keys of its sender if they are not cached. Returns the
+// error of the result if the keys could not be fetched, or one of the errors of VerifyMessageSignature.
+func (v *MessageVerifier) Verify(accessToken string, message ChatMessage) error {
+	if message.Signature == nil {
+		return ErrMessageNotSigned
+	}
+
+	key, found := v.cachedKey(message.SenderUserId, message.Signature.KeyId)
+
+	if !found {
+		result := v.client.GetSigningKeys(accessToken, message.SenderUserId)
+
+		if err := result.Err(); err != nil {
+			return err
+		}
+
+		v.mu.Lock()
+		v.keys[message.SenderUserId] = result.Content
+		v.mu.Unlock()
+
+		if key, found = v.cachedKey(message.SenderUserId, message.Signature.KeyId); !found {
+			return ErrSigningKeyNotFound
+		}
+	}
+
+	return VerifyMessageSignature(message, key, v.tolerance)
+}
+
+// Invalidate drops the cached signing keys of a user, so revocations are picked up on the next Verify.
+func (v *MessageVerifier) Invalidate(userId string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.keys, userId)
+}
+
+// cachedKey returns the cached signing key of the user with the ID.
+func (v *MessageVerifier) cachedKey(userId string, keyId string) (SigningKey, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys := v.keys[userId]
+
+	if i := slices.IndexFunc(keys, func(k SigningKey) bool { return k.Id == keyId }); i >= 0 {
+		return keys[i], true
+	}
+
+	return SigningKey{}, false
+}
@@ -0,0 +1,181 @@
+package chat
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A SpamVerdict is the outcome of checking a message for spam.
+type SpamVerdict struct {
+	// True if the message should be rejected.
+	IsSpam bool
+	// A score between 0 and 1 indicating how spam-like the sender's recent behaviour is. Values of 1 or more are considered spam.
+	Score float64
+	// A human readable reason for the verdict. Empty if the message is not spam.
+	Reason string
+}
+
+// A SpamDetector decides whether an inbound chat message should be rejected as spam.
+// Server implementations should invoke the detector before storing a message and respond with
+// BROCHAT_RESPONSE_CODE_SPAM_DETECTED_ERROR when the verdict is spam. Implementations must be safe for concurrent use.
+type SpamDetector interface {
+	Check(userId string, request ChatMessageRequest, receivedAtUtc time.Time) SpamVerdict
+}
+
+// NewSpamDetectedError creates the error response returned to a client whose message was rejected as spam.
+func NewSpamDetectedError(verdict SpamVerdict) *BroChatError {
+	return NewErrorResponse(BROCHAT_RESPONSE_CODE_SPAM_DETECTED_ERROR, verdict.Reason)
+}
+
+// HeuristicSpamDetector is the default SpamDetector. It scores users by how many messages they have sent within a sliding window
+// and how many times they have repeated the same message.
+type HeuristicSpamDetector struct {
+	mu             sync.Mutex
+	users          map[string]*spamHistory
+	rateLimit      int
+	rateWindow     time.Duration
+	duplicateLimit int
+	duplicateWin   time.Duration
+	lastSweep      time.Time
+}
+
+// A record of a users recently sent messages.
+type spamHistory struct {
+	sent []spamEntry
+}
+
+type spamEntry struct {
+	at   time.Time
+	hash uint64
+}
+
+// HeuristicSpamDetectorOption is a type for the options that can be passed to NewHeuristicSpamDetector.
+type HeuristicSpamDetectorOption func(*HeuristicSpamDetector)
+
+// Sets the maximum number of messages a user may send within the given window. Defaults to 10 messages every 10 seconds.
+func HeuristicSpamDetectorOption_RateLimit(maxMessages int, window time.Duration) HeuristicSpamDetectorOption {
+	return func(d *HeuristicSpamDetector) {
+		d.rateLimit = maxMessages
+		d.rateWindow = window
+	}
+}
+
+// Sets the maximum number of times a user may send identical content within the given window. Defaults to 3 times every minute.
+func HeuristicSpamDetectorOption_DuplicateLimit(maxDuplicates int, window time.Duration) HeuristicSpamDetectorOption {
+	return func(d *HeuristicSpamDetector) {
+		d.duplicateLimit = maxDuplicates
+		d.duplicateWin = window
+	}
+}
+
+// NewHeuristicSpamDetector creates a new HeuristicSpamDetector.
+func NewHeuristicSpamDetector(options ...HeuristicSpamDetectorOption) *HeuristicSpamDetector {
+	d := &HeuristicSpamDetector{
+		users:          make(map[string]*spamHistory),
+		rateLimit:      10,
+		rateWindow:     10 * time.Second,
+		duplicateLimit: 3,
+		duplicateWin:   time.Minute,
+	}
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d
+}
+
+// Check implements the SpamDetector interface. Messages that are rejected are not counted against the user.
+func (d *HeuristicSpamDetector) Check(userId string, request ChatMessageRequest, receivedAtUtc time.Time) SpamVerdict {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep(receivedAtUtc)
+
+	history, ok := d.users[userId]
+
+	if !ok {
+		history = &spamHistory{}
+		d.users[userId] = history
+	}
+
+	history.prune(receivedAtUtc.Add(-d.longestWindow()))
+
+	hash := hashContent(request.Content)
+	rateCount, duplicateCount := 1, 1
+
+	for _, entry := range history.sent {
+		if receivedAtUtc.Sub(entry.at) < d.rateWindow {
+			rateCount++
+		}
+
+		if entry.hash == hash && receivedAtUtc.Sub(entry.at) < d.duplicateWin {
+			duplicateCount++
+		}
+	}
+
+	rateScore := float64(rateCount) / float64(d.rateLimit+1)
+	duplicateScore := float64(duplicateCount) / float64(d.duplicateLimit+1)
+
+	switch {
+	case rateCount > d.rateLimit:
+		return SpamVerdict{IsSpam: true, Score: rateScore, Reason: "too many messages sent in a short period of time"}
+	case duplicateCount > d.duplicateLimit:
+		return SpamVerdict{IsSpam: true, Score: duplicateScore, Reason: "the same message has been sent too many times"}
+	}
+
+	history.sent = append(history.sent, spamEntry{at: receivedAtUtc, hash: hash})
+
+	if duplicateScore > rateScore {
+		return SpamVerdict{Score: duplicateScore}
+	}
+
+	return SpamVerdict{Score: rateScore}
+}
+
+// sweep removes the history of users who have not sent a message recently. Runs at most once per window.
+func (d *HeuristicSpamDetector) sweep(now time.Time) {
+	window := d.longestWindow()
+
+	if now.Sub(d.lastSweep) < window {
+		return
+	}
+
+	d.lastSweep = now
+
+	for userId, history := range d.users {
+		history.prune(now.Add(-window))
+
+		if len(history.sent) == 0 {
+			delete(d.users, userId)
+		}
+	}
+}
+
+func (d *HeuristicSpamDetector) longestWindow() time.Duration {
+	if d.duplicateWin > d.rateWindow {
+		return d.duplicateWin
+	}
+
+	return d.rateWindow
+}
+
+// prune removes entries older than the cutoff.
+func (h *spamHistory) prune(cutoff time.Time) {
+	i := 0
+
+	for i < len(h.sent) && h.sent[i].at.Before(cutoff) {
+		i++
+	}
+
+	h.sent = h.sent[i:]
+}
+
+// hashContent hashes normalized message content so trivially different messages are treated as duplicates.
+func hashContent(content string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(strings.Join(strings.Fields(content), " "))))
+	return h.Sum64()
+}
@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HttpStatusCode returns the HTTP status code a server should respond with for the response code.
+// Client side error codes are never sent by a server and map to 500 Internal Server Error.
+func (c BroChatResponseCode) HttpStatusCode() int {
+	switch c {
+	case BROCHAT_RESPONSE_CODE_SUCCESS:
+		return http.StatusOK
+	case BROCHAT_RESPONSE_CODE_NO_CONTENT:
+		return http.StatusNoContent
+	case BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR, BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR:
+		return http.StatusForbidden
+	case BROCHAT_RESPONSE_CODE_VALIDATION_ERROR, BROCHAT_RESPONSE_CODE_REQUEST_PARSE_ERROR:
+		return http.StatusBadRequest
+	case BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR:
+		return http.StatusNotFound
+	case BROCHAT_RESPONSE_CODE_DATA_CONFLICT_ERROR, BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR:
+		return http.StatusConflict
+	case BROCHAT_RESPONSE_CODE_INVALID_OPERATION, BROCHAT_RESPONSE_CODE_SPAM_DETECTED_ERROR:
+		return http.StatusUnprocessableEntity
+	case BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR:
+		return http.StatusUnauthorized
+	case BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ResponseCodeFromHttpStatus returns the response code for an HTTP status code. Used when an error response has no
+// parsable body. Several response codes share a status code, in which case the most general response code is returned.
+func ResponseCodeFromHttpStatus(status int) BroChatResponseCode {
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return BROCHAT_RESPONSE_CODE_SUCCESS
+	case http.StatusNoContent:
+		return BROCHAT_RESPONSE_CODE_NO_CONTENT
+	case http.StatusUnauthorized:
+		return BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR
+	case http.StatusForbidden:
+		return BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR
+	case http.StatusNotFound:
+		return BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR
+	case http.StatusBadRequest:
+		return BROCHAT_RESPONSE_CODE_VALIDATION_ERROR
+	case http.StatusConflict:
+		return BROCHAT_RESPONSE_CODE_DATA_CONFLICT_ERROR
+	case http.StatusUnprocessableEntity:
+		return BROCHAT_RESPONSE_CODE_INVALID_OPERATION
+	case http.StatusTooManyRequests:
+		return BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR
+	default:
+		return BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR
+	}
+}
+
+// RateLimit is the rate limit of a request as reported by the X-RateLimit-* headers of the response.
+type RateLimit struct {
+	// The number of requests allowed in a burst. Zero if the server did not send the headers.
+	Limit int `json:"limit"`
+	// The number of requests left in the current burst.
+	Remaining int `json:"remaining"`
+	// How long until the full burst is available again.
+	Reset time.Duration `json:"reset"`
+}
+
+// ParseRateLimit reads the X-RateLimit-* headers of a response. Headers which are missing or invalid are left zero.
+func ParseRateLimit(header http.Header) RateLimit {
+	var limit RateLimit
+
+	limit.Limit, _ = strconv.Atoi(header.Get(RATE_LIMIT_LIMIT_HEADER))
+	limit.Remaining, _ = strconv.Atoi(header.Get(RATE_LIMIT_REMAINING_HEADER))
+
+	if seconds, err := strconv.Atoi(header.Get(RATE_LIMIT_RESET_HEADER)); err == nil && seconds > 0 {
+		limit.Reset = secondsDuration(seconds)
+	}
+
+	return limit
+}
+
+// ParseRetryAfter reads the Retry-After header of a response, given in seconds or as an HTTP date relative to now.
+// Returns zero if the header is missing, invalid or in the past.
+func ParseRetryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get(RETRY_AFTER_HEADER)
+
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return secondsDuration(max(seconds, 0))
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// secondsDuration converts whole seconds to a duration, saturating instead of overflowing.
+func secondsDuration(seconds int) time.Duration {
+	const maxSeconds = math.MaxInt64 / int64(time.Second)
+
+	return time.Duration(min(int64(seconds), maxSeconds)) * time.Second
+}
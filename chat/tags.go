@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeRoomTag converts a tag to its canonical form: lower case with runs of whitespace and punctuation collapsed into single hyphens.
+// Returns an empty string if the tag contains no letters or numbers.
+// Usage: NormalizeRoomTag("  Retro Gaming!! ") // "retro-gaming"
+func NormalizeRoomTag(tag string) string {
+	var sb strings.Builder
+	pendingHyphen := false
+
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+
+			pendingHyphen = false
+			sb.WriteRune(r)
+			continue
+		}
+
+		pendingHyphen = true
+	}
+
+	return sb.String()
+}
+
+// HasTag returns true if the room has the given tag. The tag is normalized before comparison.
+func (r Room) HasTag(tag string) bool {
+	tag = NormalizeRoomTag(tag)
+
+	for _, t := range r.Tags {
+		if NormalizeRoomTag(t) == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Connection pool defaults used when the clients create their own transport.
+const (
+	// The default number of idle connections kept open to the API host. http.DefaultTransport only keeps two, which forces
+	// a chatty client making concurrent requests to constantly open new connections.
+	DEFAULT_MAX_IDLE_CONNS_PER_HOST = 32
+	// The default number of idle connections kept open across all hosts.
+	DEFAULT_MAX_IDLE_CONNS = 100
+	// The default time an idle connection is kept open.
+	DEFAULT_IDLE_CONN_TIMEOUT = 90 * time.Second
+	// The default number of TLS sessions cached for resumption, which skips a full handshake when reconnecting.
+	DEFAULT_TLS_SESSION_CACHE_SIZE = 64
+)
+
+// TransportConfig holds the connection pool settings of a transport created by NewTransport.
+type TransportConfig struct {
+	// The number of idle connections kept open per host.
+	MaxIdleConnsPerHost int
+	// The number of idle connections kept open across all hosts.
+	MaxIdleConns int
+	// How long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// The number of TLS sessions cached for resumption. Zero disables session resumption.
+	TLSSessionCacheSize int
+}
+
+// DefaultTransportConfig returns the connection pool defaults.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConnsPerHost: DEFAULT_MAX_IDLE_CONNS_PER_HOST,
+		MaxIdleConns:        DEFAULT_MAX_IDLE_CONNS,
+		IdleConnTimeout:     DEFAULT_IDLE_CONN_TIMEOUT,
+		TLSSessionCacheSize: DEFAULT_TLS_SESSION_CACHE_SIZE,
+	}
+}
+
+// NewTransport creates a transport tuned for making many small requests to a single API host. It is a clone of
+// http.DefaultTransport, so proxies from the environment, dial timeouts and HTTP/2 are kept, with the pool settings of the config.
+func NewTransport(config TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.IdleConnTimeout = config.IdleConnTimeout
+
+	if config.TLSSessionCacheSize > 0 {
+		transport.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(config.TLSSessionCacheSize)}
+	}
+
+	return transport
+}
+
+// sharedTransports holds the transports of the clients created without one, by config, so clients with the same
+// settings share connections and TLS sessions instead of each opening their own.
+var sharedTransports = struct {
+	sync.Mutex
+	byConfig map[TransportConfig]*http.Transport
+}{byConfig: make(map[TransportConfig]*http.Transport)}
+
+// sharedTransport returns the transport shared by clients with the config, creating it with NewTransport on first use.
+func sharedTransport(config TransportConfig) *http.Transport {
+	sharedTransports.Lock()
+	defer sharedTransports.Unlock()
+
+	transport, ok := sharedTransports.byConfig[config]
+
+	if !ok {
+		transport = NewTransport(config)
+		sharedTransports.byConfig[config] = transport
+	}
+
+	return transport
+}
+
+// WithTunedTransport returns an http client using a transport tuned with the config if the client would otherwise use
+// http.DefaultTransport, that is if the client is nil or its Transport is nil. The transport is created by NewTransport
+// once per config and shared by every client given the same config. A client without a redirect policy of its
+// own gets one which removes the authorization header from redirects to another scheme or host. The client passed is
+// not modified. A client with its own transport and redirect policy is returned unchanged.
+func WithTunedTransport(httpClient *http.Client, config TransportConfig) *http.Client {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	if httpClient.Transport != nil && httpClient.CheckRedirect != nil {
+		return httpClient
+	}
+
+	client := *httpClient
+
+	if client.Transport == nil {
+		client.Transport = sharedTransport(config)
+	}
+
+	if client.CheckRedirect == nil {
+		client.CheckRedirect = dropCredentialsOnCrossOriginRedirect
+	}
+
+	return &client
+}
@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWithTunedTransport_Shared(t *testing.T) {
+	a := NewBroChatClient(nil, "https://example.com")
+	b := NewBroChatClient(&http.Client{}, "https://example.org")
+
+	if a.httpClient.Transport != b.httpClient.Transport {
+		t.Errorf("clients created without a transport do not share one")
+	}
+
+	tuned := NewBroChatClient(nil, "https://example.com", BroChatClientOption_MaxIdleConnsPerHost(DEFAULT_MAX_IDLE_CONNS_PER_HOST+1))
+
+	if tuned.httpClient.Transport == a.httpClient.Transport {
+		t.Errorf("a client with other transport options shares the default transport")
+	}
+
+	if again := NewBroChatClient(nil, "https://example.com", BroChatClientOption_MaxIdleConnsPerHost(DEFAULT_MAX_IDLE_CONNS_PER_HOST+1)); again.httpClient.Transport != tuned.httpClient.Transport {
+		t.Errorf("clients with the same transport options do not share a transport")
+	}
+
+	own := &http.Transport{}
+
+	if c := NewBroChatClient(&http.Client{Transport: own}, "https://example.com"); c.httpClient.Transport != own {
+		t.Errorf("the transport of the http client was replaced")
+	}
+}
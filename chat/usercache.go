@@ -0,0 +1,209 @@
+package chat
+
+import (
+	"container/list"
+	"sync"
+)
+
+// The default number of users held by a UserInfoCache.
+const DEFAULT_USER_INFO_CACHE_CAPACITY = 1000
+
+// UserInfoCache is a bounded least recently used cache of user info keyed by user ID. It is intended for resolving the
+// senders of messages when rendering channel history, so that scrolling back and forth does not repeatedly look up the
+// same users. Misses are resolved with BroChatClient.GetUsersByIds.
+//
+// Pass feed messages to HandleFeedMessage to keep the cache fresh: a profile updated event empties the cache and presence
+// events evict the user they describe. UserInfoCache is safe for concurrent use.
+type UserInfoCache struct {
+	mu       sync.Mutex
+	client   *BroChatClient
+	capacity int
+	codec    Codec
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// UserInfoCacheOption is a type for the options that can be passed to NewUserInfoCache.
+type UserInfoCacheOption func(*UserInfoCache)
+
+// Sets the maximum number of users held by the cache. Defaults to DEFAULT_USER_INFO_CACHE_CAPACITY.
+func UserInfoCacheOption_Capacity(capacity int) UserInfoCacheOption {
+	return func(c *UserInfoCache) {
+		c.capacity = capacity
+	}
+}
+
+// Sets the codec used to decode the content of feed messages. Defaults to StdCodec.
+func UserInfoCacheOption_Codec(codec Codec) UserInfoCacheOption {
+	return func(c *UserInfoCache) {
+		c.codec = codec
+	}
+}
+
+// NewUserInfoCache creates an empty cache which resolves misses with the client.
+func NewUserInfoCache(client *BroChatClient, options ...UserInfoCacheOption) *UserInfoCache {
+	c := &UserInfoCache{
+		client:   client,
+		capacity: DEFAULT_USER_INFO_CACHE_CAPACITY,
+		codec:    StdCodec{},
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	c.capacity = max(c.capacity, 1)
+
+	return c
+}
+
+// GetUser returns the user info of a user, looking it up if it is not cached.
+// Returns a BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR result if the user does not exist.
+func (c *UserInfoCache) GetUser(accessToken string, userId string) BroChatClientContentResult[UserInfo] {
+	if user, ok := c.Peek(userId); ok {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, user)
+	}
+
+	result := c.client.GetUsersByIds(accessToken, []string{userId})
+
+	if result.Err() != nil {
+		return makeBroChatClientContentResult(result.ResponseCode, UserInfo{}, result.ErrorDetails...)
+	}
+
+	c.Add(result.Content...)
+
+	for _, user := range result.Content {
+		if user.Id == userId {
+			return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, user)
+		}
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR, UserInfo{}, "user not found")
+}
+
+// GetUsers returns the user info of the users keyed by ID, looking up all uncached users in as few round trips as
+// possible. Users which do not exist are omitted. Useful for resolving the senders of a page of messages at once.
+func (c *UserInfoCache) GetUsers(accessToken string, userIds []string) BroChatClientContentResult[map[string]UserInfo] {
+	users := make(map[string]UserInfo, len(userIds))
+	missing := make([]string, 0)
+
+	c.mu.Lock()
+
+	for _, id := range userIds {
+		if element, ok := c.entries[id]; ok {
+			c.order.MoveToFront(element)
+			users[id] = element.Value.(UserInfo)
+		} else if id != "" {
+			missing = append(missing, id)
+		}
+	}
+
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+	}
+
+	result := c.client.GetUsersByIds(accessToken, missing)
+
+	if result.Err() != nil {
+		return makeBroChatClientContentResult(result.ResponseCode, make(map[string]UserInfo), result.ErrorDetails...)
+	}
+
+	c.Add(result.Content...)
+
+	for _, user := range result.Content {
+		users[user.Id] = user
+	}
+
+	return makeBroChatClientContentResult(BROCHAT_RESPONSE_CODE_SUCCESS, users)
+}
+
+// Peek returns the cached user info of a user without looking it up. A hit marks the user as recently used.
+func (c *UserInfoCache) Peek(userId string) (UserInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[userId]
+
+	if !ok {
+		return UserInfo{}, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return element.Value.(UserInfo), true
+}
+
+// Add caches user info which the application already has, such as the users of a channel, replacing any cached
+// entries and evicting the least recently used users if the cache is full.
+func (c *UserInfoCache) Add(users ...UserInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, user := range users {
+		if element, ok := c.entries[user.Id]; ok {
+			element.Value = user
+			c.order.MoveToFront(element)
+			continue
+		}
+
+		c.entries[user.Id] = c.order.PushFront(user)
+
+		if c.order.Len() > c.capacity {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(UserInfo).Id)
+		}
+	}
+}
+
+// Invalidate removes a user from the cache.
+func (c *UserInfoCache) Invalidate(userId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[userId]; ok {
+		c.order.Remove(element)
+		delete(c.entries, userId)
+	}
+}
+
+// Purge removes all users from the cache.
+func (c *UserInfoCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+// Len returns the number of cached users.
+func (c *UserInfoCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// HandleFeedMessage updates the cache from a feed message. A FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED event empties the
+// cache, since it does not say which users changed, and presence events evict the user whose presence changed. Other
+// feed messages are ignored.
+func (c *UserInfoCache) HandleFeedMessage(message *FeedMessage) error {
+	switch message.Type {
+	case FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED:
+		c.Purge()
+	case FEED_MESSAGE_TYPE_USER_ONLINE_EVENT, FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT:
+		var event UserPresenceEvent
+
+		if err := message.DecodeContent(c.codec, &event); err != nil {
+			return err
+		}
+
+		c.Invalidate(event.UserId)
+	}
+
+	return nil
+}
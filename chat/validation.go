@@ -0,0 +1,737 @@
+package chat
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A FieldError describes a single invalid field in a request.
+type FieldError struct {
+	// The JSON name of the invalid field.
+	Field string `json:"field"`
+	// A human readable description of the problem.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is a list of field errors produced by validating a request. A nil or empty list indicates the request is valid.
+type ValidationErrors []FieldError
+
+// Error implements the error interface.
+func (v ValidationErrors) Error() string {
+	return strings.Join(v.Details(), "; ")
+}
+
+// Details returns the field errors as a list of strings. Suitable for use as BroChatError details.
+func (v ValidationErrors) Details() []string {
+	details := make([]string, 0, len(v))
+
+	for _, e := range v {
+		details = append(details, e.Error())
+	}
+
+	return details
+}
+
+// Err returns the validation errors as an error. Will return nil if there are no validation errors.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// add appends a field error to the list.
+func (v *ValidationErrors) add(field string, format string, args ...interface{}) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the chat message request against the shared message limits.
+func (r ChatMessageRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.ChannelId) == "" {
+		errs.add("channel_id", "is required")
+	}
+
+	contentLength := utf8.RuneCountInString(r.Content)
+
+	if strings.TrimSpace(r.Content) == "" && len(r.AttachmentIds) == 0 && r.Embed == nil {
+		errs.add("content", "is required")
+	} else if contentLength > MAX_MESSAGE_LENGTH {
+		errs.add("content", "must not exceed %d characters", MAX_MESSAGE_LENGTH)
+	}
+
+	if len(r.AttachmentIds) > MAX_ATTACHMENTS_PER_MESSAGE {
+		errs.add("attachment_ids", "must not contain more than %d attachments", MAX_ATTACHMENTS_PER_MESSAGE)
+	}
+
+	if r.TtlSeconds > MAX_MESSAGE_TTL_SECONDS {
+		errs.add("ttl_seconds", "must not exceed %d seconds", MAX_MESSAGE_TTL_SECONDS)
+	}
+
+	switch ResolveContentType(r.ContentType) {
+	case MESSAGE_CONTENT_TYPE_TEXT:
+	case MESSAGE_CONTENT_TYPE_EMBED:
+		if r.Embed == nil {
+			errs.add("embed", "is required when the content type is %s", MESSAGE_CONTENT_TYPE_EMBED)
+		} else {
+			errs = append(errs, r.Embed.validate("embed")...)
+		}
+	case MESSAGE_CONTENT_TYPE_VOICE_NOTE:
+		if len(r.AttachmentIds) != 1 {
+			errs.add("attachment_ids", "must contain exactly one attachment when the content type is %s", MESSAGE_CONTENT_TYPE_VOICE_NOTE)
+		}
+	default:
+		errs.add("content_type", "%q is not a recognized content type", r.ContentType)
+	}
+
+	if len(r.Nonce) > MAX_REPLAY_NONCE_LENGTH {
+		errs.add("nonce", "must not exceed %d characters", MAX_REPLAY_NONCE_LENGTH)
+	}
+
+	if r.Nonce != "" && r.SentAtUtc == nil {
+		errs.add("sent_at_utc", "is required when a nonce is set")
+	}
+
+	return errs
+}
+
+// Validate checks the edit message request against the shared message limits.
+func (r EditMessageRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Content) == "" {
+		errs.add("content", "is required")
+	} else if utf8.RuneCountInString(r.Content) > MAX_MESSAGE_LENGTH {
+		errs.add("content", "must not exceed %d characters", MAX_MESSAGE_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks the create room request against the shared room limits.
+func (r CreateRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Name) == "" {
+		errs.add("name", "is required")
+	} else {
+		validateRoomName(&errs, r.Name)
+	}
+
+	if r.MembershipModel == "" {
+		errs.add("membership_model", "is required")
+	} else {
+		validateMembershipModel(&errs, r.MembershipModel)
+	}
+
+	if r.JoinPassword != "" {
+		validateRoomPassword(&errs, r.JoinPassword)
+	}
+
+	if r.MaxMembers != 0 {
+		validateRoomCapacity(&errs, r.MaxMembers)
+	}
+
+	validateRoomTags(&errs, r.Tags)
+
+	return errs
+}
+
+// Validate checks the fields set on the update room request against the shared room limits.
+func (r UpdateRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if r.Name != nil {
+		validateRoomName(&errs, *r.Name)
+	}
+
+	if r.MembershipModel != nil {
+		validateMembershipModel(&errs, *r.MembershipModel)
+	}
+
+	if r.JoinPassword != nil && *r.JoinPassword != "" {
+		validateRoomPassword(&errs, *r.JoinPassword)
+	}
+
+	if r.MaxMembers != nil {
+		validateRoomCapacity(&errs, *r.MaxMembers)
+	}
+
+	if r.Tags != nil {
+		validateRoomTags(&errs, *r.Tags)
+	}
+
+	if r.ChannelMode != nil {
+		switch *r.ChannelMode {
+		case CHANNEL_MODE_DEFAULT, CHANNEL_MODE_ANNOUNCEMENT:
+		default:
+			errs.add("channel_mode", "%q is not a recognized channel mode", *r.ChannelMode)
+		}
+	}
+
+	return errs
+}
+
+func validateRoomName(errs *ValidationErrors, name string) {
+	nameLength := utf8.RuneCountInString(strings.TrimSpace(name))
+
+	if nameLength < MIN_ROOM_NAME_LENGTH || nameLength > MAX_ROOM_NAME_LENGTH {
+		errs.add("name", "must be between %d and %d characters", MIN_ROOM_NAME_LENGTH, MAX_ROOM_NAME_LENGTH)
+	}
+}
+
+func validateRoomPassword(errs *ValidationErrors, password string) {
+	passwordLength := utf8.RuneCountInString(password)
+
+	if passwordLength < MIN_ROOM_PASSWORD_LENGTH || passwordLength > MAX_ROOM_PASSWORD_LENGTH {
+		errs.add("join_password", "must be between %d and %d characters", MIN_ROOM_PASSWORD_LENGTH, MAX_ROOM_PASSWORD_LENGTH)
+	}
+}
+
+func validateRoomCapacity(errs *ValidationErrors, maxMembers uint64) {
+	if maxMembers < MIN_ROOM_MEMBERS || maxMembers > MAX_ROOM_MEMBERS {
+		errs.add("max_members", "must be between %d and %d", MIN_ROOM_MEMBERS, MAX_ROOM_MEMBERS)
+	}
+}
+
+func validateRoomTags(errs *ValidationErrors, tags []string) {
+	if len(tags) > MAX_ROOM_TAGS {
+		errs.add("tags", "must not contain more than %d tags", MAX_ROOM_TAGS)
+	}
+
+	seen := make(map[string]struct{}, len(tags))
+
+	for i, tag := range tags {
+		normalized := NormalizeRoomTag(tag)
+
+		switch {
+		case normalized == "":
+			errs.add(fmt.Sprintf("tags[%d]", i), "must contain at least one letter or number")
+		case utf8.RuneCountInString(normalized) > MAX_ROOM_TAG_LENGTH:
+			errs.add(fmt.Sprintf("tags[%d]", i), "must not exceed %d characters", MAX_ROOM_TAG_LENGTH)
+		}
+
+		if _, ok := seen[normalized]; ok && normalized != "" {
+			errs.add(fmt.Sprintf("tags[%d]", i), "is a duplicate")
+		}
+
+		seen[normalized] = struct{}{}
+	}
+}
+
+func validateMembershipModel(errs *ValidationErrors, model string) {
+	switch RoomMembershipModel(model) {
+	case FRIENDS_MEMBERSHIP_MODEL, PUBLIC_MEMBERSHIP_MODEL:
+	default:
+		errs.add("membership_model", "%q is not a recognized membership model", model)
+	}
+}
+
+// Validate checks the create group direct message request against the shared group direct message limits.
+func (r CreateGroupDirectMessageRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	seen := make(map[string]struct{}, len(r.UserIds))
+
+	for i, id := range r.UserIds {
+		if strings.TrimSpace(id) == "" {
+			errs.add(fmt.Sprintf("user_ids[%d]", i), "is required")
+			continue
+		}
+
+		if _, ok := seen[id]; ok {
+			errs.add(fmt.Sprintf("user_ids[%d]", i), "is a duplicate")
+		}
+
+		seen[id] = struct{}{}
+	}
+
+	switch {
+	case len(seen) < 2:
+		errs.add("user_ids", "must contain at least 2 users")
+	case len(seen)+1 > MAX_GROUP_DIRECT_MESSAGE_PARTICIPANTS:
+		errs.add("user_ids", "must not contain more than %d users", MAX_GROUP_DIRECT_MESSAGE_PARTICIPANTS-1)
+	}
+
+	if utf8.RuneCountInString(r.Name) > MAX_ROOM_NAME_LENGTH {
+		errs.add("name", "must not exceed %d characters", MAX_ROOM_NAME_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks the set status request against the shared status limits.
+func (r SetStatusRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Text) == "" && strings.TrimSpace(r.Emoji) == "" {
+		errs.add("text", "is required")
+	} else if utf8.RuneCountInString(r.Text) > MAX_STATUS_TEXT_LENGTH {
+		errs.add("text", "must not exceed %d characters", MAX_STATUS_TEXT_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks that the set presence request contains a settable presence state.
+func (r SetPresenceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	switch r.Presence {
+	case PRESENCE_STATE_ONLINE, PRESENCE_STATE_AWAY, PRESENCE_STATE_DO_NOT_DISTURB, PRESENCE_STATE_INVISIBLE:
+	case "":
+		errs.add("presence", "is required")
+	default:
+		errs.add("presence", "%q is not a settable presence state", r.Presence)
+	}
+
+	return errs
+}
+
+// Validate checks that the notification preferences are well formed.
+func (p NotificationPreferences) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateNotificationLevel(&errs, "default_level", p.DefaultLevel)
+
+	for i, c := range p.Channels {
+		if strings.TrimSpace(c.ChannelId) == "" {
+			errs.add(fmt.Sprintf("channels[%d].channel_id", i), "is required")
+		}
+
+		validateNotificationLevel(&errs, fmt.Sprintf("channels[%d].level", i), c.Level)
+	}
+
+	for i, m := range p.MutedChannels {
+		if strings.TrimSpace(m.ChannelId) == "" {
+			errs.add(fmt.Sprintf("muted_channels[%d].channel_id", i), "is required")
+		}
+	}
+
+	if q := p.QuietHours; q != nil {
+		if q.StartMinute < 0 || q.StartMinute >= 24*60 {
+			errs.add("quiet_hours.start_minute", "must be between 0 and %d", 24*60-1)
+		}
+
+		if q.EndMinute < 0 || q.EndMinute >= 24*60 {
+			errs.add("quiet_hours.end_minute", "must be between 0 and %d", 24*60-1)
+		}
+
+		if _, err := time.LoadLocation(q.TimeZone); err != nil {
+			errs.add("quiet_hours.time_zone", "%q is not a recognized time zone", q.TimeZone)
+		}
+	}
+
+	return errs
+}
+
+// validateNotificationLevel checks the notification level is recognized. An empty level is allowed and inherits the default.
+func validateNotificationLevel(errs *ValidationErrors, field string, level NotificationLevel) {
+	switch level {
+	case "", NOTIFICATION_LEVEL_ALL, NOTIFICATION_LEVEL_MENTIONS_ONLY, NOTIFICATION_LEVEL_NONE:
+	default:
+		errs.add(field, "%q is not a recognized notification level", level)
+	}
+}
+
+// Validate checks the report request has a recognized reason and the details are within the shared limits.
+func (r ReportRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	switch r.Reason {
+	case REPORT_REASON_SPAM, REPORT_REASON_HARASSMENT, REPORT_REASON_HATE_SPEECH, REPORT_REASON_INAPPROPRIATE_CONTENT,
+		REPORT_REASON_IMPERSONATION, REPORT_REASON_SELF_HARM:
+	case REPORT_REASON_OTHER:
+		if strings.TrimSpace(r.Details) == "" {
+			errs.add("details", "is required when the reason is %s", REPORT_REASON_OTHER)
+		}
+	case "":
+		errs.add("reason", "is required")
+	default:
+		errs.add("reason", "%q is not a recognized report reason", r.Reason)
+	}
+
+	if utf8.RuneCountInString(r.Details) > MAX_REPORT_DETAILS_LENGTH {
+		errs.add("details", "must not exceed %d characters", MAX_REPORT_DETAILS_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks that the privacy settings are recognized.
+func (s PrivacySettings) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	switch s.LastSeenVisibility {
+	case "", LAST_SEEN_VISIBILITY_EVERYONE, LAST_SEEN_VISIBILITY_FRIENDS, LAST_SEEN_VISIBILITY_NOBODY:
+	default:
+		errs.add("last_seen_visibility", "%q is not a recognized visibility", s.LastSeenVisibility)
+	}
+
+	return errs
+}
+
+// Validate checks the join request message is within the shared limits.
+func (r RequestToJoinRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if utf8.RuneCountInString(r.Message) > MAX_JOIN_REQUEST_MESSAGE_LENGTH {
+		errs.add("message", "must not exceed %d characters", MAX_JOIN_REQUEST_MESSAGE_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks the draft content is within the shared message limits. An empty draft is valid.
+func (r SaveMessageDraftRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if utf8.RuneCountInString(r.Content) > MAX_MESSAGE_LENGTH {
+		errs.add("content", "must not exceed %d characters", MAX_MESSAGE_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks a reaction emoji was provided.
+func (r AddReactionRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Emoji) == "" {
+		errs.add("emoji", "is required")
+	} else if len(r.Emoji) > MAX_REACTION_EMOJI_LENGTH {
+		errs.add("emoji", "must not exceed %d bytes", MAX_REACTION_EMOJI_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks the user lookup contains between one and MAX_USERS_PER_LOOKUP user IDs.
+func (r GetUsersByIdsRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if len(r.UserIds) == 0 {
+		errs.add("user_ids", "must contain at least one user ID")
+	} else if len(r.UserIds) > MAX_USERS_PER_LOOKUP {
+		errs.add("user_ids", "must not contain more than %d user IDs", MAX_USERS_PER_LOOKUP)
+	}
+
+	for i, userId := range r.UserIds {
+		if strings.TrimSpace(userId) == "" {
+			errs.add(fmt.Sprintf("user_ids[%d]", i), "must not be blank")
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the room password is within the shared limits when one is provided.
+func (r JoinRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if r.Password != "" {
+		validateRoomPassword(&errs, r.Password)
+	}
+
+	return errs
+}
+
+// Validate checks the kick reason is within the shared limits.
+func (r KickUserFromRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if utf8.RuneCountInString(r.Reason) > MAX_MODERATION_REASON_LENGTH {
+		errs.add("reason", "must not exceed %d characters", MAX_MODERATION_REASON_LENGTH)
+	}
+
+	return errs
+}
+
+// Validate checks the room and user were provided.
+func (r InviteUserToRoomRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRequiredId(&errs, "room_id", r.RoomId)
+	validateRequiredId(&errs, "user_id", r.UserId)
+
+	return errs
+}
+
+// Validate checks the room was provided.
+func (r AcceptRoomInviteRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRequiredId(&errs, "room_id", r.RoomId)
+
+	return errs
+}
+
+// Validate checks the requested user was provided.
+func (r SendFriendRequestRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRequiredId(&errs, "requested_user_id", r.RequestedUserId)
+
+	return errs
+}
+
+// Validate checks the initiating user was provided.
+func (r AcceptFriendRequestRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRequiredId(&errs, "initiating_user_id", r.InitiatingUserId)
+
+	return errs
+}
+
+// Validate checks the channel was provided.
+func (r SetActiveChannelRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRequiredId(&errs, "channel_id", r.ChannelId)
+
+	return errs
+}
+
+// Validate checks the import batch contains between one and MAX_IMPORT_BATCH_SIZE messages within the shared message limits.
+func (r ImportMessagesRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if len(r.Messages) == 0 {
+		errs.add("messages", "must contain at least one message")
+	} else if len(r.Messages) > MAX_IMPORT_BATCH_SIZE {
+		errs.add("messages", "must not contain more than %d messages", MAX_IMPORT_BATCH_SIZE)
+	}
+
+	for i, message := range r.Messages {
+		if utf8.RuneCountInString(message.Content) > MAX_MESSAGE_LENGTH {
+			errs.add(fmt.Sprintf("messages[%d].content", i), "must not exceed %d characters", MAX_MESSAGE_LENGTH)
+		}
+	}
+
+	return errs
+}
+
+// validateRequiredId adds a field error if the ID is blank.
+func validateRequiredId(errs *ValidationErrors, field string, id string) {
+	if strings.TrimSpace(id) == "" {
+		errs.add(field, "is required")
+	}
+}
+
+// validate checks the embed against the shared embed limits. The prefix is prepended to the reported field names.
+func (e Embed) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+
+	titleLength := utf8.RuneCountInString(e.Title)
+
+	if titleLength == 0 {
+		errs.add(prefix+".title", "is required")
+	} else if titleLength > MAX_EMBED_TITLE_LENGTH {
+		errs.add(prefix+".title", "must not exceed %d characters", MAX_EMBED_TITLE_LENGTH)
+	}
+
+	if utf8.RuneCountInString(e.Description) > MAX_EMBED_DESCRIPTION_LENGTH {
+		errs.add(prefix+".description", "must not exceed %d characters", MAX_EMBED_DESCRIPTION_LENGTH)
+	}
+
+	if e.Color > 0xFFFFFF {
+		errs.add(prefix+".color", "must be a 24 bit RGB value")
+	}
+
+	if len(e.Fields) > MAX_EMBED_FIELDS {
+		errs.add(prefix+".fields", "must not contain more than %d fields", MAX_EMBED_FIELDS)
+	}
+
+	for i, f := range e.Fields {
+		if strings.TrimSpace(f.Name) == "" {
+			errs.add(fmt.Sprintf("%s.fields[%d].name", prefix, i), "is required")
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the create webhook request against the shared webhook limits.
+func (r CreateWebhookRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(r.Url) == "" {
+		errs.add("url", "is required")
+	} else if len(r.Url) > MAX_WEBHOOK_URL_LENGTH {
+		errs.add("url", "must not exceed %d characters", MAX_WEBHOOK_URL_LENGTH)
+	} else if u, err := url.Parse(r.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("url", "must be an absolute http or https URL")
+	} else if !isPublicWebhookHost(u.Hostname()) {
+		// Host names are checked again when each delivery connects, as they may resolve to another address later
+		errs.add("url", "must not point at a loopback, private or link-local address")
+	}
+
+	if len(r.EventTypes) == 0 {
+		errs.add("event_types", "must contain at least one event type")
+	}
+
+	for i, t := range r.EventTypes {
+		if !strings.HasPrefix(string(t), "brochat:feed_message_type:") {
+			errs.add(fmt.Sprintf("event_types[%d]", i), "%q is not a recognized event type", t)
+		}
+	}
+
+	return errs
+}
+
+// isPublicWebhookHost returns false if the host of a webhook url is a local name or an address which is not public.
+func isPublicWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return IsPublicWebhookAddress(addr)
+	}
+
+	return true
+}
+
+// Validate checks that the offer identifies the call and carries a session description.
+func (o CallOffer) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, o.CallId, o.ChannelId)
+
+	if strings.TrimSpace(o.Sdp) == "" {
+		errs.add("sdp", "is required")
+	}
+
+	return errs
+}
+
+// Validate checks that the answer identifies the call and carries a session description.
+func (a CallAnswer) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, a.CallId, a.ChannelId)
+
+	if strings.TrimSpace(a.Sdp) == "" {
+		errs.add("sdp", "is required")
+	}
+
+	return errs
+}
+
+// Validate checks that the candidate identifies the call and, unless it signals the end of candidates, the media it
+// belongs to.
+func (c CallIceCandidate) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, c.CallId, c.ChannelId)
+
+	if c.Candidate != "" && c.SdpMid == "" && c.SdpMLineIndex == nil {
+		errs.add("sdp_mid", "or sdp_m_line_index is required")
+	}
+
+	return errs
+}
+
+// Validate checks that the signal identifies the call and gives a known reason.
+func (e CallEnded) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, e.CallId, e.ChannelId)
+
+	if !e.Reason.IsValid() {
+		errs.add("reason", "%q is not a recognized call end reason", e.Reason)
+	}
+
+	return errs
+}
+
+// Validate checks that the signal identifies the call.
+func (c CallMediaStateChanged) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, c.CallId, c.ChannelId)
+
+	return errs
+}
+
+// Validate checks that the signal identifies the call and the stream, and that the description of the stream is valid.
+func (c CallScreenShareStarted) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, c.CallId, c.ChannelId)
+
+	validateRequiredId(&errs, "stream_id", c.StreamId)
+
+	if c.Source != "" && !c.Source.IsValid() {
+		errs.add("source", "%q is not a recognized screen share source", c.Source)
+	}
+
+	if c.Width < 0 {
+		errs.add("width", "must not be negative")
+	}
+
+	if c.Height < 0 {
+		errs.add("height", "must not be negative")
+	}
+
+	if c.FrameRate < 0 {
+		errs.add("frame_rate", "must not be negative")
+	}
+
+	return errs
+}
+
+// Validate checks that the signal identifies the call and the stream.
+func (c CallScreenShareStopped) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateCallSignal(&errs, c.CallId, c.ChannelId)
+
+	validateRequiredId(&errs, "stream_id", c.StreamId)
+
+	return errs
+}
+
+// validateCallSignal checks the fields shared by every call signal.
+func validateCallSignal(errs *ValidationErrors, callId string, channelId string) {
+	validateRequiredId(errs, "call_id", callId)
+	validateRequiredId(errs, "channel_id", channelId)
+}
+
+// Validate checks that the request names the voice channel.
+func (r CreateVoiceChannelRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	validateRoomName(&errs, r.Name)
+
+	return errs
+}
+
+// Validate checks the expiry and use limit of the invite against the shared invite limits.
+func (r CreateRoomInviteRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if r.TtlSeconds > MAX_ROOM_INVITE_TTL_SECONDS {
+		errs.add("ttl_seconds", "must not exceed %d seconds", MAX_ROOM_INVITE_TTL_SECONDS)
+	}
+
+	if r.MaxUses > MAX_ROOM_INVITE_USES {
+		errs.add("max_uses", "must not exceed %d", MAX_ROOM_INVITE_USES)
+	}
+
+	return errs
+}
@@ -0,0 +1,36 @@
+package chat
+
+import (
+	"testing"
+)
+
+func TestCreateWebhookRequest_Validate_Url(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://hooks.example.com/brochat"},
+		{url: "http://203.0.113.10:8080/hook"},
+		{url: "https://[2606:4700::1111]/hook"},
+		{url: "ftp://hooks.example.com", wantErr: true},
+		{url: "/relative", wantErr: true},
+		{url: "http://localhost:8080/hook", wantErr: true},
+		{url: "http://api.localhost./hook", wantErr: true},
+		{url: "http://127.0.0.1/hook", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://10.0.0.5/hook", wantErr: true},
+		{url: "http://192.168.1.1/hook", wantErr: true},
+		{url: "http://100.64.0.1/hook", wantErr: true},
+		{url: "http://0.0.0.0/hook", wantErr: true},
+		{url: "http://[::ffff:127.0.0.1]/hook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		request := CreateWebhookRequest{Url: tt.url, EventTypes: []FeedMessageType{FEED_MESSAGE_TYPE_CHAT_MESSAGE}}
+
+		if errs := request.Validate(); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("Validate(%q) = %v, want error %v", tt.url, errs, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package chat
+
+import "time"
+
+// A room can host voice channels, channels of type CHANNEL_TYPE_VOICE, where members hang out and talk for as long as
+// they like. Members join with JoinVoiceChannel and leave with LeaveVoiceChannel; a user is connected to at most one
+// voice channel at a time, so joining another voice channel leaves the current one. The participants of a voice channel
+// are sent to the members of the room as they join, leave and change their state.
+
+// A VoiceParticipant is a user connected to a voice channel.
+type VoiceParticipant struct {
+	// The user.
+	User UserInfo `json:"user"`
+	// If true the user's microphone is muted.
+	Muted bool `json:"muted"`
+	// If true the user is not hearing the channel. Deafened users are always muted.
+	Deafened bool `json:"deafened"`
+	// If true the user is currently speaking.
+	Speaking bool `json:"speaking"`
+	// When the user joined the voice channel.
+	JoinedAtUtc time.Time `json:"joined_at_utc"`
+}
+
+// A request to create a voice channel in a room.
+type CreateVoiceChannelRequest struct {
+	// The display name of the voice channel.
+	Name string `json:"name"`
+}
+
+// A request to join a voice channel.
+type JoinVoiceChannelRequest struct {
+	// If true the user joins muted.
+	Muted bool `json:"muted"`
+	// If true the user joins deafened.
+	Deafened bool `json:"deafened"`
+}
+
+// A request to change the state of the user in the voice channel they are connected to. Fields left nil are unchanged.
+type UpdateVoiceStateRequest struct {
+	// Mutes or unmutes the user's microphone. Unmuting a deafened user also undeafens them.
+	Muted *bool `json:"muted,omitempty"`
+	// Deafens or undeafens the user. Deafening also mutes the user.
+	Deafened *bool `json:"deafened,omitempty"`
+	// Sets whether the user is speaking. Clients should only send changes, as detected by voice activity detection.
+	Speaking *bool `json:"speaking,omitempty"`
+}
+
+// Represents an event where a user joined, left or changed their state in a voice channel. Sent to the members of the
+// room with the FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_JOINED, FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_LEFT and
+// FEED_MESSAGE_TYPE_VOICE_STATE_UPDATED message types.
+type VoiceParticipantEvent struct {
+	// The ID of the voice channel.
+	ChannelId string `json:"channel_id"`
+	// The ID of the room the voice channel belongs to.
+	RoomId string `json:"room_id"`
+	// The participant, in their state after the event.
+	Participant VoiceParticipant `json:"participant"`
+}
@@ -0,0 +1,317 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ChannelWatcher defaults.
+const (
+	// The default number of recent messages delivered when a ChannelWatcher starts.
+	DEFAULT_CHANNEL_WATCHER_HISTORY = 50
+	// The default time a ChannelWatcher waits before retrying a failed history request.
+	DEFAULT_CHANNEL_WATCHER_RETRY_INTERVAL = 5 * time.Second
+	// The default number of messages a ChannelWatcher buffers for a slow reader.
+	DEFAULT_CHANNEL_WATCHER_BUFFER = 64
+	// The default number of live messages a ChannelWatcher holds back while it cannot deliver them.
+	DEFAULT_CHANNEL_WATCHER_MAX_PENDING = 1000
+)
+
+// The number of most recently delivered message IDs a ChannelWatcher remembers to drop duplicates. Duplicates only
+// arrive where history and live messages overlap, which is never more than a few pages.
+const channelWatcherSeenLimit = 4 * MAX_PAGE_SIZE
+
+// ChannelWatcher turns the history and the live messages of one channel into a single stream of messages, oldest first,
+// with each message delivered once. It starts with the most recent history, then delivers the chat messages passed to
+// HandleFeedMessage as they arrive. Messages sent while the feed was disconnected are only available from the history,
+// so call Resync whenever the feed reconnects: the watcher fetches the messages after the newest one it delivered and
+// delivers them before any live message which arrived since.
+//
+// Subscribe to the feed before calling Run so no message falls between the history and the first live message; the
+// overlap is dropped as duplicates. Failed history requests are retried until they succeed or Run is stopped, holding
+// back live messages meanwhile so the order is kept. If more live messages are held back than the watcher keeps, they
+// are dropped and fetched from the history once it can be reached again. Edits, reactions and expiries are not part of
+// the stream. ChannelWatcher is safe for concurrent use.
+type ChannelWatcher struct {
+	client        *BroChatClient
+	accessToken   string
+	channelId     string
+	history       uint64
+	retryInterval time.Duration
+	maxPending    int
+	codec         Codec
+	onError       func(error)
+	messages      chan ChatMessage
+
+	mu      sync.Mutex
+	pending []ChatMessage
+	resync  bool
+	wake    chan struct{}
+
+	// Only used by Run.
+	seen      map[string]struct{}
+	seenOrder []string
+	newest    string
+}
+
+// ChannelWatcherOption is a type for the options that can be passed to NewChannelWatcher.
+type ChannelWatcherOption func(*ChannelWatcher)
+
+// Sets the number of recent messages delivered when the watcher starts, at most MAX_PAGE_SIZE. Zero starts with live
+// messages only. Defaults to DEFAULT_CHANNEL_WATCHER_HISTORY.
+func ChannelWatcherOption_History(count uint64) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		w.history = min(count, MAX_PAGE_SIZE)
+	}
+}
+
+// Sets how long the watcher waits before retrying a failed history request. Defaults to DEFAULT_CHANNEL_WATCHER_RETRY_INTERVAL.
+func ChannelWatcherOption_RetryInterval(retryInterval time.Duration) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		if retryInterval > 0 {
+			w.retryInterval = retryInterval
+		}
+	}
+}
+
+// Sets the number of messages buffered for a slow reader before the watcher waits for it. Defaults to DEFAULT_CHANNEL_WATCHER_BUFFER.
+func ChannelWatcherOption_Buffer(size int) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		w.messages = make(chan ChatMessage, max(size, 0))
+	}
+}
+
+// Sets the number of live messages held back while history is being fetched or the reader is slow. Beyond it the live
+// messages are dropped and fetched from the history instead. Defaults to DEFAULT_CHANNEL_WATCHER_MAX_PENDING.
+func ChannelWatcherOption_MaxPending(maxPending int) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		if maxPending > 0 {
+			w.maxPending = maxPending
+		}
+	}
+}
+
+// Sets the codec used to decode the content of feed messages. Defaults to StdCodec.
+func ChannelWatcherOption_Codec(codec Codec) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		w.codec = codec
+	}
+}
+
+// Sets a function called with the error of each failed history request, such as to show that the channel is catching up.
+func ChannelWatcherOption_OnError(onError func(error)) ChannelWatcherOption {
+	return func(w *ChannelWatcher) {
+		w.onError = onError
+	}
+}
+
+// NewChannelWatcher creates a watcher of a channel which fetches history with the client. Call Run to start it.
+func NewChannelWatcher(client *BroChatClient, accessToken string, channelId string, options ...ChannelWatcherOption) *ChannelWatcher {
+	w := &ChannelWatcher{
+		client:        client,
+		accessToken:   accessToken,
+		channelId:     channelId,
+		history:       DEFAULT_CHANNEL_WATCHER_HISTORY,
+		retryInterval: DEFAULT_CHANNEL_WATCHER_RETRY_INTERVAL,
+		maxPending:    DEFAULT_CHANNEL_WATCHER_MAX_PENDING,
+		codec:         StdCodec{},
+		messages:      make(chan ChatMessage, DEFAULT_CHANNEL_WATCHER_BUFFER),
+		wake:          make(chan struct{}, 1),
+		seen:          make(map[string]struct{}),
+	}
+
+	for _, opt := range options {
+		opt(w)
+	}
+
+	return w
+}
+
+// Messages returns the stream of messages. The channel is closed when Run returns.
+func (w *ChannelWatcher) Messages() <-chan ChatMessage {
+	return w.messages
+}
+
+// HandleFeedMessage queues the chat messages of the watched channel for delivery. Other feed messages are ignored.
+func (w *ChannelWatcher) HandleFeedMessage(message *FeedMessage) error {
+	if message.Type != FEED_MESSAGE_TYPE_CHAT_MESSAGE {
+		return nil
+	}
+
+	var chatMessage ChatMessage
+
+	if err := message.DecodeContent(w.codec, &chatMessage); err != nil {
+		return err
+	}
+
+	if chatMessage.ChannelId != w.channelId {
+		return nil
+	}
+
+	w.mu.Lock()
+
+	// Too far behind: the history holds the held back messages as well, so fetch them from there instead
+	if len(w.pending) >= w.maxPending {
+		w.pending = nil
+		w.resync = true
+	} else {
+		w.pending = append(w.pending, chatMessage)
+	}
+
+	w.mu.Unlock()
+
+	w.notify()
+
+	return nil
+}
+
+// Resync fills the gap left by a feed disconnection: the messages sent after the newest delivered message are fetched
+// and delivered before the live messages which arrive afterwards.
+func (w *ChannelWatcher) Resync() {
+	w.mu.Lock()
+	w.resync = true
+	w.mu.Unlock()
+
+	w.notify()
+}
+
+// Run delivers the history of the channel, then live messages, until the context is done. Returns the error of the
+// context. Run must only be called once.
+func (w *ChannelWatcher) Run(ctx context.Context) error {
+	defer close(w.messages)
+
+	loadHistory := w.history > 0
+
+	for {
+		w.mu.Lock()
+		resync := w.resync
+		w.resync = false
+		var live []ChatMessage
+
+		// Live messages wait until the history before them has been delivered.
+		if !loadHistory && !resync {
+			live, w.pending = w.pending, nil
+		}
+
+		w.mu.Unlock()
+
+		var err error
+
+		switch {
+		case loadHistory:
+			if err = w.deliverHistory(ctx); err == nil {
+				loadHistory = false
+			}
+		case resync:
+			if err = w.deliverGap(ctx); err != nil {
+				w.Resync()
+			}
+		default:
+			err = w.deliver(ctx, live)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			if w.onError != nil {
+				w.onError(err)
+			}
+
+			timer := time.NewTimer(w.retryInterval)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+
+			continue
+		}
+
+		if loadHistory || resync {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.wake:
+		}
+	}
+}
+
+// deliverHistory delivers the most recent messages of the channel.
+func (w *ChannelWatcher) deliverHistory(ctx context.Context) error {
+	result := w.client.getChannelMessages(ctx, w.accessToken, w.channelId, GetChannelMessages_PageSize(w.history))
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return w.deliver(ctx, MergeMessages(result.Content))
+}
+
+// deliverGap delivers the messages sent after the newest delivered message. Without a delivered message there is no
+// gap to fill, so nothing is fetched.
+func (w *ChannelWatcher) deliverGap(ctx context.Context) error {
+	if w.newest == "" {
+		return nil
+	}
+
+	pages := make([][]ChatMessage, 0, 1)
+
+	for page := uint64(1); ; page++ {
+		result := w.client.getChannelMessages(ctx, w.accessToken, w.channelId, GetChannelMessages_AfterMessage(w.newest),
+			GetChannelMessages_Page(page), GetChannelMessages_PageSize(MAX_PAGE_SIZE))
+
+		if err := result.Err(); err != nil {
+			return err
+		}
+
+		pages = append(pages, result.Content)
+
+		if len(result.Content) < MAX_PAGE_SIZE {
+			break
+		}
+	}
+
+	return w.deliver(ctx, MergeMessages(pages...))
+}
+
+// deliver sends the messages which have not been delivered before, in the order given.
+func (w *ChannelWatcher) deliver(ctx context.Context, messages []ChatMessage) error {
+	for _, m := range messages {
+		if _, ok := w.seen[m.Id]; ok {
+			continue
+		}
+
+		select {
+		case w.messages <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		w.seen[m.Id] = struct{}{}
+		w.seenOrder = append(w.seenOrder, m.Id)
+		w.newest = m.Id
+
+		if len(w.seenOrder) > channelWatcherSeenLimit {
+			delete(w.seen, w.seenOrder[0])
+			w.seenOrder = w.seenOrder[1:]
+		}
+	}
+
+	return nil
+}
+
+// notify wakes Run if it is waiting.
+func (w *ChannelWatcher) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
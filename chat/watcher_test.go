@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// historyServer is a channel history which can be made to fail.
+type historyServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []ChatMessage
+	failing  atomic.Bool
+}
+
+func newHistoryServer(t *testing.T) *historyServer {
+	s := &historyServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		query := r.URL.Query()
+
+		s.mu.Lock()
+		messages := slices.Clone(s.messages)
+		s.mu.Unlock()
+
+		if after := query.Get("after-msg"); after != "" {
+			i := slices.IndexFunc(messages, func(m ChatMessage) bool { return m.Id == after })
+			messages = messages[i+1:]
+		}
+
+		// Pages are newest first
+		slices.Reverse(messages)
+
+		page := max(1, atoiOrZero(query.Get("page")))
+		size := atoiOrZero(query.Get("page-size"))
+		messages = messages[min((page-1)*size, len(messages)):min(page*size, len(messages))]
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(messages)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// send stores a message and returns it as the feed would deliver it.
+func (s *historyServer) send(t *testing.T, content string) *FeedMessage {
+	s.mu.Lock()
+	message := ChatMessage{
+		Id:            fmt.Sprintf("m%03d", len(s.messages)+1),
+		ChannelId:     "c",
+		Content:       content,
+		ReceivedAtUtc: time.Date(2024, 1, 1, 0, 0, len(s.messages)+1, 0, time.UTC),
+	}
+	s.messages = append(s.messages, message)
+	s.mu.Unlock()
+
+	feedMessage, err := NewFeedMessageJSON(FEED_MESSAGE_TYPE_CHAT_MESSAGE, message)
+
+	if err != nil {
+		t.Fatalf("NewFeedMessageJSON() error = %v", err)
+	}
+
+	return feedMessage
+}
+
+func TestChannelWatcher_PendingOverflow(t *testing.T) {
+	const sent = 30
+
+	server := newHistoryServer(t)
+	server.failing.Store(true)
+
+	errs := make(chan error, 100)
+	w := NewChannelWatcher(NewBroChatClient(server.Client(), server.URL), "token", "c",
+		ChannelWatcherOption_History(MAX_PAGE_SIZE),
+		ChannelWatcherOption_MaxPending(5),
+		ChannelWatcherOption_RetryInterval(10*time.Millisecond),
+		ChannelWatcherOption_OnError(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx)
+
+	// Wait for the first failed history request so live messages are being held back
+	<-errs
+
+	for i := 0; i < sent; i++ {
+		if err := w.HandleFeedMessage(server.send(t, fmt.Sprint(i))); err != nil {
+			t.Fatalf("HandleFeedMessage() error = %v", err)
+		}
+	}
+
+	w.mu.Lock()
+	pending, resync := len(w.pending), w.resync
+	w.mu.Unlock()
+
+	if pending > 5 || !resync {
+		t.Errorf("pending = %d, resync = %v, want at most 5 held back and a resync queued", pending, resync)
+	}
+
+	server.failing.Store(false)
+
+	for i := 0; i < sent; i++ {
+		select {
+		case m := <-w.Messages():
+			if m.Content != fmt.Sprint(i) {
+				t.Fatalf("message %d = %q, want every message once, in order", i, m.Content)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+
+	select {
+	case m := <-w.Messages():
+		t.Errorf("unexpected extra message %q", m.Content)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// Headers set on every webhook delivery.
+const (
+	// The header carrying the signature of a webhook delivery. Format: sha256=<hex encoded HMAC-SHA256>
+	WEBHOOK_SIGNATURE_HEADER = "X-BroChat-Signature"
+	// The header carrying the unix time in seconds at which a webhook delivery was signed.
+	WEBHOOK_TIMESTAMP_HEADER = "X-BroChat-Timestamp"
+	// The header carrying the ID of the event being delivered. Retries of the same event share an ID.
+	WEBHOOK_EVENT_ID_HEADER = "X-BroChat-Event-Id"
+	// The header carrying the feed message type of the event being delivered.
+	WEBHOOK_EVENT_TYPE_HEADER = "X-BroChat-Event-Type"
+)
+
+// The default maximum age of a webhook delivery signature accepted by ParseWebhookEvent.
+const DEFAULT_WEBHOOK_SIGNATURE_TOLERANCE = 5 * time.Minute
+
+var (
+	// ErrWebhookSignatureInvalid is returned when a webhook delivery is unsigned or the signature does not match the body.
+	ErrWebhookSignatureInvalid = errors.New("invalid webhook signature")
+	// ErrWebhookSignatureExpired is returned when a webhook delivery was signed outside the accepted tolerance.
+	ErrWebhookSignatureExpired = errors.New("webhook signature timestamp is outside the tolerance")
+)
+
+// A Webhook is a URL registered to receive the events of a room as HTTP POST requests.
+type Webhook struct {
+	// The ID of the webhook.
+	Id string `json:"id"`
+	// The ID of the room the webhook receives events from.
+	RoomId string `json:"room_id"`
+	// The URL events are delivered to.
+	Url string `json:"url"`
+	// The feed message types delivered to the webhook.
+	EventTypes []FeedMessageType `json:"event_types"`
+	// The ID of the user that created the webhook.
+	CreatedByUserId string `json:"created_by_user_id"`
+	// When the webhook was created.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// The secret used to sign deliveries. Only returned when the webhook is created; store it securely.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Delivers returns true if events of the type are delivered to the webhook.
+func (w Webhook) Delivers(eventType FeedMessageType) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type CreateWebhookRequest struct {
+	// The URL events are delivered to. Must be an absolute http or https URL.
+	Url string `json:"url"`
+	// The feed message types to deliver to the webhook.
+	EventTypes []FeedMessageType `json:"event_types"`
+}
+
+// A WebhookEvent is the body of a webhook delivery.
+type WebhookEvent struct {
+	// The ID of the event. Retries of the same event share an ID, so receivers can ignore duplicates.
+	Id string `json:"id"`
+	// The ID of the webhook the event was delivered to.
+	WebhookId string `json:"webhook_id"`
+	// The ID of the room the event happened in.
+	RoomId string `json:"room_id"`
+	// The feed message type of the event.
+	Type FeedMessageType `json:"type"`
+	// The content of the feed message. The same JSON a feed connection would receive for the type.
+	Content json.RawMessage `json:"content"`
+	// When the event happened.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+}
+
+// The shared address space used for carrier grade NAT, which IsPrivate does not cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicWebhookAddress returns true if webhook deliveries may connect to the address. Loopback, private, link-local
+// (including the cloud metadata address 169.254.169.254), shared, multicast and unspecified addresses are not public, so
+// a webhook cannot be used to reach services inside the network of the server.
+func IsPublicWebhookAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() &&
+		addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// SignWebhookPayload returns the signature of a webhook delivery body signed at the timestamp, in the format of the
+// WEBHOOK_SIGNATURE_HEADER. The signed message is the unix timestamp in seconds, a period, then the body.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature and timestamp headers of a webhook delivery against the body.
+// Returns ErrWebhookSignatureExpired if the timestamp is further than the tolerance from now.
+func VerifyWebhookSignature(secret string, timestampHeader string, signatureHeader string, body []byte, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestampHeader, 10, 64)
+
+	if err != nil {
+		return ErrWebhookSignatureInvalid
+	}
+
+	timestamp := time.Unix(seconds, 0)
+
+	if now.Sub(timestamp).Abs() > tolerance {
+		return ErrWebhookSignatureExpired
+	}
+
+	if !hmac.Equal([]byte(signatureHeader), []byte(SignWebhookPayload(secret, timestamp, body))) {
+		return ErrWebhookSignatureInvalid
+	}
+
+	return nil
+}
+
+// ParseWebhookEvent reads a webhook delivery from an incoming request, verifying its signature with the secret
+// returned when the webhook was created. Signatures older than DEFAULT_WEBHOOK_SIGNATURE_TOLERANCE are rejected.
+func ParseWebhookEvent(r *http.Request, secret string) (WebhookEvent, error) {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		return WebhookEvent{}, err
+	}
+
+	err = VerifyWebhookSignature(secret, r.Header.Get(WEBHOOK_TIMESTAMP_HEADER), r.Header.Get(WEBHOOK_SIGNATURE_HEADER),
+		body, time.Now(), DEFAULT_WEBHOOK_SIGNATURE_TOLERANCE)
+
+	if err != nil {
+		return WebhookEvent{}, err
+	}
+
+	var event WebhookEvent
+
+	if err := json.Unmarshal(body, &event); err != nil {
+		return WebhookEvent{}, err
+	}
+
+	return event, nil
+}
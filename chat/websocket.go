@@ -0,0 +1,298 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Websocket opcodes, see RFC 6455 section 5.2.
+const (
+	websocketOpContinuation = 0x0
+	websocketOpText         = 0x1
+	websocketOpBinary       = 0x2
+	websocketOpClose        = 0x8
+	websocketOpPing         = 0x9
+	websocketOpPong         = 0xA
+)
+
+// The GUID appended to the handshake key, see RFC 6455 section 1.3.
+const websocketAcceptGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrWebsocketHandshake is returned when the server does not upgrade a feed request to a websocket.
+	ErrWebsocketHandshake = errors.New("the server did not accept the websocket handshake")
+	// ErrWebsocketMessageTooLarge is returned when a websocket message is larger than the limit of the connection.
+	ErrWebsocketMessageTooLarge = errors.New("websocket message too large")
+	// ErrWebsocketProtocol is returned when the peer violates the websocket protocol.
+	ErrWebsocketProtocol = errors.New("websocket protocol error")
+)
+
+// websocketConn is the client side of a websocket connection. It implements the parts of RFC 6455 the feed needs: text
+// and binary messages, fragmentation, ping, pong and close. Messages may be written concurrently with reading, but
+// only one goroutine may read.
+type websocketConn struct {
+	rwc            io.ReadWriteCloser
+	reader         *bufio.Reader
+	protocol       string
+	maxMessageSize int64
+	// Clients mask the frames they send, servers do not.
+	mask bool
+
+	writeMu sync.Mutex
+}
+
+// dialWebsocket upgrades a GET request to the url, authorized with the access token, to a websocket connection using
+// the http client, so the transport, proxies and token provider of the client apply to the handshake. The subprotocol
+// selected by the server is kept on the connection.
+func dialWebsocket(ctx context.Context, httpClient *http.Client, url string, accessToken string, protocols []string, maxMessageSize int64) (*websocketConn, error) {
+	key := make([]byte, 16)
+
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	encodedKey := base64.StdEncoding.EncodeToString(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+	}
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", encodedKey)
+
+	if len(protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		defer res.Body.Close()
+		return nil, fmt.Errorf("%w: %w", ErrWebsocketHandshake, handleUnsuccessfulStatusCode(StdCodec{}, res).Err())
+	}
+
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+
+	if !ok || !strings.EqualFold(res.Header.Get("Upgrade"), "websocket") || res.Header.Get("Sec-WebSocket-Accept") != websocketAccept(encodedKey) {
+		res.Body.Close()
+		return nil, ErrWebsocketHandshake
+	}
+
+	protocol := res.Header.Get("Sec-WebSocket-Protocol")
+
+	if protocol != "" && !slices.Contains(protocols, protocol) {
+		rwc.Close()
+		return nil, ErrWebsocketHandshake
+	}
+
+	return &websocketConn{rwc: rwc, reader: bufio.NewReader(rwc), protocol: protocol, maxMessageSize: maxMessageSize, mask: true}, nil
+}
+
+// websocketAccept returns the Sec-WebSocket-Accept value for a handshake key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketAcceptGuid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadMessage returns the opcode and payload of the next text or binary message. Pings are answered and pongs are
+// skipped. Returns io.EOF once the peer closes the connection.
+func (c *websocketConn) ReadMessage() (byte, []byte, error) {
+	var (
+		opcode  byte
+		message []byte
+		started bool
+	)
+
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case websocketOpPing:
+			if err := c.writeFrame(websocketOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+
+			continue
+		case websocketOpPong:
+			continue
+		case websocketOpClose:
+			// Echo the status code, completing the closing handshake
+			c.writeFrame(websocketOpClose, payload[:min(len(payload), 2)])
+			return 0, nil, io.EOF
+		case websocketOpText, websocketOpBinary:
+			if started {
+				return 0, nil, ErrWebsocketProtocol
+			}
+
+			opcode, started = frameOpcode, true
+		case websocketOpContinuation:
+			if !started {
+				return 0, nil, ErrWebsocketProtocol
+			}
+		default:
+			return 0, nil, ErrWebsocketProtocol
+		}
+
+		if int64(len(message))+int64(len(payload)) > c.maxMessageSize {
+			return 0, nil, ErrWebsocketMessageTooLarge
+		}
+
+		message = append(message, payload...)
+
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// WriteMessage sends a text or binary message in one frame.
+func (c *websocketConn) WriteMessage(opcode byte, payload []byte) error {
+	return c.writeFrame(opcode, payload)
+}
+
+// Close sends a normal closure and closes the connection without waiting for the peer to answer.
+func (c *websocketConn) Close() error {
+	c.writeFrame(websocketOpClose, binary.BigEndian.AppendUint16(nil, 1000))
+	return c.rwc.Close()
+}
+
+// readFrame reads one frame, unmasking its payload.
+func (c *websocketConn) readFrame() (bool, byte, []byte, error) {
+	var header [2]byte
+
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	// Extensions are never negotiated, so the reserved bits must be clear
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, ErrWebsocketProtocol
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > uint64(c.maxMessageSize) {
+		return false, 0, nil, ErrWebsocketMessageTooLarge
+	}
+
+	var key [4]byte
+
+	if masked {
+		if _, err := io.ReadFull(c.reader, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		maskPayload(payload, key)
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a final frame, masking the payload if the connection is a client.
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|opcode)
+
+	maskBit := byte(0)
+
+	if c.mask {
+		maskBit = 0x80
+	}
+
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	if c.mask {
+		var key [4]byte
+
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+
+		frame = append(frame, key[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskPayload(frame[start:], key)
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.rwc.Write(frame)
+
+	return err
+}
+
+// maskPayload masks or unmasks the payload in place with the key.
+func maskPayload(payload []byte, key [4]byte) {
+	for i := range payload {
+		payload[i] ^= key[i%4]
+	}
+}
@@ -0,0 +1,415 @@
+// Package clientstore persists a client's view of BroChat to disk so it can render instantly on startup: the logged in
+// user with their relationships, other users, rooms, channels and the most recent messages of each channel.
+//
+// Load the snapshot when the client starts, render it, then refresh from the API in the background and save the
+// results. While connected, pass every feed message to HandleFeedMessage to keep the store current. To keep working
+// while offline, make changes through a SyncEngine, which queues them in the store and replays them on reconnect. A
+// NotificationCenter keeps the user's notifications in the store as well.
+//
+// Usage:
+//
+//	store, err := clientstore.Open(filepath.Join(configDir, "state.db"))
+//	snapshot, err := store.Load()
+//	render(snapshot)
+//	result := client.GetUser(token, userId)
+//	err = store.SaveUser(result.Content)
+package clientstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The default number of messages kept for each channel.
+const DEFAULT_MAX_MESSAGES_PER_CHANNEL = 200
+
+// The buckets of the store. Every value is JSON: the self bucket holds the logged in user under selfKey, the messages
+// bucket maps a channel ID to its recent messages oldest first, the outbox bucket maps the big endian sequence of a
+// queued Mutation to the mutation and the other buckets map IDs to models.
+var (
+	metaBucket          = []byte("meta")
+	versionKey          = []byte("version")
+	selfBucket          = []byte("self")
+	selfKey             = []byte("user")
+	usersBucket         = []byte("users")
+	roomsBucket         = []byte("rooms")
+	channelsBucket      = []byte("channels")
+	messagesBucket      = []byte("messages")
+	outboxBucket        = []byte("outbox")
+	notificationsBucket = []byte("notifications")
+)
+
+// migrations are applied in order to bring the database up to date. The index of a migration plus one is the schema
+// version it produces. Never edit a released migration, append a new one instead.
+var migrations = []func(tx *bolt.Tx) error{
+	func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{selfBucket, usersBucket, roomsBucket, channelsBucket, messagesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(outboxBucket)
+		return err
+	},
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(notificationsBucket)
+		return err
+	},
+}
+
+// A Snapshot is everything held by the store.
+type Snapshot struct {
+	// The logged in user, including their relationships and rooms. Nil if SaveUser has not been called.
+	User *chat.User
+	// Other users which have been seen, keyed by ID.
+	Users map[string]chat.UserInfo
+	// Rooms keyed by ID.
+	Rooms map[string]chat.Room
+	// Channels keyed by ID.
+	Channels map[string]chat.Channel
+	// The most recent messages of each channel, keyed by channel ID and sorted oldest first.
+	Messages map[string][]chat.ChatMessage
+}
+
+// Store is a client state store backed by a bbolt database. Store is safe for concurrent use.
+type Store struct {
+	db          *bolt.DB
+	ownsDb      bool
+	maxMessages int
+	codec       chat.Codec
+}
+
+// StoreOption is a type for the options that can be passed to NewStore and Open.
+type StoreOption func(*Store)
+
+// Sets the number of messages kept for each channel. Older messages are dropped. Defaults to DEFAULT_MAX_MESSAGES_PER_CHANNEL.
+func StoreOption_MaxMessagesPerChannel(maxMessages int) StoreOption {
+	return func(s *Store) {
+		s.maxMessages = maxMessages
+	}
+}
+
+// Sets the codec used to decode the content of feed messages. Defaults to chat.StdCodec.
+func StoreOption_Codec(codec chat.Codec) StoreOption {
+	return func(s *Store) {
+		s.codec = codec
+	}
+}
+
+// Open opens or creates the store at the path. Close closes the database. Fails after a second if another process has
+// the store open.
+func Open(path string, options ...StoreOption) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := NewStore(db, options...)
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store.ownsDb = true
+
+	return store, nil
+}
+
+// NewStore creates a store using the database, applying any pending migrations.
+// The caller remains responsible for closing the database.
+func NewStore(db *bolt.DB, options ...StoreOption) (*Store, error) {
+	s := &Store{
+		db:          db,
+		maxMessages: DEFAULT_MAX_MESSAGES_PER_CHANNEL,
+		codec:       chat.StdCodec{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	s.maxMessages = max(s.maxMessages, 1)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+
+		if err != nil {
+			return err
+		}
+
+		version := 0
+
+		if value := meta.Get(versionKey); value != nil {
+			version = int(binary.BigEndian.Uint64(value))
+		}
+
+		if version > len(migrations) {
+			return fmt.Errorf("client store schema version %d is newer than the supported version %d", version, len(migrations))
+		}
+
+		for ; version < len(migrations); version++ {
+			if err := migrations[version](tx); err != nil {
+				return fmt.Errorf("applying client store migration %d: %w", version+1, err)
+			}
+		}
+
+		return meta.Put(versionKey, binary.BigEndian.AppendUint64(nil, uint64(version)))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close closes the database if the store was created with Open. Stores created with NewStore are left open.
+func (s *Store) Close() error {
+	if !s.ownsDb {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+// Load reads everything held by the store.
+func (s *Store) Load() (Snapshot, error) {
+	snapshot := Snapshot{
+		Users:    make(map[string]chat.UserInfo),
+		Rooms:    make(map[string]chat.Room),
+		Channels: make(map[string]chat.Channel),
+		Messages: make(map[string][]chat.ChatMessage),
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(selfBucket).Get(selfKey); data != nil {
+			snapshot.User = new(chat.User)
+
+			if err := json.Unmarshal(data, snapshot.User); err != nil {
+				return err
+			}
+		}
+
+		if err := loadBucket(tx.Bucket(usersBucket), snapshot.Users); err != nil {
+			return err
+		}
+
+		if err := loadBucket(tx.Bucket(roomsBucket), snapshot.Rooms); err != nil {
+			return err
+		}
+
+		if err := loadBucket(tx.Bucket(channelsBucket), snapshot.Channels); err != nil {
+			return err
+		}
+
+		return loadBucket(tx.Bucket(messagesBucket), snapshot.Messages)
+	})
+
+	return snapshot, err
+}
+
+// SaveUser stores the logged in user, replacing the previously stored user.
+func (s *Store) SaveUser(user chat.User) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(selfBucket), selfKey, user)
+	})
+}
+
+// SaveUsers stores other users, replacing stored copies.
+func (s *Store) SaveUsers(users ...chat.UserInfo) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, user := range users {
+			if err := putJSON(tx.Bucket(usersBucket), []byte(user.Id), user); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SaveRooms stores rooms, replacing stored copies.
+func (s *Store) SaveRooms(rooms ...chat.Room) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, room := range rooms {
+			if err := putJSON(tx.Bucket(roomsBucket), []byte(room.Id), room); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteRoom removes a room along with its channel and messages, and removes it from the logged in user's rooms.
+func (s *Store) DeleteRoom(roomId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteRoom(tx, roomId)
+	})
+}
+
+// SaveChannels stores channels, replacing stored copies.
+func (s *Store) SaveChannels(channels ...chat.Channel) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, channel := range channels {
+			if err := putJSON(tx.Bucket(channelsBucket), []byte(channel.Id), channel); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteChannel removes a channel and its messages.
+func (s *Store) DeleteChannel(channelId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(channelsBucket).Delete([]byte(channelId)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(messagesBucket).Delete([]byte(channelId))
+	})
+}
+
+// SaveMessages merges messages, such as a page of history, into the stored messages of a channel. Messages already
+// stored are replaced and only the most recent messages are kept.
+func (s *Store) SaveMessages(channelId string, messages ...chat.ChatMessage) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.updateMessages(tx, channelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			return chat.MergeMessages(stored, messages)
+		})
+	})
+}
+
+// DeleteMessage removes a message from the stored messages of a channel. Does nothing if the message is not stored.
+func (s *Store) DeleteMessage(channelId string, messageId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.updateMessages(tx, channelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			for i, m := range stored {
+				if m.Id == messageId {
+					return append(stored[:i], stored[i+1:]...)
+				}
+			}
+
+			return stored
+		})
+	})
+}
+
+// updateMessages replaces the stored messages of a channel with the result of update, keeping the most recent messages.
+func (s *Store) updateMessages(tx *bolt.Tx, channelId string, update func([]chat.ChatMessage) []chat.ChatMessage) error {
+	bucket := tx.Bucket(messagesBucket)
+	stored := make([]chat.ChatMessage, 0)
+
+	if _, err := getJSON(bucket, []byte(channelId), &stored); err != nil {
+		return err
+	}
+
+	messages := update(stored)
+
+	if len(messages) > s.maxMessages {
+		messages = messages[len(messages)-s.maxMessages:]
+	}
+
+	return putJSON(bucket, []byte(channelId), messages)
+}
+
+// deleteRoom removes a room, its channel and messages, and the room from the logged in user's rooms.
+func deleteRoom(tx *bolt.Tx, roomId string) error {
+	var room chat.Room
+
+	found, err := getJSON(tx.Bucket(roomsBucket), []byte(roomId), &room)
+
+	if err != nil {
+		return err
+	}
+
+	if found {
+		if err := tx.Bucket(channelsBucket).Delete([]byte(room.ChannelId)); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(messagesBucket).Delete([]byte(room.ChannelId)); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(roomsBucket).Delete([]byte(roomId)); err != nil {
+			return err
+		}
+	}
+
+	return updateSelf(tx, func(user *chat.User) {
+		for i, r := range user.Rooms {
+			if r.Id == roomId {
+				user.Rooms = append(user.Rooms[:i], user.Rooms[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+// updateSelf applies the update to the stored logged in user. Does nothing if no user is stored.
+func updateSelf(tx *bolt.Tx, update func(user *chat.User)) error {
+	bucket := tx.Bucket(selfBucket)
+
+	var user chat.User
+
+	found, err := getJSON(bucket, selfKey, &user)
+
+	if err != nil || !found {
+		return err
+	}
+
+	update(&user)
+
+	return putJSON(bucket, selfKey, user)
+}
+
+// loadBucket decodes every value of the bucket into the map.
+func loadBucket[T any](bucket *bolt.Bucket, values map[string]T) error {
+	return bucket.ForEach(func(k, v []byte) error {
+		var value T
+
+		if err := json.Unmarshal(v, &value); err != nil {
+			return fmt.Errorf("decoding %s: %w", k, err)
+		}
+
+		values[string(k)] = value
+
+		return nil
+	})
+}
+
+// getJSON decodes the value of the key into v. Returns false if the key does not exist.
+func getJSON(bucket *bolt.Bucket, key []byte, v any) (bool, error) {
+	data := bucket.Get(key)
+
+	if data == nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+// putJSON encodes v as the value of the key.
+func putJSON(bucket *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, data)
+}
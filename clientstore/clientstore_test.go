@@ -0,0 +1,181 @@
+package clientstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// openTestStore opens a store in a temporary directory which is closed when the test ends.
+func openTestStore(t *testing.T, options ...StoreOption) *Store {
+	t.Helper()
+
+	store, err := Open(filepath.Join(t.TempDir(), "store.db"), options...)
+
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+// fakeChatServer is an in memory stand in for the message endpoints of the chat API.
+type fakeChatServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages map[string][]chat.ChatMessage
+	nextId   int
+	requests []string
+	// Called while a message is being sent, before the response is written.
+	onSend func(request chat.ChatMessageRequest)
+}
+
+func newFakeChatServer(t *testing.T) *fakeChatServer {
+	s := &fakeChatServer{messages: make(map[string][]chat.ChatMessage)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/brochat/channels/{channelId}/messages", s.getMessages)
+	mux.HandleFunc("POST /api/brochat/channels/{channelId}/messages", s.sendMessage)
+	mux.HandleFunc("PUT /api/brochat/channels/{channelId}/messages/{messageId}", s.editMessage)
+	mux.HandleFunc("DELETE /api/brochat/channels/{channelId}/messages/{messageId}", s.deleteMessage)
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// add stores a message as if it had been sent by another client.
+func (s *fakeChatServer) add(channelId string, content string) chat.ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addLocked(channelId, "other", content)
+}
+
+func (s *fakeChatServer) addLocked(channelId string, senderUserId string, content string) chat.ChatMessage {
+	s.nextId++
+
+	message := chat.ChatMessage{
+		Id:            fmt.Sprintf("m%d", s.nextId),
+		ChannelId:     channelId,
+		SenderUserId:  senderUserId,
+		Content:       content,
+		ReceivedAtUtc: time.Date(2024, 1, 1, 0, 0, s.nextId, 0, time.UTC),
+	}
+
+	s.messages[channelId] = append(s.messages[channelId], message)
+
+	return message
+}
+
+// channel returns the messages of the channel held by the server, oldest first.
+func (s *fakeChatServer) channel(channelId string) []chat.ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.messages[channelId])
+}
+
+// log returns the method and path of every request which changed a message.
+func (s *fakeChatServer) log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+func (s *fakeChatServer) getMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	messages := slices.Clone(s.messages[r.PathValue("channelId")])
+	s.mu.Unlock()
+
+	if after := r.URL.Query().Get("after-msg"); after != "" {
+		i := slices.IndexFunc(messages, func(m chat.ChatMessage) bool { return m.Id == after })
+		messages = messages[i+1:]
+	}
+
+	// Pages are newest first
+	slices.Reverse(messages)
+
+	writeJSON(w, http.StatusOK, messages)
+}
+
+func (s *fakeChatServer) sendMessage(w http.ResponseWriter, r *http.Request) {
+	var request chat.ChatMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if s.onSend != nil {
+		s.onSend(request)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	message := s.addLocked(r.PathValue("channelId"), "self", request.Content)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, message)
+}
+
+func (s *fakeChatServer) editMessage(w http.ResponseWriter, r *http.Request) {
+	var request chat.EditMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	messages := s.messages[r.PathValue("channelId")]
+	i := slices.IndexFunc(messages, func(m chat.ChatMessage) bool { return m.Id == r.PathValue("messageId") })
+
+	if i < 0 {
+		writeJSON(w, http.StatusNotFound, chat.BroChatError{Code: chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR})
+		return
+	}
+
+	messages[i].Content = request.Content
+	writeJSON(w, http.StatusOK, messages[i])
+}
+
+func (s *fakeChatServer) deleteMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	channelId := r.PathValue("channelId")
+	messages := s.messages[channelId]
+	i := slices.IndexFunc(messages, func(m chat.ChatMessage) bool { return m.Id == r.PathValue("messageId") })
+
+	if i < 0 {
+		writeJSON(w, http.StatusNotFound, chat.BroChatError{Code: chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR})
+		return
+	}
+
+	s.messages[channelId] = slices.Delete(messages, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,251 @@
+package clientstore
+
+import (
+	"slices"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// HandleFeedMessage applies a feed message to the store:
+//   - chat messages are added to the messages of their channel and expired messages are removed
+//   - presence and status events update the stored user and the logged in user's relationship with them
+//   - friend request, accepted, removed and relationship changed events update the logged in user's relationships, and
+//     archive the direct message channel when a removed friend's channel is archived
+//   - rooms changed events store added and updated rooms, and update the logged in user's rooms
+//   - being kicked from a room or a room being removed by a rooms changed event removes the room, its channel and
+//     messages
+//
+// Relationship updates need the logged in user, so they are skipped until SaveUser has been called. Feed messages which
+// carry too little to update the store, such as FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED, are ignored; refresh the
+// affected models from the API and save them instead.
+func (s *Store) HandleFeedMessage(message *chat.FeedMessage) error {
+	switch message.Type {
+	case chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE:
+		var m chat.ChatMessage
+
+		if err := message.DecodeContent(s.codec, &m); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			return s.updateMessages(tx, m.ChannelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+				return chat.InsertMessage(stored, m)
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_MESSAGE_EXPIRED:
+		var event chat.MessageExpiredEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.DeleteMessage(event.ChannelId, event.MessageId)
+	case chat.FEED_MESSAGE_TYPE_USER_ONLINE_EVENT, chat.FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT:
+		var event chat.UserPresenceEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			var user chat.UserInfo
+
+			found, err := getJSON(tx.Bucket(usersBucket), []byte(event.UserId), &user)
+
+			if err != nil {
+				return err
+			}
+
+			if found {
+				user.Presence, user.LastOnlineUtc = event.Presence, event.LastOnlineUtc
+
+				if err := putJSON(tx.Bucket(usersBucket), []byte(user.Id), user); err != nil {
+					return err
+				}
+			}
+
+			return updateSelf(tx, func(self *chat.User) {
+				if r := findRelationship(self, event.UserId); r != nil {
+					r.Presence, r.LastOnlineUtc = event.Presence, event.LastOnlineUtc
+				}
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_USER_STATUS_CHANGED:
+		var event chat.UserStatusChangedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			return updateSelf(tx, func(self *chat.User) {
+				if r := findRelationship(self, event.UserId); r != nil {
+					r.Status = event.Status
+				}
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED:
+		var event chat.FriendRequestRecievedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			return updateSelf(tx, func(self *chat.User) {
+				// The event is sent to both users, so the relationship depends on which side the logged in user is on.
+				if event.RequestedUser.Id == self.Id {
+					setRelationship(self, event.InitiatingUser, chat.RELATIONSHIP_TYPE_FRIEND_REQUEST_RECIEVED, "")
+				} else if event.InitiatingUser.Id == self.Id {
+					setRelationship(self, event.RequestedUser, chat.RELATIONSHIP_TYPE_FRIENDSHIP_REQUESTED, "")
+				}
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED:
+		var event chat.FriendRequestAcceptedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			return updateSelf(tx, func(self *chat.User) {
+				if event.AcceptingUser.Id == self.Id {
+					setRelationship(self, event.InitiatingUser, chat.RELATIONSHIP_TYPE_FRIEND, event.DirectMessageChannel)
+				} else if event.InitiatingUser.Id == self.Id {
+					setRelationship(self, event.AcceptingUser, chat.RELATIONSHIP_TYPE_FRIEND, event.DirectMessageChannel)
+				}
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_FRIEND_REMOVED:
+		var event chat.FriendRemovedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			err := updateSelf(tx, func(self *chat.User) {
+				for _, id := range []string{event.InitiatingUser.Id, event.RemovedUser.Id} {
+					if id != self.Id {
+						removeRelationship(self, id)
+					}
+				}
+			})
+
+			if err != nil || event.DirectMessageChannelDisposition != chat.DIRECT_MESSAGE_CHANNEL_DISPOSITION_ARCHIVE {
+				return err
+			}
+
+			var channel chat.Channel
+
+			found, err := getJSON(tx.Bucket(channelsBucket), []byte(event.DirectMessageChannel), &channel)
+
+			if err != nil || !found {
+				return err
+			}
+
+			channel.IsArchived = true
+
+			return putJSON(tx.Bucket(channelsBucket), []byte(channel.Id), channel)
+		})
+	case chat.FEED_MESSAGE_TYPE_RELATIONSHIP_CHANGED:
+		var event chat.RelationshipChangedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			return updateSelf(tx, func(self *chat.User) {
+				chat.ApplyRelationshipChange(self, event)
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_ROOMS_CHANGED:
+		var event chat.RoomsChangedEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			for _, room := range slices.Concat(event.Added, event.Updated) {
+				if err := putJSON(tx.Bucket(roomsBucket), []byte(room.Id), room); err != nil {
+					return err
+				}
+			}
+
+			for _, roomId := range event.RemovedRoomIds {
+				if err := deleteRoom(tx, roomId); err != nil {
+					return err
+				}
+			}
+
+			return updateSelf(tx, func(self *chat.User) {
+				chat.ApplyRoomsChange(self, event)
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_USER_KICKED_FROM_ROOM:
+		var event chat.UserKickedFromRoomEvent
+
+		if err := message.DecodeContent(s.codec, &event); err != nil {
+			return err
+		}
+
+		return s.db.Update(func(tx *bolt.Tx) error {
+			var self chat.User
+
+			found, err := getJSON(tx.Bucket(selfBucket), selfKey, &self)
+
+			if err != nil || !found || self.Id != event.KickedUser.Id {
+				return err
+			}
+
+			return deleteRoom(tx, event.RoomId)
+		})
+	}
+
+	return nil
+}
+
+// findRelationship returns the user's relationship with the other user, or nil if there is none.
+func findRelationship(user *chat.User, otherUserId string) *chat.UserRelationship {
+	for i := range user.Relationships {
+		if user.Relationships[i].UserId == otherUserId {
+			return &user.Relationships[i]
+		}
+	}
+
+	return nil
+}
+
+// setRelationship creates or updates the user's relationship with the other user. An empty channel ID keeps the
+// existing direct message channel.
+func setRelationship(user *chat.User, other chat.UserInfo, relationshipType chat.RelationshipType, directMessageChannelId string) {
+	r := findRelationship(user, other.Id)
+
+	if r == nil {
+		user.Relationships = append(user.Relationships, chat.UserRelationship{UserId: other.Id})
+		r = &user.Relationships[len(user.Relationships)-1]
+	}
+
+	r.Type = relationshipType
+	r.Username = other.Username
+	r.LastOnlineUtc = other.LastOnlineUtc
+	r.Presence = other.Presence
+
+	if directMessageChannelId != "" {
+		r.DirectMessageChannelId = directMessageChannelId
+	}
+}
+
+// removeRelationship removes the user's relationship with the other user.
+func removeRelationship(user *chat.User, otherUserId string) {
+	for i, r := range user.Relationships {
+		if r.UserId == otherUserId {
+			user.Relationships = append(user.Relationships[:i], user.Relationships[i+1:]...)
+			return
+		}
+	}
+}
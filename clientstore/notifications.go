@@ -0,0 +1,424 @@
+package clientstore
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/render"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The default number of notifications kept by a NotificationCenter.
+const DEFAULT_MAX_NOTIFICATIONS = 500
+
+type NotificationKind string
+
+const (
+	// New messages in a channel, from ChatNotification feed messages. One notification is kept per channel, counting
+	// the messages received since it was last read.
+	NOTIFICATION_KIND_CHANNEL_ACTIVITY NotificationKind = "channel_activity"
+	// A message which mentions the logged in user.
+	NOTIFICATION_KIND_MENTION NotificationKind = "mention"
+	// A friend request sent to the logged in user.
+	NOTIFICATION_KIND_FRIEND_REQUEST NotificationKind = "friend_request"
+	// An invite of the logged in user to a room.
+	NOTIFICATION_KIND_ROOM_INVITE NotificationKind = "room_invite"
+)
+
+// A Notification is an entry of a NotificationCenter.
+type Notification struct {
+	// The ID of the notification. Derived from what the notification is about, so the same event received twice, such
+	// as when the feed replays after a reconnect, updates one notification.
+	Id string `json:"id"`
+	// What the notification is about.
+	Kind NotificationKind `json:"kind"`
+	// When the notification was last received.
+	ReceivedAtUtc time.Time `json:"received_at_utc"`
+	// If true the notification has been read.
+	Read bool `json:"read"`
+	// The number of events the notification stands for. Greater than one when a channel receives several messages
+	// before its notification is read.
+	Count uint64 `json:"count"`
+	// The ID of the channel. Set for NOTIFICATION_KIND_CHANNEL_ACTIVITY and NOTIFICATION_KIND_MENTION.
+	ChannelId string `json:"channel_id,omitempty"`
+	// The ID of the message. Set for NOTIFICATION_KIND_MENTION.
+	MessageId string `json:"message_id,omitempty"`
+	// The ID of the room. Set for NOTIFICATION_KIND_ROOM_INVITE.
+	RoomId string `json:"room_id,omitempty"`
+	// The name of the room. Set for NOTIFICATION_KIND_ROOM_INVITE.
+	RoomName string `json:"room_name,omitempty"`
+	// The ID of the user that caused the notification: the sender of the mention, the request or the invite.
+	UserId string `json:"user_id,omitempty"`
+	// The username of the user that caused the notification. May be empty for mentions by users not in the store.
+	Username string `json:"username,omitempty"`
+	// A preview of the message. Set for NOTIFICATION_KIND_MENTION.
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// NotificationCenter keeps the notifications of the logged in user in a Store, so they survive restarts and can be
+// shown in one place with their unread state. Pass every feed message to HandleFeedMessage; ChatNotification, chat
+// message, friend request and room invite feed messages create notifications and the rest are ignored.
+//
+// Mentions are found by matching the content of chat messages against the username of the user saved with SaveUser,
+// so they are only recorded after SaveUser has been called. Accepting a friend request marks its notification read.
+// When there are more notifications than the limit, the oldest are dropped. NotificationCenter is safe for concurrent use.
+type NotificationCenter struct {
+	store            *Store
+	maxNotifications int
+	now              func() time.Time
+}
+
+// NotificationCenterOption is a type for the options that can be passed to NewNotificationCenter.
+type NotificationCenterOption func(*NotificationCenter)
+
+// Sets the number of notifications kept. The oldest are dropped first. Defaults to DEFAULT_MAX_NOTIFICATIONS.
+func NotificationCenterOption_MaxNotifications(maxNotifications int) NotificationCenterOption {
+	return func(n *NotificationCenter) {
+		n.maxNotifications = maxNotifications
+	}
+}
+
+// NewNotificationCenter creates a notification center which keeps its notifications in the store.
+func NewNotificationCenter(store *Store, options ...NotificationCenterOption) *NotificationCenter {
+	n := &NotificationCenter{
+		store:            store,
+		maxNotifications: DEFAULT_MAX_NOTIFICATIONS,
+		now:              time.Now,
+	}
+
+	for _, opt := range options {
+		opt(n)
+	}
+
+	n.maxNotifications = max(n.maxNotifications, 1)
+
+	return n
+}
+
+// ListNotificationsOption is a type for the options that can be passed to List.
+type ListNotificationsOption func(*listNotificationsOptions)
+
+type listNotificationsOptions struct {
+	unreadOnly bool
+	kinds      []NotificationKind
+	limit      int
+}
+
+// Only lists notifications which have not been read.
+func ListNotifications_Unread() ListNotificationsOption {
+	return func(o *listNotificationsOptions) {
+		o.unreadOnly = true
+	}
+}
+
+// Only lists notifications of the given kinds.
+func ListNotifications_Kinds(kinds ...NotificationKind) ListNotificationsOption {
+	return func(o *listNotificationsOptions) {
+		o.kinds = append(o.kinds, kinds...)
+	}
+}
+
+// Lists at most limit notifications, the most recent. Zero or less lists all notifications.
+func ListNotifications_Limit(limit int) ListNotificationsOption {
+	return func(o *listNotificationsOptions) {
+		o.limit = limit
+	}
+}
+
+// List returns the notifications matching the options, most recent first.
+func (n *NotificationCenter) List(options ...ListNotificationsOption) ([]Notification, error) {
+	var opts listNotificationsOptions
+
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	var notifications []Notification
+
+	err := n.store.db.View(func(tx *bolt.Tx) error {
+		var err error
+		notifications, err = loadNotifications(tx)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	notifications = slices.DeleteFunc(notifications, func(notification Notification) bool {
+		return (opts.unreadOnly && notification.Read) || (len(opts.kinds) > 0 && !slices.Contains(opts.kinds, notification.Kind))
+	})
+
+	if opts.limit > 0 && len(notifications) > opts.limit {
+		notifications = notifications[:opts.limit]
+	}
+
+	return notifications, nil
+}
+
+// UnreadCount returns the number of notifications which have not been read.
+func (n *NotificationCenter) UnreadCount() (int, error) {
+	unread, err := n.List(ListNotifications_Unread())
+	return len(unread), err
+}
+
+// MarkRead marks the notifications with the given IDs as read. Unknown IDs are ignored.
+func (n *NotificationCenter) MarkRead(ids ...string) error {
+	return n.store.db.Update(func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			if err := markNotificationRead(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MarkAllRead marks every notification as read.
+func (n *NotificationCenter) MarkAllRead() error {
+	return n.store.db.Update(func(tx *bolt.Tx) error {
+		notifications, err := loadNotifications(tx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, notification := range notifications {
+			if notification.Read {
+				continue
+			}
+
+			notification.Read = true
+
+			if err := putJSON(tx.Bucket(notificationsBucket), []byte(notification.Id), notification); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete removes the notifications with the given IDs. Unknown IDs are ignored.
+func (n *NotificationCenter) Delete(ids ...string) error {
+	return n.store.db.Update(func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			if err := tx.Bucket(notificationsBucket).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// HandleFeedMessage records the notification carried by a feed message. Feed messages which do not notify the logged
+// in user are ignored.
+func (n *NotificationCenter) HandleFeedMessage(message *chat.FeedMessage) error {
+	switch message.Type {
+	case chat.FEED_MESSAGE_TYPE_CHAT_NOTIFICATION:
+		var event chat.ChatNotification
+
+		if err := message.DecodeContent(n.store.codec, &event); err != nil {
+			return err
+		}
+
+		return n.store.db.Update(func(tx *bolt.Tx) error {
+			return n.record(tx, Notification{
+				Id:        "channel:" + event.ChannelId,
+				Kind:      NOTIFICATION_KIND_CHANNEL_ACTIVITY,
+				ChannelId: event.ChannelId,
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE:
+		var m chat.ChatMessage
+
+		if err := message.DecodeContent(n.store.codec, &m); err != nil {
+			return err
+		}
+
+		return n.store.db.Update(func(tx *bolt.Tx) error {
+			var self chat.User
+
+			found, err := getJSON(tx.Bucket(selfBucket), selfKey, &self)
+
+			if err != nil || !found || m.SenderUserId == self.Id || !mentions(m.Content, self.Username) {
+				return err
+			}
+
+			var sender chat.UserInfo
+
+			if _, err := getJSON(tx.Bucket(usersBucket), []byte(m.SenderUserId), &sender); err != nil {
+				return err
+			}
+
+			// Mentions are recorded once; a replayed message leaves the notification as it is.
+			if tx.Bucket(notificationsBucket).Get([]byte("mention:"+m.Id)) != nil {
+				return nil
+			}
+
+			return n.record(tx, Notification{
+				Id:        "mention:" + m.Id,
+				Kind:      NOTIFICATION_KIND_MENTION,
+				ChannelId: m.ChannelId,
+				MessageId: m.Id,
+				UserId:    m.SenderUserId,
+				Username:  sender.Username,
+				Snippet:   chat.QuoteSnippet(m.Content, 0),
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED:
+		var event chat.FriendRequestRecievedEvent
+
+		if err := message.DecodeContent(n.store.codec, &event); err != nil {
+			return err
+		}
+
+		return n.store.db.Update(func(tx *bolt.Tx) error {
+			// The event is also sent to the user that sent the request.
+			if isSelf, err := isSelf(tx, event.InitiatingUser.Id); err != nil || isSelf {
+				return err
+			}
+
+			return n.record(tx, Notification{
+				Id:       "friend_request:" + event.InitiatingUser.Id,
+				Kind:     NOTIFICATION_KIND_FRIEND_REQUEST,
+				UserId:   event.InitiatingUser.Id,
+				Username: event.InitiatingUser.Username,
+			})
+		})
+	case chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED:
+		var event chat.FriendRequestAcceptedEvent
+
+		if err := message.DecodeContent(n.store.codec, &event); err != nil {
+			return err
+		}
+
+		return n.store.db.Update(func(tx *bolt.Tx) error {
+			return markNotificationRead(tx, "friend_request:"+event.InitiatingUser.Id)
+		})
+	case chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED:
+		var event chat.RoomInviteReceivedEvent
+
+		if err := message.DecodeContent(n.store.codec, &event); err != nil {
+			return err
+		}
+
+		return n.store.db.Update(func(tx *bolt.Tx) error {
+			return n.record(tx, Notification{
+				Id:       "room_invite:" + event.RoomId,
+				Kind:     NOTIFICATION_KIND_ROOM_INVITE,
+				RoomId:   event.RoomId,
+				RoomName: event.RoomName,
+				UserId:   event.InvitingUser.Id,
+				Username: event.InvitingUser.Username,
+			})
+		})
+	}
+
+	return nil
+}
+
+// record stores the notification as unread, received now. A notification with the same ID which has not been read is
+// counted into the new one. Drops the oldest notifications over the limit.
+func (n *NotificationCenter) record(tx *bolt.Tx, notification Notification) error {
+	bucket := tx.Bucket(notificationsBucket)
+
+	var existing Notification
+
+	found, err := getJSON(bucket, []byte(notification.Id), &existing)
+
+	if err != nil {
+		return err
+	}
+
+	notification.Count = 1
+
+	if found && !existing.Read {
+		notification.Count = existing.Count + 1
+	}
+
+	notification.ReceivedAtUtc = n.now().UTC()
+
+	if err := putJSON(bucket, []byte(notification.Id), notification); err != nil {
+		return err
+	}
+
+	notifications, err := loadNotifications(tx)
+
+	if err != nil || len(notifications) <= n.maxNotifications {
+		return err
+	}
+
+	for _, dropped := range notifications[n.maxNotifications:] {
+		if err := bucket.Delete([]byte(dropped.Id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadNotifications returns every stored notification, most recent first.
+func loadNotifications(tx *bolt.Tx) ([]Notification, error) {
+	notifications := make([]Notification, 0)
+
+	err := tx.Bucket(notificationsBucket).ForEach(func(k, v []byte) error {
+		var notification Notification
+
+		if err := json.Unmarshal(v, &notification); err != nil {
+			return err
+		}
+
+		notifications = append(notifications, notification)
+
+		return nil
+	})
+
+	slices.SortStableFunc(notifications, func(a, b Notification) int {
+		return b.ReceivedAtUtc.Compare(a.ReceivedAtUtc)
+	})
+
+	return notifications, err
+}
+
+// markNotificationRead marks the notification with the ID as read, if there is one.
+func markNotificationRead(tx *bolt.Tx, id string) error {
+	bucket := tx.Bucket(notificationsBucket)
+
+	var notification Notification
+
+	found, err := getJSON(bucket, []byte(id), &notification)
+
+	if err != nil || !found || notification.Read {
+		return err
+	}
+
+	notification.Read = true
+
+	return putJSON(bucket, []byte(id), notification)
+}
+
+// isSelf returns true if the ID is the ID of the logged in user.
+func isSelf(tx *bolt.Tx, userId string) (bool, error) {
+	var self chat.User
+
+	found, err := getJSON(tx.Bucket(selfBucket), selfKey, &self)
+
+	return found && self.Id == userId, err
+}
+
+// mentions returns true if the content mentions the username.
+func mentions(content string, username string) bool {
+	if username == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(render.Mentions(content), func(mentioned string) bool {
+		return strings.EqualFold(mentioned, username)
+	})
+}
@@ -0,0 +1,293 @@
+package clientstore
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	testSelf   = chat.User{Id: "self", Username: "alice"}
+	testSender = chat.UserInfo{Id: "bob", Username: "bob"}
+)
+
+// newTestNotificationCenter creates a notification center for testSelf whose clock moves forward a second on every read.
+func newTestNotificationCenter(t *testing.T, options ...NotificationCenterOption) *NotificationCenter {
+	t.Helper()
+
+	store := openTestStore(t)
+
+	if err := store.SaveUser(testSelf); err != nil {
+		t.Fatalf("SaveUser() error = %v", err)
+	}
+
+	if err := store.SaveUsers(testSender); err != nil {
+		t.Fatalf("SaveUsers() error = %v", err)
+	}
+
+	n := NewNotificationCenter(store, options...)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	n.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	return n
+}
+
+func feedMessage(t *testing.T, messageType chat.FeedMessageType, content any) *chat.FeedMessage {
+	t.Helper()
+
+	message, err := chat.NewFeedMessageJSON(messageType, content)
+
+	if err != nil {
+		t.Fatalf("NewFeedMessageJSON() error = %v", err)
+	}
+
+	return message
+}
+
+func chatMessageFeed(t *testing.T, id string, senderUserId string, content string) *chat.FeedMessage {
+	return feedMessage(t, chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE, chat.ChatMessage{Id: id, ChannelId: "c", SenderUserId: senderUserId, Content: content})
+}
+
+// notificationSummary describes a notification by the fields the tests check.
+type notificationSummary struct {
+	Id    string
+	Count uint64
+	Read  bool
+}
+
+func summarize(notifications []Notification) []notificationSummary {
+	summaries := make([]notificationSummary, len(notifications))
+
+	for i, notification := range notifications {
+		summaries[i] = notificationSummary{notification.Id, notification.Count, notification.Read}
+	}
+
+	return summaries
+}
+
+func TestNotificationCenter_HandleFeedMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage
+		want     []notificationSummary
+	}{
+		{
+			name: "channel activity is counted into one notification",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				activity := feedMessage(t, chat.FEED_MESSAGE_TYPE_CHAT_NOTIFICATION, chat.ChatNotification{ChannelId: "c"})
+				return []*chat.FeedMessage{activity, activity}
+			},
+			want: []notificationSummary{{"channel:c", 2, false}},
+		},
+		{
+			name: "channel activity after it was read starts a new count",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				activity := feedMessage(t, chat.FEED_MESSAGE_TYPE_CHAT_NOTIFICATION, chat.ChatNotification{ChannelId: "c"})
+
+				if err := n.HandleFeedMessage(activity); err != nil {
+					t.Fatalf("HandleFeedMessage() error = %v", err)
+				}
+
+				if err := n.MarkRead("channel:c"); err != nil {
+					t.Fatalf("MarkRead() error = %v", err)
+				}
+
+				return []*chat.FeedMessage{activity}
+			},
+			want: []notificationSummary{{"channel:c", 1, false}},
+		},
+		{
+			name: "mention of the user",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{chatMessageFeed(t, "m1", testSender.Id, "hi @Alice")}
+			},
+			want: []notificationSummary{{"mention:m1", 1, false}},
+		},
+		{
+			name: "replayed mention is recorded once",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				mention := chatMessageFeed(t, "m1", testSender.Id, "hi @alice")
+				return []*chat.FeedMessage{mention, mention}
+			},
+			want: []notificationSummary{{"mention:m1", 1, false}},
+		},
+		{
+			name: "mention by the user themselves",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{chatMessageFeed(t, "m1", testSelf.Id, "note to @alice")}
+			},
+			want: []notificationSummary{},
+		},
+		{
+			name: "message mentioning someone else",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{chatMessageFeed(t, "m1", testSender.Id, "hi @alicia, mail alice@example.com")}
+			},
+			want: []notificationSummary{},
+		},
+		{
+			name: "friend request to the user",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{feedMessage(t, chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED,
+					chat.FriendRequestRecievedEvent{InitiatingUser: testSender, RequestedUser: chat.UserInfo{Id: testSelf.Id}})}
+			},
+			want: []notificationSummary{{"friend_request:bob", 1, false}},
+		},
+		{
+			name: "friend request sent by the user",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{feedMessage(t, chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED,
+					chat.FriendRequestRecievedEvent{InitiatingUser: chat.UserInfo{Id: testSelf.Id}, RequestedUser: testSender})}
+			},
+			want: []notificationSummary{},
+		},
+		{
+			name: "accepted friend request is marked read",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{
+					feedMessage(t, chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED,
+						chat.FriendRequestRecievedEvent{InitiatingUser: testSender, RequestedUser: chat.UserInfo{Id: testSelf.Id}}),
+					feedMessage(t, chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED,
+						chat.FriendRequestAcceptedEvent{InitiatingUser: testSender, AcceptingUser: chat.UserInfo{Id: testSelf.Id}}),
+				}
+			},
+			want: []notificationSummary{{"friend_request:bob", 1, true}},
+		},
+		{
+			name: "room invite",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{feedMessage(t, chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED,
+					chat.RoomInviteReceivedEvent{RoomId: "r", RoomName: "room", InvitingUser: testSender})}
+			},
+			want: []notificationSummary{{"room_invite:r", 1, false}},
+		},
+		{
+			name: "other feed messages are ignored",
+			messages: func(t *testing.T, n *NotificationCenter) []*chat.FeedMessage {
+				return []*chat.FeedMessage{feedMessage(t, chat.FEED_MESSAGE_TYPE_USER_ONLINE_EVENT, chat.UserPresenceEvent{UserId: testSender.Id})}
+			},
+			want: []notificationSummary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newTestNotificationCenter(t)
+
+			for _, message := range tt.messages(t, n) {
+				if err := n.HandleFeedMessage(message); err != nil {
+					t.Fatalf("HandleFeedMessage() error = %v", err)
+				}
+			}
+
+			notifications, err := n.List()
+
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			if got := summarize(notifications); !slices.Equal(got, tt.want) {
+				t.Errorf("notifications = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationCenter_DropsOldest(t *testing.T) {
+	n := newTestNotificationCenter(t, NotificationCenterOption_MaxNotifications(2))
+
+	for _, roomId := range []string{"r1", "r2", "r3"} {
+		if err := n.HandleFeedMessage(feedMessage(t, chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED, chat.RoomInviteReceivedEvent{RoomId: roomId})); err != nil {
+			t.Fatalf("HandleFeedMessage(%s) error = %v", roomId, err)
+		}
+	}
+
+	// A notification received again becomes the most recent, so the oldest other one is dropped
+	if err := n.HandleFeedMessage(feedMessage(t, chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED, chat.RoomInviteReceivedEvent{RoomId: "r2"})); err != nil {
+		t.Fatalf("HandleFeedMessage(r2) error = %v", err)
+	}
+
+	if err := n.HandleFeedMessage(feedMessage(t, chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED, chat.RoomInviteReceivedEvent{RoomId: "r4"})); err != nil {
+		t.Fatalf("HandleFeedMessage(r4) error = %v", err)
+	}
+
+	notifications, err := n.List()
+
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []notificationSummary{{"room_invite:r4", 1, false}, {"room_invite:r2", 2, false}}
+
+	if got := summarize(notifications); !slices.Equal(got, want) {
+		t.Errorf("notifications = %+v, want %+v", got, want)
+	}
+}
+
+func TestNotificationCenter_List(t *testing.T) {
+	n := newTestNotificationCenter(t)
+
+	for _, message := range []*chat.FeedMessage{
+		feedMessage(t, chat.FEED_MESSAGE_TYPE_CHAT_NOTIFICATION, chat.ChatNotification{ChannelId: "c"}),
+		chatMessageFeed(t, "m1", testSender.Id, "@alice look"),
+		feedMessage(t, chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED, chat.RoomInviteReceivedEvent{RoomId: "r"}),
+	} {
+		if err := n.HandleFeedMessage(message); err != nil {
+			t.Fatalf("HandleFeedMessage() error = %v", err)
+		}
+	}
+
+	if err := n.MarkRead("mention:m1", "unknown"); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options []ListNotificationsOption
+		want    []string
+	}{
+		{"all, most recent first", nil, []string{"room_invite:r", "mention:m1", "channel:c"}},
+		{"unread", []ListNotificationsOption{ListNotifications_Unread()}, []string{"room_invite:r", "channel:c"}},
+		{"by kind", []ListNotificationsOption{ListNotifications_Kinds(NOTIFICATION_KIND_MENTION, NOTIFICATION_KIND_CHANNEL_ACTIVITY)}, []string{"mention:m1", "channel:c"}},
+		{"limited", []ListNotificationsOption{ListNotifications_Limit(1)}, []string{"room_invite:r"}},
+		{"unread and limited", []ListNotificationsOption{ListNotifications_Unread(), ListNotifications_Limit(5)}, []string{"room_invite:r", "channel:c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications, err := n.List(tt.options...)
+
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			ids := make([]string, len(notifications))
+
+			for i, notification := range notifications {
+				ids[i] = notification.Id
+			}
+
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("List() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if unread, err := n.UnreadCount(); err != nil || unread != 2 {
+		t.Errorf("UnreadCount() = %d, %v, want 2", unread, err)
+	}
+
+	if err := n.MarkAllRead(); err != nil {
+		t.Fatalf("MarkAllRead() error = %v", err)
+	}
+
+	if unread, err := n.UnreadCount(); err != nil || unread != 0 {
+		t.Errorf("UnreadCount() after MarkAllRead = %d, %v, want 0", unread, err)
+	}
+}
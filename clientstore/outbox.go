@@ -0,0 +1,153 @@
+package clientstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Messages sent while offline are stored under a local ID made of this prefix and the sequence of the queued
+// mutation until the server assigns them an ID.
+const LOCAL_MESSAGE_ID_PREFIX = "local:"
+
+// IsLocalMessageId returns true if the ID belongs to a message which has been queued but not yet sent.
+func IsLocalMessageId(id string) bool {
+	return strings.HasPrefix(id, LOCAL_MESSAGE_ID_PREFIX)
+}
+
+type MutationKind string
+
+const (
+	// Sends a chat message.
+	MUTATION_KIND_SEND_MESSAGE MutationKind = "send_message"
+	// Replaces the content of a message.
+	MUTATION_KIND_EDIT_MESSAGE MutationKind = "edit_message"
+	// Deletes a message.
+	MUTATION_KIND_DELETE_MESSAGE MutationKind = "delete_message"
+	// Adds the logged in user's reaction to a message.
+	MUTATION_KIND_ADD_REACTION MutationKind = "add_reaction"
+	// Removes the logged in user's reaction from a message.
+	MUTATION_KIND_REMOVE_REACTION MutationKind = "remove_reaction"
+)
+
+// A Mutation is a change made locally which has not yet been sent to the server.
+type Mutation struct {
+	// The position of the mutation in the queue. Mutations are replayed in sequence order.
+	Sequence uint64 `json:"sequence"`
+	// The kind of change.
+	Kind MutationKind `json:"kind"`
+	// The ID of the channel of the message.
+	ChannelId string `json:"channel_id"`
+	// The ID of the message. For MUTATION_KIND_SEND_MESSAGE, the local ID of the stored copy of the message.
+	MessageId string `json:"message_id"`
+	// The message to send. Only set for MUTATION_KIND_SEND_MESSAGE.
+	Message *chat.ChatMessageRequest `json:"message,omitempty"`
+	// The new content of the message. Only set for MUTATION_KIND_EDIT_MESSAGE.
+	Content string `json:"content,omitempty"`
+	// The reaction emoji. Only set for MUTATION_KIND_ADD_REACTION and MUTATION_KIND_REMOVE_REACTION.
+	Emoji string `json:"emoji,omitempty"`
+	// When the change was made.
+	QueuedAtUtc time.Time `json:"queued_at_utc"`
+}
+
+// PendingMutations returns the queued mutations in the order they will be replayed.
+func (s *Store) PendingMutations() ([]Mutation, error) {
+	mutations := make([]Mutation, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(outboxBucket).ForEach(func(k, v []byte) error {
+			var m Mutation
+
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+
+			mutations = append(mutations, m)
+
+			return nil
+		})
+	})
+
+	return mutations, err
+}
+
+// pendingCount returns the number of queued mutations.
+func (s *Store) pendingCount() (int, error) {
+	count := 0
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = countMutations(tx)
+		return nil
+	})
+
+	return count, err
+}
+
+// countMutations returns the number of queued mutations, including changes made earlier in the transaction.
+func countMutations(tx *bolt.Tx) int {
+	count := 0
+	cursor := tx.Bucket(outboxBucket).Cursor()
+
+	for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+		count++
+	}
+
+	return count
+}
+
+// queueMutation assigns the mutation the next sequence, and the local message ID if it sends a message, and queues it.
+func queueMutation(tx *bolt.Tx, m *Mutation) error {
+	sequence, err := tx.Bucket(outboxBucket).NextSequence()
+
+	if err != nil {
+		return err
+	}
+
+	m.Sequence = sequence
+
+	if m.Kind == MUTATION_KIND_SEND_MESSAGE {
+		m.MessageId = LOCAL_MESSAGE_ID_PREFIX + strconv.FormatUint(sequence, 10)
+	}
+
+	return putMutation(tx, *m)
+}
+
+// putMutation stores the mutation under its sequence, replacing the stored copy.
+func putMutation(tx *bolt.Tx, m Mutation) error {
+	return putJSON(tx.Bucket(outboxBucket), mutationKey(m.Sequence), m)
+}
+
+// deleteMutation removes the mutation from the queue.
+func deleteMutation(tx *bolt.Tx, sequence uint64) error {
+	return tx.Bucket(outboxBucket).Delete(mutationKey(sequence))
+}
+
+// findQueuedSend returns the queued mutation sending the message with the local ID. Returns false if the message is
+// not queued.
+func findQueuedSend(tx *bolt.Tx, localMessageId string) (Mutation, bool, error) {
+	sequence, err := strconv.ParseUint(strings.TrimPrefix(localMessageId, LOCAL_MESSAGE_ID_PREFIX), 10, 64)
+
+	if err != nil {
+		return Mutation{}, false, nil
+	}
+
+	var m Mutation
+
+	found, err := getJSON(tx.Bucket(outboxBucket), mutationKey(sequence), &m)
+
+	if err != nil || !found || m.Kind != MUTATION_KIND_SEND_MESSAGE {
+		return Mutation{}, false, err
+	}
+
+	return m, true, nil
+}
+
+// mutationKey returns the key of a mutation. Keys are big endian so the bucket is iterated in sequence order.
+func mutationKey(sequence uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, sequence)
+}
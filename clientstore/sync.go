@@ -0,0 +1,763 @@
+package clientstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The default time a SyncEngine waits before retrying a failed sync.
+const DEFAULT_SYNC_RETRY_INTERVAL = 30 * time.Second
+
+var (
+	// Returned when editing or deleting a local message which is no longer queued, usually because it has been sent.
+	ErrLocalMessageNotFound = errors.New("local message not found")
+	// Returned when reacting to a message which has not been sent yet.
+	ErrMessageNotSent = errors.New("message has not been sent yet")
+)
+
+type SyncState uint8
+
+const (
+	// The engine is offline. Changes are queued until SetOnline(true) is called.
+	SYNC_STATE_OFFLINE SyncState = iota
+	// The engine is replaying queued changes and fetching messages missed while offline.
+	SYNC_STATE_SYNCING
+	// Every queued change has been replayed and the stored messages are up to date.
+	SYNC_STATE_SYNCED
+	// The last sync failed and will be retried. SyncStatus.Err holds the reason.
+	SYNC_STATE_FAILED
+)
+
+// A SyncStatus is a snapshot of the state of a SyncEngine, for display by UIs.
+type SyncStatus struct {
+	// The state of the engine.
+	State SyncState
+	// The number of queued changes which have not been sent.
+	Pending int
+	// When the last sync completed. Zero if no sync has completed.
+	LastSyncedAtUtc time.Time
+	// Why the last sync failed. Only set in SYNC_STATE_FAILED.
+	Err error
+}
+
+// A Conflict is a queued change which the server rejected, for example an edit of a message which was deleted while
+// offline. The server wins: the change is dropped and the stored messages of the channel are refreshed from the server.
+type Conflict struct {
+	// The rejected change.
+	Mutation Mutation
+	// The result of replaying the change.
+	Result chat.BroChatClientResult
+}
+
+// SyncEngine makes a client work offline first. Changes to messages are applied to the store immediately, so the UI
+// can render them, and queued in the store until they are sent. Queued changes survive restarts.
+//
+// While online the engine replays the queue in order and then fetches the messages posted to each stored channel
+// since its newest stored message. Messages sent while offline are stored under a local ID, see IsLocalMessageId,
+// which is replaced by the server's copy once sent. Changes the server rejects are dropped and reported to the
+// conflict handler. Changes which fail because the server could not be reached are kept and retried. A message
+// deleted or edited locally while it is being sent is deleted or edited on the server once the send completes.
+//
+// The API has no way to deduplicate requests, so a change whose request timed out after reaching the server may be
+// applied twice when retried.
+//
+// Usage:
+//
+//	engine, err := clientstore.NewSyncEngine(store, client, accessToken, clientstore.SyncEngineOption_OnConflict(showConflict))
+//	go engine.Run(ctx)
+//	status, cancel := engine.Subscribe()
+//	// Call SetOnline(true) when the feed connects and SetOnline(false) when it drops.
+//	engine.SetOnline(true)
+//	message, err := engine.SendMessage(request)
+//
+// SyncEngine is safe for concurrent use.
+type SyncEngine struct {
+	store         *Store
+	client        *chat.BroChatClient
+	accessToken   string
+	onConflict    func(Conflict)
+	retryInterval time.Duration
+	now           func() time.Time
+	// syncMu is held for the duration of a sync so only one sync runs at a time.
+	syncMu      sync.Mutex
+	mu          sync.Mutex
+	online      bool
+	status      SyncStatus
+	subscribers map[chan SyncStatus]struct{}
+	trigger     chan struct{}
+}
+
+// SyncEngineOption is a type for the options that can be passed to NewSyncEngine.
+type SyncEngineOption func(*SyncEngine)
+
+// Sets the function called with each change the server rejects. Called from the goroutine running the sync.
+func SyncEngineOption_OnConflict(onConflict func(Conflict)) SyncEngineOption {
+	return func(e *SyncEngine) {
+		e.onConflict = onConflict
+	}
+}
+
+// Sets how long Run waits before retrying a failed sync. Defaults to DEFAULT_SYNC_RETRY_INTERVAL.
+func SyncEngineOption_RetryInterval(retryInterval time.Duration) SyncEngineOption {
+	return func(e *SyncEngine) {
+		e.retryInterval = retryInterval
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func SyncEngineOption_Clock(now func() time.Time) SyncEngineOption {
+	return func(e *SyncEngine) {
+		e.now = now
+	}
+}
+
+// NewSyncEngine creates an offline sync engine which queues changes in the store and replays them with the client.
+// The engine starts offline. To keep syncing across token refreshes, create the client with an http client from
+// chat.NewTokenProviderHttpClient and pass an empty access token.
+func NewSyncEngine(store *Store, client *chat.BroChatClient, accessToken string, options ...SyncEngineOption) (*SyncEngine, error) {
+	e := &SyncEngine{
+		store:         store,
+		client:        client,
+		accessToken:   accessToken,
+		onConflict:    func(Conflict) {},
+		retryInterval: DEFAULT_SYNC_RETRY_INTERVAL,
+		now:           time.Now,
+		subscribers:   make(map[chan SyncStatus]struct{}),
+		trigger:       make(chan struct{}, 1),
+	}
+
+	for _, opt := range options {
+		opt(e)
+	}
+
+	pending, err := store.pendingCount()
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.status = SyncStatus{State: SYNC_STATE_OFFLINE, Pending: pending}
+
+	return e, nil
+}
+
+// Run syncs whenever the engine comes online or a change is queued while online, retrying failed syncs after the
+// retry interval. Blocks until the context is cancelled and returns the context's error.
+func (e *SyncEngine) Run(ctx context.Context) error {
+	var retry <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.trigger:
+		case <-retry:
+		}
+
+		retry = nil
+
+		e.mu.Lock()
+		online := e.online
+		e.mu.Unlock()
+
+		if !online {
+			continue
+		}
+
+		if err := e.Sync(ctx); err != nil && ctx.Err() == nil {
+			retry = time.After(e.retryInterval)
+		}
+	}
+}
+
+// SetOnline tells the engine whether the server can be reached. Coming online starts a sync if Run is running.
+func (e *SyncEngine) SetOnline(online bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.online = online
+
+	if !online {
+		e.status.State, e.status.Err = SYNC_STATE_OFFLINE, nil
+		e.publish()
+		return
+	}
+
+	e.requestSync()
+}
+
+// Status returns the current status of the engine.
+func (e *SyncEngine) Status() SyncStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.status
+}
+
+// Subscribe returns a channel which receives the status of the engine whenever it changes, starting with the current
+// status. Only the latest status is buffered, so a slow reader skips intermediate states. Call cancel to stop
+// receiving, after which the channel is closed.
+func (e *SyncEngine) Subscribe() (<-chan SyncStatus, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan SyncStatus, 1)
+	ch <- e.status
+	e.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			delete(e.subscribers, ch)
+			close(ch)
+		})
+	}
+}
+
+// SendMessage stores a copy of the message under a local ID and queues it to be sent. Returns the stored copy.
+func (e *SyncEngine) SendMessage(request chat.ChatMessageRequest) (chat.ChatMessage, error) {
+	m := Mutation{Kind: MUTATION_KIND_SEND_MESSAGE, ChannelId: request.ChannelId, Message: &request, QueuedAtUtc: e.now().UTC()}
+
+	var message chat.ChatMessage
+
+	err := e.update(func(tx *bolt.Tx) error {
+		if err := queueMutation(tx, &m); err != nil {
+			return err
+		}
+
+		var self chat.User
+
+		if _, err := getJSON(tx.Bucket(selfBucket), selfKey, &self); err != nil {
+			return err
+		}
+
+		message = chat.ChatMessage{
+			Id:            m.MessageId,
+			ChannelId:     request.ChannelId,
+			SenderUserId:  self.Id,
+			Content:       request.Content,
+			ContentType:   request.ContentType,
+			Embed:         request.Embed,
+			ReceivedAtUtc: m.QueuedAtUtc,
+		}
+
+		return e.store.updateMessages(tx, request.ChannelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			return chat.InsertMessage(stored, message)
+		})
+	})
+
+	return message, err
+}
+
+// EditMessage replaces the content of the stored message and queues the edit. Editing a message which has not been
+// sent yet changes the queued message instead.
+func (e *SyncEngine) EditMessage(channelId string, messageId string, content string) error {
+	now := e.now().UTC()
+
+	return e.update(func(tx *bolt.Tx) error {
+		if IsLocalMessageId(messageId) {
+			m, found, err := findQueuedSend(tx, messageId)
+
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return ErrLocalMessageNotFound
+			}
+
+			m.Message.Content = content
+
+			if err := putMutation(tx, m); err != nil {
+				return err
+			}
+		} else {
+			m := Mutation{Kind: MUTATION_KIND_EDIT_MESSAGE, ChannelId: channelId, MessageId: messageId, Content: content, QueuedAtUtc: now}
+
+			if err := queueMutation(tx, &m); err != nil {
+				return err
+			}
+		}
+
+		return e.updateMessage(tx, channelId, messageId, func(message *chat.ChatMessage) {
+			message.Content = content
+
+			if !IsLocalMessageId(messageId) {
+				message.EditedAtUtc = &now
+			}
+		})
+	})
+}
+
+// DeleteMessage removes the message from the store and queues the deletion. Deleting a message which has not been
+// sent yet removes it from the queue instead.
+func (e *SyncEngine) DeleteMessage(channelId string, messageId string) error {
+	return e.update(func(tx *bolt.Tx) error {
+		if IsLocalMessageId(messageId) {
+			m, found, err := findQueuedSend(tx, messageId)
+
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return ErrLocalMessageNotFound
+			}
+
+			if err := deleteMutation(tx, m.Sequence); err != nil {
+				return err
+			}
+		} else {
+			m := Mutation{Kind: MUTATION_KIND_DELETE_MESSAGE, ChannelId: channelId, MessageId: messageId, QueuedAtUtc: e.now().UTC()}
+
+			if err := queueMutation(tx, &m); err != nil {
+				return err
+			}
+		}
+
+		return e.store.updateMessages(tx, channelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			return slices.DeleteFunc(stored, func(m chat.ChatMessage) bool { return m.Id == messageId })
+		})
+	})
+}
+
+// AddReaction adds the logged in user's reaction to the stored message and queues it. Messages which have not been
+// sent yet cannot be reacted to.
+func (e *SyncEngine) AddReaction(channelId string, messageId string, emoji string) error {
+	return e.react(MUTATION_KIND_ADD_REACTION, channelId, messageId, emoji)
+}
+
+// RemoveReaction removes the logged in user's reaction from the stored message and queues the removal.
+func (e *SyncEngine) RemoveReaction(channelId string, messageId string, emoji string) error {
+	return e.react(MUTATION_KIND_REMOVE_REACTION, channelId, messageId, emoji)
+}
+
+// react applies and queues a reaction change.
+func (e *SyncEngine) react(kind MutationKind, channelId string, messageId string, emoji string) error {
+	if IsLocalMessageId(messageId) {
+		return ErrMessageNotSent
+	}
+
+	return e.update(func(tx *bolt.Tx) error {
+		m := Mutation{Kind: kind, ChannelId: channelId, MessageId: messageId, Emoji: emoji, QueuedAtUtc: e.now().UTC()}
+
+		if err := queueMutation(tx, &m); err != nil {
+			return err
+		}
+
+		var self chat.User
+
+		if _, err := getJSON(tx.Bucket(selfBucket), selfKey, &self); err != nil {
+			return err
+		}
+
+		return e.updateMessage(tx, channelId, messageId, func(message *chat.ChatMessage) {
+			message.Reactions = applyReaction(message.Reactions, emoji, self.Id, kind == MUTATION_KIND_ADD_REACTION)
+		})
+	})
+}
+
+// Sync replays the queued changes in order and then fetches the messages posted to each stored channel since its
+// newest stored message. Stops at the first change which fails because the server could not be reached, leaving it
+// queued, and returns the error. Rejected changes are dropped, reported to the conflict handler and the channel is
+// refreshed with its latest page of messages. Run calls Sync automatically; call it directly when not using Run.
+func (e *SyncEngine) Sync(ctx context.Context) error {
+	e.syncMu.Lock()
+	defer e.syncMu.Unlock()
+
+	e.setState(SYNC_STATE_SYNCING, nil)
+
+	mutations, err := e.store.PendingMutations()
+
+	if err != nil {
+		return e.fail(err)
+	}
+
+	// Channels whose stored messages may have diverged from the server because a change was rejected.
+	stale := make(map[string]bool)
+
+	for _, m := range mutations {
+		if ctx.Err() != nil {
+			return e.fail(ctx.Err())
+		}
+
+		result, sent := e.replay(m)
+
+		if isTransient(result.ResponseCode) {
+			return e.fail(result.Err())
+		}
+
+		rejected := result.Err() != nil
+
+		err := e.update(func(tx *bolt.Tx) error {
+			if m.Kind == MUTATION_KIND_SEND_MESSAGE {
+				return e.completeSend(tx, m, sent, rejected)
+			}
+
+			return deleteMutation(tx, m.Sequence)
+		})
+
+		if err != nil {
+			return e.fail(err)
+		}
+
+		if rejected {
+			stale[m.ChannelId] = true
+			e.onConflict(Conflict{Mutation: m, Result: result})
+		}
+	}
+
+	if err := e.refresh(ctx, stale); err != nil {
+		return e.fail(err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.status.LastSyncedAtUtc = e.now().UTC()
+
+	if e.online {
+		e.status.State, e.status.Err = SYNC_STATE_SYNCED, nil
+	}
+
+	e.publish()
+
+	// Changes queued while syncing are picked up by another sync.
+	if e.status.Pending > 0 {
+		e.requestSync()
+	}
+
+	return nil
+}
+
+// completeSend removes a replayed send from the queue and replaces the local copy of the message with the server's
+// copy, or drops it if the server rejected the message. The message may have been deleted or edited locally while it
+// was being sent; the deletion or edit is then queued against the sent message so it is not lost.
+func (e *SyncEngine) completeSend(tx *bolt.Tx, m Mutation, sent chat.ChatMessage, rejected bool) error {
+	current, queued, err := findQueuedSend(tx, m.MessageId)
+
+	if err != nil {
+		return err
+	}
+
+	if queued {
+		if err := deleteMutation(tx, m.Sequence); err != nil {
+			return err
+		}
+	}
+
+	if rejected {
+		return e.store.updateMessages(tx, m.ChannelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			return slices.DeleteFunc(stored, func(message chat.ChatMessage) bool { return message.Id == m.MessageId })
+		})
+	}
+
+	// Deleted while being sent: the local copy is already gone, so delete the sent message from the server too.
+	if !queued {
+		deletion := Mutation{Kind: MUTATION_KIND_DELETE_MESSAGE, ChannelId: m.ChannelId, MessageId: sent.Id, QueuedAtUtc: e.now().UTC()}
+		return queueMutation(tx, &deletion)
+	}
+
+	// Edited while being sent: the server has the old content, so edit the sent message and keep the new content.
+	if current.Message.Content != m.Message.Content {
+		edit := Mutation{Kind: MUTATION_KIND_EDIT_MESSAGE, ChannelId: m.ChannelId, MessageId: sent.Id, Content: current.Message.Content, QueuedAtUtc: e.now().UTC()}
+
+		if err := queueMutation(tx, &edit); err != nil {
+			return err
+		}
+
+		sent.Content = current.Message.Content
+		sent.EditedAtUtc = &edit.QueuedAtUtc
+	}
+
+	return e.store.updateMessages(tx, m.ChannelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+		stored = slices.DeleteFunc(stored, func(message chat.ChatMessage) bool { return message.Id == m.MessageId })
+		return chat.InsertMessage(stored, sent)
+	})
+}
+
+// replay sends a queued change to the server. For MUTATION_KIND_SEND_MESSAGE the sent message is also returned.
+func (e *SyncEngine) replay(m Mutation) (chat.BroChatClientResult, chat.ChatMessage) {
+	switch m.Kind {
+	case MUTATION_KIND_SEND_MESSAGE:
+		result := e.client.SendChatMessage(e.accessToken, *m.Message)
+		return result.BroChatClientResult, result.Content
+	case MUTATION_KIND_EDIT_MESSAGE:
+		return e.client.EditMessage(e.accessToken, m.ChannelId, m.MessageId, chat.EditMessageRequest{Content: m.Content}).BroChatClientResult, chat.ChatMessage{}
+	case MUTATION_KIND_DELETE_MESSAGE:
+		return e.client.DeleteMessage(e.accessToken, m.ChannelId, m.MessageId), chat.ChatMessage{}
+	case MUTATION_KIND_ADD_REACTION:
+		return e.client.AddReaction(e.accessToken, m.ChannelId, m.MessageId, chat.AddReactionRequest{Emoji: m.Emoji}), chat.ChatMessage{}
+	case MUTATION_KIND_REMOVE_REACTION:
+		return e.client.RemoveReaction(e.accessToken, m.ChannelId, m.MessageId, m.Emoji), chat.ChatMessage{}
+	default:
+		// Written by a newer version of the package. Report it as a conflict so it does not block the queue.
+		return chat.BroChatClientResult{ResponseCode: chat.BROCHAT_RESPONSE_CODE_INVALID_OPERATION}, chat.ChatMessage{}
+	}
+}
+
+// refresh fetches the messages posted to each stored channel since its newest sent message. Stale channels, and
+// channels without a sent message, are refreshed with their latest page instead. Channels which can no longer be
+// read are skipped.
+func (e *SyncEngine) refresh(ctx context.Context, stale map[string]bool) error {
+	newest := make(map[string]string)
+
+	err := e.store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(messagesBucket).ForEach(func(k, v []byte) error {
+			stored := make([]chat.ChatMessage, 0)
+
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return err
+			}
+
+			newest[string(k)] = ""
+
+			for i := len(stored) - 1; i >= 0; i-- {
+				if !IsLocalMessageId(stored[i].Id) {
+					newest[string(k)] = stored[i].Id
+					break
+				}
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for channelId, messageId := range newest {
+		latestOnly := stale[channelId] || messageId == ""
+
+		for page := uint64(1); ; page++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			options := []chat.GetChannelMessagesOption{chat.GetChannelMessages_Page(page), chat.GetChannelMessages_PageSize(chat.MAX_PAGE_SIZE)}
+
+			if !latestOnly {
+				options = append(options, chat.GetChannelMessages_AfterMessage(messageId))
+			}
+
+			result := e.client.GetChannelMessages(e.accessToken, channelId, options...)
+
+			if isTransient(result.ResponseCode) {
+				return result.Err()
+			}
+
+			if result.Err() != nil {
+				break
+			}
+
+			if err := e.saveRefreshed(channelId, result.Content); err != nil {
+				return err
+			}
+
+			// Only the newest messages are kept, so there is no point fetching more than fit.
+			if latestOnly || len(result.Content) < chat.MAX_PAGE_SIZE || int(page)*chat.MAX_PAGE_SIZE >= e.store.maxMessages {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// saveRefreshed merges fetched messages into the stored messages of a channel. Deletions and edits which are still
+// queued, such as those queued while a sync was sending, are applied to the fetched copies so the refresh does not
+// undo them.
+func (e *SyncEngine) saveRefreshed(channelId string, messages []chat.ChatMessage) error {
+	return e.store.db.Update(func(tx *bolt.Tx) error {
+		queued := make(map[string]Mutation)
+
+		err := tx.Bucket(outboxBucket).ForEach(func(k, v []byte) error {
+			var m Mutation
+
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+
+			if m.ChannelId == channelId && (m.Kind == MUTATION_KIND_DELETE_MESSAGE || m.Kind == MUTATION_KIND_EDIT_MESSAGE) {
+				if previous, ok := queued[m.MessageId]; !ok || previous.Kind != MUTATION_KIND_DELETE_MESSAGE {
+					queued[m.MessageId] = m
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		fetched := make([]chat.ChatMessage, 0, len(messages))
+
+		for _, message := range messages {
+			m, ok := queued[message.Id]
+
+			if ok && m.Kind == MUTATION_KIND_DELETE_MESSAGE {
+				continue
+			}
+
+			if ok {
+				message.Content = m.Content
+				message.EditedAtUtc = &m.QueuedAtUtc
+			}
+
+			fetched = append(fetched, message)
+		}
+
+		return e.store.updateMessages(tx, channelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+			return chat.MergeMessages(stored, fetched)
+		})
+	})
+}
+
+// update runs a store transaction and publishes the new number of queued changes, starting a sync if online.
+func (e *SyncEngine) update(fn func(tx *bolt.Tx) error) error {
+	pending := 0
+
+	err := e.store.db.Update(func(tx *bolt.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		pending = countMutations(tx)
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if pending != e.status.Pending {
+		e.status.Pending = pending
+		e.publish()
+	}
+
+	if pending > 0 && e.online {
+		e.requestSync()
+	}
+
+	return nil
+}
+
+// updateMessage applies the update to a stored message. Does nothing if the message is not stored.
+func (e *SyncEngine) updateMessage(tx *bolt.Tx, channelId string, messageId string, update func(*chat.ChatMessage)) error {
+	return e.store.updateMessages(tx, channelId, func(stored []chat.ChatMessage) []chat.ChatMessage {
+		if i := slices.IndexFunc(stored, func(m chat.ChatMessage) bool { return m.Id == messageId }); i >= 0 {
+			update(&stored[i])
+		}
+
+		return stored
+	})
+}
+
+// setState changes the state of the engine and publishes the status.
+func (e *SyncEngine) setState(state SyncState, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.status.State, e.status.Err = state, err
+	e.publish()
+}
+
+// fail publishes a failed sync and returns the error. An engine which went offline during the sync stays offline.
+func (e *SyncEngine) fail(err error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.online {
+		e.status.State, e.status.Err = SYNC_STATE_FAILED, err
+		e.publish()
+	}
+
+	return err
+}
+
+// requestSync wakes Run without blocking. The lock must be held.
+func (e *SyncEngine) requestSync() {
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// publish sends the status to every subscriber, replacing any status they have not yet received. The lock must be held.
+func (e *SyncEngine) publish() {
+	for ch := range e.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- e.status
+	}
+}
+
+// isTransient returns true if a request failed in a way which may succeed when retried: the server could not be
+// reached, was overloaded, the access token had expired or the error was unhandled.
+func isTransient(code chat.BroChatResponseCode) bool {
+	switch code {
+	case chat.BROCHAT_RESPONSE_CODE_CONNECTION_TIMEOUT_ERROR, chat.BROCHAT_RESPONSE_CODE_GENERIC_CONNECTION_ERROR,
+		chat.BROCHAT_RESPONSE_CODE_RATE_LIMITED_ERROR, chat.BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR,
+		chat.BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyReaction adds or removes the user's reaction to a message's reactions, dropping reactions nobody has made.
+func applyReaction(reactions []chat.ReactionSummary, emoji string, userId string, add bool) []chat.ReactionSummary {
+	i := slices.IndexFunc(reactions, func(r chat.ReactionSummary) bool { return r.Emoji == emoji })
+
+	if i < 0 {
+		if !add {
+			return reactions
+		}
+
+		reactions = append(reactions, chat.ReactionSummary{Emoji: emoji})
+		i = len(reactions) - 1
+	}
+
+	r := &reactions[i]
+	reacted := r.ReactedByMe || (userId != "" && slices.Contains(r.UserIds, userId))
+
+	switch {
+	case add && !reacted:
+		r.Count++
+
+		if userId != "" {
+			r.UserIds = append(r.UserIds, userId)
+		}
+	case !add && reacted:
+		r.Count--
+		r.UserIds = slices.DeleteFunc(r.UserIds, func(id string) bool { return id == userId })
+	}
+
+	r.ReactedByMe = add
+
+	if r.Count <= 0 {
+		return slices.Delete(reactions, i, i+1)
+	}
+
+	return reactions
+}
@@ -0,0 +1,229 @@
+package clientstore
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// newTestSyncEngine creates an online engine syncing the store with the server.
+func newTestSyncEngine(t *testing.T, store *Store, server *fakeChatServer, options ...SyncEngineOption) *SyncEngine {
+	t.Helper()
+
+	engine, err := NewSyncEngine(store, chat.NewBroChatClient(server.Client(), server.URL), "token", options...)
+
+	if err != nil {
+		t.Fatalf("NewSyncEngine() error = %v", err)
+	}
+
+	engine.SetOnline(true)
+
+	return engine
+}
+
+// syncAll runs syncs until no change is queued.
+func syncAll(t *testing.T, engine *SyncEngine) {
+	t.Helper()
+
+	for i := 0; i < 5; i++ {
+		if err := engine.Sync(context.Background()); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+
+		if engine.Status().Pending == 0 {
+			return
+		}
+	}
+
+	t.Fatalf("changes still queued after syncing: %d", engine.Status().Pending)
+}
+
+// storedMessages returns the stored messages of the channel.
+func storedMessages(t *testing.T, store *Store, channelId string) []chat.ChatMessage {
+	t.Helper()
+
+	snapshot, err := store.Load()
+
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	return snapshot.Messages[channelId]
+}
+
+func contents(messages []chat.ChatMessage) []string {
+	values := make([]string, len(messages))
+
+	for i, m := range messages {
+		values[i] = m.Content
+	}
+
+	return values
+}
+
+func TestSyncEngine_ReplaysQueuedSend(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+	engine := newTestSyncEngine(t, store, server)
+
+	local, err := engine.SendMessage(chat.ChatMessageRequest{ChannelId: "c", Content: "hello"})
+
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if !IsLocalMessageId(local.Id) {
+		t.Fatalf("SendMessage() id = %q, want a local id", local.Id)
+	}
+
+	syncAll(t, engine)
+
+	stored := storedMessages(t, store, "c")
+
+	if len(stored) != 1 || IsLocalMessageId(stored[0].Id) || stored[0].Content != "hello" {
+		t.Errorf("stored messages = %+v, want the server's copy of the sent message", stored)
+	}
+}
+
+func TestSyncEngine_DeleteWhileSending(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+	engine := newTestSyncEngine(t, store, server)
+
+	local, err := engine.SendMessage(chat.ChatMessageRequest{ChannelId: "c", Content: "oops"})
+
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	server.onSend = func(chat.ChatMessageRequest) {
+		if err := engine.DeleteMessage("c", local.Id); err != nil {
+			t.Errorf("DeleteMessage() error = %v", err)
+		}
+	}
+
+	syncAll(t, engine)
+
+	if stored := storedMessages(t, store, "c"); len(stored) != 0 {
+		t.Errorf("stored messages = %+v, want none", stored)
+	}
+
+	if messages := server.channel("c"); len(messages) != 0 {
+		t.Errorf("server messages = %+v, want the sent message deleted", messages)
+	}
+}
+
+func TestSyncEngine_EditWhileSending(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+	engine := newTestSyncEngine(t, store, server)
+
+	local, err := engine.SendMessage(chat.ChatMessageRequest{ChannelId: "c", Content: "helo"})
+
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	server.onSend = func(chat.ChatMessageRequest) {
+		if err := engine.EditMessage("c", local.Id, "hello"); err != nil {
+			t.Errorf("EditMessage() error = %v", err)
+		}
+	}
+
+	syncAll(t, engine)
+
+	if got := contents(storedMessages(t, store, "c")); !slices.Equal(got, []string{"hello"}) {
+		t.Errorf("stored contents = %v, want [hello]", got)
+	}
+
+	if got := contents(server.channel("c")); !slices.Equal(got, []string{"hello"}) {
+		t.Errorf("server contents = %v, want [hello]", got)
+	}
+}
+
+func TestSyncEngine_Conflict(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+
+	kept := server.add("c", "kept")
+	removed := chat.ChatMessage{Id: "gone", ChannelId: "c", Content: "gone", ReceivedAtUtc: kept.ReceivedAtUtc.Add(-1)}
+
+	if err := store.SaveMessages("c", removed, kept); err != nil {
+		t.Fatalf("SaveMessages() error = %v", err)
+	}
+
+	var conflicts []Conflict
+	engine := newTestSyncEngine(t, store, server, SyncEngineOption_OnConflict(func(c Conflict) { conflicts = append(conflicts, c) }))
+
+	if err := engine.EditMessage("c", removed.Id, "edited"); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+
+	if err := engine.EditMessage("c", kept.Id, "kept and edited"); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+
+	syncAll(t, engine)
+
+	if len(conflicts) != 1 || conflicts[0].Mutation.MessageId != removed.Id || conflicts[0].Result.ResponseCode != chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR {
+		t.Fatalf("conflicts = %+v, want the rejected edit of %s", conflicts, removed.Id)
+	}
+
+	if got := contents(server.channel("c")); !slices.Equal(got, []string{"kept and edited"}) {
+		t.Errorf("server contents = %v, want the edit after the conflict replayed", got)
+	}
+
+	stored := storedMessages(t, store, "c")
+
+	if i := slices.IndexFunc(stored, func(m chat.ChatMessage) bool { return m.Id == kept.Id }); i < 0 || stored[i].Content != "kept and edited" {
+		t.Errorf("stored messages = %+v, want the channel refreshed from the server", stored)
+	}
+}
+
+func TestSyncEngine_RefreshesMissedMessages(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+
+	first := server.add("c", "first")
+
+	if err := store.SaveMessages("c", first); err != nil {
+		t.Fatalf("SaveMessages() error = %v", err)
+	}
+
+	server.add("c", "second")
+	server.add("c", "third")
+
+	engine := newTestSyncEngine(t, store, server)
+
+	syncAll(t, engine)
+
+	if got := contents(storedMessages(t, store, "c")); !slices.Equal(got, []string{"first", "second", "third"}) {
+		t.Errorf("stored contents = %v, want the missed messages after the stored one", got)
+	}
+
+	if status := engine.Status(); status.State != SYNC_STATE_SYNCED || status.LastSyncedAtUtc.IsZero() {
+		t.Errorf("status = %+v, want synced", status)
+	}
+}
+
+func TestSyncEngine_KeepsChangesWhenOffline(t *testing.T) {
+	server := newFakeChatServer(t)
+	store := openTestStore(t)
+	engine := newTestSyncEngine(t, store, server)
+
+	if _, err := engine.SendMessage(chat.ChatMessageRequest{ChannelId: "c", Content: "later"}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	server.Close()
+
+	if err := engine.Sync(context.Background()); err == nil {
+		t.Fatalf("Sync() error = nil, want the connection error")
+	}
+
+	if status := engine.Status(); status.State != SYNC_STATE_FAILED || status.Pending != 1 {
+		t.Errorf("status = %+v, want failed with the send still queued", status)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dmars8047/brolib/idam"
+)
+
+func runLogin(ctx context.Context, a *app, args []string) error {
+	flags := a.newFlagSet("login")
+	email := flags.String("email", "", "the email address of the account")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	sessions, err := a.sessionManager()
+
+	if err != nil {
+		return err
+	}
+
+	password := os.Getenv(passwordEnv)
+
+	if password == "" {
+		fmt.Fprint(a.stderr, "Password: ")
+
+		line, err := bufio.NewReader(a.stdin).ReadString('\n')
+
+		if err != nil && line == "" {
+			return fmt.Errorf("reading password: %w", err)
+		}
+
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	result := sessions.Login(idam.LoginRequest{Email: *email, Password: password})
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Logged in as %s\n", result.Content.UserId)
+
+	return nil
+}
+
+func runLogout(ctx context.Context, a *app, args []string) error {
+	if err := parse(a.newFlagSet("logout"), args); err != nil {
+		return err
+	}
+
+	userId, _, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	// The session is forgotten even if revoking it fails, so report the failure without failing the command.
+	if err := a.sessions.Logout(userId).Err(); err != nil {
+		fmt.Fprintf(a.stderr, "warning: the session could not be revoked: %v\n", err)
+	}
+
+	fmt.Fprintf(a.stdout, "Logged out %s\n", userId)
+
+	return nil
+}
+
+func runWhoami(ctx context.Context, a *app, args []string) error {
+	if err := parse(a.newFlagSet("whoami"), args); err != nil {
+		return err
+	}
+
+	userId, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	result := client.GetUser("", userId)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "%s (%s)\n", result.Content.Username, result.Content.Id)
+
+	return nil
+}
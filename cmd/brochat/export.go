@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		a.newFlagSet("export").Usage()
+		return errUsage
+	}
+
+	switch args[0] {
+	case "channel":
+		return runExportChannel(a, args[1:])
+	case "data":
+		return runExportData(ctx, a, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "brochat export: unknown subcommand %q\n", args[0])
+		a.newFlagSet("export").Usage()
+		return errUsage
+	}
+}
+
+func runExportChannel(a *app, args []string) error {
+	flags := a.newFlagSet("export")
+	channelId := flags.String("channel", "", "the ID of the channel to export")
+	format := flags.String("format", string(chat.EXPORT_FORMAT_JSON), "the format of the export: ndjson or html")
+	output := flags.String("o", "", "the file to write the export to. Defaults to standard output")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *channelId == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	return a.writeOutput(*output, func(w io.Writer) error {
+		return chat.NewChannelExporter(client).Export("", *channelId, chat.ExportFormat(*format), w)
+	})
+}
+
+func runExportData(ctx context.Context, a *app, args []string) error {
+	flags := a.newFlagSet("export")
+	output := flags.String("o", "brochat-export.zip", "the file to write the archive to")
+	interval := flags.Duration("interval", chat.DEFAULT_DATA_EXPORT_POLL_INTERVAL, "how often to check whether the export is ready")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	requested := client.RequestDataExport("")
+
+	if err := requested.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stderr, "Export %s requested, waiting for it to be ready...\n", requested.Content.Id)
+
+	result := client.WaitForDataExport(ctx, "", requested.Content.Id, *interval)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	export := result.Content
+
+	switch export.Status {
+	case chat.DATA_EXPORT_STATUS_READY:
+	case chat.DATA_EXPORT_STATUS_FAILED:
+		return fmt.Errorf("export %s failed: %s", export.Id, export.FailureReason)
+	default:
+		return fmt.Errorf("export %s is %s", export.Id, export.Status)
+	}
+
+	err = a.writeOutput(*output, func(w io.Writer) error {
+		return client.DownloadDataExport("", export.Id, w).Err()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stderr, "Saved export %s to %s\n", export.Id, *output)
+
+	return nil
+}
+
+// writeOutput calls write with the named file, or with standard output if the name is empty or "-". A file is removed
+// again if write fails, so a partial export is never left behind.
+func (a *app) writeOutput(name string, write func(w io.Writer) error) error {
+	if name == "" || name == "-" {
+		return write(a.stdout)
+	}
+
+	file, err := os.Create(name)
+
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(name)
+	}
+
+	return err
+}
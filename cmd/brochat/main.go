@@ -0,0 +1,239 @@
+// Command brochat is a command line client for BroChat built on this library. It is both a tool for power users and
+// living integration coverage for the client APIs: every subcommand goes through the same clients applications use.
+//
+// Usage:
+//
+//	brochat [global flags] <command> [flags] [arguments]
+//
+// Sessions are saved by login and reused by the other commands until logout, refreshing the access token as needed.
+// Run brochat help for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/idam"
+)
+
+// The environment variables holding the defaults of the global flags.
+const (
+	serverEnv     = "BROCHAT_SERVER"
+	idamServerEnv = "BROCHAT_IDAM_SERVER"
+	passwordEnv   = "BROCHAT_PASSWORD"
+)
+
+// errUsage is returned by commands given invalid arguments. The usage of the command has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// A command is a subcommand of the CLI.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+// commands lists the subcommands in the order they are shown by help. It is set by init because the commands refer to it
+// through newFlagSet.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "login", usage: "login -email <email>", summary: "Log in and save the session. Reads the password from " + passwordEnv + " or standard input.", run: runLogin},
+		{name: "logout", usage: "logout", summary: "Revoke and forget the session of the account.", run: runLogout},
+		{name: "whoami", usage: "whoami", summary: "Show the logged in user.", run: runWhoami},
+		{name: "send", usage: "send -channel <id> [-reply-to <id>] [-ttl <duration>] [message]", summary: "Send a message. Reads the message from standard input if not given.", run: runSend},
+		{name: "listen", usage: "listen -channel <id> [-history <n>] [-interval <duration>]", summary: "Print new messages of a channel as they arrive until interrupted.", run: runListen},
+		{name: "rooms", usage: "rooms <list|discover|create|join> [flags]", summary: "List, discover, create and join rooms.", run: runRooms},
+		{name: "export", usage: "export <channel|data> [flags]", summary: "Export the history of a channel, or request and download all of your data.", run: runExport},
+	}
+}
+
+// app holds the global configuration and the state shared by commands.
+type app struct {
+	serverUrl    string
+	idamUrl      string
+	sessionsPath string
+	userId       string
+	stdin        io.Reader
+	stdout       io.Writer
+	stderr       io.Writer
+	sessions     *idam.SessionManager
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run parses the global flags, runs the command and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("brochat", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&a.serverUrl, "server", os.Getenv(serverEnv), "the base URL of the BroChat API. Defaults to $"+serverEnv)
+	flags.StringVar(&a.idamUrl, "idam-server", os.Getenv(idamServerEnv), "the base URL of the identity API. Defaults to $"+idamServerEnv+", then -server")
+	flags.StringVar(&a.sessionsPath, "sessions", defaultSessionsPath(), "the file the sessions are saved to")
+	flags.StringVar(&a.userId, "user", "", "the ID of the account to use when several are logged in")
+	flags.Usage = func() { printUsage(stderr, flags) }
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		printUsage(stdout, flags)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != flags.Arg(0) {
+			continue
+		}
+
+		err := cmd.run(ctx, a, flags.Args()[1:])
+
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		default:
+			fmt.Fprintf(stderr, "brochat %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "brochat: unknown command %q\n", flags.Arg(0))
+	printUsage(stderr, flags)
+
+	return 2
+}
+
+// printUsage prints the global flags and the commands.
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: brochat [global flags] <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n        %s\n", cmd.usage, cmd.summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
+
+// newFlagSet creates the flag set of a command, printing the usage of the command on error.
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			flags.Usage = func() {
+				fmt.Fprintf(a.stderr, "Usage: brochat %s\n", cmd.usage)
+				flags.PrintDefaults()
+			}
+		}
+	}
+
+	return flags
+}
+
+// parse parses the flags of a command, returning errUsage if they are invalid.
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	return nil
+}
+
+// sessionManager returns the session manager, loading the saved sessions the first time it is called.
+func (a *app) sessionManager() (*idam.SessionManager, error) {
+	if a.sessions != nil {
+		return a.sessions, nil
+	}
+
+	if a.serverUrl == "" {
+		return nil, fmt.Errorf("no server given: pass -server or set $%s", serverEnv)
+	}
+
+	idamUrl := a.idamUrl
+
+	if idamUrl == "" {
+		idamUrl = a.serverUrl
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.sessionsPath), 0700); err != nil {
+		return nil, err
+	}
+
+	sessions, err := idam.NewSessionManager(idam.NewIdamClient(http.DefaultClient, idamUrl), http.DefaultClient, a.serverUrl,
+		idam.NewFileSessionStore(a.sessionsPath))
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.sessions = sessions
+
+	return sessions, nil
+}
+
+// client returns the user ID and client of the account to use: the account given with -user, or the only logged in
+// account. Requests made with the client are authorized with the saved session, so an empty access token is passed.
+func (a *app) client() (string, *chat.BroChatClient, error) {
+	sessions, err := a.sessionManager()
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	userId := a.userId
+
+	if userId == "" {
+		switch accounts := sessions.Accounts(); len(accounts) {
+		case 0:
+			return "", nil, errors.New("not logged in: run brochat login")
+		case 1:
+			userId = accounts[0]
+		default:
+			return "", nil, errors.New("several accounts are logged in: pick one with -user")
+		}
+	}
+
+	client, ok := sessions.Client(userId)
+
+	if !ok {
+		return "", nil, fmt.Errorf("account %s is not logged in", userId)
+	}
+
+	return userId, client, nil
+}
+
+// defaultSessionsPath returns the sessions file in the user's configuration directory.
+func defaultSessionsPath() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "brochat", "sessions.json")
+}
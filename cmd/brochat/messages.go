@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/render"
+)
+
+func runSend(ctx context.Context, a *app, args []string) error {
+	flags := a.newFlagSet("send")
+	channelId := flags.String("channel", "", "the ID of the channel to send to")
+	replyTo := flags.String("reply-to", "", "the ID of the message to reply to")
+	ttl := flags.Duration("ttl", 0, "how long the message lives for before it expires")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *channelId == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	content := strings.Join(flags.Args(), " ")
+
+	if content == "" {
+		data, err := io.ReadAll(a.stdin)
+
+		if err != nil {
+			return err
+		}
+
+		content = strings.TrimRight(string(data), "\r\n")
+	}
+
+	options := []chat.ChatMessageRequestOption{chat.ChatMessageRequestOption_TTL(*ttl)}
+
+	if *replyTo != "" {
+		options = append(options, chat.ChatMessageRequestOption_ReplyTo(*replyTo))
+	}
+
+	request := chat.NewChatMessageRequest(*channelId, content, options...)
+
+	if err := request.Validate().Err(); err != nil {
+		return err
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	result := client.SendChatMessage("", request)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout, result.Content.Id)
+
+	return nil
+}
+
+// listen polls the channel for messages after the newest one printed, so it needs no feed connection.
+func runListen(ctx context.Context, a *app, args []string) error {
+	flags := a.newFlagSet("listen")
+	channelId := flags.String("channel", "", "the ID of the channel to listen to")
+	history := flags.Uint64("history", 10, "the number of recent messages to print first")
+	interval := flags.Duration("interval", 2*time.Second, "how often to check for new messages")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *channelId == "" || *interval <= 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	printer := newMessagePrinter(client, a.stdout)
+	newest := ""
+
+	if *history > 0 {
+		result := client.GetChannelMessages("", *channelId, chat.GetChannelMessages_PageSize(min(*history, chat.MAX_PAGE_SIZE)))
+
+		if err := result.Err(); err != nil {
+			return err
+		}
+
+		newest = printer.print(result.Content, newest)
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		options := []chat.GetChannelMessagesOption{chat.GetChannelMessages_PageSize(chat.MAX_PAGE_SIZE)}
+
+		if newest != "" {
+			options = append(options, chat.GetChannelMessages_AfterMessage(newest))
+		}
+
+		result := client.GetChannelMessages("", *channelId, options...)
+
+		if err := result.Err(); err != nil {
+			// Keep listening through connection failures; the next poll picks up where this one left off.
+			fmt.Fprintf(a.stderr, "warning: %v\n", err)
+			continue
+		}
+
+		newest = printer.print(result.Content, newest)
+	}
+}
+
+// messagePrinter prints messages with the render package, resolving sender usernames through a cache.
+type messagePrinter struct {
+	users     *chat.UserInfoCache
+	usernames map[string]string
+	renderer  *render.Renderer
+	w         io.Writer
+}
+
+func newMessagePrinter(client *chat.BroChatClient, w io.Writer) *messagePrinter {
+	p := &messagePrinter{users: chat.NewUserInfoCache(client), usernames: make(map[string]string), w: w}
+	p.renderer = render.NewRenderer(render.RendererOption_Usernames(func(userId string) string { return p.usernames[userId] }),
+		render.RendererOption_TimeFormat("2006-01-02 15:04"), render.RendererOption_Width(0))
+
+	return p
+}
+
+// print prints a page of messages oldest first and returns the ID of the newest message, or newest if the page is empty.
+func (p *messagePrinter) print(page []chat.ChatMessage, newest string) string {
+	messages := chat.MergeMessages(page)
+
+	if len(messages) == 0 {
+		return newest
+	}
+
+	userIds := make([]string, 0, len(messages))
+
+	for _, m := range messages {
+		userIds = append(userIds, m.SenderUserId)
+
+		if m.ReplyTo != nil {
+			userIds = append(userIds, m.ReplyTo.SenderUserId)
+		}
+	}
+
+	// Unresolved senders are printed by ID, so a failed lookup is not an error.
+	for id, user := range p.users.GetUsers("", userIds).Content {
+		p.usernames[id] = user.Username
+	}
+
+	for _, m := range messages {
+		fmt.Fprintln(p.w, p.renderer.Terminal(m))
+	}
+
+	return messages[len(messages)-1].Id
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+func runRooms(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		a.newFlagSet("rooms").Usage()
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return runRoomsList(a, args[1:])
+	case "discover":
+		return runRoomsDiscover(a, args[1:])
+	case "create":
+		return runRoomsCreate(a, args[1:])
+	case "join":
+		return runRoomsJoin(a, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "brochat rooms: unknown subcommand %q\n", args[0])
+		a.newFlagSet("rooms").Usage()
+		return errUsage
+	}
+}
+
+func runRoomsList(a *app, args []string) error {
+	if err := parse(a.newFlagSet("rooms"), args); err != nil {
+		return err
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	result := client.GetRooms("")
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return a.printRooms(result.Content)
+}
+
+func runRoomsDiscover(a *app, args []string) error {
+	flags := a.newFlagSet("rooms")
+	name := flags.String("name", "", "only show rooms whose name contains the value")
+	tags := flags.String("tags", "", "only show rooms with any of the comma separated tags")
+	page := flags.Uint64("page", 1, "the page of results")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	options := []chat.DiscoverRoomsOption{chat.DiscoverRoomsOption_Page(*page)}
+
+	if *name != "" {
+		options = append(options, chat.DiscoverRoomsOption_NameFilter(*name))
+	}
+
+	if *tags != "" {
+		options = append(options, chat.DiscoverRoomsOption_Tags(strings.Split(*tags, ",")...))
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	result := client.DiscoverRooms("", options...)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return a.printRooms(result.Content)
+}
+
+func runRoomsCreate(a *app, args []string) error {
+	flags := a.newFlagSet("rooms")
+	name := flags.String("name", "", "the name of the room")
+	model := flags.String("model", string(chat.FRIENDS_MEMBERSHIP_MODEL), "who can join the room: friends or public")
+	password := flags.String("password", "", "a password required to join the room")
+	maxMembers := flags.Uint64("max-members", 0, "the maximum number of members, 0 for the server default")
+	tags := flags.String("tags", "", "comma separated tags describing the room")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	request := chat.CreateRoomRequest{Name: *name, MembershipModel: *model, JoinPassword: *password, MaxMembers: *maxMembers}
+
+	if *tags != "" {
+		request.Tags = strings.Split(*tags, ",")
+	}
+
+	if err := request.Validate().Err(); err != nil {
+		return err
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	result := client.CreateRoom("", request)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return a.printRooms([]chat.Room{result.Content})
+}
+
+func runRoomsJoin(a *app, args []string) error {
+	flags := a.newFlagSet("rooms")
+	password := flags.String("password", "", "the password of a password protected room")
+
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(a.stderr, "Usage: brochat rooms join [-password <password>] <room id>")
+		return errUsage
+	}
+
+	_, client, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	var options []chat.JoinRoomOption
+
+	if *password != "" {
+		options = append(options, chat.JoinRoomOption_Password(*password))
+	}
+
+	if err := client.JoinRoom("", flags.Arg(0), options...).Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Joined %s\n", flags.Arg(0))
+
+	return nil
+}
+
+// printRooms prints rooms as a table.
+func (a *app) printRooms(rooms []chat.Room) error {
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCHANNEL\tOWNER\tMEMBERSHIP")
+
+	for _, room := range rooms {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", room.Id, room.Name, room.ChannelId, room.Owner.Username, room.MembershipModel)
+	}
+
+	return w.Flush()
+}
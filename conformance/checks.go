@@ -0,0 +1,488 @@
+package conformance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/idam"
+	"github.com/dmars8047/brolib/schemas"
+)
+
+// A check is a single assertion about the behaviour of the server. Checks share state through the suite and are run in
+// order, so a check may rely on the state left by the checks it requires.
+type check struct {
+	name     string
+	requires []string
+	run      func(s *suite) error
+}
+
+// marker is included in the names and content created by the suite so it can be found and cleaned up.
+var marker = "conformance-" + randomHex(4)
+
+var checks = []check{
+	{name: "idam/login", run: func(s *suite) error {
+		session, err := expectContent(s, s.idam.Login(idam.LoginRequest{Email: s.config.Primary.Email, Password: s.config.Primary.Password}))
+
+		if err != nil {
+			return err
+		}
+
+		if session.AccessToken == "" || session.RefreshToken == "" {
+			return fmt.Errorf("session is missing its access or refresh token")
+		}
+
+		s.primary = session
+
+		return nil
+	}},
+	{name: "idam/login_secondary", run: func(s *suite) error {
+		session, err := expectContent(s, s.idam.Login(idam.LoginRequest{Email: s.config.Secondary.Email, Password: s.config.Secondary.Password}))
+
+		if err != nil {
+			return err
+		}
+
+		if session.UserId == s.primary.UserId && session.UserId != "" {
+			return fmt.Errorf("the primary and secondary credentials belong to the same user")
+		}
+
+		s.secondary = session
+
+		return nil
+	}},
+	{name: "idam/login_invalid_credentials", run: func(s *suite) error {
+		result := s.idam.Login(idam.LoginRequest{Email: s.config.Primary.Email, Password: s.config.Primary.Password + marker})
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR)
+	}},
+	{name: "idam/refresh_token", requires: []string{"idam/login"}, run: func(s *suite) error {
+		session, err := expectContent(s, s.idam.RefreshToken(s.primary.RefreshToken))
+
+		if err != nil {
+			return err
+		}
+
+		if session.AccessToken == "" {
+			return fmt.Errorf("refreshed session is missing its access token")
+		}
+
+		// Refresh tokens may be rotated, so continue with the new session.
+		s.primary = session
+
+		return nil
+	}},
+	{name: "idam/list_sessions", requires: []string{"idam/login"}, run: func(s *suite) error {
+		sessions, err := expectContent(s, s.idam.ListSessions(s.primary.AccessToken))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(sessions, func(d idam.DeviceSession) bool { return d.IsCurrent }) {
+			return fmt.Errorf("no session is marked as current")
+		}
+
+		return nil
+	}},
+	{name: "auth/invalid_token", run: func(s *suite) error {
+		return s.expectError(s.chat.GetUser(marker, "").BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR)
+	}},
+	{name: "users/get_user", requires: []string{"idam/login"}, run: func(s *suite) error {
+		user, err := expectContent(s, s.chat.GetUser(s.primary.AccessToken, s.primary.UserId))
+
+		if err != nil {
+			return err
+		}
+
+		if s.primary.UserId != "" && user.Id != s.primary.UserId {
+			return fmt.Errorf("expected user %s, got %s", s.primary.UserId, user.Id)
+		}
+
+		return nil
+	}},
+	{name: "users/get_users", requires: []string{"idam/login"}, run: func(s *suite) error {
+		users, err := expectContent(s, s.chat.GetUsers(s.primary.AccessToken, chat.GetUsersOption_PageSize(1)))
+
+		if err != nil {
+			return err
+		}
+
+		if len(users) > 1 {
+			return fmt.Errorf("requested a page size of 1, got %d users", len(users))
+		}
+
+		return nil
+	}},
+	{name: "users/get_users_by_ids", requires: []string{"idam/login", "idam/login_secondary"}, run: func(s *suite) error {
+		users, err := expectContent(s, s.chat.GetUsersByIds(s.primary.AccessToken, []string{s.secondary.UserId}))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(users, func(u chat.UserInfo) bool { return u.Id == s.secondary.UserId }) {
+			return fmt.Errorf("user %s was not resolved", s.secondary.UserId)
+		}
+
+		return nil
+	}},
+	{name: "users/status", requires: []string{"idam/login"}, run: func(s *suite) error {
+		status, err := expectContent(s, s.chat.SetStatus(s.primary.AccessToken, chat.SetStatusRequest{Text: marker}))
+
+		if err != nil {
+			return err
+		}
+
+		if status.Text != marker {
+			return fmt.Errorf("expected status text %q, got %q", marker, status.Text)
+		}
+
+		return s.expectNoContent(s.chat.ClearStatus(s.primary.AccessToken))
+	}},
+	{name: "users/notification_preferences", requires: []string{"idam/login"}, run: func(s *suite) error {
+		preferences, err := expectContent(s, s.chat.GetNotificationPreferences(s.primary.AccessToken))
+
+		if err != nil {
+			return err
+		}
+
+		_, err = expectContent(s, s.chat.UpdateNotificationPreferences(s.primary.AccessToken, preferences))
+
+		return err
+	}},
+	{name: "users/privacy_settings", requires: []string{"idam/login"}, run: func(s *suite) error {
+		settings, err := expectContent(s, s.chat.GetPrivacySettings(s.primary.AccessToken))
+
+		if err != nil {
+			return err
+		}
+
+		_, err = expectContent(s, s.chat.UpdatePrivacySettings(s.primary.AccessToken, settings))
+
+		return err
+	}},
+	{name: "rooms/create_invalid", requires: []string{"idam/login"}, run: func(s *suite) error {
+		result := s.chat.CreateRoom(s.primary.AccessToken, chat.CreateRoomRequest{})
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_VALIDATION_ERROR)
+	}},
+	{name: "rooms/create", requires: []string{"idam/login"}, run: func(s *suite) error {
+		room, err := expectContent(s, s.chat.CreateRoom(s.primary.AccessToken, chat.CreateRoomRequest{
+			Name:            marker,
+			MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL),
+		}))
+
+		if err != nil {
+			return err
+		}
+
+		if room.Name != marker {
+			return fmt.Errorf("expected room name %q, got %q", marker, room.Name)
+		}
+
+		if room.ChannelId == "" {
+			return fmt.Errorf("room has no channel")
+		}
+
+		s.room = room
+
+		return nil
+	}},
+	{name: "rooms/get_rooms", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		rooms, err := expectContent(s, s.chat.GetRooms(s.primary.AccessToken))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(rooms, func(r chat.Room) bool { return r.Id == s.room.Id }) {
+			return fmt.Errorf("created room %s is not listed", s.room.Id)
+		}
+
+		return nil
+	}},
+	{name: "rooms/update", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		name := marker + "-updated"
+		room, err := expectContent(s, s.chat.UpdateRoom(s.primary.AccessToken, s.room.Id, chat.NewUpdateRoomRequest(chat.UpdateRoomOption_Name(name))))
+
+		if err != nil {
+			return err
+		}
+
+		if room.Name != name {
+			return fmt.Errorf("expected room name %q, got %q", name, room.Name)
+		}
+
+		return nil
+	}},
+	{name: "rooms/join", requires: []string{"rooms/create", "idam/login_secondary"}, run: func(s *suite) error {
+		return s.expectNoContent(s.chat.JoinRoom(s.secondary.AccessToken, s.room.Id))
+	}},
+	{name: "channels/get_channel", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		channel, err := expectContent(s, s.chat.GetChannel(s.primary.AccessToken, s.room.ChannelId))
+
+		if err != nil {
+			return err
+		}
+
+		if channel.Id != s.room.ChannelId {
+			return fmt.Errorf("expected channel %s, got %s", s.room.ChannelId, channel.Id)
+		}
+
+		return nil
+	}},
+	{name: "channels/get_channel_not_found", requires: []string{"idam/login"}, run: func(s *suite) error {
+		result := s.chat.GetChannel(s.primary.AccessToken, marker)
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR)
+	}},
+	{name: "messages/send", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		content := "hello from " + marker
+		message, err := expectContent(s, s.chat.SendChatMessage(s.primary.AccessToken, chat.ChatMessageRequest{ChannelId: s.room.ChannelId, Content: content}))
+
+		if err != nil {
+			return err
+		}
+
+		if message.Id == "" || message.Content != content || message.ChannelId != s.room.ChannelId {
+			return fmt.Errorf("sent message does not match the request: %+v", message)
+		}
+
+		s.message = message
+
+		return nil
+	}},
+	{name: "messages/send_invalid", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		result := s.chat.SendChatMessage(s.primary.AccessToken, chat.ChatMessageRequest{ChannelId: s.room.ChannelId})
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_VALIDATION_ERROR)
+	}},
+	{name: "messages/get_messages", requires: []string{"messages/send"}, run: func(s *suite) error {
+		messages, err := expectContent(s, s.chat.GetChannelMessages(s.primary.AccessToken, s.room.ChannelId, chat.GetChannelMessages_PageSize(10)))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(messages, func(m chat.ChatMessage) bool { return m.Id == s.message.Id }) {
+			return fmt.Errorf("sent message %s is not listed", s.message.Id)
+		}
+
+		return nil
+	}},
+	{name: "messages/get_message", requires: []string{"messages/send"}, run: func(s *suite) error {
+		message, err := expectContent(s, s.chat.GetChannelMessage(s.primary.AccessToken, s.room.ChannelId, s.message.Id))
+
+		if err != nil {
+			return err
+		}
+
+		if message.Id != s.message.Id {
+			return fmt.Errorf("expected message %s, got %s", s.message.Id, message.Id)
+		}
+
+		return nil
+	}},
+	{name: "messages/edit", requires: []string{"messages/send"}, run: func(s *suite) error {
+		content := "edited by " + marker
+		message, err := expectContent(s, s.chat.EditMessage(s.primary.AccessToken, s.room.ChannelId, s.message.Id, chat.EditMessageRequest{Content: content}))
+
+		if err != nil {
+			return err
+		}
+
+		if message.Content != content || !message.IsEdited() {
+			return fmt.Errorf("edited message does not reflect the edit: %+v", message)
+		}
+
+		s.message = message
+
+		return nil
+	}},
+	{name: "messages/edit_forbidden", requires: []string{"messages/send", "rooms/join"}, run: func(s *suite) error {
+		result := s.chat.EditMessage(s.secondary.AccessToken, s.room.ChannelId, s.message.Id, chat.EditMessageRequest{Content: marker})
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR)
+	}},
+	{name: "messages/edit_history", requires: []string{"messages/edit"}, run: func(s *suite) error {
+		revisions, err := expectContent(s, s.chat.GetMessageEditHistory(s.primary.AccessToken, s.room.ChannelId, s.message.Id))
+
+		if err != nil {
+			return err
+		}
+
+		if len(revisions) == 0 {
+			return fmt.Errorf("edited message has no revisions")
+		}
+
+		return nil
+	}},
+	{name: "messages/reactions", requires: []string{"messages/send"}, run: func(s *suite) error {
+		const emoji = "👍"
+
+		if err := s.expectNoContent(s.chat.AddReaction(s.primary.AccessToken, s.room.ChannelId, s.message.Id, chat.AddReactionRequest{Emoji: emoji})); err != nil {
+			return err
+		}
+
+		reactions, err := expectContent(s, s.chat.GetReactions(s.primary.AccessToken, s.room.ChannelId, s.message.Id))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(reactions, func(r chat.ReactionSummary) bool { return r.Emoji == emoji && r.ReactedByMe }) {
+			return fmt.Errorf("added reaction is not listed")
+		}
+
+		return s.expectNoContent(s.chat.RemoveReaction(s.primary.AccessToken, s.room.ChannelId, s.message.Id, emoji))
+	}},
+	{name: "messages/search", requires: []string{"messages/send"}, run: func(s *suite) error {
+		results, err := expectContent(s, s.chat.SearchMessages(s.primary.AccessToken, marker, chat.SearchMessagesOption_Channel(s.room.ChannelId)))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(results, func(r chat.MessageSearchResult) bool { return r.Message.Id == s.message.Id }) {
+			return fmt.Errorf("sent message %s was not found by search", s.message.Id)
+		}
+
+		return nil
+	}},
+	{name: "channels/drafts", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		draft, err := expectContent(s, s.chat.SaveMessageDraft(s.primary.AccessToken, s.room.ChannelId, chat.SaveMessageDraftRequest{Content: marker}))
+
+		if err != nil {
+			return err
+		}
+
+		if draft.Content != marker {
+			return fmt.Errorf("expected draft content %q, got %q", marker, draft.Content)
+		}
+
+		if _, err := expectContent(s, s.chat.GetMessageDraft(s.primary.AccessToken, s.room.ChannelId)); err != nil {
+			return err
+		}
+
+		drafts, err := expectContent(s, s.chat.GetMessageDrafts(s.primary.AccessToken))
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(drafts, func(d chat.MessageDraft) bool { return d.ChannelId == s.room.ChannelId }) {
+			return fmt.Errorf("saved draft is not listed")
+		}
+
+		if err := s.expectNoContent(s.chat.DeleteMessageDraft(s.primary.AccessToken, s.room.ChannelId)); err != nil {
+			return err
+		}
+
+		result := s.chat.GetMessageDraft(s.primary.AccessToken, s.room.ChannelId)
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR)
+	}},
+	{name: "channels/unread", requires: []string{"messages/send", "rooms/join"}, run: func(s *suite) error {
+		if _, err := expectContent(s, s.chat.GetUnreadCounts(s.secondary.AccessToken)); err != nil {
+			return err
+		}
+
+		return s.expectNoContent(s.chat.MarkChannelRead(s.secondary.AccessToken, s.room.ChannelId, chat.MarkChannelReadRequest{}))
+	}},
+	{name: "rooms/kick_owner_forbidden", requires: []string{"rooms/join"}, run: func(s *suite) error {
+		result := s.chat.KickUserFromRoom(s.secondary.AccessToken, s.room.Id, s.primary.UserId, chat.KickUserFromRoomRequest{})
+
+		return s.expectError(result, chat.BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR)
+	}},
+	{name: "rooms/kick", requires: []string{"rooms/join"}, run: func(s *suite) error {
+		return s.expectNoContent(s.chat.KickUserFromRoom(s.primary.AccessToken, s.room.Id, s.secondary.UserId, chat.KickUserFromRoomRequest{Reason: marker}))
+	}},
+	{name: "rooms/audit_log", requires: []string{"rooms/create"}, run: func(s *suite) error {
+		_, err := expectContent(s, s.chat.GetRoomAuditLog(s.primary.AccessToken, s.room.Id))
+
+		return err
+	}},
+	{name: "messages/delete", requires: []string{"messages/send"}, run: func(s *suite) error {
+		if err := s.expectNoContent(s.chat.DeleteMessage(s.primary.AccessToken, s.room.ChannelId, s.message.Id)); err != nil {
+			return err
+		}
+
+		result := s.chat.GetChannelMessage(s.primary.AccessToken, s.room.ChannelId, s.message.Id)
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR)
+	}},
+	{name: "idam/logout", requires: []string{"idam/login_secondary"}, run: func(s *suite) error {
+		if err := s.expectNoContent(s.idam.Logout(s.secondary.AccessToken, s.secondary.RefreshToken)); err != nil {
+			return err
+		}
+
+		result := s.idam.RefreshToken(s.secondary.RefreshToken)
+
+		return s.expectError(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR)
+	}},
+}
+
+// expectContent checks the result is a success and the response body matches the schema of T.
+func expectContent[T any](s *suite, result chat.BroChatClientContentResult[T]) (T, error) {
+	if result.ResponseCode != chat.BROCHAT_RESPONSE_CODE_SUCCESS {
+		return result.Content, unexpectedResult(result.BroChatClientResult, chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+	}
+
+	if err := validateJSON(schemas.Generate(reflect.TypeFor[T]()), s.recorder.lastResponse().body); err != nil {
+		return result.Content, err
+	}
+
+	return result.Content, nil
+}
+
+// expectNoContent checks the result is a success. The body, if any, is not validated.
+func (s *suite) expectNoContent(result chat.BroChatClientResult) error {
+	if result.ResponseCode < chat.BROCHAT_RESPONSE_CODE_SUCCESS {
+		return unexpectedResult(result, chat.BROCHAT_RESPONSE_CODE_NO_CONTENT)
+	}
+
+	return nil
+}
+
+// expectError checks the result failed with the code, the HTTP status code matches the code and the response body is a
+// BroChatError.
+func (s *suite) expectError(result chat.BroChatClientResult, code chat.BroChatResponseCode) error {
+	if result.ResponseCode != code {
+		return unexpectedResult(result, code)
+	}
+
+	res := s.recorder.lastResponse()
+
+	if res.statusCode != code.HttpStatusCode() {
+		return fmt.Errorf("expected HTTP status %d for response code %d, got %d", code.HttpStatusCode(), code, res.statusCode)
+	}
+
+	return validateJSON(schemas.For[chat.BroChatError](), res.body)
+}
+
+func unexpectedResult(result chat.BroChatClientResult, expected chat.BroChatResponseCode) error {
+	var details strings.Builder
+
+	if err := result.Err(); err != nil {
+		details.WriteString(": " + err.Error())
+	}
+
+	if len(result.ErrorDetails) > 0 {
+		details.WriteString(" (" + strings.Join(result.ErrorDetails, "; ") + ")")
+	}
+
+	return fmt.Errorf("expected response code %d, got %d%s", expected, result.ResponseCode, details.String())
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,178 @@
+// Package conformance is a test suite which exercises the BroChat API endpoints used by the chat and idam clients against
+// a running server, asserting the shape of every response and the error codes of failed requests. Alternative server
+// implementations can run it to prove they are compatible with the clients in this library.
+//
+// The suite creates data on the server (a room, messages, drafts) and changes the preferences of the accounts it is given,
+// so it should only be run against a test deployment.
+//
+// Usage from a test in the server repository:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Test(t, conformance.Config{
+//			BaseUrl:   "http://localhost:8080",
+//			Primary:   conformance.Credentials{Email: "alice@example.com", Password: "password1"},
+//			Secondary: conformance.Credentials{Email: "bob@example.com", Password: "password2"},
+//		})
+//	}
+package conformance
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/idam"
+)
+
+// Credentials are the login details of an existing, verified account on the server under test.
+type Credentials struct {
+	Email    string
+	Password string
+}
+
+// Config describes the server under test.
+type Config struct {
+	// The base URL of the server. The BroChat and idam endpoints are both expected to be served from it.
+	BaseUrl string
+	// The HTTP client used to make requests. Defaults to http.DefaultClient.
+	HttpClient *http.Client
+	// The account which creates and owns the data used by the suite.
+	Primary Credentials
+	// A second account which interacts with the data created by the primary account. Must not be the same account.
+	Secondary Credentials
+}
+
+// A Result is the outcome of a single check.
+type Result struct {
+	// The name of the check. Example: messages/send
+	Name string
+	// Why the check failed or was skipped. Nil if the check passed.
+	Err error
+	// True if the check was not run because a check it depends on did not pass.
+	Skipped bool
+}
+
+// Passed returns true if the check ran and passed.
+func (r Result) Passed() bool {
+	return !r.Skipped && r.Err == nil
+}
+
+// A Report is the outcome of a run of the suite.
+type Report struct {
+	// The results of every check, in the order they were run.
+	Results []Result
+}
+
+// Passed returns true if every check ran and passed.
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Failures returns the results of the checks which failed or were skipped.
+func (r Report) Failures() []Result {
+	failures := make([]Result, 0)
+
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// Run runs every check of the suite against the server in order.
+func Run(config Config) Report {
+	s := newSuite(config)
+	report := Report{Results: make([]Result, 0, len(checks))}
+
+	for _, c := range checks {
+		report.Results = append(report.Results, s.run(c))
+	}
+
+	return report
+}
+
+// Test runs every check of the suite against the server as a subtest of t.
+func Test(t *testing.T, config Config) {
+	t.Helper()
+
+	s := newSuite(config)
+
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			result := s.run(c)
+
+			switch {
+			case result.Skipped:
+				t.Skip(result.Err)
+			case result.Err != nil:
+				t.Fatal(result.Err)
+			}
+		})
+	}
+}
+
+// suite holds the clients and the state shared by the checks of a single run.
+type suite struct {
+	recorder *recorder
+	chat     *chat.BroChatClient
+	idam     *idam.IdamClient
+	config   Config
+	passed   map[string]bool
+
+	primary   idam.Session
+	secondary idam.Session
+	room      chat.Room
+	message   chat.ChatMessage
+}
+
+func newSuite(config Config) *suite {
+	httpClient := http.DefaultClient
+
+	if config.HttpClient != nil {
+		httpClient = config.HttpClient
+	}
+
+	rec := &recorder{base: httpClient.Transport}
+
+	if rec.base == nil {
+		rec.base = http.DefaultTransport
+	}
+
+	// Copy the client so the recorder does not leak into the caller's client.
+	recordingClient := *httpClient
+	recordingClient.Transport = rec
+
+	return &suite{
+		recorder: rec,
+		chat:     chat.NewBroChatClient(&recordingClient, config.BaseUrl),
+		idam:     idam.NewIdamClient(&recordingClient, config.BaseUrl),
+		config:   config,
+		passed:   make(map[string]bool),
+	}
+}
+
+// run runs the check unless a check it requires has not passed.
+func (s *suite) run(c check) Result {
+	for _, name := range c.requires {
+		if !s.passed[name] {
+			return Result{Name: c.name, Skipped: true, Err: fmt.Errorf("requires %s to pass", name)}
+		}
+	}
+
+	if err := c.run(s); err != nil {
+		return Result{Name: c.name, Err: err}
+	}
+
+	s.passed[c.name] = true
+
+	return Result{Name: c.name}
+}
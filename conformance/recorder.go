@@ -0,0 +1,53 @@
+package conformance
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// recorder is an http.RoundTripper which keeps the last response received, so the raw body can be validated after the
+// clients have decoded it.
+type recorder struct {
+	base http.RoundTripper
+	mu   sync.Mutex
+	last recordedResponse
+}
+
+// recordedResponse is the status code and body of a response.
+type recordedResponse struct {
+	statusCode int
+	body       []byte
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.base.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.last = recordedResponse{statusCode: res.StatusCode, body: body}
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// lastResponse returns the last response received.
+func (r *recorder) lastResponse() recordedResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.last
+}
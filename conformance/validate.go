@@ -0,0 +1,202 @@
+package conformance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/schemas"
+)
+
+// validateJSON decodes the body and validates it against the standalone schema.
+func validateJSON(schema *schemas.Schema, body []byte) error {
+	var value any
+
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+
+	return validate(schema, schema.Defs, value, "$")
+}
+
+// validate checks the decoded JSON value against the subset of JSON Schema produced by the schemas package. References
+// are resolved against defs. Additional properties of objects are allowed so servers may extend the models.
+//
+// Null is accepted for arrays and maps, as that is how Go encodes nil slices and maps and the clients decode both alike.
+func validate(schema *schemas.Schema, defs map[string]*schemas.Schema, value any, path string) error {
+	if schema.Ref != "" {
+		def, ok := defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+
+		if !ok {
+			return fmt.Errorf("%s: unresolved schema reference %s", path, schema.Ref)
+		}
+
+		return validate(def, defs, value, path)
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+
+		for _, option := range schema.OneOf {
+			if validate(option, defs, value, path) == nil {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			return fmt.Errorf("%s: expected exactly one of %d schemas to match, %d matched", path, len(schema.OneOf), matches)
+		}
+
+		return nil
+	}
+
+	if err := validateType(schema, defs, value, path); err != nil {
+		return err
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, schema.Enum)
+	}
+
+	return nil
+}
+
+func validateType(schema *schemas.Schema, defs map[string]*schemas.Schema, value any, path string) error {
+	switch schema.Type {
+	case "":
+		return nil
+	case "null":
+		if value != nil {
+			return typeError(path, schema.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(path, schema.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return typeError(path, schema.Type, value)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return typeError(path, schema.Type, value)
+		}
+	case "string":
+		s, ok := value.(string)
+
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q is not an RFC 3339 date-time", path, s)
+			}
+		}
+
+		if schema.ContentEncoding == "base64" {
+			if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+				return fmt.Errorf("%s: %q is not base64 encoded", path, s)
+			}
+		}
+	case "array":
+		if value == nil {
+			return nil
+		}
+
+		items, ok := value.([]any)
+
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+
+		if schema.Items != nil {
+			for i, item := range items {
+				if err := validate(schema.Items, defs, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		if value == nil && schema.AdditionalProperties != nil {
+			return nil
+		}
+
+		object, ok := value.(map[string]any)
+
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+
+		for name, property := range object {
+			propertySchema := schema.Properties[name]
+
+			if propertySchema == nil {
+				propertySchema = schema.AdditionalProperties
+			}
+
+			if propertySchema == nil {
+				continue
+			}
+
+			if err := validate(propertySchema, defs, property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", path, schema.Type)
+	}
+
+	return nil
+}
+
+// inEnum returns true if the decoded JSON value equals one of the enum values once they are encoded as JSON.
+func inEnum(enum []any, value any) bool {
+	for _, option := range enum {
+		data, err := json.Marshal(option)
+
+		if err != nil {
+			continue
+		}
+
+		var decoded any
+
+		if json.Unmarshal(data, &decoded) == nil && reflect.DeepEqual(decoded, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeError(path string, expected string, value any) error {
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, jsonTypeName(value))
+}
+
+// jsonTypeName returns the JSON type of a decoded value.
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
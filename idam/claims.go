@@ -0,0 +1,99 @@
+package idam
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformedToken is returned when an access token is not a well formed JWT.
+	ErrMalformedToken = errors.New("the access token is not a well formed jwt")
+)
+
+// Claims are the claims carried by an access token. Claims are decoded without verifying the token signature,
+// so they must only be used for client side decisions such as when to refresh and never to authorize anything.
+type Claims struct {
+	// The ID of the user the token was issued to.
+	UserId string
+	// The username of the user the token was issued to.
+	Username string
+	// The issuer of the token.
+	Issuer string
+	// IssuedAtUtc is when the token was issued. Zero if the token has no iat claim.
+	IssuedAtUtc time.Time
+	// ExpiresAtUtc is when the token expires. Zero if the token has no exp claim.
+	ExpiresAtUtc time.Time
+}
+
+// jwtClaims is the wire representation of the registered and BroChat specific claims.
+type jwtClaims struct {
+	Subject           string `json:"sub"`
+	Username          string `json:"username"`
+	PreferredUsername string `json:"preferred_username"`
+	Issuer            string `json:"iss"`
+	IssuedAt          int64  `json:"iat"`
+	ExpiresAt         int64  `json:"exp"`
+}
+
+// ParseClaims decodes the claims of the access token without verifying its signature.
+func ParseClaims(accessToken string) (Claims, error) {
+	parts := strings.Split(accessToken, ".")
+
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+
+	if err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+
+	var raw jwtClaims
+
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+
+	claims := Claims{
+		UserId:   raw.Subject,
+		Username: raw.Username,
+		Issuer:   raw.Issuer,
+	}
+
+	if claims.Username == "" {
+		claims.Username = raw.PreferredUsername
+	}
+
+	if raw.IssuedAt > 0 {
+		claims.IssuedAtUtc = time.Unix(raw.IssuedAt, 0).UTC()
+	}
+
+	if raw.ExpiresAt > 0 {
+		claims.ExpiresAtUtc = time.Unix(raw.ExpiresAt, 0).UTC()
+	}
+
+	return claims, nil
+}
+
+// ExpiresWithin reports whether the token expires within the given duration of now. Tokens without an expiry never expire.
+func (c Claims) ExpiresWithin(d time.Duration) bool {
+	if c.ExpiresAtUtc.IsZero() {
+		return false
+	}
+
+	return time.Now().Add(d).After(c.ExpiresAtUtc)
+}
+
+// IsExpired reports whether the token has expired.
+func (c Claims) IsExpired() bool {
+	return c.ExpiresWithin(0)
+}
+
+// Claims decodes the claims of the session's access token without verifying its signature.
+func (s Session) Claims() (Claims, error) {
+	return ParseClaims(s.AccessToken)
+}
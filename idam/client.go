@@ -0,0 +1,497 @@
+package idam
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// IdamClient is a client for the idam (identity and access management) API. It is used to obtain the access tokens required by the chat.BroChatClient.
+type IdamClient struct {
+	httpClient *http.Client
+	baseUrl    string
+	codec      chat.Codec
+	transport  chat.TransportConfig
+}
+
+// IdamClientOption is a type for the options that can be passed to NewIdamClient.
+type IdamClientOption func(*IdamClient)
+
+// Sets the codec used to encode request bodies and decode response bodies. Defaults to chat.StdCodec.
+func IdamClientOption_Codec(codec chat.Codec) IdamClientOption {
+	return func(c *IdamClient) {
+		c.codec = codec
+	}
+}
+
+// Sets the connection pool settings of the transport. Defaults to chat.DefaultTransportConfig.
+// Only applies when the client creates its own transport, see NewIdamClient.
+func IdamClientOption_Transport(config chat.TransportConfig) IdamClientOption {
+	return func(c *IdamClient) {
+		c.transport = config
+	}
+}
+
+// NewIdamClient creates a new IdamClient with the given http client and base url.
+// If the http client is nil or has no transport of its own, such as http.DefaultClient, the client uses the tuned
+// transport shared by the clients created this way, see chat.WithTunedTransport.
+func NewIdamClient(httpClient *http.Client, baseUrl string, options ...IdamClientOption) *IdamClient {
+	client := &IdamClient{
+		baseUrl:   baseUrl,
+		codec:     chat.StdCodec{},
+		transport: chat.DefaultTransportConfig(),
+	}
+
+	for _, opt := range options {
+		opt(client)
+	}
+
+	client.httpClient = chat.WithTunedTransport(httpClient, client.transport)
+
+	return client
+}
+
+// Register creates a new user account. Depending on the server configuration the user may need to verify their email address before logging in.
+func (c *IdamClient) Register(request RegisterRequest) chat.BroChatClientContentResult[UserRegistration] {
+	url, err := buildUrl(c.baseUrl, REGISTER_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, UserRegistration{})
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, UserRegistration{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, UserRegistration{})
+	}
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, UserRegistration{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, UserRegistration{})
+	}
+
+	var registration UserRegistration
+
+	err = chat.DecodeReader(c.codec, res.Body, &registration)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, UserRegistration{})
+	}
+
+	return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS, registration)
+}
+
+// Login authenticates a user with their email address and password and returns a new session.
+func (c *IdamClient) Login(request LoginRequest) chat.BroChatClientContentResult[Session] {
+	url, err := buildUrl(c.baseUrl, LOGIN_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Session{})
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(request)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Session{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Session{})
+	}
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Session{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Session{})
+	}
+
+	var session Session
+
+	err = chat.DecodeReader(c.codec, res.Body, &session)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Session{})
+	}
+
+	return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS, session)
+}
+
+// RefreshToken exchanges a refresh token for a new session. The old refresh token is invalidated.
+func (c *IdamClient) RefreshToken(refreshToken string) chat.BroChatClientContentResult[Session] {
+	url, err := buildUrl(c.baseUrl, REFRESH_TOKEN_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, Session{})
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(RefreshTokenRequest{RefreshToken: refreshToken})
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Session{})
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, Session{})
+	}
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, Session{})
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, Session{})
+	}
+
+	var session Session
+
+	err = chat.DecodeReader(c.codec, res.Body, &session)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, Session{})
+	}
+
+	return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS, session)
+}
+
+// Logout ends a session by revoking its refresh token. The access token remains valid until it expires.
+func (c *IdamClient) Logout(accessToken string, refreshToken string) chat.BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, LOGOUT_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(LogoutRequest{RefreshToken: refreshToken})
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// ListSessions returns the logged in sessions of the user across all of their devices.
+func (c *IdamClient) ListSessions(accessToken string) chat.BroChatClientContentResult[[]DeviceSession] {
+	url, err := buildUrl(c.baseUrl, SESSIONS_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS, make([]DeviceSession, 0))
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR, make([]DeviceSession, 0))
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestErrorWithContent(err, make([]DeviceSession, 0))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return handleUnsuccessfulStatusCodeWithContent(c.codec, res, make([]DeviceSession, 0))
+	}
+
+	var sessions []DeviceSession
+
+	err = chat.DecodeReader(c.codec, res.Body, &sessions)
+
+	if err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_UNEXEPECTED_RESPONSE_ERROR, make([]DeviceSession, 0))
+	}
+
+	return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS, sessions)
+}
+
+// RevokeSession ends one of the user's sessions by ID, logging out the device it belongs to.
+// The revoked session's access token remains valid until it expires.
+func (c *IdamClient) RevokeSession(accessToken string, sessionId string) chat.BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, strings.Replace(SESSION_URL_SUFFIX, "{sessionId}", sessionId, 1))
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set authorization header to the req
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", defaultTokenType, accessToken))
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// ForgotPassword sends a password reset email to the given address. The result is successful even if no account exists for the address.
+func (c *IdamClient) ForgotPassword(request ForgotPasswordRequest) chat.BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, FORGOT_PASSWORD_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(request)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// VerifyEmail confirms a user's email address using the token sent to it.
+func (c *IdamClient) VerifyEmail(request VerifyEmailRequest) chat.BroChatClientResult {
+	url, err := buildUrl(c.baseUrl, VERIFY_EMAIL_URL_SUFFIX)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_INVALID_HOST_ADDRESS)
+	}
+
+	requestBodyBytes, err := c.codec.Marshal(request)
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Create a new request using http
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+
+	if err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_REQUEST_FORMATTING_ERROR)
+	}
+
+	// Set the content type header
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send req using http Client
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return handleHttpRequestError(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return handleUnsuccessfulStatusCode(c.codec, res)
+	}
+
+	return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+}
+
+// The default token type used for authorization.
+const defaultTokenType = "Bearer"
+
+// makeBroChatClientResult creates a chat.BroChatClientResult with the given code and message.
+func makeBroChatClientResult(code chat.BroChatResponseCode, details ...string) chat.BroChatClientResult {
+	return chat.BroChatClientResult{
+		ResponseCode: code,
+		ErrorDetails: details,
+	}
+}
+
+func makeBroChatClientContentResult[T any](code chat.BroChatResponseCode, content T, details ...string) chat.BroChatClientContentResult[T] {
+	return chat.BroChatClientContentResult[T]{
+		BroChatClientResult: makeBroChatClientResult(code, details...),
+		Content:             content,
+	}
+}
+
+// buildUrl is a helper function that builds a url from a base url and a suffix.
+func buildUrl(baseUrl, suffix string) (string, error) {
+	base, err := url.Parse(baseUrl)
+
+	if err != nil {
+		return "", err
+	}
+
+	suffixUrl, err := url.Parse(suffix)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(suffixUrl).String(), nil
+}
+
+// handleHttpRequestErrorWithContent creates a chat.BroChatClientContentResult generated from an error after attempting an http request.
+func handleHttpRequestErrorWithContent[T any](err error, content T) chat.BroChatClientContentResult[T] {
+	return chat.BroChatClientContentResult[T]{
+		BroChatClientResult: handleHttpRequestError(err),
+		Content:             content,
+	}
+}
+
+// handleHttpRequestError creates a chat.BroChatClientResult generated from an error after attempting an http request.
+func handleHttpRequestError(err error) chat.BroChatClientResult {
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		// If it was a timeout error
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_CONNECTION_TIMEOUT_ERROR)
+	}
+
+	return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_GENERIC_CONNECTION_ERROR)
+}
+
+// handleUnsuccessfulStatusCodeWithContent is a helper function that handles the response from the server when the response is not successful.
+func handleUnsuccessfulStatusCodeWithContent[T any](codec chat.Codec, res *http.Response, content T) chat.BroChatClientContentResult[T] {
+	return chat.BroChatClientContentResult[T]{
+		BroChatClientResult: handleUnsuccessfulStatusCode(codec, res),
+		Content:             content,
+	}
+}
+
+// handleUnsuccessfulStatusCode is a helper function that handles the response from the server when the response is not successful.
+// The idam API reports errors in the same format as the BroChat API.
+func handleUnsuccessfulStatusCode(codec chat.Codec, res *http.Response) chat.BroChatClientResult {
+	var serverSideErr chat.BroChatError
+
+	err := chat.DecodeReader(codec, res.Body, &serverSideErr)
+
+	var result chat.BroChatClientResult
+
+	if err != nil {
+		code := chat.ResponseCodeFromHttpStatus(res.StatusCode)
+
+		// An unexpected success status is still a failure of the operation
+		if code >= chat.BROCHAT_RESPONSE_CODE_SUCCESS {
+			code = chat.BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR
+		}
+
+		result = makeBroChatClientResult(code)
+	} else {
+		result = makeBroChatClientResult(serverSideErr.Code, serverSideErr.AllDetails()...)
+	}
+
+	result.RequestId = serverSideErr.RequestId
+
+	if result.RequestId == "" {
+		result.RequestId = res.Header.Get(chat.REQUEST_ID_HEADER)
+	}
+
+	result.RetryAfter = chat.ParseRetryAfter(res.Header, time.Now())
+	result.RateLimit = chat.ParseRateLimit(res.Header)
+
+	return result
+}
@@ -0,0 +1,12 @@
+package idam
+
+const (
+	REGISTER_URL_SUFFIX        = "/api/idam/register"
+	LOGIN_URL_SUFFIX           = "/api/idam/login"
+	REFRESH_TOKEN_URL_SUFFIX   = "/api/idam/token/refresh"
+	LOGOUT_URL_SUFFIX          = "/api/idam/logout"
+	FORGOT_PASSWORD_URL_SUFFIX = "/api/idam/forgot-password"
+	VERIFY_EMAIL_URL_SUFFIX    = "/api/idam/verify-email"
+	SESSIONS_URL_SUFFIX        = "/api/idam/sessions"
+	SESSION_URL_SUFFIX         = "/api/idam/sessions/{sessionId}"
+)
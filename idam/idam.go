@@ -0,0 +1,84 @@
+package idam
+
+import "time"
+
+// A Session holds the tokens issued to a user when they log in. The access token is passed to the chat.BroChatClient methods.
+type Session struct {
+	// The ID of the authenticated user.
+	UserId string `json:"user_id"`
+	// The short lived token used to authorize requests.
+	AccessToken string `json:"access_token"`
+	// The long lived token used to obtain a new access token when it expires.
+	RefreshToken string `json:"refresh_token"`
+	// The type of the access token. Always "Bearer".
+	TokenType string `json:"token_type"`
+	// The number of seconds the access token is valid for from when it was issued.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// ExpiresAt returns when the access token expires given the time it was issued.
+func (s Session) ExpiresAt(issuedAt time.Time) time.Time {
+	return issuedAt.Add(time.Duration(s.ExpiresIn) * time.Second)
+}
+
+// A DeviceSession describes a logged in session of the user on one of their devices.
+type DeviceSession struct {
+	// The ID of the session.
+	Id string `json:"id"`
+	// The name of the device the session was created on. Example: "Firefox on Windows"
+	DeviceName string `json:"device_name"`
+	// The IP address the session was last used from.
+	IpAddress string `json:"ip_address"`
+	// CreatedAtUtc is when the user logged in.
+	CreatedAtUtc time.Time `json:"created_at_utc"`
+	// LastSeenAtUtc is when the session was last used.
+	LastSeenAtUtc time.Time `json:"last_seen_at_utc"`
+	// True if this is the session that made the request.
+	IsCurrent bool `json:"is_current"`
+}
+
+// A UserRegistration is returned when a new account is registered.
+type UserRegistration struct {
+	// The ID of the new user.
+	UserId string `json:"user_id"`
+	// The username of the new user.
+	Username string `json:"username"`
+	// True if the user must verify their email address before they can log in.
+	EmailVerificationRequired bool `json:"email_verification_required"`
+}
+
+type RegisterRequest struct {
+	// The desired username.
+	Username string `json:"username"`
+	// The user's email address.
+	Email string `json:"email"`
+	// The user's password.
+	Password string `json:"password"`
+}
+
+type LoginRequest struct {
+	// The user's email address.
+	Email string `json:"email"`
+	// The user's password.
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	// The refresh token issued with the session.
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	// The refresh token of the session to end.
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	// The email address of the account to reset.
+	Email string `json:"email"`
+}
+
+type VerifyEmailRequest struct {
+	// The verification token sent to the user's email address.
+	Token string `json:"token"`
+}
@@ -0,0 +1,158 @@
+package idam
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrKeyringItemNotFound is returned by a Keyring when it holds no secret for the user.
+	ErrKeyringItemNotFound = errors.New("the keyring has no secret for the user")
+)
+
+// The keyring user under which a KeyringSessionStore keeps the IDs of its accounts, since keyrings cannot list their items.
+const keyringIndexUser = "brolib:accounts"
+
+// A Keyring stores secrets in the keyring of the operating system, such as the macOS Keychain, the Windows Credential
+// Manager or the Secret Service on Linux. brolib does not bind to a platform keyring itself, so the application
+// supplies one by adapting a keyring library such as github.com/zalando/go-keyring, whose functions have these
+// signatures. Implementations must be safe for concurrent use.
+type Keyring interface {
+	// Get returns the secret stored for the user under the service. Returns ErrKeyringItemNotFound if there is none.
+	Get(service, user string) (string, error)
+	// Set creates or replaces the secret stored for the user under the service.
+	Set(service, user, secret string) error
+	// Delete removes the secret stored for the user under the service. Returns ErrKeyringItemNotFound if there is none.
+	Delete(service, user string) error
+}
+
+// KeyringSessionStore is a SessionStore which keeps each session as a secret in a Keyring, so tokens are encrypted at
+// rest by the operating system. The IDs of the stored accounts are kept in one more secret of the service.
+type KeyringSessionStore struct {
+	mu      sync.Mutex
+	keyring Keyring
+	service string
+}
+
+// NewKeyringSessionStore creates a session store keeping sessions in the keyring under the service, such as the name of the application.
+func NewKeyringSessionStore(keyring Keyring, service string) *KeyringSessionStore {
+	return &KeyringSessionStore{keyring: keyring, service: service}
+}
+
+func (s *KeyringSessionStore) List() ([]StoredSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userIds, err := s.readIndex()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]StoredSession, 0, len(userIds))
+
+	for _, userId := range userIds {
+		secret, err := s.keyring.Get(s.service, userId)
+
+		// The secret may have been removed with the tools of the operating system
+		if errors.Is(err, ErrKeyringItemNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		var session StoredSession
+
+		if err := json.Unmarshal([]byte(secret), &session); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (s *KeyringSessionStore) Save(session StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(session)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.keyring.Set(s.service, session.Session.UserId, string(data)); err != nil {
+		return err
+	}
+
+	userIds, err := s.readIndex()
+
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(userIds, session.Session.UserId) {
+		return nil
+	}
+
+	return s.writeIndex(append(userIds, session.Session.UserId))
+}
+
+func (s *KeyringSessionStore) Delete(userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.keyring.Delete(s.service, userId); err != nil && !errors.Is(err, ErrKeyringItemNotFound) {
+		return err
+	}
+
+	userIds, err := s.readIndex()
+
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(userIds, userId) {
+		return nil
+	}
+
+	return s.writeIndex(slices.DeleteFunc(userIds, func(id string) bool { return id == userId }))
+}
+
+// readIndex loads the IDs of the stored accounts. A missing index is treated as an empty store.
+func (s *KeyringSessionStore) readIndex() ([]string, error) {
+	secret, err := s.keyring.Get(s.service, keyringIndexUser)
+
+	if errors.Is(err, ErrKeyringItemNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var userIds []string
+
+	if err := json.Unmarshal([]byte(secret), &userIds); err != nil {
+		return nil, err
+	}
+
+	return userIds, nil
+}
+
+// writeIndex replaces the IDs of the stored accounts.
+func (s *KeyringSessionStore) writeIndex(userIds []string) error {
+	data, err := json.Marshal(userIds)
+
+	if err != nil {
+		return err
+	}
+
+	return s.keyring.Set(s.service, keyringIndexUser, string(data))
+}
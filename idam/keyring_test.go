@@ -0,0 +1,90 @@
+package idam
+
+import (
+	"sync"
+	"testing"
+)
+
+// memoryKeyring is a Keyring which keeps secrets in memory.
+type memoryKeyring struct {
+	mu      sync.Mutex
+	secrets map[[2]string]string
+}
+
+func newMemoryKeyring() *memoryKeyring {
+	return &memoryKeyring{secrets: make(map[[2]string]string)}
+}
+
+func (k *memoryKeyring) Get(service, user string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	secret, ok := k.secrets[[2]string{service, user}]
+
+	if !ok {
+		return "", ErrKeyringItemNotFound
+	}
+
+	return secret, nil
+}
+
+func (k *memoryKeyring) Set(service, user, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.secrets[[2]string{service, user}] = secret
+	return nil
+}
+
+func (k *memoryKeyring) Delete(service, user string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.secrets[[2]string{service, user}]; !ok {
+		return ErrKeyringItemNotFound
+	}
+
+	delete(k.secrets, [2]string{service, user})
+	return nil
+}
+
+func TestKeyringSessionStore(t *testing.T) {
+	keyring := newMemoryKeyring()
+	store := NewKeyringSessionStore(keyring, "brochat")
+
+	for _, userId := range []string{"alice", "bob"} {
+		if err := store.Save(StoredSession{Session: Session{UserId: userId, AccessToken: userId + "-token"}}); err != nil {
+			t.Fatalf("Save(%s) error = %v", userId, err)
+		}
+	}
+
+	// Saving again replaces the session without listing the account twice
+	if err := store.Save(StoredSession{Session: Session{UserId: "alice", AccessToken: "alice-refreshed"}}); err != nil {
+		t.Fatalf("Save(alice) error = %v", err)
+	}
+
+	if err := store.Delete("bob"); err != nil {
+		t.Fatalf("Delete(bob) error = %v", err)
+	}
+
+	if err := store.Delete("carol"); err != nil {
+		t.Fatalf("Delete(carol) error = %v", err)
+	}
+
+	sessions, err := store.List()
+
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(sessions) != 1 || sessions[0].Session.AccessToken != "alice-refreshed" {
+		t.Fatalf("List() = %+v, want the refreshed session of alice only", sessions)
+	}
+
+	// A secret removed outside of the store is skipped
+	keyring.Delete("brochat", "alice")
+
+	if sessions, err := store.List(); err != nil || len(sessions) != 0 {
+		t.Errorf("List() = %+v, %v, want no sessions", sessions, err)
+	}
+}
@@ -0,0 +1,164 @@
+package idam
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrSessionEnded is returned when the refresh token has been rejected.
+	ErrSessionEnded = errors.New("the session has ended and must be re-established by logging in")
+)
+
+// The amount of time before expiry at which an access token is proactively refreshed.
+const tokenExpirySkew = 30 * time.Second
+
+// SessionTokenProvider is a chat.TokenProvider backed by an idam session. It refreshes the session with the IdamClient
+// when the access token expires or is rejected by the server. Concurrent refreshes are collapsed into a single request.
+type SessionTokenProvider struct {
+	client    *IdamClient
+	mu        sync.Mutex
+	session   Session
+	expiresAt time.Time
+	ended     bool
+	onRefresh func(Session)
+}
+
+// SessionTokenProviderOption is a type for the options that can be passed to NewSessionTokenProvider.
+type SessionTokenProviderOption func(*SessionTokenProvider)
+
+// Sets a callback which is invoked with the new session every time it is refreshed. Useful for persisting the refresh token.
+func SessionTokenProviderOption_OnRefresh(callback func(Session)) SessionTokenProviderOption {
+	return func(p *SessionTokenProvider) {
+		p.onRefresh = callback
+	}
+}
+
+// NewSessionTokenProvider creates a token provider for a session that was issued at the given time.
+func NewSessionTokenProvider(client *IdamClient, session Session, issuedAt time.Time, options ...SessionTokenProviderOption) *SessionTokenProvider {
+	provider := &SessionTokenProvider{
+		client:    client,
+		session:   session,
+		expiresAt: sessionExpiry(session, issuedAt),
+	}
+
+	for _, opt := range options {
+		opt(provider)
+	}
+
+	return provider
+}
+
+// Token implements the chat.TokenProvider interface. The session is refreshed if the access token is about to expire.
+func (p *SessionTokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ended {
+		return "", ErrSessionEnded
+	}
+
+	if !p.expiresAt.IsZero() && time.Now().Add(tokenExpirySkew).After(p.expiresAt) {
+		if err := p.refresh(); err != nil {
+			return "", err
+		}
+	}
+
+	return p.session.AccessToken, nil
+}
+
+// Refresh implements the chat.TokenProvider interface. If another caller has already refreshed the stale token the current token is returned without a new request.
+func (p *SessionTokenProvider) Refresh(staleToken string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ended {
+		return "", ErrSessionEnded
+	}
+
+	if p.session.AccessToken != staleToken {
+		return p.session.AccessToken, nil
+	}
+
+	if err := p.refresh(); err != nil {
+		return "", err
+	}
+
+	return p.session.AccessToken, nil
+}
+
+// Logout revokes the session's refresh token on the server. The provider is ended even if revocation fails,
+// after which Token and Refresh return ErrSessionEnded.
+func (p *SessionTokenProvider) Logout() chat.BroChatClientResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ended {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_SUCCESS)
+	}
+
+	p.ended = true
+
+	return p.client.Logout(p.session.AccessToken, p.session.RefreshToken)
+}
+
+// Session returns a copy of the current session.
+func (p *SessionTokenProvider) Session() Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.session
+}
+
+// refresh exchanges the refresh token for a new session. Must be called with the lock held.
+func (p *SessionTokenProvider) refresh() error {
+	issuedAt := time.Now()
+	result := p.client.RefreshToken(p.session.RefreshToken)
+
+	if err := result.Err(); err != nil {
+		// A rejected refresh token cannot be recovered from without logging in again
+		if result.ResponseCode == chat.BROCHAT_RESPONSE_CODE_UNAUTHORIZED_ERROR {
+			p.ended = true
+			return ErrSessionEnded
+		}
+
+		return err
+	}
+
+	session := result.Content
+
+	// The server may omit values which have not changed
+	if session.UserId == "" {
+		session.UserId = p.session.UserId
+	}
+
+	if session.RefreshToken == "" {
+		session.RefreshToken = p.session.RefreshToken
+	}
+
+	p.session = session
+	p.expiresAt = sessionExpiry(session, issuedAt)
+
+	if p.onRefresh != nil {
+		p.onRefresh(session)
+	}
+
+	return nil
+}
+
+// sessionExpiry returns when the session's access token expires. The expiry claim of the token is used when the
+// session does not include a lifetime. Zero if the expiry is unknown.
+func sessionExpiry(session Session, issuedAt time.Time) time.Time {
+	if session.ExpiresIn > 0 {
+		return session.ExpiresAt(issuedAt)
+	}
+
+	if claims, err := session.Claims(); err == nil {
+		return claims.ExpiresAtUtc
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,58 @@
+package idam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+func TestSessionTokenProvider_ConcurrentRefresh(t *testing.T) {
+	const n = 20
+
+	idamServer := newRefreshServer(t)
+
+	// The first token issued by the refresh server is the only one accepted
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer refreshed-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	provider := NewSessionTokenProvider(NewIdamClient(idamServer.Client(), idamServer.URL),
+		Session{UserId: "alice", AccessToken: "stale", RefreshToken: "r", ExpiresIn: 3600}, time.Now())
+	client := chat.NewTokenProviderHttpClient(api.Client(), provider)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := client.Get(api.URL)
+
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+
+			res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want every request to succeed after the refresh", res.StatusCode)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if token := provider.Session().AccessToken; token != "refreshed-1" {
+		t.Errorf("access token = %q, want the stale token refreshed once", token)
+	}
+}
@@ -0,0 +1,535 @@
+package idam
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrUnknownAccount is returned when an account has not been added to the SessionManager.
+	ErrUnknownAccount = errors.New("the account is not known to the session manager")
+)
+
+// A StoredSession is a session along with the time it was issued, as persisted by a SessionStore.
+type StoredSession struct {
+	// The session tokens.
+	Session Session `json:"session"`
+	// IssuedAtUtc is when the access token was issued.
+	IssuedAtUtc time.Time `json:"issued_at_utc"`
+}
+
+// A SessionStore persists the sessions of the accounts managed by a SessionManager.
+// Implementations must be safe for concurrent use. Sessions are kept in memory by MemorySessionStore, in a file by
+// FileSessionStore and in the keyring of the operating system by KeyringSessionStore.
+type SessionStore interface {
+	// List returns all stored sessions.
+	List() ([]StoredSession, error)
+	// Save creates or replaces the stored session for the session's user.
+	Save(session StoredSession) error
+	// Delete removes the stored session for the user. Deleting a session that does not exist is not an error.
+	Delete(userId string) error
+}
+
+// MemorySessionStore is a SessionStore which keeps sessions in memory only.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]StoredSession
+}
+
+// NewMemorySessionStore creates an empty in memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]StoredSession)}
+}
+
+func (s *MemorySessionStore) List() ([]StoredSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make([]StoredSession, 0, len(s.sessions))
+
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (s *MemorySessionStore) Save(session StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[session.Session.UserId] = session
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, userId)
+	return nil
+}
+
+// FileSessionStore is a SessionStore which keeps sessions in a JSON file readable only by the current user.
+type FileSessionStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSessionStore creates a session store backed by the file at the given path. The file is created on the first save.
+func NewFileSessionStore(path string) *FileSessionStore {
+	return &FileSessionStore{path: path}
+}
+
+func (s *FileSessionStore) List() ([]StoredSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]StoredSession, 0, len(sessions))
+
+	for _, session := range sessions {
+		list = append(list, session)
+	}
+
+	return list, nil
+}
+
+func (s *FileSessionStore) Save(session StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+
+	if err != nil {
+		return err
+	}
+
+	sessions[session.Session.UserId] = session
+
+	return s.write(sessions)
+}
+
+func (s *FileSessionStore) Delete(userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := sessions[userId]; !ok {
+		return nil
+	}
+
+	delete(sessions, userId)
+
+	return s.write(sessions)
+}
+
+// read loads the sessions from the file. A missing file is treated as an empty store.
+func (s *FileSessionStore) read() (map[string]StoredSession, error) {
+	sessions := make(map[string]StoredSession)
+	data, err := os.ReadFile(s.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return sessions, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// write replaces the file atomically so a crash cannot leave a partially written store behind.
+func (s *FileSessionStore) write(sessions map[string]StoredSession) error {
+	data, err := json.MarshalIndent(sessions, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// account holds the per account state of a SessionManager.
+type account struct {
+	provider *SessionTokenProvider
+	client   *chat.BroChatClient
+	feed     *chat.FeedClient
+}
+
+// SessionManager manages the sessions of multiple accounts and the BroChatClient and FeedClient used by each of them.
+// Sessions are persisted to the SessionStore, including when they are refreshed.
+//
+// The feed client of an account is not connected by the manager. Applications run the feed of the active account and
+// move it between accounts in the callback set with SessionManagerOption_OnSwitch. The feed client of an account is
+// closed when the account is removed, replaced or logged out.
+type SessionManager struct {
+	idamClient  *IdamClient
+	httpClient  *http.Client
+	chatBaseUrl string
+	store       SessionStore
+	feedOptions []chat.FeedClientOption
+	onSwitch    func(previousUserId, userId string)
+	onLogout    func(userId string)
+	mu          sync.RWMutex
+	accounts    map[string]*account
+	active      string
+	// Switches waiting to be passed to onSwitch, and whether a goroutine is passing them.
+	switches    []accountSwitch
+	dispatching bool
+}
+
+// accountSwitch is a change of the active account waiting to be passed to the switch callback.
+type accountSwitch struct {
+	previousUserId string
+	userId         string
+}
+
+// SessionManagerOption is a type for the options that can be passed to NewSessionManager.
+type SessionManagerOption func(*SessionManager)
+
+// Sets a callback which is invoked when the active account changes. Either user ID may be empty.
+// The callback is called once per switch, in the order of the switches and never concurrently, so it is safe to tear
+// down the previous account's feed connection and establish the new one within the callback. No lock is held while it
+// runs, so it may call Switch, Add or Remove; the switches they make are passed to the callback after it returns.
+func SessionManagerOption_OnSwitch(callback func(previousUserId, userId string)) SessionManagerOption {
+	return func(m *SessionManager) {
+		m.onSwitch = callback
+	}
+}
+
+// Sets a callback which is invoked when an account is logged out, before its session is revoked and its feed client is
+// closed. Use it to tear down any other connection of the account so it does not try to reconnect with the revoked session.
+func SessionManagerOption_OnLogout(callback func(userId string)) SessionManagerOption {
+	return func(m *SessionManager) {
+		m.onLogout = callback
+	}
+}
+
+// Sets the options of the FeedClient created for each account.
+func SessionManagerOption_FeedClientOptions(options ...chat.FeedClientOption) SessionManagerOption {
+	return func(m *SessionManager) {
+		m.feedOptions = options
+	}
+}
+
+// NewSessionManager creates a session manager and loads the accounts already held by the store.
+// The http client is used for the BroChatClient and FeedClient of each account and chatBaseUrl is the base url of the chat API.
+func NewSessionManager(idamClient *IdamClient, httpClient *http.Client, chatBaseUrl string, store SessionStore, options ...SessionManagerOption) (*SessionManager, error) {
+	m := &SessionManager{
+		idamClient:  idamClient,
+		httpClient:  httpClient,
+		chatBaseUrl: chatBaseUrl,
+		store:       store,
+		accounts:    make(map[string]*account),
+	}
+
+	for _, opt := range options {
+		opt(m)
+	}
+
+	sessions, err := store.List()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stored := range sessions {
+		m.accounts[stored.Session.UserId] = m.newAccount(stored)
+	}
+
+	return m, nil
+}
+
+// Login logs in to an account and adds it to the manager. The account becomes active if there is no active account.
+func (m *SessionManager) Login(request LoginRequest) chat.BroChatClientContentResult[Session] {
+	issuedAt := time.Now().UTC()
+	result := m.idamClient.Login(request)
+
+	if result.Err() != nil {
+		return result
+	}
+
+	if err := m.Add(result.Content, issuedAt); err != nil {
+		return makeBroChatClientContentResult(chat.BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR, Session{}, err.Error())
+	}
+
+	return result
+}
+
+// Add adds a session to the manager, replacing any existing session for the same user.
+// The account becomes active if there is no active account.
+func (m *SessionManager) Add(session Session, issuedAt time.Time) error {
+	stored := StoredSession{Session: session, IssuedAtUtc: issuedAt.UTC()}
+
+	// The session is saved with the lock held so a refresh of a replaced account cannot overwrite it
+	m.mu.Lock()
+
+	if err := m.store.Save(stored); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	replaced := m.accounts[session.UserId]
+	m.accounts[session.UserId] = m.newAccount(stored)
+	activate := m.active == ""
+	m.mu.Unlock()
+
+	if replaced != nil {
+		replaced.feed.Close()
+	}
+
+	if activate {
+		return m.Switch(session.UserId)
+	}
+
+	return nil
+}
+
+// Remove removes an account from the manager and the store and closes its feed client. If the account was active there
+// will be no active account. The session is not revoked, see Logout.
+func (m *SessionManager) Remove(userId string) error {
+	// The session is deleted with the lock held so a refresh of the removed account cannot save it again
+	m.mu.Lock()
+
+	if err := m.store.Delete(userId); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	removed := m.accounts[userId]
+	delete(m.accounts, userId)
+	wasActive := m.active == userId
+	m.mu.Unlock()
+
+	if removed != nil {
+		removed.feed.Close()
+	}
+
+	if wasActive {
+		return m.Switch("")
+	}
+
+	return nil
+}
+
+// Logout signs an account out. The logout callback is invoked, the session is revoked on the server and the account is removed.
+// The account is removed even if revocation fails, in which case the failure is returned.
+func (m *SessionManager) Logout(userId string) chat.BroChatClientResult {
+	provider, ok := m.TokenProvider(userId)
+
+	if !ok {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR, ErrUnknownAccount.Error())
+	}
+
+	if m.onLogout != nil {
+		m.onLogout(userId)
+	}
+
+	// Stop the feed before revoking the session so it does not reconnect with it
+	if feed, ok := m.FeedClient(userId); ok {
+		feed.Close()
+	}
+
+	result := provider.Logout()
+
+	if err := m.Remove(userId); err != nil {
+		return makeBroChatClientResult(chat.BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR, err.Error())
+	}
+
+	return result
+}
+
+// Accounts returns the user IDs of all managed accounts in ascending order.
+func (m *SessionManager) Accounts() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userIds := make([]string, 0, len(m.accounts))
+
+	for userId := range m.accounts {
+		userIds = append(userIds, userId)
+	}
+
+	sort.Strings(userIds)
+
+	return userIds
+}
+
+// Client returns the BroChatClient for the account. Requests made with the client are authorized with the account's
+// session, so the access token passed to its methods is ignored. The second return value will be false if the account is unknown.
+func (m *SessionManager) Client(userId string) (*chat.BroChatClient, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, ok := m.accounts[userId]
+
+	if !ok {
+		return nil, false
+	}
+
+	return acc.client, true
+}
+
+// FeedClient returns the FeedClient for the account. Its connections are authorized with the account's session, so
+// the access token passed to Run is ignored. The second return value will be false if the account is unknown.
+func (m *SessionManager) FeedClient(userId string) (*chat.FeedClient, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, ok := m.accounts[userId]
+
+	if !ok {
+		return nil, false
+	}
+
+	return acc.feed, true
+}
+
+// TokenProvider returns the token provider for the account, for use with connections other than the BroChatClient and FeedClient.
+// The second return value will be false if the account is unknown.
+func (m *SessionManager) TokenProvider(userId string) (*SessionTokenProvider, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, ok := m.accounts[userId]
+
+	if !ok {
+		return nil, false
+	}
+
+	return acc.provider, true
+}
+
+// Active returns the user ID and BroChatClient of the active account. The last return value will be false if there is no active account.
+func (m *SessionManager) Active() (string, *chat.BroChatClient, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, ok := m.accounts[m.active]
+
+	if !ok {
+		return "", nil, false
+	}
+
+	return m.active, acc.client, true
+}
+
+// Switch makes the account active. An empty user ID leaves no account active.
+// Returns ErrUnknownAccount if the account has not been added. The switch callback has been called when Switch
+// returns, unless another switch was being passed to it, such as when Switch is called from the callback.
+func (m *SessionManager) Switch(userId string) error {
+	m.mu.Lock()
+
+	if _, ok := m.accounts[userId]; !ok && userId != "" {
+		m.mu.Unlock()
+		return ErrUnknownAccount
+	}
+
+	previous := m.active
+	m.active = userId
+
+	if previous != userId && m.onSwitch != nil {
+		m.switches = append(m.switches, accountSwitch{previousUserId: previous, userId: userId})
+	}
+
+	dispatch := !m.dispatching && len(m.switches) > 0
+	m.dispatching = m.dispatching || dispatch
+	m.mu.Unlock()
+
+	if dispatch {
+		m.dispatchSwitches()
+	}
+
+	return nil
+}
+
+// dispatchSwitches passes the waiting switches to the switch callback one at a time, including those made by the
+// callback itself, until none are left.
+func (m *SessionManager) dispatchSwitches() {
+	for {
+		m.mu.Lock()
+
+		if len(m.switches) == 0 {
+			m.dispatching = false
+			m.mu.Unlock()
+			return
+		}
+
+		next := m.switches[0]
+		m.switches = m.switches[1:]
+		m.mu.Unlock()
+
+		m.onSwitch(next.previousUserId, next.userId)
+	}
+}
+
+// newAccount creates the token provider, client and feed client for a stored session.
+// Refreshed sessions are saved to the store. Failing to save is not fatal as the refreshed session remains usable in memory.
+// Clients handed out before the account was removed or replaced keep refreshing, so their sessions are not saved.
+func (m *SessionManager) newAccount(stored StoredSession) *account {
+	acc := &account{}
+
+	acc.provider = NewSessionTokenProvider(m.idamClient, stored.Session, stored.IssuedAtUtc,
+		SessionTokenProviderOption_OnRefresh(func(session Session) {
+			m.mu.RLock()
+			defer m.mu.RUnlock()
+
+			if m.accounts[session.UserId] != acc {
+				return
+			}
+
+			_ = m.store.Save(StoredSession{Session: session, IssuedAtUtc: time.Now().UTC()})
+		}))
+
+	httpClient := chat.NewTokenProviderHttpClient(m.httpClient, acc.provider)
+	acc.client = chat.NewBroChatClient(httpClient, m.chatBaseUrl)
+	acc.feed = chat.NewFeedClient(httpClient, m.chatBaseUrl, m.feedOptions...)
+
+	return acc
+}
@@ -0,0 +1,210 @@
+package idam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// newRefreshServer starts an idam server whose refresh endpoint issues a new access token for every request.
+func newRefreshServer(t *testing.T) *httptest.Server {
+	var issued atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+REFRESH_TOKEN_URL_SUFFIX, func(w http.ResponseWriter, r *http.Request) {
+		var request RefreshTokenRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Session{
+			AccessToken:  fmt.Sprintf("refreshed-%d", issued.Add(1)),
+			RefreshToken: request.RefreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    3600,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestSessionManager(t *testing.T, store SessionStore, options ...SessionManagerOption) *SessionManager {
+	t.Helper()
+
+	server := newRefreshServer(t)
+
+	m, err := NewSessionManager(NewIdamClient(server.Client(), server.URL), server.Client(), "https://chat.example.com", store, options...)
+
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	return m
+}
+
+func storedAccessTokens(t *testing.T, store SessionStore) map[string]string {
+	t.Helper()
+
+	sessions, err := store.List()
+
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	tokens := make(map[string]string)
+
+	for _, stored := range sessions {
+		tokens[stored.Session.UserId] = stored.Session.AccessToken
+	}
+
+	return tokens
+}
+
+func TestSessionManager_RefreshAfterRemove(t *testing.T) {
+	store := NewMemorySessionStore()
+	m := newTestSessionManager(t, store)
+
+	if err := m.Add(Session{UserId: "alice", AccessToken: "a1", RefreshToken: "r", ExpiresIn: 3600}, time.Now()); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	provider, _ := m.TokenProvider("alice")
+
+	if err := m.Remove("alice"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	// A client handed out before the removal rejects the token and refreshes
+	if _, err := provider.Refresh("a1"); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if tokens := storedAccessTokens(t, store); len(tokens) != 0 {
+		t.Errorf("stored sessions = %v, want the removed account to stay removed", tokens)
+	}
+}
+
+func TestSessionManager_RefreshAfterReplace(t *testing.T) {
+	store := NewMemorySessionStore()
+	m := newTestSessionManager(t, store)
+
+	if err := m.Add(Session{UserId: "alice", AccessToken: "old", RefreshToken: "r-old", ExpiresIn: 3600}, time.Now()); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	replaced, _ := m.TokenProvider("alice")
+
+	if err := m.Add(Session{UserId: "alice", AccessToken: "new", RefreshToken: "r-new", ExpiresIn: 3600}, time.Now()); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if _, err := replaced.Refresh("old"); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if tokens := storedAccessTokens(t, store); tokens["alice"] != "new" {
+		t.Errorf("stored access token = %q, want the session which replaced it", tokens["alice"])
+	}
+
+	current, _ := m.TokenProvider("alice")
+
+	if _, err := current.Refresh("new"); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if tokens := storedAccessTokens(t, store); tokens["alice"] == "new" {
+		t.Errorf("stored access token = %q, want the refreshed session of the current account", tokens["alice"])
+	}
+}
+
+func TestSessionManager_SwitchFromCallback(t *testing.T) {
+	var m *SessionManager
+	var switches []string
+
+	m = newTestSessionManager(t, NewMemorySessionStore(), SessionManagerOption_OnSwitch(func(previousUserId, userId string) {
+		switches = append(switches, previousUserId+">"+userId)
+
+		// Removing the account logged out on another device switches away from it from within the callback
+		if userId == "bob" {
+			if err := m.Remove("bob"); err != nil {
+				t.Errorf("Remove() error = %v", err)
+			}
+		}
+	}))
+
+	for _, userId := range []string{"alice", "bob"} {
+		if err := m.Add(Session{UserId: userId, AccessToken: userId, ExpiresIn: 3600}, time.Now()); err != nil {
+			t.Fatalf("Add(%s) error = %v", userId, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.Switch("bob") }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Switch() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Switch() deadlocked when the callback removed the account")
+	}
+
+	want := []string{">alice", "alice>bob", "bob>"}
+
+	if fmt.Sprint(switches) != fmt.Sprint(want) {
+		t.Errorf("switches = %v, want %v", switches, want)
+	}
+
+	if userId, _, ok := m.Active(); ok {
+		t.Errorf("Active() = %q, want no active account", userId)
+	}
+}
+
+func TestSessionManager_FeedClientClosedOnRemove(t *testing.T) {
+	m := newTestSessionManager(t, NewMemorySessionStore())
+
+	if err := m.Add(Session{UserId: "alice", AccessToken: "a", ExpiresIn: 3600}, time.Now()); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	feed, ok := m.FeedClient("alice")
+
+	if !ok || feed == nil {
+		t.Fatalf("FeedClient() = %v, %v, want the feed client of the account", feed, ok)
+	}
+
+	// The feed points at a server which is not listening, so Run keeps reconnecting until it is closed
+	done := make(chan error, 1)
+	go func() { done <- feed.Run(context.Background(), "", func(*chat.FeedMessage) error { return nil }) }()
+
+	if err := m.Remove("alice"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil after the account was removed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the feed client of the removed account kept running")
+	}
+
+	if _, ok := m.FeedClient("alice"); ok {
+		t.Errorf("FeedClient() of a removed account ok = true")
+	}
+}
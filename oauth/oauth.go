@@ -0,0 +1,87 @@
+// Package oauth adapts golang.org/x/oauth2 token sources for use with the BroChat clients, allowing deployments
+// that use a standard OAuth2/OIDC identity provider in place of idam.
+package oauth
+
+import (
+	"sync"
+
+	"github.com/dmars8047/brolib/chat"
+	"golang.org/x/oauth2"
+)
+
+// TokenSourceProvider is a chat.TokenProvider backed by an oauth2.TokenSource.
+type TokenSourceProvider struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	renew  func() oauth2.TokenSource
+}
+
+// TokenSourceProviderOption is a type for the options that can be passed to NewTokenSourceProvider.
+type TokenSourceProviderOption func(*TokenSourceProvider)
+
+// Sets a function which creates a new token source when the server rejects a token before it has expired.
+// Without this option a rejected token is only replaced once the token source itself considers it expired.
+// Usage: TokenSourceProviderOption_Renew(func() oauth2.TokenSource { return config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}) })
+func TokenSourceProviderOption_Renew(renew func() oauth2.TokenSource) TokenSourceProviderOption {
+	return func(p *TokenSourceProvider) {
+		p.renew = renew
+	}
+}
+
+// NewTokenSourceProvider creates a token provider from the token source.
+// The token source is wrapped with oauth2.ReuseTokenSource so that tokens are cached until they expire.
+func NewTokenSourceProvider(source oauth2.TokenSource, options ...TokenSourceProviderOption) *TokenSourceProvider {
+	provider := &TokenSourceProvider{
+		source: oauth2.ReuseTokenSource(nil, source),
+	}
+
+	for _, opt := range options {
+		opt(provider)
+	}
+
+	return provider
+}
+
+// Token implements the chat.TokenProvider interface.
+func (p *TokenSourceProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token, err := p.source.Token()
+
+	if err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+// Refresh implements the chat.TokenProvider interface. If the token source still returns the stale token and a
+// renew function was provided, the token source is replaced with a new one.
+func (p *TokenSourceProvider) Refresh(staleToken string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token, err := p.source.Token()
+
+	if err != nil {
+		return "", err
+	}
+
+	if token.AccessToken != staleToken || p.renew == nil {
+		return token.AccessToken, nil
+	}
+
+	p.source = oauth2.ReuseTokenSource(nil, p.renew())
+
+	token, err = p.source.Token()
+
+	if err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+// Ensure TokenSourceProvider satisfies the chat.TokenProvider interface.
+var _ chat.TokenProvider = (*TokenSourceProvider)(nil)
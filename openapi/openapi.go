@@ -0,0 +1,236 @@
+// Package openapi describes the BroChat API as an OpenAPI 3.1 document, so non-Go clients and API gateways can consume
+// the same contract as the chat and idam clients. The document is generated from the URL suffix constants and the
+// model structs, so it cannot drift from the Go clients.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/schemas"
+	"github.com/dmars8047/brolib/serverutil"
+)
+
+// The OpenAPI version of the generated document.
+const OPENAPI_VERSION = "3.1.0"
+
+// The name of the bearer token security scheme in the generated document.
+const BEARER_SECURITY_SCHEME = "bearerAuth"
+
+// A Schema is a JSON Schema within a Document. OpenAPI 3.1 schemas are JSON Schema, so the schemas package is reused.
+type Schema = schemas.Schema
+
+// A Document is an OpenAPI document. Only the parts of the specification used by the BroChat API are modelled.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the metadata of a Document.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// A Server is a base URL the API is served from.
+type Server struct {
+	Url string `json:"url"`
+}
+
+// A Tag groups related operations.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// A PathItem maps the lower case HTTP methods supported by a path to their operations.
+type PathItem map[string]*Operation
+
+// An Operation is a single endpoint.
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// A Parameter is a path or query string parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// A RequestBody describes the body of a request by media type.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// A Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// A MediaType describes a body with a given content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable parts of a Document.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// A SecurityScheme describes how operations are authenticated.
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// SpecOption is a type for the options that can be passed to Spec.
+type SpecOption func(*Document)
+
+// Sets the version of the API reported in the document info. Defaults to 1.0.0.
+func SpecOption_Version(version string) SpecOption {
+	return func(d *Document) {
+		d.Info.Version = version
+	}
+}
+
+// Adds a base URL the API is served from. May be passed several times.
+func SpecOption_ServerUrl(url string) SpecOption {
+	return func(d *Document) {
+		d.Servers = append(d.Servers, Server{Url: url})
+	}
+}
+
+// pathParamPattern matches the :name and {name} path parameter forms used by the URL suffix constants.
+var pathParamPattern = regexp.MustCompile(`:(\w+)|\{(\w+)\}`)
+
+// Spec generates the OpenAPI document of the BroChat API.
+func Spec(options ...SpecOption) *Document {
+	doc := &Document{
+		OpenAPI: OPENAPI_VERSION,
+		Info:    Info{Title: "BroChat API", Version: "1.0.0"},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{BEARER_SECURITY_SCHEME: {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	for _, opt := range options {
+		opt(doc)
+	}
+
+	builder := schemas.NewGenerator("#/components/schemas/")
+	errorSchema := builder.SchemaFor(reflect.TypeFor[chat.BroChatError]())
+	tags := make(map[string]bool)
+
+	for _, r := range routes {
+		path := pathParamPattern.ReplaceAllString(r.path, "{$1$2}")
+		op := &Operation{
+			OperationId: r.operationId,
+			Summary:     r.summary,
+			Tags:        []string{r.tag},
+			Parameters:  make([]Parameter, 0),
+			Responses: map[string]Response{
+				"default": {Description: "An error.", Content: jsonContent(errorSchema)},
+			},
+		}
+
+		for _, match := range pathParamPattern.FindAllStringSubmatch(r.path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     match[1] + match[2],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+
+		for _, p := range r.query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        p.name,
+				In:          "query",
+				Description: p.description,
+				Required:    p.required,
+				Schema:      builder.SchemaFor(reflect.TypeOf(p.value)),
+			})
+		}
+
+		switch {
+		case r.upload:
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"multipart/form-data": {Schema: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"file":        {Type: "string", ContentEncoding: "binary"},
+						"kind":        builder.SchemaFor(reflect.TypeFor[chat.AttachmentKind]()),
+						"duration_ms": {Type: "integer"},
+					},
+					Required: []string{"file"},
+				}},
+			}}
+		case r.body != nil:
+			op.RequestBody = &RequestBody{Required: !r.optionalBody, Content: jsonContent(builder.SchemaFor(reflect.TypeOf(r.body)))}
+		}
+
+		success := Response{Description: http.StatusText(r.status)}
+
+		switch {
+		case r.download:
+			success.Content = map[string]MediaType{"application/octet-stream": {Schema: &Schema{Type: "string", ContentEncoding: "binary"}}}
+		case r.response != nil:
+			success.Content = jsonContent(builder.SchemaFor(reflect.TypeOf(r.response)))
+		}
+
+		op.Responses[strconv.Itoa(r.status)] = success
+
+		if !r.public {
+			op.Security = []map[string][]string{{BEARER_SECURITY_SCHEME: {}}}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+
+		doc.Paths[path][strings.ToLower(r.method)] = op
+
+		if !tags[r.tag] {
+			tags[r.tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: r.tag})
+		}
+	}
+
+	doc.Components.Schemas = builder.Definitions()
+
+	return doc
+}
+
+// Handler returns an HTTP handler which serves the OpenAPI document as JSON. The document is generated once.
+func Handler(options ...SpecOption) http.Handler {
+	doc := Spec(options...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverutil.WriteJSON(w, http.StatusOK, doc)
+	})
+}
+
+// jsonContent returns the content of a JSON body with the schema.
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
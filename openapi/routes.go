@@ -0,0 +1,277 @@
+package openapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/idam"
+)
+
+// A route describes a single BroChat API endpoint as called by the chat.BroChatClient and idam.IdamClient.
+type route struct {
+	method      string
+	path        string
+	operationId string
+	summary     string
+	tag         string
+	// True if the endpoint does not require a bearer token.
+	public bool
+	query  []param
+	// A zero value of the JSON request body type. Nil if the endpoint has no request body.
+	body any
+	// True if the request body is optional.
+	optionalBody bool
+	// True if the request body is a multipart file upload.
+	upload bool
+	status int
+	// A zero value of the JSON response body type. Nil if the endpoint has no response body.
+	response any
+	// True if the response body is raw file content.
+	download bool
+}
+
+// A param is a query string parameter of a route.
+type param struct {
+	name string
+	// A zero value of the Go type the parameter is formatted from.
+	value       any
+	description string
+	required    bool
+}
+
+const (
+	tagUsers    = "users"
+	tagChannels = "channels"
+	tagMessages = "messages"
+	tagFriends  = "friends"
+	tagRooms    = "rooms"
+	tagIdam     = "idam"
+	tagServer   = "server"
+	tagVoice    = "voice"
+)
+
+// Common query parameters.
+var (
+	pageParam     = param{name: "page", value: uint64(0), description: "The page of results to return."}
+	pageSizeParam = param{name: "page-size", value: uint64(0), description: "The size of each page. Anything over 100 will be set to 100."}
+)
+
+// routes lists every endpoint of the API. Paths come from the URL suffix constants so the document follows the clients.
+var routes = []route{
+	// Users
+	{method: http.MethodGet, path: chat.GET_USER_URL_SUFFIX, operationId: "getUser", summary: "Returns the authenticated user.", tag: tagUsers,
+		status: http.StatusOK, response: chat.User{}},
+	{method: http.MethodPut, path: chat.USER_STATUS_URL_SUFFIX, operationId: "setStatus", summary: "Sets the custom status of the authenticated user.", tag: tagUsers,
+		body: chat.SetStatusRequest{}, status: http.StatusOK, response: chat.UserStatus{}},
+	{method: http.MethodDelete, path: chat.USER_STATUS_URL_SUFFIX, operationId: "clearStatus", summary: "Clears the custom status of the authenticated user.", tag: tagUsers,
+		status: http.StatusNoContent},
+	{method: http.MethodPut, path: chat.USER_PRESENCE_URL_SUFFIX, operationId: "setPresence", summary: "Sets the presence of the authenticated user.", tag: tagUsers,
+		body: chat.SetPresenceRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.NOTIFICATION_PREFERENCES_URL_SUFFIX, operationId: "getNotificationPreferences", summary: "Returns the notification preferences of the authenticated user.", tag: tagUsers,
+		status: http.StatusOK, response: chat.NotificationPreferences{}},
+	{method: http.MethodPut, path: chat.NOTIFICATION_PREFERENCES_URL_SUFFIX, operationId: "updateNotificationPreferences", summary: "Replaces the notification preferences of the authenticated user.", tag: tagUsers,
+		body: chat.NotificationPreferences{}, status: http.StatusOK, response: chat.NotificationPreferences{}},
+	{method: http.MethodGet, path: chat.PRIVACY_SETTINGS_URL_SUFFIX, operationId: "getPrivacySettings", summary: "Returns the privacy settings of the authenticated user.", tag: tagUsers,
+		status: http.StatusOK, response: chat.PrivacySettings{}},
+	{method: http.MethodPut, path: chat.PRIVACY_SETTINGS_URL_SUFFIX, operationId: "updatePrivacySettings", summary: "Replaces the privacy settings of the authenticated user.", tag: tagUsers,
+		body: chat.PrivacySettings{}, status: http.StatusOK, response: chat.PrivacySettings{}},
+	{method: http.MethodPost, path: chat.SIGNING_KEYS_URL_SUFFIX, operationId: "addSigningKey", summary: "Registers a public key the authenticated user signs their messages with.", tag: tagUsers,
+		body: chat.AddSigningKeyRequest{}, status: http.StatusCreated, response: chat.SigningKey{}},
+	{method: http.MethodDelete, path: chat.SIGNING_KEY_URL_SUFFIX, operationId: "revokeSigningKey", summary: "Revokes a signing key of the authenticated user.", tag: tagUsers,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.USER_SIGNING_KEYS_URL_SUFFIX, operationId: "getSigningKeys", summary: "Returns the signing keys of a user, including revoked keys.", tag: tagUsers,
+		status: http.StatusOK, response: []chat.SigningKey{}},
+	{method: http.MethodPost, path: chat.DATA_EXPORTS_URL_SUFFIX, operationId: "requestDataExport", summary: "Requests an archive of the profile, relationships and messages of the authenticated user.", tag: tagUsers,
+		status: http.StatusAccepted, response: chat.DataExport{}},
+	{method: http.MethodGet, path: chat.DATA_EXPORT_URL_SUFFIX, operationId: "getDataExport", summary: "Returns a data export of the authenticated user.", tag: tagUsers,
+		status: http.StatusOK, response: chat.DataExport{}},
+	{method: http.MethodGet, path: chat.DOWNLOAD_DATA_EXPORT_URL_SUFFIX, operationId: "downloadDataExport", summary: "Downloads the zip archive of a ready data export.", tag: tagUsers,
+		status: http.StatusOK, download: true},
+	{method: http.MethodGet, path: chat.GET_USERS_URL_SUFFIX, operationId: "getUsers", summary: "Returns a page of users.", tag: tagUsers,
+		query: []param{
+			{name: "exclude-self", value: false, description: "Excludes the authenticated user."},
+			{name: "exclude-friends", value: false, description: "Excludes the friends of the authenticated user."},
+			{name: "online-only", value: false, description: "Only returns users who are visibly online."},
+			{name: "sort", value: chat.UserSortOrder(""), description: "The order of the users. Defaults to the server's order."},
+			{name: "username-filter", value: "", description: "Only returns users whose username matches the filter."},
+			pageParam, pageSizeParam,
+		},
+		status: http.StatusOK, response: []chat.UserInfo{}},
+	{method: http.MethodPost, path: chat.GET_USERS_BY_IDS_URL_SUFFIX, operationId: "getUsersByIds", summary: "Returns the users with the given IDs.", tag: tagUsers,
+		body: chat.GetUsersByIdsRequest{}, status: http.StatusOK, response: []chat.UserInfo{}},
+	{method: http.MethodPost, path: chat.REPORT_USER_URL_SUFFIX, operationId: "reportUser", summary: "Reports a user to the moderators.", tag: tagUsers,
+		body: chat.ReportRequest{}, status: http.StatusCreated, response: chat.Report{}},
+
+	// Channels
+	{method: http.MethodGet, path: chat.GET_CHANNEL_URL_SUFFIX, operationId: "getChannel", summary: "Returns a channel.", tag: tagChannels,
+		status: http.StatusOK, response: chat.Channel{}},
+	{method: http.MethodPut, path: chat.GET_DIRECT_MESSAGE_CHANNEL_URL_SUFFIX, operationId: "getDirectMessageChannel", summary: "Returns the direct message channel with a user, creating it if needed.", tag: tagChannels,
+		status: http.StatusOK, response: chat.Channel{}},
+	{method: http.MethodPost, path: chat.CREATE_GROUP_DIRECT_MESSAGE_URL_SUFFIX, operationId: "createGroupDirectMessage", summary: "Creates a group direct message channel.", tag: tagChannels,
+		body: chat.CreateGroupDirectMessageRequest{}, status: http.StatusCreated, response: chat.Channel{}},
+	{method: http.MethodPut, path: chat.GROUP_DIRECT_MESSAGE_PARTICIPANT_URL_SUFFIX, operationId: "addGroupDirectMessageParticipant", summary: "Adds a participant to a group direct message channel.", tag: tagChannels,
+		status: http.StatusNoContent},
+	{method: http.MethodDelete, path: chat.GROUP_DIRECT_MESSAGE_PARTICIPANT_URL_SUFFIX, operationId: "removeGroupDirectMessageParticipant", summary: "Removes a participant from a group direct message channel.", tag: tagChannels,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.GET_UNREAD_COUNTS_URL_SUFFIX, operationId: "getUnreadCounts", summary: "Returns the unread state of each channel of the authenticated user.", tag: tagChannels,
+		status: http.StatusOK, response: []chat.UnreadState{}},
+	{method: http.MethodGet, path: chat.MARK_CHANNEL_READ_URL_SUFFIX, operationId: "getChannelReadPointer", summary: "Returns the read pointer of the authenticated user in a channel.", tag: tagChannels,
+		status: http.StatusOK, response: chat.ReadPointer{}},
+	{method: http.MethodPut, path: chat.MARK_CHANNEL_READ_URL_SUFFIX, operationId: "markChannelRead", summary: "Marks a channel as read up to a message.", tag: tagChannels,
+		body: chat.MarkChannelReadRequest{}, status: http.StatusNoContent},
+	{method: http.MethodPut, path: chat.MUTE_CHANNEL_URL_SUFFIX, operationId: "muteChannel", summary: "Mutes the notifications of a channel.", tag: tagChannels,
+		body: chat.MuteChannelRequest{}, status: http.StatusNoContent},
+	{method: http.MethodDelete, path: chat.MUTE_CHANNEL_URL_SUFFIX, operationId: "unmuteChannel", summary: "Unmutes the notifications of a channel.", tag: tagChannels,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.GET_MESSAGE_DRAFTS_URL_SUFFIX, operationId: "getMessageDrafts", summary: "Returns the message drafts of the authenticated user.", tag: tagChannels,
+		status: http.StatusOK, response: []chat.MessageDraft{}},
+	{method: http.MethodGet, path: chat.MESSAGE_DRAFT_URL_SUFFIX, operationId: "getMessageDraft", summary: "Returns the message draft of a channel.", tag: tagChannels,
+		status: http.StatusOK, response: chat.MessageDraft{}},
+	{method: http.MethodPut, path: chat.MESSAGE_DRAFT_URL_SUFFIX, operationId: "saveMessageDraft", summary: "Saves the message draft of a channel.", tag: tagChannels,
+		body: chat.SaveMessageDraftRequest{}, status: http.StatusOK, response: chat.MessageDraft{}},
+	{method: http.MethodDelete, path: chat.MESSAGE_DRAFT_URL_SUFFIX, operationId: "deleteMessageDraft", summary: "Deletes the message draft of a channel.", tag: tagChannels,
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: chat.UPLOAD_ATTACHMENT_URL_SUFFIX, operationId: "uploadAttachment", summary: "Uploads a file to a channel to be attached to a message.", tag: tagChannels,
+		upload: true, status: http.StatusCreated, response: chat.Attachment{}},
+	{method: http.MethodGet, path: chat.DOWNLOAD_ATTACHMENT_URL_SUFFIX, operationId: "downloadAttachment", summary: "Returns the content of an attachment.", tag: tagChannels,
+		status: http.StatusOK, download: true},
+
+	// Messages
+	{method: http.MethodGet, path: chat.GET_CHANNEL_MESSAGES_URL_SUFFIX, operationId: "getChannelMessages", summary: "Returns a page of the messages in a channel.", tag: tagMessages,
+		query: []param{
+			{name: "before-msg", value: "", description: "Only returns messages sent before the message with this ID."},
+			{name: "after-msg", value: "", description: "Only returns messages sent after the message with this ID."},
+			{name: "from", value: time.Time{}, description: "Only returns messages sent at or after this time."},
+			{name: "to", value: time.Time{}, description: "Only returns messages sent before this time."},
+			pageParam, pageSizeParam,
+		},
+		status: http.StatusOK, response: []chat.ChatMessage{}},
+	{method: http.MethodPost, path: chat.SEND_CHAT_MESSAGE_URL_SUFFIX, operationId: "sendChatMessage", summary: "Sends a message to a channel.", tag: tagMessages,
+		body: chat.ChatMessageRequest{}, status: http.StatusCreated, response: chat.ChatMessage{}},
+	{method: http.MethodGet, path: chat.GET_CHANNEL_MESSAGE_URL_SUFFIX, operationId: "getChannelMessage", summary: "Returns a single message.", tag: tagMessages,
+		status: http.StatusOK, response: chat.ChatMessage{}},
+	{method: http.MethodPut, path: chat.EDIT_MESSAGE_URL_SUFFIX, operationId: "editMessage", summary: "Edits the content of a message.", tag: tagMessages,
+		body: chat.EditMessageRequest{}, status: http.StatusOK, response: chat.ChatMessage{}},
+	{method: http.MethodDelete, path: chat.DELETE_MESSAGE_URL_SUFFIX, operationId: "deleteMessage", summary: "Deletes a message.", tag: tagMessages,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.GET_MESSAGE_EDIT_HISTORY_URL_SUFFIX, operationId: "getMessageEditHistory", summary: "Returns the previous revisions of a message.", tag: tagMessages,
+		status: http.StatusOK, response: []chat.MessageRevision{}},
+	{method: http.MethodPost, path: chat.IMPORT_CHANNEL_MESSAGES_URL_SUFFIX, operationId: "importChannelMessages", summary: "Imports a transcript into a channel.", tag: tagMessages,
+		body: chat.ImportMessagesRequest{}, status: http.StatusOK, response: chat.ImportMessagesResult{}},
+	{method: http.MethodGet, path: chat.SEARCH_MESSAGES_URL_SUFFIX, operationId: "searchMessages", summary: "Searches the messages in the channels of the authenticated user.", tag: tagMessages,
+		query: []param{
+			{name: "q", value: "", description: "The text to search for.", required: true},
+			{name: "channel-id", value: "", description: "Limits the search to a channel."},
+			{name: "sender-id", value: "", description: "Limits the search to messages sent by a user."},
+			{name: "from", value: time.Time{}, description: "Limits the search to messages sent at or after this time."},
+			{name: "to", value: time.Time{}, description: "Limits the search to messages sent before this time."},
+			pageParam, pageSizeParam,
+		},
+		status: http.StatusOK, response: []chat.MessageSearchResult{}},
+	{method: http.MethodGet, path: chat.GET_REACTIONS_URL_SUFFIX, operationId: "getReactions", summary: "Returns the reactions on a message.", tag: tagMessages,
+		status: http.StatusOK, response: []chat.ReactionSummary{}},
+	{method: http.MethodPut, path: chat.ADD_REACTION_URL_SUFFIX, operationId: "addReaction", summary: "Adds a reaction to a message.", tag: tagMessages,
+		body: chat.AddReactionRequest{}, status: http.StatusNoContent},
+	{method: http.MethodDelete, path: chat.REMOVE_REACTION_URL_SUFFIX, operationId: "removeReaction", summary: "Removes a reaction from a message.", tag: tagMessages,
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: chat.REPORT_MESSAGE_URL_SUFFIX, operationId: "reportMessage", summary: "Reports a message to the moderators.", tag: tagMessages,
+		body: chat.ReportRequest{}, status: http.StatusCreated, response: chat.Report{}},
+
+	// Friends
+	{method: http.MethodPut, path: chat.SEND_FRIEND_REQUEST_URL_SUFFIX, operationId: "sendFriendRequest", summary: "Sends a friend request to a user.", tag: tagFriends,
+		body: chat.SendFriendRequestRequest{}, status: http.StatusNoContent},
+	{method: http.MethodPut, path: chat.ACCEPT_FRIEND_REQUEST_URL_SUFFIX, operationId: "acceptFriendRequest", summary: "Accepts a friend request from a user.", tag: tagFriends,
+		body: chat.AcceptFriendRequestRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.GET_MUTUAL_FRIENDS_URL_SUFFIX, operationId: "getMutualFriends", summary: "Returns the friends shared with a user.", tag: tagFriends,
+		status: http.StatusOK, response: []chat.UserInfo{}},
+	{method: http.MethodDelete, path: chat.REMOVE_FRIEND_URL_SUFFIX, operationId: "removeFriend", summary: "Removes a friend.", tag: tagFriends,
+		query: []param{
+			{name: "dm-channel", value: chat.DirectMessageChannelDisposition(""), description: "What happens to the direct message channel with the friend."},
+		},
+		status: http.StatusNoContent},
+
+	// Rooms
+	{method: http.MethodGet, path: chat.GET_ROOMS_URL_SUFFIX, operationId: "getRooms", summary: "Returns the rooms the authenticated user is a member of.", tag: tagRooms,
+		status: http.StatusOK, response: []chat.Room{}},
+	{method: http.MethodPost, path: chat.CREATE_ROOM_URL_SUFFIX, operationId: "createRoom", summary: "Creates a room owned by the authenticated user.", tag: tagRooms,
+		body: chat.CreateRoomRequest{}, status: http.StatusCreated, response: chat.Room{}},
+	{method: http.MethodGet, path: chat.DISCOVER_ROOMS_URL_SUFFIX, operationId: "discoverRooms", summary: "Returns a page of public rooms.", tag: tagRooms,
+		query: []param{
+			{name: "tags", value: "", description: "A comma separated list of tags the rooms must have."},
+			{name: "name-filter", value: "", description: "Only returns rooms whose name matches the filter."},
+			pageParam, pageSizeParam,
+		},
+		status: http.StatusOK, response: []chat.Room{}},
+	{method: http.MethodPatch, path: chat.UPDATE_ROOM_URL_SUFFIX, operationId: "updateRoom", summary: "Updates the settings of a room.", tag: tagRooms,
+		body: chat.UpdateRoomRequest{}, status: http.StatusOK, response: chat.Room{}},
+	{method: http.MethodPut, path: chat.JOIN_ROOM_URL_SUFFIX, operationId: "joinRoom", summary: "Joins a room. The body is only required for password protected rooms.", tag: tagRooms,
+		body: chat.JoinRoomRequest{}, optionalBody: true, status: http.StatusNoContent},
+	{method: http.MethodPost, path: chat.ROOM_JOIN_REQUESTS_URL_SUFFIX, operationId: "requestToJoinRoom", summary: "Requests to join a room.", tag: tagRooms,
+		body: chat.RequestToJoinRoomRequest{}, status: http.StatusCreated, response: chat.RoomJoinRequest{}},
+	{method: http.MethodGet, path: chat.ROOM_JOIN_REQUESTS_URL_SUFFIX, operationId: "getRoomJoinRequests", summary: "Returns the pending join requests of a room.", tag: tagRooms,
+		status: http.StatusOK, response: []chat.RoomJoinRequest{}},
+	{method: http.MethodPut, path: chat.APPROVE_JOIN_REQUEST_URL_SUFFIX, operationId: "approveJoinRequest", summary: "Approves a join request.", tag: tagRooms,
+		status: http.StatusNoContent},
+	{method: http.MethodPut, path: chat.DENY_JOIN_REQUEST_URL_SUFFIX, operationId: "denyJoinRequest", summary: "Denies a join request.", tag: tagRooms,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.GET_ROOM_AUDIT_LOG_URL_SUFFIX, operationId: "getRoomAuditLog", summary: "Returns a page of the audit log of a room.", tag: tagRooms,
+		query: []param{
+			{name: "action", value: chat.AuditAction(""), description: "Only returns entries with this action."},
+			{name: "actor-id", value: "", description: "Only returns entries performed by this user."},
+			pageParam, pageSizeParam,
+		},
+		status: http.StatusOK, response: []chat.AuditEntry{}},
+	{method: http.MethodPost, path: chat.KICK_USER_FROM_ROOM_URL_SUFFIX, operationId: "kickUserFromRoom", summary: "Removes a member from a room.", tag: tagRooms,
+		body: chat.KickUserFromRoomRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.ROOM_WEBHOOKS_URL_SUFFIX, operationId: "getWebhooks", summary: "Returns the webhooks of a room.", tag: tagRooms,
+		status: http.StatusOK, response: []chat.Webhook{}},
+	{method: http.MethodPost, path: chat.ROOM_WEBHOOKS_URL_SUFFIX, operationId: "createWebhook", summary: "Registers a URL to receive the events of a room.", tag: tagRooms,
+		body: chat.CreateWebhookRequest{}, status: http.StatusCreated, response: chat.Webhook{}},
+	{method: http.MethodDelete, path: chat.ROOM_WEBHOOK_URL_SUFFIX, operationId: "deleteWebhook", summary: "Removes a webhook from a room.", tag: tagRooms,
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: chat.ROOM_INVITES_URL_SUFFIX, operationId: "getRoomInvites", summary: "Returns the usable invites of a room.", tag: tagRooms,
+		status: http.StatusOK, response: []chat.RoomInvite{}},
+	{method: http.MethodPost, path: chat.ROOM_INVITES_URL_SUFFIX, operationId: "createRoomInvite", summary: "Creates a shareable invite to a room.", tag: tagRooms,
+		body: chat.CreateRoomInviteRequest{}, status: http.StatusCreated, response: chat.RoomInvite{}},
+	{method: http.MethodDelete, path: chat.ROOM_INVITE_URL_SUFFIX, operationId: "revokeRoomInvite", summary: "Revokes an invite to a room.", tag: tagRooms,
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: chat.JOIN_ROOM_BY_INVITE_URL_SUFFIX, operationId: "joinRoomByInvite", summary: "Joins the room of an invite.", tag: tagRooms,
+		status: http.StatusOK, response: chat.Room{}},
+
+	// Voice
+	{method: http.MethodPost, path: chat.ROOM_VOICE_CHANNELS_URL_SUFFIX, operationId: "createVoiceChannel", summary: "Creates a voice channel in a room.", tag: tagVoice,
+		body: chat.CreateVoiceChannelRequest{}, status: http.StatusCreated, response: chat.Channel{}},
+	{method: http.MethodGet, path: chat.VOICE_PARTICIPANTS_URL_SUFFIX, operationId: "getVoiceParticipants", summary: "Returns the users connected to a voice channel.", tag: tagVoice,
+		status: http.StatusOK, response: []chat.VoiceParticipant{}},
+	{method: http.MethodPost, path: chat.JOIN_VOICE_CHANNEL_URL_SUFFIX, operationId: "joinVoiceChannel", summary: "Connects the user to a voice channel.", tag: tagVoice,
+		body: chat.JoinVoiceChannelRequest{}, status: http.StatusOK, response: []chat.VoiceParticipant{}},
+	{method: http.MethodPost, path: chat.LEAVE_VOICE_CHANNEL_URL_SUFFIX, operationId: "leaveVoiceChannel", summary: "Disconnects the user from a voice channel.", tag: tagVoice,
+		status: http.StatusNoContent},
+	{method: http.MethodPut, path: chat.VOICE_STATE_URL_SUFFIX, operationId: "updateVoiceState", summary: "Changes the muted, deafened or speaking state of the user in a voice channel.", tag: tagVoice,
+		body: chat.UpdateVoiceStateRequest{}, status: http.StatusOK, response: chat.VoiceParticipant{}},
+
+	// Identity and access management
+	{method: http.MethodPost, path: idam.REGISTER_URL_SUFFIX, operationId: "register", summary: "Registers a new user.", tag: tagIdam, public: true,
+		body: idam.RegisterRequest{}, status: http.StatusCreated, response: idam.UserRegistration{}},
+	{method: http.MethodPost, path: idam.LOGIN_URL_SUFFIX, operationId: "login", summary: "Authenticates a user and starts a session.", tag: tagIdam, public: true,
+		body: idam.LoginRequest{}, status: http.StatusOK, response: idam.Session{}},
+	{method: http.MethodPost, path: idam.REFRESH_TOKEN_URL_SUFFIX, operationId: "refreshToken", summary: "Exchanges a refresh token for a new session.", tag: tagIdam, public: true,
+		body: idam.RefreshTokenRequest{}, status: http.StatusOK, response: idam.Session{}},
+	{method: http.MethodPost, path: idam.LOGOUT_URL_SUFFIX, operationId: "logout", summary: "Ends the session of a refresh token.", tag: tagIdam,
+		body: idam.LogoutRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: idam.SESSIONS_URL_SUFFIX, operationId: "listSessions", summary: "Returns the device sessions of the authenticated user.", tag: tagIdam,
+		status: http.StatusOK, response: []idam.DeviceSession{}},
+	{method: http.MethodDelete, path: idam.SESSION_URL_SUFFIX, operationId: "revokeSession", summary: "Revokes a device session.", tag: tagIdam,
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: idam.FORGOT_PASSWORD_URL_SUFFIX, operationId: "forgotPassword", summary: "Sends a password reset email.", tag: tagIdam, public: true,
+		body: idam.ForgotPasswordRequest{}, status: http.StatusNoContent},
+	{method: http.MethodPost, path: idam.VERIFY_EMAIL_URL_SUFFIX, operationId: "verifyEmail", summary: "Verifies the email address of a user.", tag: tagIdam, public: true,
+		body: idam.VerifyEmailRequest{}, status: http.StatusNoContent},
+
+	// Server
+	{method: http.MethodGet, path: chat.SERVER_HEALTH_URL_SUFFIX, operationId: "getServerHealth", summary: "Returns the health of the server. Responds with 503 when the server is unavailable.", tag: tagServer, public: true,
+		status: http.StatusOK, response: chat.ServerHealth{}},
+	{method: http.MethodGet, path: chat.SERVER_STATS_URL_SUFFIX, operationId: "getServerStats", summary: "Returns the uptime, connected users and message throughput of the server.", tag: tagServer,
+		status: http.StatusOK, response: chat.ServerStats{}},
+}
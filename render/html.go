@@ -0,0 +1,219 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// HTML_STYLESHEET styles the markup produced by HTML. It is included by WriteHTMLTranscript and can be included by pages
+// which embed rendered messages.
+const HTML_STYLESHEET = `.message { margin: 0.25em 0; }
+.message.mentions-self { background: #fff6d5; }
+.message .time { color: #888; font-size: 0.85em; }
+.message .sender { font-weight: bold; }
+.message .content { white-space: pre-wrap; }
+.message.macro .content { font-style: italic; }
+.message .mention { color: #1a6fb5; font-weight: bold; }
+.message .mention.self { background: #ffd84d; color: inherit; }
+.message .reply { color: #888; font-size: 0.85em; margin-left: 1em; }
+.message .embed { border-left: 4px solid #ccc; margin: 0.25em 0 0.25em 1em; padding: 0.25em 0.5em; }
+.message .embed .title { font-weight: bold; }
+.message .embed .description { white-space: pre-wrap; }
+.message .embed dt { font-weight: bold; }
+.message .embed dd { margin: 0 0 0.25em 0; }
+.message .embed .footer { color: #888; font-size: 0.85em; }
+.message .attachments { margin: 0.25em 0 0.25em 1em; padding: 0; list-style: none; }
+.message .edited { color: #888; font-size: 0.85em; }
+`
+
+// HTML renders a message as an HTML fragment: a div with the class "message" holding the timestamp, sender, content,
+// reply, embed and attachments, styled by HTML_STYLESHEET. Message content is escaped, so the fragment is safe to embed
+// in a page. Only http and https links are made clickable.
+func (r *Renderer) HTML(m chat.ChatMessage) template.HTML {
+	var b strings.Builder
+
+	class := "message"
+
+	if IsMacro(m) {
+		class += " macro"
+	}
+
+	if r.MentionsSelf(m) {
+		class += " mentions-self"
+	}
+
+	sentAt := m.ReceivedAt().In(r.location)
+	fmt.Fprintf(&b, `<div class="%s" id="%s">`, class, escape(m.Id))
+
+	if m.ReplyTo != nil {
+		fmt.Fprintf(&b, `<div class="reply">&gt; <span class="sender">%s</span>: %s</div>`,
+			escape(r.username(m.ReplyTo.SenderUserId)), escape(m.ReplyTo.Snippet))
+	}
+
+	fmt.Fprintf(&b, `<time class="time" datetime="%s">%s</time> `, sentAt.Format(time.RFC3339), escape(sentAt.Format(r.timeFormat)))
+
+	if IsMacro(m) {
+		fmt.Fprintf(&b, `* <span class="sender">%s</span> `, escape(r.username(m.SenderUserId)))
+	} else {
+		fmt.Fprintf(&b, `<span class="sender">%s</span>: `, escape(r.username(m.SenderUserId)))
+	}
+
+	if chat.ResolveContentType(m.ContentType) == chat.MESSAGE_CONTENT_TYPE_EMBED && m.Embed != nil {
+		r.writeEmbed(&b, *m.Embed)
+	} else {
+		fmt.Fprintf(&b, `<span class="content">%s</span>`, r.richText(m.Content))
+	}
+
+	if len(m.Attachments) > 0 {
+		b.WriteString(`<ul class="attachments">`)
+
+		for _, a := range m.Attachments {
+			b.WriteString("<li>")
+			writeLink(&b, a.Url, describeAttachment(a))
+			b.WriteString("</li>")
+		}
+
+		b.WriteString("</ul>")
+	}
+
+	if m.EditedAtUtc != nil {
+		b.WriteString(` <span class="edited">(edited)</span>`)
+	}
+
+	b.WriteString("</div>")
+
+	return template.HTML(b.String())
+}
+
+var htmlTranscriptTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.day { color: #888; margin: 1em 0 0.5em 0; }
+{{.Stylesheet}}</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Days}}<h2 class="day">{{.Date}}</h2>
+{{range .Messages}}{{.}}
+{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTMLTranscript writes the messages in the order given as a self contained HTML document with the given title,
+// starting a new section headed by the date whenever the date changes.
+func (r *Renderer) WriteHTMLTranscript(w io.Writer, title string, messages []chat.ChatMessage) error {
+	type day struct {
+		Date     string
+		Messages []template.HTML
+	}
+
+	days := make([]day, 0)
+
+	for _, m := range messages {
+		date := m.ReceivedAt().In(r.location).Format("Monday, 2 January 2006")
+
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, day{Date: date})
+		}
+
+		days[len(days)-1].Messages = append(days[len(days)-1].Messages, r.HTML(m))
+	}
+
+	bw := bufio.NewWriter(w)
+
+	err := htmlTranscriptTemplate.Execute(bw, struct {
+		Title      string
+		Stylesheet template.CSS
+		Days       []day
+	}{title, template.CSS(HTML_STYLESHEET), days})
+
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// writeEmbed writes the markup of an embed, bordered with its accent color.
+func (r *Renderer) writeEmbed(b *strings.Builder, e chat.Embed) {
+	if e.Color != 0 {
+		fmt.Fprintf(b, `<div class="embed" style="border-color: #%06x">`, e.Color&0xFFFFFF)
+	} else {
+		b.WriteString(`<div class="embed">`)
+	}
+
+	b.WriteString(`<div class="title">`)
+	writeLink(b, e.Url, e.Title)
+	b.WriteString("</div>")
+
+	if e.Description != "" {
+		fmt.Fprintf(b, `<div class="description">%s</div>`, r.richText(e.Description))
+	}
+
+	if len(e.Fields) > 0 {
+		b.WriteString("<dl>")
+
+		for _, f := range e.Fields {
+			fmt.Fprintf(b, "<dt>%s</dt><dd>%s</dd>", escape(f.Name), r.richText(f.Value))
+		}
+
+		b.WriteString("</dl>")
+	}
+
+	if e.Footer != nil && e.Footer.Text != "" {
+		fmt.Fprintf(b, `<div class="footer">%s</div>`, escape(e.Footer.Text))
+	}
+
+	b.WriteString("</div>")
+}
+
+// richText escapes text, highlighting mentions and making links clickable.
+func (r *Renderer) richText(text string) string {
+	var b strings.Builder
+
+	for _, s := range parseSegments(text) {
+		switch s.kind {
+		case segmentMention:
+			class := "mention"
+
+			if r.isSelf(strings.TrimPrefix(s.text, "@")) {
+				class += " self"
+			}
+
+			fmt.Fprintf(&b, `<span class="%s">%s</span>`, class, escape(s.text))
+		case segmentLink:
+			writeLink(&b, s.text, s.text)
+		default:
+			b.WriteString(escape(s.text))
+		}
+	}
+
+	return b.String()
+}
+
+// writeLink writes a link to the URL, or only the escaped text if the URL is not an http or https URL.
+func writeLink(b *strings.Builder, rawUrl string, text string) {
+	u, err := url.Parse(rawUrl)
+
+	if rawUrl == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		b.WriteString(escape(text))
+		return
+	}
+
+	fmt.Fprintf(b, `<a href="%s" rel="noopener noreferrer nofollow">%s</a>`, escape(u.String()), escape(text))
+}
+
+func escape(s string) string {
+	return template.HTMLEscapeString(s)
+}
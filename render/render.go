@@ -0,0 +1,302 @@
+// Package render formats chat messages for display, so that clients built on this library present messages the same
+// way. A Renderer formats messages as wrapped, optionally colored, terminal text with Terminal and as HTML fragments
+// safe to embed in a page with HTML. Both resolve sender usernames, highlight mentions, show macro results as actions
+// and include replies, embeds, attachments and edits.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+const (
+	// The default layout of message timestamps.
+	DEFAULT_TIME_FORMAT = "15:04"
+	// The default width terminal output is wrapped to, in characters.
+	DEFAULT_WIDTH = 80
+)
+
+// A Renderer formats chat messages for display. A Renderer is safe for concurrent use if its username resolver is.
+type Renderer struct {
+	resolveUsername func(userId string) string
+	self            string
+	location        *time.Location
+	timeFormat      string
+	width           int
+	color           bool
+}
+
+// RendererOption is a type for the options that can be passed to NewRenderer.
+type RendererOption func(*Renderer)
+
+// Sets the function used to look up the username of a user ID, such as a lookup in the results of a
+// chat.UserInfoCache. Users it returns an empty username for are shown by ID. Defaults to showing every user by ID.
+func RendererOption_Usernames(resolve func(userId string) string) RendererOption {
+	return func(r *Renderer) {
+		r.resolveUsername = resolve
+	}
+}
+
+// Sets the username of the user the messages are displayed to. Mentions of the user are highlighted differently to
+// other mentions.
+func RendererOption_Self(username string) RendererOption {
+	return func(r *Renderer) {
+		r.self = username
+	}
+}
+
+// Sets the time zone timestamps are shown in. Defaults to time.Local.
+func RendererOption_Location(location *time.Location) RendererOption {
+	return func(r *Renderer) {
+		if location != nil {
+			r.location = location
+		}
+	}
+}
+
+// Sets the layout of message timestamps, as accepted by time.Time.Format. Defaults to DEFAULT_TIME_FORMAT.
+func RendererOption_TimeFormat(layout string) RendererOption {
+	return func(r *Renderer) {
+		if layout != "" {
+			r.timeFormat = layout
+		}
+	}
+}
+
+// Sets the width terminal output is wrapped to, in characters. Zero disables wrapping. Defaults to DEFAULT_WIDTH.
+func RendererOption_Width(width int) RendererOption {
+	return func(r *Renderer) {
+		if width >= 0 {
+			r.width = width
+		}
+	}
+}
+
+// Enables ANSI colors and styles in terminal output. Disabled by default.
+func RendererOption_Color(enabled bool) RendererOption {
+	return func(r *Renderer) {
+		r.color = enabled
+	}
+}
+
+// NewRenderer creates a new Renderer.
+func NewRenderer(options ...RendererOption) *Renderer {
+	r := &Renderer{
+		location:   time.Local,
+		timeFormat: DEFAULT_TIME_FORMAT,
+		width:      DEFAULT_WIDTH,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// MentionsSelf returns true if the message mentions the user set with RendererOption_Self.
+func (r *Renderer) MentionsSelf(m chat.ChatMessage) bool {
+	if r.self == "" {
+		return false
+	}
+
+	for _, username := range Mentions(m.Content) {
+		if strings.EqualFold(username, r.self) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// username returns the username of a user, or the user ID if it cannot be resolved.
+func (r *Renderer) username(userId string) string {
+	if r.resolveUsername != nil {
+		if username := r.resolveUsername(userId); username != "" {
+			return username
+		}
+	}
+
+	return userId
+}
+
+// isSelf returns true if the username is the username of the user the messages are displayed to.
+func (r *Renderer) isSelf(username string) bool {
+	return r.self != "" && strings.EqualFold(username, r.self)
+}
+
+// IsMacro returns true if the message is the result of a macro, such as a /roll, which is shown as an action of the
+// sender rather than as something they said.
+func IsMacro(m chat.ChatMessage) bool {
+	if chat.ResolveContentType(m.ContentType) != chat.MESSAGE_CONTENT_TYPE_TEXT {
+		return false
+	}
+
+	isMacro, _ := chat.IsMacro(m.Content)
+
+	return isMacro
+}
+
+// Mentions returns the usernames mentioned in the content with an @ prefix, in the order they first appear. An @ inside
+// a word, as in an email address, is not a mention.
+func Mentions(content string) []string {
+	mentions := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, s := range parseSegments(content) {
+		if s.kind != segmentMention {
+			continue
+		}
+
+		username := strings.TrimPrefix(s.text, "@")
+		key := strings.ToLower(username)
+
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			mentions = append(mentions, username)
+		}
+	}
+
+	return mentions
+}
+
+type segmentKind int
+
+const (
+	segmentText segmentKind = iota
+	segmentMention
+	segmentLink
+)
+
+// A segment is a run of message content which is displayed the same way.
+type segment struct {
+	kind segmentKind
+	text string
+}
+
+// parseSegments splits message content into text, mentions and http(s) links.
+func parseSegments(content string) []segment {
+	segments := make([]segment, 0, 1)
+	start := 0
+
+	for i := 0; i < len(content); {
+		if i > 0 && isWordRune(lastRune(content[:i])) {
+			i++
+			continue
+		}
+
+		end := 0
+
+		if content[i] == '@' {
+			end = mentionEnd(content, i)
+		} else if strings.HasPrefix(content[i:], "http://") || strings.HasPrefix(content[i:], "https://") {
+			end = linkEnd(content, i)
+		}
+
+		if end == 0 {
+			i++
+			continue
+		}
+
+		if start < i {
+			segments = append(segments, segment{segmentText, content[start:i]})
+		}
+
+		kind := segmentLink
+
+		if content[i] == '@' {
+			kind = segmentMention
+		}
+
+		segments = append(segments, segment{kind, content[i:end]})
+		start, i = end, end
+	}
+
+	if start < len(content) {
+		segments = append(segments, segment{segmentText, content[start:]})
+	}
+
+	return segments
+}
+
+// mentionEnd returns the end of the mention starting at i, or 0 if the @ at i does not start a mention.
+func mentionEnd(content string, i int) int {
+	end := i + 1
+
+	for end < len(content) && isUsernameByte(content[end]) {
+		end++
+	}
+
+	// A trailing full stop ends the sentence rather than the username.
+	for end > i+1 && content[end-1] == '.' {
+		end--
+	}
+
+	if end == i+1 {
+		return 0
+	}
+
+	return end
+}
+
+// linkEnd returns the end of the link starting at i, or 0 if the scheme at i is not followed by an address.
+func linkEnd(content string, i int) int {
+	end := i
+
+	for end < len(content) && !strings.ContainsRune(" \t\r\n<>\"", rune(content[end])) {
+		end++
+	}
+
+	// Trailing punctuation usually belongs to the sentence around the link.
+	for end > i && strings.ContainsRune(".,;:!?)'", rune(content[end-1])) {
+		end--
+	}
+
+	if !strings.Contains(content[i:end], "://") || strings.HasSuffix(content[i:end], "://") {
+		return 0
+	}
+
+	return end
+}
+
+func isUsernameByte(b byte) bool {
+	return b == '_' || b == '-' || b == '.' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// formatSize formats a size in bytes for display. Example: 1.5 MB
+func formatSize(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value, suffix := float64(bytes)/unit, 0
+
+	for value >= unit && suffix < 3 {
+		value /= unit
+		suffix++
+	}
+
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[suffix])
+}
+
+// formatDuration formats the duration of a voice note for display. Example: 1:05
+func formatDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
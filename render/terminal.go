@@ -0,0 +1,293 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// ANSI escape sequences used when colors are enabled.
+const (
+	ansiReset       = "\x1b[0m"
+	ansiBold        = "\x1b[1m"
+	ansiDim         = "\x1b[2m"
+	ansiMention     = "\x1b[1;36m"
+	ansiSelfMention = "\x1b[1;30;43m"
+)
+
+// The narrowest wrapped content column. Continuation lines are not indented when indenting would leave less room.
+const minContentWidth = 20
+
+// A paragraph is a line of the body of a message before wrapping.
+type paragraph struct {
+	text  string
+	style string
+	// If true mentions in the text are highlighted.
+	rich bool
+}
+
+// Terminal renders a message as one or more lines of text, without a trailing newline. The first line is the timestamp,
+// the sender and the start of the content, and the content is wrapped to the width of the renderer with continuation
+// lines indented under the sender. Widths are counted in runes, so wide characters such as emoji may overflow.
+func (r *Renderer) Terminal(m chat.ChatMessage) string {
+	var b strings.Builder
+
+	stamp := "[" + m.ReceivedAt().In(r.location).Format(r.timeFormat) + "] "
+	sender := r.username(m.SenderUserId)
+
+	if m.ReplyTo != nil {
+		reply := strings.Repeat(" ", utf8.RuneCountInString(stamp)) + "> " + r.username(m.ReplyTo.SenderUserId) + ": " +
+			strings.Join(strings.Fields(m.ReplyTo.Snippet), " ")
+		b.WriteString(r.style(ansiDim, truncate(reply, r.width)))
+		b.WriteByte('\n')
+	}
+
+	var prefix, styledPrefix string
+
+	if IsMacro(m) {
+		prefix = stamp + "* " + sender + " "
+		styledPrefix = r.style(ansiDim, stamp) + "* " + r.style(ansiBold, sender) + " "
+	} else {
+		prefix = stamp + sender + ": "
+		styledPrefix = r.style(ansiDim, stamp) + r.style(ansiBold, sender) + ": "
+	}
+
+	indent := utf8.RuneCountInString(stamp)
+	firstWidth, restWidth := r.width-utf8.RuneCountInString(prefix), r.width-indent
+
+	if r.width == 0 {
+		firstWidth, restWidth = 0, 0
+	} else if firstWidth < minContentWidth || restWidth < minContentWidth {
+		firstWidth, restWidth, indent = r.width, r.width, 0
+		styledPrefix = strings.TrimSuffix(styledPrefix, " ")
+	}
+
+	b.WriteString(styledPrefix)
+
+	for i, line := range r.bodyLines(m, firstWidth, restWidth) {
+		if i > 0 || (indent == 0 && r.width != 0) {
+			b.WriteByte('\n')
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// WriteTerminalTranscript writes the messages in the order given, one after another, starting a new section headed by
+// the date whenever the date changes.
+func (r *Renderer) WriteTerminalTranscript(w io.Writer, messages []chat.ChatMessage) error {
+	bw := bufio.NewWriter(w)
+	day := ""
+
+	for _, m := range messages {
+		if d := m.ReceivedAt().In(r.location).Format("Monday, 2 January 2006"); d != day {
+			if day != "" {
+				bw.WriteByte('\n')
+			}
+
+			day = d
+			fmt.Fprintln(bw, r.style(ansiDim, "--- "+day+" ---"))
+		}
+
+		fmt.Fprintln(bw, r.Terminal(m))
+	}
+
+	return bw.Flush()
+}
+
+// bodyLines returns the wrapped and styled lines of the body of a message: the content or embed, the attachments and
+// the edited marker.
+func (r *Renderer) bodyLines(m chat.ChatMessage, firstWidth int, restWidth int) []string {
+	paragraphs := make([]paragraph, 0, 1)
+
+	if chat.ResolveContentType(m.ContentType) == chat.MESSAGE_CONTENT_TYPE_EMBED && m.Embed != nil {
+		paragraphs = append(paragraphs, embedParagraphs(*m.Embed)...)
+	} else if m.Content != "" {
+		for _, text := range strings.Split(m.Content, "\n") {
+			paragraphs = append(paragraphs, paragraph{text: text, rich: true})
+		}
+	}
+
+	for _, a := range m.Attachments {
+		paragraphs = append(paragraphs, paragraph{text: "[" + describeAttachment(a) + "]", style: ansiDim})
+	}
+
+	lines := make([]string, 0, len(paragraphs))
+	width := firstWidth
+
+	for _, p := range paragraphs {
+		for _, line := range wrap(p.text, width, restWidth) {
+			if p.rich {
+				lines = append(lines, r.styleContent(line))
+			} else {
+				lines = append(lines, r.style(p.style, line))
+			}
+
+			width = restWidth
+		}
+	}
+
+	if m.EditedAtUtc != nil {
+		edited := r.style(ansiDim, "(edited)")
+
+		if len(lines) == 0 {
+			lines = append(lines, edited)
+		} else {
+			lines[len(lines)-1] += " " + edited
+		}
+	}
+
+	return lines
+}
+
+// embedParagraphs returns the title, description, fields and footer of an embed.
+func embedParagraphs(e chat.Embed) []paragraph {
+	title := e.Title
+
+	if e.Url != "" {
+		title += " <" + e.Url + ">"
+	}
+
+	paragraphs := []paragraph{{text: title, style: ansiBold}}
+
+	for _, text := range strings.Split(e.Description, "\n") {
+		if text != "" {
+			paragraphs = append(paragraphs, paragraph{text: text, rich: true})
+		}
+	}
+
+	for _, f := range e.Fields {
+		paragraphs = append(paragraphs, paragraph{text: f.Name + ": " + f.Value, rich: true})
+	}
+
+	if e.Footer != nil && e.Footer.Text != "" {
+		paragraphs = append(paragraphs, paragraph{text: e.Footer.Text, style: ansiDim})
+	}
+
+	return paragraphs
+}
+
+// describeAttachment returns a short description of an attachment. Example: image: cat.png, 1.2 MB
+func describeAttachment(a chat.Attachment) string {
+	switch a.Kind {
+	case chat.ATTACHMENT_KIND_VOICE_NOTE:
+		return "voice note, " + formatDuration(a.Duration())
+	case chat.ATTACHMENT_KIND_IMAGE:
+		return "image: " + a.FileName + ", " + formatSize(a.SizeBytes)
+	default:
+		return "file: " + a.FileName + ", " + formatSize(a.SizeBytes)
+	}
+}
+
+// style wraps text in an ANSI style if colors are enabled.
+func (r *Renderer) style(style string, text string) string {
+	if !r.color || style == "" || text == "" {
+		return text
+	}
+
+	return style + text + ansiReset
+}
+
+// styleContent highlights the mentions in a line of content.
+func (r *Renderer) styleContent(line string) string {
+	if !r.color {
+		return line
+	}
+
+	var b strings.Builder
+
+	for _, s := range parseSegments(line) {
+		switch {
+		case s.kind == segmentMention && r.isSelf(strings.TrimPrefix(s.text, "@")):
+			b.WriteString(r.style(ansiSelfMention, s.text))
+		case s.kind == segmentMention:
+			b.WriteString(r.style(ansiMention, s.text))
+		default:
+			b.WriteString(s.text)
+		}
+	}
+
+	return b.String()
+}
+
+// wrap breaks text into lines of at most firstWidth runes for the first line and restWidth runes for the others,
+// breaking at spaces where possible. A width of zero disables wrapping.
+func wrap(text string, firstWidth int, restWidth int) []string {
+	if firstWidth == 0 {
+		return []string{text}
+	}
+
+	lines := make([]string, 0, 1)
+	width := firstWidth
+	var line strings.Builder
+	lineLen := 0
+
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		lineLen = 0
+		width = restWidth
+	}
+
+	for _, word := range strings.Fields(text) {
+		wordLen := utf8.RuneCountInString(word)
+
+		if lineLen > 0 && lineLen+1+wordLen > width {
+			flush()
+		}
+
+		if lineLen > 0 {
+			line.WriteByte(' ')
+			lineLen++
+		}
+
+		// Words longer than a line are broken wherever the line ends.
+		for lineLen+wordLen > width {
+			head, tail := splitRunes(word, width-lineLen)
+			line.WriteString(head)
+			flush()
+			word, wordLen = tail, wordLen-utf8.RuneCountInString(head)
+		}
+
+		line.WriteString(word)
+		lineLen += wordLen
+	}
+
+	if lineLen > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+// splitRunes splits s after n runes.
+func splitRunes(s string, n int) (string, string) {
+	for i := range s {
+		if n == 0 {
+			return s[:i], s[i:]
+		}
+
+		n--
+	}
+
+	return s, ""
+}
+
+// truncate shortens text to at most width runes, ending it with an ellipsis if it was shortened. A width of zero
+// disables truncation.
+func truncate(text string, width int) string {
+	if width == 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+
+	head, _ := splitRunes(text, width-1)
+
+	return head + "…"
+}
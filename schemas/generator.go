@@ -0,0 +1,243 @@
+package schemas
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// A Schema is the subset of JSON Schema used to describe the BroChat models.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// enumValues lists the values of the named enum types, which cannot be discovered by reflection.
+var enumValues = map[reflect.Type][]any{
+	reflect.TypeFor[chat.RelationshipType](): names(
+		chat.RELATIONSHIP_TYPE_DEFAULT, chat.RELATIONSHIP_TYPE_FRIEND,
+		chat.RELATIONSHIP_TYPE_FRIEND_REQUEST_RECIEVED, chat.RELATIONSHIP_TYPE_FRIENDSHIP_REQUESTED),
+	reflect.TypeFor[chat.ChannelType](): names(
+		chat.CHANNEL_TYPE_DIRECT_MESSAGE, chat.CHANNEL_TYPE_ROOM, chat.CHANNEL_TYPE_GROUP_DM, chat.CHANNEL_TYPE_VOICE),
+	reflect.TypeFor[chat.UserProfileUpdateCode](): names(
+		chat.USER_PROFILE_UPDATE_CODE_ROOM_UPDATE, chat.USER_PROFILE_UPDATE_REASON_RELATIONSHIP_UPDATE),
+	reflect.TypeFor[chat.MessageDeliveryState](): values(
+		chat.DELIVERY_STATE_UNKNOWN, chat.DELIVERY_STATE_SENT, chat.DELIVERY_STATE_DELIVERED, chat.DELIVERY_STATE_READ),
+	reflect.TypeFor[chat.PresenceState](): values(
+		chat.PRESENCE_STATE_ONLINE, chat.PRESENCE_STATE_AWAY, chat.PRESENCE_STATE_DO_NOT_DISTURB,
+		chat.PRESENCE_STATE_INVISIBLE, chat.PRESENCE_STATE_OFFLINE),
+	reflect.TypeFor[chat.NotificationLevel](): values(
+		chat.NOTIFICATION_LEVEL_ALL, chat.NOTIFICATION_LEVEL_MENTIONS_ONLY, chat.NOTIFICATION_LEVEL_NONE),
+	reflect.TypeFor[chat.JoinRequestStatus](): values(
+		chat.JOIN_REQUEST_STATUS_PENDING, chat.JOIN_REQUEST_STATUS_APPROVED, chat.JOIN_REQUEST_STATUS_DENIED),
+	reflect.TypeFor[chat.AuditAction](): values(
+		chat.AUDIT_ACTION_MEMBER_JOINED, chat.AUDIT_ACTION_MEMBER_LEFT, chat.AUDIT_ACTION_MEMBER_KICKED,
+		chat.AUDIT_ACTION_ROOM_RENAMED, chat.AUDIT_ACTION_ROOM_SETTINGS_CHANGED, chat.AUDIT_ACTION_ROLE_CHANGED,
+		chat.AUDIT_ACTION_JOIN_REQUEST_APPROVED, chat.AUDIT_ACTION_JOIN_REQUEST_DENIED, chat.AUDIT_ACTION_MESSAGE_DELETED),
+	reflect.TypeFor[chat.LastSeenVisibility](): values(
+		chat.LAST_SEEN_VISIBILITY_EVERYONE, chat.LAST_SEEN_VISIBILITY_FRIENDS, chat.LAST_SEEN_VISIBILITY_NOBODY),
+	reflect.TypeFor[chat.DirectMessageChannelDisposition](): values(
+		chat.DIRECT_MESSAGE_CHANNEL_DISPOSITION_ARCHIVE, chat.DIRECT_MESSAGE_CHANNEL_DISPOSITION_RETAIN),
+	reflect.TypeFor[chat.ChannelMode]():         values(chat.CHANNEL_MODE_DEFAULT, chat.CHANNEL_MODE_ANNOUNCEMENT),
+	reflect.TypeFor[chat.RoomRole]():            values(chat.ROOM_ROLE_OWNER, chat.ROOM_ROLE_MODERATOR, chat.ROOM_ROLE_MEMBER),
+	reflect.TypeFor[chat.RoomMembershipModel](): values(chat.FRIENDS_MEMBERSHIP_MODEL, chat.PUBLIC_MEMBERSHIP_MODEL),
+	reflect.TypeFor[chat.MessageContentType](): values(
+		chat.MESSAGE_CONTENT_TYPE_TEXT, chat.MESSAGE_CONTENT_TYPE_EMBED, chat.MESSAGE_CONTENT_TYPE_VOICE_NOTE),
+	reflect.TypeFor[chat.AttachmentKind](): values(
+		chat.ATTACHMENT_KIND_FILE, chat.ATTACHMENT_KIND_IMAGE, chat.ATTACHMENT_KIND_VOICE_NOTE),
+	reflect.TypeFor[chat.ReportReason](): values(
+		chat.REPORT_REASON_SPAM, chat.REPORT_REASON_HARASSMENT, chat.REPORT_REASON_HATE_SPEECH,
+		chat.REPORT_REASON_INAPPROPRIATE_CONTENT, chat.REPORT_REASON_IMPERSONATION, chat.REPORT_REASON_SELF_HARM,
+		chat.REPORT_REASON_OTHER),
+	reflect.TypeFor[chat.ReportStatus](): values(
+		chat.REPORT_STATUS_OPEN, chat.REPORT_STATUS_ACTIONED, chat.REPORT_STATUS_DISMISSED),
+	reflect.TypeFor[chat.UserSortOrder]():   values(chat.USER_SORT_ORDER_USERNAME, chat.USER_SORT_ORDER_LAST_ONLINE),
+	reflect.TypeFor[chat.FeedMessageType](): values(chat.KnownFeedMessageTypes()...),
+	reflect.TypeFor[chat.DataExportStatus](): values(
+		chat.DATA_EXPORT_STATUS_PENDING, chat.DATA_EXPORT_STATUS_READY, chat.DATA_EXPORT_STATUS_FAILED,
+		chat.DATA_EXPORT_STATUS_EXPIRED),
+	reflect.TypeFor[chat.HealthStatus](): values(chat.HEALTH_STATUS_OK, chat.HEALTH_STATUS_DEGRADED, chat.HEALTH_STATUS_UNAVAILABLE),
+	reflect.TypeFor[chat.CallEndReason](): values(
+		chat.CALL_END_REASON_HANGUP, chat.CALL_END_REASON_DECLINED, chat.CALL_END_REASON_BUSY, chat.CALL_END_REASON_MISSED,
+		chat.CALL_END_REASON_FAILED),
+	reflect.TypeFor[chat.ScreenShareSource](): values(chat.SCREEN_SHARE_SOURCE_SCREEN, chat.SCREEN_SHARE_SOURCE_WINDOW, chat.SCREEN_SHARE_SOURCE_TAB),
+}
+
+// brochatErrorSchema describes the wire form of chat.BroChatError, which is produced by its MarshalJSON method.
+var brochatErrorSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"error_code":    {Type: "integer", Description: "The BroChatResponseCode of the error."},
+		"message":       {Type: "string", Description: "A human readable summary of the error."},
+		"details":       {Type: "array", Items: &Schema{Type: "string"}, Description: "Additional details about the error."},
+		"error_details": {Type: "array", Items: &Schema{Type: "string"}, Description: "The message followed by the details, for clients which predate those fields."},
+		"request_id":    {Type: "string", Description: "The ID of the request that failed."},
+	},
+	Required: []string{"error_code", "error_details"},
+}
+
+// A Generator creates schemas for Go types, adding a definition for each named struct and enum type it encounters.
+// Definitions are referred to by their type name appended to the reference prefix.
+type Generator struct {
+	refPrefix   string
+	definitions map[string]*Schema
+}
+
+// NewGenerator creates a generator whose references use the prefix. Example: "#/$defs/" for a standalone JSON Schema
+// or "#/components/schemas/" for an OpenAPI document.
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{refPrefix: refPrefix, definitions: make(map[string]*Schema)}
+}
+
+// Definitions returns the definitions added so far, keyed by type name.
+func (g *Generator) Definitions() map[string]*Schema {
+	return g.definitions
+}
+
+// SchemaFor returns the schema of the type, as a reference if the type has a definition.
+func (g *Generator) SchemaFor(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return &Schema{OneOf: []*Schema{g.SchemaFor(t.Elem()), {Type: "null"}}}
+	}
+
+	if t == reflect.TypeFor[chat.BroChatError]() {
+		return g.definition(t, func() *Schema { return brochatErrorSchema })
+	}
+
+	if enum, ok := enumValues[t]; ok {
+		return g.definition(t, func() *Schema {
+			return &Schema{Type: jsonType(reflect.TypeOf(enum[0])), Enum: enum}
+		})
+	}
+
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeFor[[]byte]():
+		return &Schema{Type: "string", ContentEncoding: "base64"}
+	case reflect.TypeFor[json.RawMessage]():
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.definition(t, func() *Schema { return g.structSchema(t) })
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.SchemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.SchemaFor(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	}
+
+	return &Schema{Type: jsonType(t)}
+}
+
+// definition adds the schema of a named type to the definitions, if it is not already present, and returns a reference to it.
+func (g *Generator) definition(t reflect.Type, build func() *Schema) *Schema {
+	name := t.Name()
+
+	if _, ok := g.definitions[name]; !ok {
+		// Reserve the name before building so recursive types refer to the definition rather than recursing forever
+		g.definitions[name] = nil
+		g.definitions[name] = build()
+	}
+
+	return &Schema{Ref: g.refPrefix + name}
+}
+
+// structSchema describes the JSON encoding of a struct following the encoding/json field rules.
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+
+	return schema
+}
+
+// addFields adds the fields of the struct to the schema. The fields of embedded structs are promoted as encoding/json does.
+func (g *Generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addFields(schema, field.Type)
+			continue
+		}
+
+		if !field.IsExported() || (name == "-" && options == "") {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldType := field.Type
+		omitEmpty := strings.Contains(options, "omitempty")
+
+		// A nil pointer with omitempty is left out rather than encoded as null
+		if omitEmpty && fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		schema.Properties[name] = g.SchemaFor(fieldType)
+
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonType returns the JSON Schema type of a basic Go type.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	}
+
+	return "object"
+}
+
+// names returns the readable names which the enum values are encoded as.
+func names[T interface{ String() string }](enum ...T) []any {
+	result := make([]any, 0, len(enum))
+
+	for _, value := range enum {
+		result = append(result, value.String())
+	}
+
+	return result
+}
+
+// values returns the enum values, which are encoded as their underlying type.
+func values[T ~string | ~uint8](enum ...T) []any {
+	result := make([]any, 0, len(enum))
+
+	for _, value := range enum {
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,239 @@
+// Package schemas exports JSON Schema documents for the BroChat models, so web frontends can validate payloads and
+// implementations in other languages can run contract tests against the same definitions as the Go clients.
+package schemas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/dmars8047/brolib/chat"
+	"github.com/dmars8047/brolib/idam"
+)
+
+// The JSON Schema dialect of the generated documents.
+const JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+
+// models lists every type sent over the wire by the BroChat API and feed.
+var models = []reflect.Type{
+	// Messages
+	reflect.TypeFor[chat.ChatMessage](),
+	reflect.TypeFor[chat.ChatMessageRequest](),
+	reflect.TypeFor[chat.EditMessageRequest](),
+	reflect.TypeFor[chat.MessageReference](),
+	reflect.TypeFor[chat.MessageRevision](),
+	reflect.TypeFor[chat.MessageSearchResult](),
+	reflect.TypeFor[chat.MessageDraft](),
+	reflect.TypeFor[chat.SaveMessageDraftRequest](),
+	reflect.TypeFor[chat.ReactionSummary](),
+	reflect.TypeFor[chat.AddReactionRequest](),
+	reflect.TypeFor[chat.Attachment](),
+	reflect.TypeFor[chat.Embed](),
+	reflect.TypeFor[chat.ExportedMessage](),
+	reflect.TypeFor[chat.ImportMessagesRequest](),
+	reflect.TypeFor[chat.ImportMessagesResult](),
+
+	// Users
+	reflect.TypeFor[chat.User](),
+	reflect.TypeFor[chat.UserInfo](),
+	reflect.TypeFor[chat.UserStatus](),
+	reflect.TypeFor[chat.SetStatusRequest](),
+	reflect.TypeFor[chat.SetPresenceRequest](),
+	reflect.TypeFor[chat.GetUsersByIdsRequest](),
+	reflect.TypeFor[chat.NotificationPreferences](),
+	reflect.TypeFor[chat.PrivacySettings](),
+	reflect.TypeFor[chat.SigningKey](),
+	reflect.TypeFor[chat.AddSigningKeyRequest](),
+	reflect.TypeFor[chat.MessageSignature](),
+	reflect.TypeFor[chat.DataExport](),
+	reflect.TypeFor[chat.SendFriendRequestRequest](),
+	reflect.TypeFor[chat.AcceptFriendRequestRequest](),
+	reflect.TypeFor[chat.Report](),
+	reflect.TypeFor[chat.ReportRequest](),
+
+	// Channels
+	reflect.TypeFor[chat.Channel](),
+	reflect.TypeFor[chat.UnreadState](),
+	reflect.TypeFor[chat.ReadPointer](),
+	reflect.TypeFor[chat.MarkChannelReadRequest](),
+	reflect.TypeFor[chat.MuteChannelRequest](),
+	reflect.TypeFor[chat.CreateGroupDirectMessageRequest](),
+
+	// Rooms
+	reflect.TypeFor[chat.Room](),
+	reflect.TypeFor[chat.CreateRoomRequest](),
+	reflect.TypeFor[chat.UpdateRoomRequest](),
+	reflect.TypeFor[chat.JoinRoomRequest](),
+	reflect.TypeFor[chat.RoomJoinRequest](),
+	reflect.TypeFor[chat.RequestToJoinRoomRequest](),
+	reflect.TypeFor[chat.AuditEntry](),
+	reflect.TypeFor[chat.KickUserFromRoomRequest](),
+	reflect.TypeFor[chat.InviteUserToRoomRequest](),
+	reflect.TypeFor[chat.AcceptRoomInviteRequest](),
+	reflect.TypeFor[chat.RoomInvite](),
+	reflect.TypeFor[chat.CreateRoomInviteRequest](),
+	reflect.TypeFor[chat.Webhook](),
+	reflect.TypeFor[chat.CreateWebhookRequest](),
+	reflect.TypeFor[chat.WebhookEvent](),
+	reflect.TypeFor[chat.CreateVoiceChannelRequest](),
+	reflect.TypeFor[chat.JoinVoiceChannelRequest](),
+	reflect.TypeFor[chat.UpdateVoiceStateRequest](),
+	reflect.TypeFor[chat.VoiceParticipant](),
+
+	// Server
+	reflect.TypeFor[chat.ServerHealth](),
+	reflect.TypeFor[chat.HealthCheck](),
+	reflect.TypeFor[chat.ServerStats](),
+
+	// Errors
+	reflect.TypeFor[chat.BroChatError](),
+	reflect.TypeFor[chat.FieldError](),
+
+	// Feed
+	reflect.TypeFor[chat.FeedMessage](),
+
+	// Identity and access management
+	reflect.TypeFor[idam.Session](),
+	reflect.TypeFor[idam.DeviceSession](),
+	reflect.TypeFor[idam.UserRegistration](),
+	reflect.TypeFor[idam.RegisterRequest](),
+	reflect.TypeFor[idam.LoginRequest](),
+	reflect.TypeFor[idam.RefreshTokenRequest](),
+	reflect.TypeFor[idam.LogoutRequest](),
+	reflect.TypeFor[idam.ForgotPasswordRequest](),
+	reflect.TypeFor[idam.VerifyEmailRequest](),
+}
+
+// feedPayloads maps each feed message type to the type of its content. Types whose content is not defined by this
+// library are absent.
+var feedPayloads = map[chat.FeedMessageType]reflect.Type{
+	chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE_REQUEST:           reflect.TypeFor[chat.ChatMessageRequest](),
+	chat.FEED_MESSAGE_TYPE_SET_ACTIVE_CHANNEL_REQUEST:     reflect.TypeFor[chat.SetActiveChannelRequest](),
+	chat.FEED_MESSAGE_TYPE_USER_ONLINE_EVENT:              reflect.TypeFor[chat.UserPresenceEvent](),
+	chat.FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT:             reflect.TypeFor[chat.UserPresenceEvent](),
+	chat.FEED_MESSAGE_TYPE_CHAT_NOTIFICATION:              reflect.TypeFor[chat.ChatNotification](),
+	chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE:                   reflect.TypeFor[chat.ChatMessage](),
+	chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_RECIEVED:        reflect.TypeFor[chat.FriendRequestRecievedEvent](),
+	chat.FEED_MESSAGE_TYPE_FRIEND_REQUEST_ACCEPTED:        reflect.TypeFor[chat.FriendRequestAcceptedEvent](),
+	chat.FEED_MESSAGE_TYPE_FRIEND_REMOVED:                 reflect.TypeFor[chat.FriendRemovedEvent](),
+	chat.FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RECEIVED:     reflect.TypeFor[chat.RoomJoinRequestReceivedEvent](),
+	chat.FEED_MESSAGE_TYPE_ROOM_JOIN_REQUEST_RESOLVED:     reflect.TypeFor[chat.RoomJoinRequestResolvedEvent](),
+	chat.FEED_MESSAGE_TYPE_ROOM_INVITE_RECEIVED:           reflect.TypeFor[chat.RoomInviteReceivedEvent](),
+	chat.FEED_MESSAGE_TYPE_USER_KICKED_FROM_ROOM:          reflect.TypeFor[chat.UserKickedFromRoomEvent](),
+	chat.FEED_MESSAGE_TYPE_USER_STATUS_CHANGED:            reflect.TypeFor[chat.UserStatusChangedEvent](),
+	chat.FEED_MESSAGE_TYPE_USER_PROFILE_UPDATED:           reflect.TypeFor[chat.UserProfileUpdatedEvent](),
+	chat.FEED_MESSAGE_TYPE_RELATIONSHIP_CHANGED:           reflect.TypeFor[chat.RelationshipChangedEvent](),
+	chat.FEED_MESSAGE_TYPE_ROOMS_CHANGED:                  reflect.TypeFor[chat.RoomsChangedEvent](),
+	chat.FEED_MESSAGE_TYPE_CHANNEL_UPDATED:                reflect.TypeFor[chat.ChannelUpdatedEvent](),
+	chat.FEED_MESSAGE_TYPE_READ_POINTER_UPDATED:           reflect.TypeFor[chat.ReadPointer](),
+	chat.FEED_MESSAGE_TYPE_MACRO_REQUEST:                  reflect.TypeFor[chat.MacroRequest](),
+	chat.FEED_MESSAGE_TYPE_MESSAGE_ENRICHED:               reflect.TypeFor[chat.MessageEnrichedEvent](),
+	chat.FEED_MESSAGE_TYPE_VOICE_NOTE:                     reflect.TypeFor[chat.VoiceNoteEvent](),
+	chat.FEED_MESSAGE_TYPE_MESSAGE_EXPIRED:                reflect.TypeFor[chat.MessageExpiredEvent](),
+	chat.FEED_MESSAGE_TYPE_MESSAGE_DELIVERY_STATE_UPDATED: reflect.TypeFor[chat.MessageDeliveryStateUpdatedEvent](),
+	chat.FEED_MESSAGE_TYPE_REACTION_ADDED:                 reflect.TypeFor[chat.ReactionEvent](),
+	chat.FEED_MESSAGE_TYPE_REACTION_REMOVED:               reflect.TypeFor[chat.ReactionEvent](),
+	chat.FEED_MESSAGE_TYPE_CALL_OFFER:                     reflect.TypeFor[chat.CallOffer](),
+	chat.FEED_MESSAGE_TYPE_CALL_ANSWER:                    reflect.TypeFor[chat.CallAnswer](),
+	chat.FEED_MESSAGE_TYPE_CALL_ICE_CANDIDATE:             reflect.TypeFor[chat.CallIceCandidate](),
+	chat.FEED_MESSAGE_TYPE_CALL_ENDED:                     reflect.TypeFor[chat.CallEnded](),
+	chat.FEED_MESSAGE_TYPE_CALL_MEDIA_STATE_CHANGED:       reflect.TypeFor[chat.CallMediaStateChanged](),
+	chat.FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STARTED:      reflect.TypeFor[chat.CallScreenShareStarted](),
+	chat.FEED_MESSAGE_TYPE_CALL_SCREEN_SHARE_STOPPED:      reflect.TypeFor[chat.CallScreenShareStopped](),
+	chat.FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_JOINED:       reflect.TypeFor[chat.VoiceParticipantEvent](),
+	chat.FEED_MESSAGE_TYPE_VOICE_PARTICIPANT_LEFT:         reflect.TypeFor[chat.VoiceParticipantEvent](),
+	chat.FEED_MESSAGE_TYPE_VOICE_STATE_UPDATED:            reflect.TypeFor[chat.VoiceParticipantEvent](),
+}
+
+// Generate returns a standalone JSON Schema document for the type. Named struct and enum types are placed in $defs.
+func Generate(t reflect.Type) *Schema {
+	g := NewGenerator("#/$defs/")
+	root := g.SchemaFor(t)
+	root.Schema = JSON_SCHEMA_DIALECT
+	root.Defs = g.Definitions()
+
+	return root
+}
+
+// For returns a standalone JSON Schema document for T.
+// Usage: schemas.For[chat.ChatMessage]()
+func For[T any]() *Schema {
+	return Generate(reflect.TypeFor[T]())
+}
+
+// All returns a standalone JSON Schema document for every model and feed payload, keyed by type name.
+func All() map[string]*Schema {
+	all := make(map[string]*Schema)
+
+	for _, t := range allTypes() {
+		all[t.Name()] = Generate(t)
+	}
+
+	return all
+}
+
+// Bundle returns a single JSON Schema document defining every model and feed payload in $defs.
+// Individual models can be referenced by fragment. Example: bundle.json#/$defs/ChatMessage
+func Bundle() *Schema {
+	g := NewGenerator("#/$defs/")
+
+	for _, t := range allTypes() {
+		g.SchemaFor(t)
+	}
+
+	return &Schema{Schema: JSON_SCHEMA_DIALECT, Defs: g.Definitions()}
+}
+
+// FeedPayload returns a standalone JSON Schema document for the decoded content of a feed message of the given type.
+// Returns false if the content of the type is not defined by this library.
+func FeedPayload(messageType chat.FeedMessageType) (*Schema, bool) {
+	t, ok := feedPayloads[messageType]
+
+	if !ok {
+		return nil, false
+	}
+
+	return Generate(t), true
+}
+
+// WriteFiles writes the schema of every model and feed payload to the directory as <TypeName>.schema.json,
+// creating the directory if needed.
+func WriteFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for name, schema := range All() {
+		data, err := json.MarshalIndent(schema, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name+".schema.json"), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allTypes returns the models followed by the feed payload types which are not also models.
+func allTypes() []reflect.Type {
+	types := append([]reflect.Type{}, models...)
+	seen := make(map[reflect.Type]bool, len(types))
+
+	for _, t := range types {
+		seen[t] = true
+	}
+
+	for _, messageType := range enumValues[reflect.TypeFor[chat.FeedMessageType]()] {
+		if t, ok := feedPayloads[messageType.(chat.FeedMessageType)]; ok && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	return types
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The buckets used by the BoltMessageStore. Each channel has a bucket under the channels bucket holding a messages
+// bucket, keyed by receive time and sequence so a cursor walks the channel in order, and an ids bucket mapping message
+// IDs to their keys.
+var (
+	boltMetaBucket     = []byte("meta")
+	boltVersionKey     = []byte("version")
+	boltChannelsBucket = []byte("channels")
+	boltMessagesBucket = []byte("messages")
+	boltIdsBucket      = []byte("ids")
+)
+
+// boltMigrations are applied in order to bring the database up to date. The index of a migration plus one is the
+// schema version it produces. Never edit a released migration, append a new one instead.
+var boltMigrations = []func(tx *bolt.Tx) error{
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltChannelsBucket)
+		return err
+	},
+}
+
+// BoltMessageStore is a MessageStore backed by a bbolt database. Suitable for self-hosted single binary servers.
+type BoltMessageStore struct {
+	db *bolt.DB
+}
+
+// NewBoltMessageStore creates a message store using the database, applying any pending migrations.
+// The caller remains responsible for closing the database.
+func NewBoltMessageStore(db *bolt.DB) (*BoltMessageStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(boltMetaBucket)
+
+		if err != nil {
+			return err
+		}
+
+		version := 0
+
+		if value := meta.Get(boltVersionKey); value != nil {
+			version = int(binary.BigEndian.Uint64(value))
+		}
+
+		if version > len(boltMigrations) {
+			return fmt.Errorf("message store schema version %d is newer than the supported version %d", version, len(boltMigrations))
+		}
+
+		for ; version < len(boltMigrations); version++ {
+			if err := boltMigrations[version](tx); err != nil {
+				return fmt.Errorf("applying message store migration %d: %w", version+1, err)
+			}
+		}
+
+		return meta.Put(boltVersionKey, binary.BigEndian.AppendUint64(nil, uint64(version)))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoltMessageStore{db: db}, nil
+}
+
+func (s *BoltMessageStore) Append(_ context.Context, message chat.ChatMessage) error {
+	data, err := json.Marshal(message)
+
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		channel, err := tx.Bucket(boltChannelsBucket).CreateBucketIfNotExists([]byte(message.ChannelId))
+
+		if err != nil {
+			return err
+		}
+
+		messages, err := channel.CreateBucketIfNotExists(boltMessagesBucket)
+
+		if err != nil {
+			return err
+		}
+
+		ids, err := channel.CreateBucketIfNotExists(boltIdsBucket)
+
+		if err != nil {
+			return err
+		}
+
+		if ids.Get([]byte(message.Id)) != nil {
+			return ErrMessageExists
+		}
+
+		seq, err := messages.NextSequence()
+
+		if err != nil {
+			return err
+		}
+
+		// Flipping the sign bit makes the big endian encoding of the receive time sort correctly for times before 1970.
+		key := binary.BigEndian.AppendUint64(nil, uint64(message.ReceivedAt().UnixNano())^(1<<63))
+		key = binary.BigEndian.AppendUint64(key, seq)
+
+		if err := messages.Put(key, data); err != nil {
+			return err
+		}
+
+		return ids.Put([]byte(message.Id), key)
+	})
+}
+
+func (s *BoltMessageStore) GetPage(_ context.Context, channelId string, page uint64, pageSize uint64) ([]chat.ChatMessage, error) {
+	pageSize = normalizePageSize(pageSize)
+	skip := (normalizePage(page) - 1) * pageSize
+	result := make([]chat.ChatMessage, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		messages, _ := boltChannelBuckets(tx, channelId)
+
+		if messages == nil {
+			return nil
+		}
+
+		c := messages.Cursor()
+		k, v := c.Last()
+
+		for ; k != nil && skip > 0; skip-- {
+			k, v = c.Prev()
+		}
+
+		var err error
+		result, err = readBoltMessages(c, k, v, pageSize)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (s *BoltMessageStore) GetBefore(_ context.Context, channelId string, messageId string, limit uint64) ([]chat.ChatMessage, error) {
+	var result []chat.ChatMessage
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		messages, ids := boltChannelBuckets(tx, channelId)
+
+		if messages == nil {
+			return ErrMessageNotFound
+		}
+
+		key := ids.Get([]byte(messageId))
+
+		if key == nil {
+			return ErrMessageNotFound
+		}
+
+		c := messages.Cursor()
+		c.Seek(key)
+		k, v := c.Prev()
+
+		var err error
+		result, err = readBoltMessages(c, k, v, normalizePageSize(limit))
+
+		return err
+	})
+
+	return result, err
+}
+
+func (s *BoltMessageStore) Delete(_ context.Context, channelId string, messageId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		messages, ids := boltChannelBuckets(tx, channelId)
+
+		if messages == nil {
+			return ErrMessageNotFound
+		}
+
+		key := ids.Get([]byte(messageId))
+
+		if key == nil {
+			return ErrMessageNotFound
+		}
+
+		if err := messages.Delete(key); err != nil {
+			return err
+		}
+
+		return ids.Delete([]byte(messageId))
+	})
+}
+
+func (s *BoltMessageStore) Search(_ context.Context, query MessageSearchQuery) ([]chat.MessageSearchResult, error) {
+	results := make([]chat.MessageSearchResult, 0)
+
+	if query.Text == "" {
+		return results, nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, channelId := range query.ChannelIds {
+			messages, _ := boltChannelBuckets(tx, channelId)
+
+			if messages == nil {
+				continue
+			}
+
+			c := messages.Cursor()
+
+			for k, v := c.Last(); k != nil; k, v = c.Prev() {
+				var m chat.ChatMessage
+
+				if err := json.Unmarshal(v, &m); err != nil {
+					return err
+				}
+
+				if !query.matchesFilters(m) {
+					continue
+				}
+
+				if highlights := MatchHighlights(m.Content, query.Text); len(highlights) > 0 {
+					results = append(results, chat.MessageSearchResult{Message: m, Highlights: highlights})
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pageSearchResults(results, query), nil
+}
+
+// boltChannelBuckets returns the messages and ids buckets of the channel, or nils if the channel has no messages.
+func boltChannelBuckets(tx *bolt.Tx, channelId string) (*bolt.Bucket, *bolt.Bucket) {
+	channel := tx.Bucket(boltChannelsBucket).Bucket([]byte(channelId))
+
+	if channel == nil {
+		return nil, nil
+	}
+
+	return channel.Bucket(boltMessagesBucket), channel.Bucket(boltIdsBucket)
+}
+
+// readBoltMessages decodes up to limit messages walking the cursor backwards from the given position.
+func readBoltMessages(c *bolt.Cursor, k []byte, v []byte, limit uint64) ([]chat.ChatMessage, error) {
+	result := make([]chat.ChatMessage, 0)
+
+	for ; k != nil && uint64(len(result)) < limit; k, v = c.Prev() {
+		var m chat.ChatMessage
+
+		if err := json.Unmarshal(v, &m); err != nil {
+			return nil, err
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
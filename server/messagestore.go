@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrMessageNotFound is returned by a MessageStore when the message does not exist in the channel.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrMessageExists is returned by a MessageStore when appending a message with an ID already used in the channel.
+	ErrMessageExists = errors.New("message already exists")
+)
+
+// A MessageSearchQuery describes a search performed by MessageStore.Search.
+type MessageSearchQuery struct {
+	// The text to search for. Matched case-insensitively anywhere in the message content.
+	Text string
+	// The channels to search. Callers should restrict this to the channels the searching user is a member of.
+	ChannelIds []string
+	// Only match messages sent by this user. Ignored if empty.
+	SenderUserId string
+	// Only match messages sent at or after this time. Ignored if zero.
+	After time.Time
+	// Only match messages sent before this time. Ignored if zero.
+	Before time.Time
+	// The page of results to return, starting at 1. Zero is treated as 1.
+	Page uint64
+	// The size of each page. Zero or anything over chat.MAX_PAGE_SIZE is treated as chat.MAX_PAGE_SIZE.
+	PageSize uint64
+}
+
+// A MessageStore persists the messages of channels. Messages are returned most recent first.
+// Implementations must be safe for concurrent use.
+type MessageStore interface {
+	// Append stores a new message in its channel or returns ErrMessageExists.
+	Append(ctx context.Context, message chat.ChatMessage) error
+	// GetPage returns a page of the channel's messages. Page numbers start at 1 and page sizes follow the same rules as
+	// MessageSearchQuery.
+	GetPage(ctx context.Context, channelId string, page uint64, pageSize uint64) ([]chat.ChatMessage, error)
+	// GetBefore returns up to limit messages sent before the given message or returns ErrMessageNotFound. The limit
+	// follows the same rules as a page size.
+	GetBefore(ctx context.Context, channelId string, messageId string, limit uint64) ([]chat.ChatMessage, error)
+	// Delete removes a message or returns ErrMessageNotFound.
+	Delete(ctx context.Context, channelId string, messageId string) error
+	// Search returns a page of the messages matching the query with the ranges of their content which matched.
+	Search(ctx context.Context, query MessageSearchQuery) ([]chat.MessageSearchResult, error)
+}
+
+// MemoryMessageStore is a MessageStore which keeps messages in memory. Intended as a reference implementation and for tests.
+type MemoryMessageStore struct {
+	mu sync.RWMutex
+	// channels maps a channel ID to its messages ordered oldest first.
+	channels map[string][]chat.ChatMessage
+}
+
+// NewMemoryMessageStore creates an empty in memory message store.
+func NewMemoryMessageStore() *MemoryMessageStore {
+	return &MemoryMessageStore{channels: make(map[string][]chat.ChatMessage)}
+}
+
+func (s *MemoryMessageStore) Append(_ context.Context, message chat.ChatMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.channels[message.ChannelId]
+
+	if indexOfMessage(messages, message.Id) >= 0 {
+		return ErrMessageExists
+	}
+
+	// Messages normally arrive in order, so search from the end to keep the slice sorted by receive time.
+	i := len(messages)
+
+	for i > 0 && messages[i-1].ReceivedAt().After(message.ReceivedAt()) {
+		i--
+	}
+
+	messages = append(messages, chat.ChatMessage{})
+	copy(messages[i+1:], messages[i:])
+	messages[i] = message
+
+	s.channels[message.ChannelId] = messages
+
+	return nil
+}
+
+func (s *MemoryMessageStore) GetPage(_ context.Context, channelId string, page uint64, pageSize uint64) ([]chat.ChatMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := s.channels[channelId]
+	pageSize = normalizePageSize(pageSize)
+	skip := (normalizePage(page) - 1) * pageSize
+
+	return newestFirst(messages, len(messages)-int(min(skip, uint64(len(messages)))), pageSize), nil
+}
+
+func (s *MemoryMessageStore) GetBefore(_ context.Context, channelId string, messageId string, limit uint64) ([]chat.ChatMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := s.channels[channelId]
+	i := indexOfMessage(messages, messageId)
+
+	if i < 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	return newestFirst(messages, i, normalizePageSize(limit)), nil
+}
+
+func (s *MemoryMessageStore) Delete(_ context.Context, channelId string, messageId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.channels[channelId]
+	i := indexOfMessage(messages, messageId)
+
+	if i < 0 {
+		return ErrMessageNotFound
+	}
+
+	s.channels[channelId] = append(messages[:i], messages[i+1:]...)
+
+	return nil
+}
+
+func (s *MemoryMessageStore) Search(_ context.Context, query MessageSearchQuery) ([]chat.MessageSearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]chat.MessageSearchResult, 0)
+
+	if query.Text == "" {
+		return results, nil
+	}
+
+	for _, channelId := range query.ChannelIds {
+		messages := s.channels[channelId]
+
+		for i := len(messages) - 1; i >= 0; i-- {
+			m := messages[i]
+
+			if !query.matchesFilters(m) {
+				continue
+			}
+
+			if highlights := MatchHighlights(m.Content, query.Text); len(highlights) > 0 {
+				results = append(results, chat.MessageSearchResult{Message: m, Highlights: highlights})
+			}
+		}
+	}
+
+	return pageSearchResults(results, query), nil
+}
+
+// matchesFilters returns true if the message satisfies the sender and time filters of the query.
+func (q MessageSearchQuery) matchesFilters(m chat.ChatMessage) bool {
+	if q.SenderUserId != "" && m.SenderUserId != q.SenderUserId {
+		return false
+	}
+
+	if !q.After.IsZero() && m.ReceivedAt().Before(q.After) {
+		return false
+	}
+
+	if !q.Before.IsZero() && !m.ReceivedAt().Before(q.Before) {
+		return false
+	}
+
+	return true
+}
+
+// MatchHighlights returns the non-overlapping ranges of the content which match the text case-insensitively.
+// Offsets are byte offsets into the content as required by chat.HighlightRange. Exposed so that MessageStore
+// implementations which find matches themselves can produce the same highlights.
+func MatchHighlights(content string, text string) []chat.HighlightRange {
+	highlights := make([]chat.HighlightRange, 0)
+	runes := utf8.RuneCountInString(text)
+
+	if runes == 0 {
+		return highlights
+	}
+
+	for i := 0; i < len(content); {
+		// Compare the same number of runes rather than bytes as case folding can change the encoded length.
+		end := i
+
+		for n := 0; n < runes && end < len(content); n++ {
+			_, size := utf8.DecodeRuneInString(content[end:])
+			end += size
+		}
+
+		if strings.EqualFold(content[i:end], text) {
+			highlights = append(highlights, chat.HighlightRange{Start: i, End: end})
+			i = end
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(content[i:])
+		i += size
+	}
+
+	return highlights
+}
+
+// pageSearchResults sorts the results most recent first and returns the page requested by the query. The results of each
+// channel must already be most recent first so that messages received at the same time keep their order.
+func pageSearchResults(results []chat.MessageSearchResult, query MessageSearchQuery) []chat.MessageSearchResult {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Message.ReceivedAt().After(results[j].Message.ReceivedAt())
+	})
+
+	pageSize := normalizePageSize(query.PageSize)
+	start := min((normalizePage(query.Page)-1)*pageSize, uint64(len(results)))
+	end := min(start+pageSize, uint64(len(results)))
+
+	return results[start:end]
+}
+
+// newestFirst returns up to limit of the messages before index end, most recent first.
+func newestFirst(messages []chat.ChatMessage, end int, limit uint64) []chat.ChatMessage {
+	start := max(end-int(limit), 0)
+	page := make([]chat.ChatMessage, 0, end-start)
+
+	for i := end - 1; i >= start; i-- {
+		page = append(page, messages[i])
+	}
+
+	return page
+}
+
+// indexOfMessage returns the index of the message with the ID or -1.
+func indexOfMessage(messages []chat.ChatMessage, messageId string) int {
+	for i, m := range messages {
+		if m.Id == messageId {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// normalizePage returns the page number with zero treated as the first page.
+func normalizePage(page uint64) uint64 {
+	return max(page, 1)
+}
+
+// normalizePageSize returns the page size with zero or oversized values replaced by chat.MAX_PAGE_SIZE.
+func normalizePageSize(pageSize uint64) uint64 {
+	if pageSize == 0 || pageSize > chat.MAX_PAGE_SIZE {
+		return chat.MAX_PAGE_SIZE
+	}
+
+	return pageSize
+}
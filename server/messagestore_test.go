@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	bolt "go.etcd.io/bbolt"
+)
+
+// testMessageStores creates an empty store of each MessageStore implementation.
+var testMessageStores = map[string]func(t *testing.T) MessageStore{
+	"memory": func(t *testing.T) MessageStore {
+		return NewMemoryMessageStore()
+	},
+	"bolt": func(t *testing.T) MessageStore {
+		db, err := bolt.Open(filepath.Join(t.TempDir(), "messages.db"), 0600, nil)
+
+		if err != nil {
+			t.Fatalf("bolt.Open() error = %v", err)
+		}
+
+		t.Cleanup(func() { db.Close() })
+
+		store, err := NewBoltMessageStore(db)
+
+		if err != nil {
+			t.Fatalf("NewBoltMessageStore() error = %v", err)
+		}
+
+		return store
+	},
+	"sqlite": func(t *testing.T) MessageStore {
+		store, err := NewSQLiteMessageStore(context.Background(), openTestSQLiteDB(t))
+
+		if err != nil {
+			t.Fatalf("NewSQLiteMessageStore() error = %v", err)
+		}
+
+		return store
+	},
+}
+
+// testMessageTime returns the receive time of the nth test message.
+func testMessageTime(n int) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, n, 0, time.UTC)
+}
+
+// seedMessages appends messages m1 to m5 to channel c, sent in order by alternating users, and m6 to channel d.
+// m3 is appended last to check that stores order messages by receive time.
+func seedMessages(t *testing.T, store MessageStore) {
+	t.Helper()
+
+	for _, n := range []int{1, 2, 4, 5, 3, 6} {
+		message := chat.ChatMessage{
+			Id:            fmt.Sprintf("m%d", n),
+			ChannelId:     "c",
+			SenderUserId:  []string{"alice", "bob"}[n%2],
+			Content:       fmt.Sprintf("Hello number %d", n),
+			ReceivedAtUtc: testMessageTime(n),
+		}
+
+		if n == 6 {
+			message.ChannelId = "d"
+		}
+
+		if err := store.Append(context.Background(), message); err != nil {
+			t.Fatalf("Append(%s) error = %v", message.Id, err)
+		}
+	}
+}
+
+func messageIds(messages []chat.ChatMessage) []string {
+	ids := make([]string, len(messages))
+
+	for i, m := range messages {
+		ids[i] = m.Id
+	}
+
+	return ids
+}
+
+func TestMessageStore_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		read    func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error)
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "first page",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetPage(ctx, "c", 1, 2)
+			},
+			want: []string{"m5", "m4"},
+		},
+		{
+			name: "last partial page",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetPage(ctx, "c", 3, 2)
+			},
+			want: []string{"m1"},
+		},
+		{
+			name: "page past the end",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetPage(ctx, "c", 4, 2)
+			},
+			want: []string{},
+		},
+		{
+			name: "page zero and size zero",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetPage(ctx, "c", 0, 0)
+			},
+			want: []string{"m5", "m4", "m3", "m2", "m1"},
+		},
+		{
+			name: "unknown channel",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetPage(ctx, "missing", 1, 10)
+			},
+			want: []string{},
+		},
+		{
+			name: "before a message",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetBefore(ctx, "c", "m4", 2)
+			},
+			want: []string{"m3", "m2"},
+		},
+		{
+			name: "before the oldest message",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetBefore(ctx, "c", "m1", 10)
+			},
+			want: []string{},
+		},
+		{
+			name: "before a message of another channel",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return store.GetBefore(ctx, "c", "m6", 10)
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name: "after deleting a message",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				if err := store.Delete(ctx, "c", "m3"); err != nil {
+					return nil, err
+				}
+
+				return store.GetPage(ctx, "c", 1, 10)
+			},
+			want: []string{"m5", "m4", "m2", "m1"},
+		},
+		{
+			name: "deleting a missing message",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return nil, store.Delete(ctx, "d", "m3")
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name: "appending an existing message",
+			read: func(ctx context.Context, store MessageStore) ([]chat.ChatMessage, error) {
+				return nil, store.Append(ctx, chat.ChatMessage{Id: "m1", ChannelId: "c", ReceivedAtUtc: testMessageTime(7)})
+			},
+			wantErr: ErrMessageExists,
+		},
+	}
+
+	for storeName, newStore := range testMessageStores {
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				store := newStore(t)
+				seedMessages(t, store)
+
+				messages, err := tt.read(context.Background(), store)
+
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+
+				if tt.wantErr == nil && !slices.Equal(messageIds(messages), tt.want) {
+					t.Errorf("messages = %v, want %v", messageIds(messages), tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestMessageStore_Search(t *testing.T) {
+	tests := []struct {
+		name  string
+		query MessageSearchQuery
+		want  []string
+	}{
+		{"matches case-insensitively", MessageSearchQuery{Text: "HELLO", ChannelIds: []string{"c"}}, []string{"m5", "m4", "m3", "m2", "m1"}},
+		{"only searches the given channels", MessageSearchQuery{Text: "number", ChannelIds: []string{"d"}}, []string{"m6"}},
+		{"searches several channels", MessageSearchQuery{Text: "number", ChannelIds: []string{"c", "d"}, PageSize: 2}, []string{"m6", "m5"}},
+		{"no channels", MessageSearchQuery{Text: "hello"}, []string{}},
+		{"empty text", MessageSearchQuery{ChannelIds: []string{"c"}}, []string{}},
+		{"by sender", MessageSearchQuery{Text: "hello", ChannelIds: []string{"c"}, SenderUserId: "bob"}, []string{"m5", "m3", "m1"}},
+		{"within a time range", MessageSearchQuery{Text: "hello", ChannelIds: []string{"c"}, After: testMessageTime(2), Before: testMessageTime(4)}, []string{"m3", "m2"}},
+		{"second page", MessageSearchQuery{Text: "hello", ChannelIds: []string{"c"}, Page: 2, PageSize: 2}, []string{"m3", "m2"}},
+		{"no match", MessageSearchQuery{Text: "goodbye", ChannelIds: []string{"c"}}, []string{}},
+		{"wildcards are literal", MessageSearchQuery{Text: "%", ChannelIds: []string{"c"}}, []string{}},
+	}
+
+	for storeName, newStore := range testMessageStores {
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				store := newStore(t)
+				seedMessages(t, store)
+
+				results, err := store.Search(context.Background(), tt.query)
+
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+
+				ids := make([]string, len(results))
+
+				for i, result := range results {
+					ids[i] = result.Message.Id
+
+					if want := []chat.HighlightRange{{Start: 0, End: len(tt.query.Text)}}; tt.query.Text == "HELLO" && !slices.Equal(result.Highlights, want) {
+						t.Errorf("highlights of %s = %v, want %v", result.Message.Id, result.Highlights, want)
+					}
+				}
+
+				if !slices.Equal(ids, tt.want) {
+					t.Errorf("results = %v, want %v", ids, tt.want)
+				}
+			})
+		}
+	}
+}
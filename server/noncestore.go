@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/serverutil"
+)
+
+// A NonceStore remembers the nonces of feed requests accepted by a ReplayGuard until they expire. Implementations must
+// be safe for concurrent use.
+type NonceStore interface {
+	// Add records the nonce until it expires. Returns false if the nonce is already recorded and has not expired, meaning
+	// the request is a replay.
+	Add(ctx context.Context, nonce string, expiresAt time.Time, now time.Time) (bool, error)
+}
+
+// MemoryNonceStore is a NonceStore which keeps nonces in memory. Suitable for a single server instance.
+type MemoryNonceStore struct {
+	mu sync.Mutex
+	// nonces maps a nonce to its expiry.
+	nonces map[string]time.Time
+	// The next time expired nonces are swept.
+	nextSweep time.Time
+}
+
+// NewMemoryNonceStore creates an empty in memory nonce store.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) Add(_ context.Context, nonce string, expiresAt time.Time, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if existing, ok := s.nonces[nonce]; ok && existing.After(now) {
+		return false, nil
+	}
+
+	s.nonces[nonce] = expiresAt
+
+	return true, nil
+}
+
+// sweep removes expired nonces, at most once a minute. Must be called with the lock held.
+func (s *MemoryNonceStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for nonce, expiresAt := range s.nonces {
+		if !expiresAt.After(now) {
+			delete(s.nonces, nonce)
+		}
+	}
+
+	s.nextSweep = now.Add(time.Minute)
+}
+
+// redisNonceAddScript sets the key only if it does not exist, expiring it at the given time in milliseconds.
+const redisNonceAddScript = `
+if redis.call('SET', KEYS[1], '1', 'PXAT', ARGV[1], 'NX') then return 1 end
+return 0
+`
+
+// RedisNonceStore is a NonceStore which keeps nonces in Redis, so a request replayed to another server instance is
+// also rejected. Requires Redis 6.2 or later.
+type RedisNonceStore struct {
+	evaluator serverutil.RedisEvaluator
+	prefix    string
+}
+
+// NewRedisNonceStore creates a nonce store using the evaluator. Keys are prefixed with the given prefix.
+func NewRedisNonceStore(evaluator serverutil.RedisEvaluator, prefix string) *RedisNonceStore {
+	return &RedisNonceStore{evaluator: evaluator, prefix: prefix}
+}
+
+func (s *RedisNonceStore) Add(ctx context.Context, nonce string, expiresAt time.Time, _ time.Time) (bool, error) {
+	reply, err := s.evaluator.Eval(ctx, redisNonceAddScript, []string{s.prefix + "nonce:" + nonce}, expiresAt.UnixMilli())
+
+	if err != nil {
+		return false, err
+	}
+
+	return redisBool(reply)
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// The default time a feed session stays live without a heartbeat.
+const DEFAULT_PRESENCE_TTL = 90 * time.Second
+
+// A FeedPublisher delivers feed messages to the feed connections of users. Typically implemented by the server's
+// connection hub.
+type FeedPublisher interface {
+	Publish(ctx context.Context, recipientUserIds []string, message *chat.FeedMessage) error
+}
+
+// FeedPublisherFunc adapts a function to the FeedPublisher interface.
+type FeedPublisherFunc func(ctx context.Context, recipientUserIds []string, message *chat.FeedMessage) error
+
+// Publish implements the FeedPublisher interface.
+func (f FeedPublisherFunc) Publish(ctx context.Context, recipientUserIds []string, message *chat.FeedMessage) error {
+	return f(ctx, recipientUserIds, message)
+}
+
+// An AudienceResolver returns the IDs of the users that should be told about changes to a user. Example: their friends.
+type AudienceResolver func(ctx context.Context, userId string) ([]string, error)
+
+// PresenceTracker tracks which users are online from their feed connections and heartbeats. When a user's first
+// session starts a FEED_MESSAGE_TYPE_USER_ONLINE_EVENT is published to their audience, and when their last session
+// ends or expires a FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT is published.
+type PresenceTracker struct {
+	store     PresenceStore
+	publisher FeedPublisher
+	audience  AudienceResolver
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// PresenceTrackerOption is a type for the options that can be passed to NewPresenceTracker.
+type PresenceTrackerOption func(*PresenceTracker)
+
+// Sets how long a session stays live without a heartbeat. Defaults to DEFAULT_PRESENCE_TTL.
+// Clients should send heartbeats at less than half this interval.
+func PresenceTrackerOption_TTL(ttl time.Duration) PresenceTrackerOption {
+	return func(t *PresenceTracker) {
+		t.ttl = ttl
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func PresenceTrackerOption_Clock(now func() time.Time) PresenceTrackerOption {
+	return func(t *PresenceTracker) {
+		t.now = now
+	}
+}
+
+// NewPresenceTracker creates a presence tracker which publishes presence events to the audience of each user.
+func NewPresenceTracker(store PresenceStore, publisher FeedPublisher, audience AudienceResolver, options ...PresenceTrackerOption) *PresenceTracker {
+	t := &PresenceTracker{
+		store:     store,
+		publisher: publisher,
+		audience:  audience,
+		ttl:       DEFAULT_PRESENCE_TTL,
+		now:       time.Now,
+	}
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	return t
+}
+
+// Connect records a new feed session for the user. Call when a feed connection is established.
+func (t *PresenceTracker) Connect(ctx context.Context, userId string, sessionId string) error {
+	return t.Heartbeat(ctx, userId, sessionId)
+}
+
+// Heartbeat extends the life of a feed session. Call whenever a heartbeat is received on a feed connection.
+func (t *PresenceTracker) Heartbeat(ctx context.Context, userId string, sessionId string) error {
+	now := t.now().UTC()
+	cameOnline, err := t.store.Touch(ctx, userId, sessionId, now.Add(t.ttl), now)
+
+	if err != nil || !cameOnline {
+		return err
+	}
+
+	return t.publish(ctx, userId, chat.PRESENCE_STATE_ONLINE, now)
+}
+
+// Disconnect ends a feed session. Call when a feed connection is closed.
+func (t *PresenceTracker) Disconnect(ctx context.Context, userId string, sessionId string) error {
+	now := t.now().UTC()
+	wentOffline, err := t.store.Remove(ctx, userId, sessionId, now)
+
+	if err != nil || !wentOffline {
+		return err
+	}
+
+	return t.publish(ctx, userId, chat.PRESENCE_STATE_OFFLINE, now)
+}
+
+// IsOnline returns true if the user has a live feed session.
+func (t *PresenceTracker) IsOnline(ctx context.Context, userId string) (bool, error) {
+	return t.store.IsOnline(ctx, userId, t.now().UTC())
+}
+
+// Sweep expires the sessions which have missed their heartbeats and publishes offline events for the affected users.
+// The first publishing error is returned after all users have been processed.
+func (t *PresenceTracker) Sweep(ctx context.Context) error {
+	now := t.now().UTC()
+	offline, err := t.store.Expire(ctx, now)
+
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+
+	for _, userId := range offline {
+		if err := t.publish(ctx, userId, chat.PRESENCE_STATE_OFFLINE, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Run sweeps expired sessions every half TTL until the context is cancelled. Sweep errors are passed to onError, which may be nil.
+func (t *PresenceTracker) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(t.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Sweep(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// publish sends a presence event for the user to their audience.
+func (t *PresenceTracker) publish(ctx context.Context, userId string, presence chat.PresenceState, now time.Time) error {
+	recipients, err := t.audience(ctx, userId)
+
+	if err != nil || len(recipients) == 0 {
+		return err
+	}
+
+	messageType := chat.FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT
+
+	if presence.IsOnline() {
+		messageType = chat.FEED_MESSAGE_TYPE_USER_ONLINE_EVENT
+	}
+
+	message, err := chat.NewFeedMessageJSON(messageType, chat.UserPresenceEvent{
+		UserId:        userId,
+		Presence:      presence,
+		LastOnlineUtc: now,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return t.publisher.Publish(ctx, recipients, message)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+const testPresenceTTL = time.Minute
+
+// A presenceStep is one call made to a PresenceTracker after moving its clock forward.
+type presenceStep struct {
+	advance time.Duration
+	// One of connect, heartbeat, disconnect or sweep.
+	call      string
+	sessionId string
+}
+
+func TestPresenceTracker(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []presenceStep
+		events []string
+		online bool
+	}{
+		{
+			name:   "first session comes online",
+			steps:  []presenceStep{{call: "connect", sessionId: "s1"}},
+			events: []string{"online"},
+			online: true,
+		},
+		{
+			name:   "second session does not publish",
+			steps:  []presenceStep{{call: "connect", sessionId: "s1"}, {call: "connect", sessionId: "s2"}},
+			events: []string{"online"},
+			online: true,
+		},
+		{
+			name: "last session to disconnect goes offline",
+			steps: []presenceStep{
+				{call: "connect", sessionId: "s1"},
+				{call: "connect", sessionId: "s2"},
+				{call: "disconnect", sessionId: "s1"},
+				{call: "disconnect", sessionId: "s2"},
+			},
+			events: []string{"online", "offline"},
+		},
+		{
+			name:  "disconnecting an unknown session",
+			steps: []presenceStep{{call: "disconnect", sessionId: "s1"}},
+		},
+		{
+			name:   "session lives until its ttl",
+			steps:  []presenceStep{{call: "connect", sessionId: "s1"}, {advance: testPresenceTTL - time.Millisecond, call: "sweep"}},
+			events: []string{"online"},
+			online: true,
+		},
+		{
+			name:   "missed heartbeat expires on sweep",
+			steps:  []presenceStep{{call: "connect", sessionId: "s1"}, {advance: testPresenceTTL, call: "sweep"}},
+			events: []string{"online", "offline"},
+		},
+		{
+			name: "heartbeat extends the session",
+			steps: []presenceStep{
+				{call: "connect", sessionId: "s1"},
+				{advance: testPresenceTTL / 2, call: "heartbeat", sessionId: "s1"},
+				{advance: testPresenceTTL / 2, call: "sweep"},
+			},
+			events: []string{"online"},
+			online: true,
+		},
+		{
+			name: "expired session of a user with a live one",
+			steps: []presenceStep{
+				{call: "connect", sessionId: "s1"},
+				{advance: testPresenceTTL / 2, call: "connect", sessionId: "s2"},
+				{advance: testPresenceTTL / 2, call: "sweep"},
+			},
+			events: []string{"online"},
+			online: true,
+		},
+		{
+			name:   "reconnecting after expiry comes online again",
+			steps:  []presenceStep{{call: "connect", sessionId: "s1"}, {advance: testPresenceTTL, call: "connect", sessionId: "s1"}},
+			events: []string{"online", "online"},
+			online: true,
+		},
+		{
+			name: "disconnecting an expired session",
+			steps: []presenceStep{
+				{call: "connect", sessionId: "s1"},
+				{advance: testPresenceTTL, call: "disconnect", sessionId: "s1"},
+			},
+			events: []string{"online"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			events := make([]string, 0)
+
+			publisher := FeedPublisherFunc(func(_ context.Context, recipientUserIds []string, message *chat.FeedMessage) error {
+				if !slices.Equal(recipientUserIds, []string{"friend"}) {
+					t.Errorf("recipients = %v, want the audience of the user", recipientUserIds)
+				}
+
+				switch message.Type {
+				case chat.FEED_MESSAGE_TYPE_USER_ONLINE_EVENT:
+					events = append(events, "online")
+				case chat.FEED_MESSAGE_TYPE_USER_OFFLINE_EVENT:
+					events = append(events, "offline")
+				}
+
+				return nil
+			})
+
+			audience := func(context.Context, string) ([]string, error) { return []string{"friend"}, nil }
+
+			tracker := NewPresenceTracker(NewMemoryPresenceStore(), publisher, audience,
+				PresenceTrackerOption_TTL(testPresenceTTL),
+				PresenceTrackerOption_Clock(func() time.Time { return now }))
+
+			for _, step := range tt.steps {
+				now = now.Add(step.advance)
+
+				var err error
+
+				switch step.call {
+				case "connect":
+					err = tracker.Connect(ctx, "user", step.sessionId)
+				case "heartbeat":
+					err = tracker.Heartbeat(ctx, "user", step.sessionId)
+				case "disconnect":
+					err = tracker.Disconnect(ctx, "user", step.sessionId)
+				case "sweep":
+					err = tracker.Sweep(ctx)
+				}
+
+				if err != nil {
+					t.Fatalf("%s(%s) error = %v", step.call, step.sessionId, err)
+				}
+			}
+
+			if !slices.Equal(events, tt.events) {
+				t.Errorf("events = %v, want %v", events, tt.events)
+			}
+
+			online, err := tracker.IsOnline(ctx, "user")
+
+			if err != nil {
+				t.Fatalf("IsOnline() error = %v", err)
+			}
+
+			if online != tt.online {
+				t.Errorf("IsOnline() = %v, want %v", online, tt.online)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/serverutil"
+)
+
+// A PresenceStore tracks the live feed sessions of users for a PresenceTracker. A user is online while they have at
+// least one unexpired session. Implementations must be safe for concurrent use.
+type PresenceStore interface {
+	// Touch creates or extends a session. Returns true if the user had no live sessions before, meaning they came online.
+	Touch(ctx context.Context, userId string, sessionId string, expiresAt time.Time, now time.Time) (bool, error)
+	// Remove ends a session. Returns true if it was the user's last live session, meaning they went offline.
+	Remove(ctx context.Context, userId string, sessionId string, now time.Time) (bool, error)
+	// Expire removes all sessions which expired at or before now and returns the IDs of the users left without a live session.
+	Expire(ctx context.Context, now time.Time) ([]string, error)
+	// IsOnline returns true if the user has a live session.
+	IsOnline(ctx context.Context, userId string, now time.Time) (bool, error)
+}
+
+// MemoryPresenceStore is a PresenceStore which keeps sessions in memory. Suitable for a single server instance.
+type MemoryPresenceStore struct {
+	mu sync.Mutex
+	// sessions maps a user ID to the expiry of each of their sessions.
+	sessions map[string]map[string]time.Time
+}
+
+// NewMemoryPresenceStore creates an empty in memory presence store.
+func NewMemoryPresenceStore() *MemoryPresenceStore {
+	return &MemoryPresenceStore{sessions: make(map[string]map[string]time.Time)}
+}
+
+func (s *MemoryPresenceStore) Touch(_ context.Context, userId string, sessionId string, expiresAt time.Time, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.prune(userId, now)
+	cameOnline := len(sessions) == 0
+
+	if sessions == nil {
+		sessions = make(map[string]time.Time)
+		s.sessions[userId] = sessions
+	}
+
+	sessions[sessionId] = expiresAt
+
+	return cameOnline, nil
+}
+
+func (s *MemoryPresenceStore) Remove(_ context.Context, userId string, sessionId string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.prune(userId, now)
+
+	if _, ok := sessions[sessionId]; !ok {
+		return false, nil
+	}
+
+	delete(sessions, sessionId)
+
+	if len(sessions) > 0 {
+		return false, nil
+	}
+
+	delete(s.sessions, userId)
+
+	return true, nil
+}
+
+func (s *MemoryPresenceStore) Expire(_ context.Context, now time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offline := make([]string, 0)
+
+	for userId := range s.sessions {
+		if s.prune(userId, now) == nil {
+			offline = append(offline, userId)
+		}
+	}
+
+	return offline, nil
+}
+
+func (s *MemoryPresenceStore) IsOnline(_ context.Context, userId string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.prune(userId, now)) > 0, nil
+}
+
+// prune removes the expired sessions of the user and returns the remaining sessions, or nil if none remain.
+// Must be called with the lock held.
+func (s *MemoryPresenceStore) prune(userId string, now time.Time) map[string]time.Time {
+	sessions, ok := s.sessions[userId]
+
+	if !ok {
+		return nil
+	}
+
+	for sessionId, expiresAt := range sessions {
+		if !expiresAt.After(now) {
+			delete(sessions, sessionId)
+		}
+	}
+
+	if len(sessions) == 0 {
+		delete(s.sessions, userId)
+		return nil
+	}
+
+	return sessions
+}
+
+// Lua scripts used by the RedisPresenceStore. Each user has a sorted set of session IDs scored by expiry in
+// milliseconds, and an index sorted set holds every user scored by their latest session expiry.
+const (
+	redisPresenceTouchScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+local before = redis.call('ZCARD', KEYS[1])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+local latest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('ZADD', KEYS[2], latest[2], ARGV[4])
+redis.call('PEXPIREAT', KEYS[1], latest[2])
+if before == 0 then return 1 end
+return 0
+`
+	redisPresenceRemoveScript = `
+local removed = redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+if redis.call('ZCARD', KEYS[1]) == 0 then
+	redis.call('ZREM', KEYS[2], ARGV[3])
+	redis.call('DEL', KEYS[1])
+	return removed
+end
+return 0
+`
+	redisPresenceExpireScript = `
+local offline = {}
+local users = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+for _, userId in ipairs(users) do
+	local key = ARGV[2] .. userId
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', ARGV[1])
+	local latest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	if #latest == 0 then
+		redis.call('ZREM', KEYS[1], userId)
+		redis.call('DEL', key)
+		table.insert(offline, userId)
+	else
+		redis.call('ZADD', KEYS[1], latest[2], userId)
+	end
+end
+return offline
+`
+)
+
+// RedisPresenceStore is a PresenceStore which keeps sessions in Redis, allowing presence to be shared by several server
+// instances. Expiry is performed atomically so only one instance reports a user going offline.
+type RedisPresenceStore struct {
+	evaluator serverutil.RedisEvaluator
+	prefix    string
+}
+
+// NewRedisPresenceStore creates a presence store using the evaluator. Keys are prefixed with the given prefix.
+// As the expire script accesses keys it does not declare, the store is not compatible with Redis Cluster.
+func NewRedisPresenceStore(evaluator serverutil.RedisEvaluator, prefix string) *RedisPresenceStore {
+	return &RedisPresenceStore{evaluator: evaluator, prefix: prefix}
+}
+
+func (s *RedisPresenceStore) Touch(ctx context.Context, userId string, sessionId string, expiresAt time.Time, now time.Time) (bool, error) {
+	reply, err := s.evaluator.Eval(ctx, redisPresenceTouchScript, []string{s.userKey(userId), s.indexKey()},
+		sessionId, expiresAt.UnixMilli(), now.UnixMilli(), userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return redisBool(reply)
+}
+
+func (s *RedisPresenceStore) Remove(ctx context.Context, userId string, sessionId string, now time.Time) (bool, error) {
+	reply, err := s.evaluator.Eval(ctx, redisPresenceRemoveScript, []string{s.userKey(userId), s.indexKey()},
+		sessionId, now.UnixMilli(), userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return redisBool(reply)
+}
+
+func (s *RedisPresenceStore) Expire(ctx context.Context, now time.Time) ([]string, error) {
+	reply, err := s.evaluator.Eval(ctx, redisPresenceExpireScript, []string{s.indexKey()}, now.UnixMilli(), s.prefix+"user:")
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]any)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected presence expire script reply: %v", reply)
+	}
+
+	offline := make([]string, 0, len(values))
+
+	for _, value := range values {
+		userId, ok := value.(string)
+
+		if !ok {
+			return nil, fmt.Errorf("unexpected presence expire script reply: %v", reply)
+		}
+
+		offline = append(offline, userId)
+	}
+
+	return offline, nil
+}
+
+func (s *RedisPresenceStore) IsOnline(ctx context.Context, userId string, now time.Time) (bool, error) {
+	reply, err := s.evaluator.Eval(ctx, "return redis.call('ZCOUNT', KEYS[1], '('..ARGV[1], '+inf')", []string{s.userKey(userId)}, now.UnixMilli())
+
+	if err != nil {
+		return false, err
+	}
+
+	count, ok := reply.(int64)
+
+	if !ok {
+		return false, fmt.Errorf("unexpected presence count reply: %v", reply)
+	}
+
+	return count > 0, nil
+}
+
+// userKey returns the key of the sorted set holding the user's sessions.
+func (s *RedisPresenceStore) userKey(userId string) string {
+	return s.prefix + "user:" + userId
+}
+
+// indexKey returns the key of the sorted set indexing every user with a session.
+func (s *RedisPresenceStore) indexKey() string {
+	return s.prefix + "users"
+}
+
+// redisBool converts an integer script reply to a bool.
+func redisBool(reply any) (bool, error) {
+	value, ok := reply.(int64)
+
+	if !ok {
+		return false, fmt.Errorf("unexpected script reply: %v", reply)
+	}
+
+	return value == 1, nil
+}
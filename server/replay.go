@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// The default maximum difference between the time a feed request was sent and the time the server receives it.
+const DEFAULT_REPLAY_WINDOW = 5 * time.Minute
+
+var (
+	// ErrReplayProtectionMissing is returned when a request without a nonce is checked by a guard which requires one.
+	ErrReplayProtectionMissing = errors.New("request has no replay protection")
+	// ErrReplayProtectionInvalid is returned when a request has a nonce but no time it was sent.
+	ErrReplayProtectionInvalid = errors.New("request replay protection has no sent time")
+	// ErrReplayWindowExceeded is returned when a request was sent outside the window of the time it is checked, which
+	// happens when an old request is replayed or the clock of the client is far off.
+	ErrReplayWindowExceeded = errors.New("request sent time is outside the replay window")
+	// ErrReplayDetected is returned when a request has a nonce which has already been accepted.
+	ErrReplayDetected = errors.New("request nonce has already been used")
+)
+
+// ReplayGuard rejects feed requests which have been sent before, using the chat.ReplayProtection embedded in the
+// request. A request is accepted if it was sent within the window of now and its nonce has not been accepted before.
+// Nonces are remembered for twice the window, covering every request that can still pass the time check, so the store
+// stays bounded. Nonces are scoped to the sending user.
+//
+// Check every chat.ChatMessageRequest and chat.MacroRequest received on the feed before processing it:
+//
+//	if err := guard.Check(ctx, userId, request.ReplayProtection); err != nil {
+//		// Drop the request
+//	}
+type ReplayGuard struct {
+	store        NonceStore
+	window       time.Duration
+	requireNonce bool
+	now          func() time.Time
+}
+
+// ReplayGuardOption is a type for the options that can be passed to NewReplayGuard.
+type ReplayGuardOption func(*ReplayGuard)
+
+// Sets the maximum difference between the time a request was sent and now. Defaults to DEFAULT_REPLAY_WINDOW.
+func ReplayGuardOption_Window(window time.Duration) ReplayGuardOption {
+	return func(g *ReplayGuard) {
+		g.window = window
+	}
+}
+
+// Rejects requests without a nonce. By default such requests are accepted, so clients which do not stamp their
+// requests keep working.
+func ReplayGuardOption_RequireNonce() ReplayGuardOption {
+	return func(g *ReplayGuard) {
+		g.requireNonce = true
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func ReplayGuardOption_Clock(now func() time.Time) ReplayGuardOption {
+	return func(g *ReplayGuard) {
+		g.now = now
+	}
+}
+
+// NewReplayGuard creates a replay guard which remembers accepted nonces in the store.
+func NewReplayGuard(store NonceStore, options ...ReplayGuardOption) *ReplayGuard {
+	g := &ReplayGuard{
+		store:  store,
+		window: DEFAULT_REPLAY_WINDOW,
+		now:    time.Now,
+	}
+
+	for _, opt := range options {
+		opt(g)
+	}
+
+	return g
+}
+
+// Check returns nil if the request of the user should be processed, recording its nonce so it is rejected if sent again.
+// Returns ErrReplayProtectionMissing, ErrReplayProtectionInvalid, ErrReplayWindowExceeded or ErrReplayDetected if the
+// request should be dropped, or the error of the store.
+func (g *ReplayGuard) Check(ctx context.Context, userId string, protection chat.ReplayProtection) error {
+	if !protection.IsSet() {
+		if g.requireNonce {
+			return ErrReplayProtectionMissing
+		}
+
+		return nil
+	}
+
+	if protection.SentAtUtc == nil {
+		return ErrReplayProtectionInvalid
+	}
+
+	now := g.now()
+
+	if skew := now.Sub(*protection.SentAtUtc); skew > g.window || skew < -g.window {
+		return ErrReplayWindowExceeded
+	}
+
+	added, err := g.store.Add(ctx, userId+":"+protection.Nonce, now.Add(2*g.window), now)
+
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return ErrReplayDetected
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+const testReplayWindow = time.Minute
+
+// A replayCheck is one request checked by a ReplayGuard after moving its clock forward.
+type replayCheck struct {
+	advance time.Duration
+	userId  string
+	nonce   string
+	// How long before the check the request was sent. Negative for a request from the future.
+	sentAgo   time.Duration
+	noSentAt  bool
+	wantError error
+}
+
+func TestReplayGuard_Check(t *testing.T) {
+	tests := []struct {
+		name         string
+		requireNonce bool
+		checks       []replayCheck
+	}{
+		{
+			name:   "request without a nonce",
+			checks: []replayCheck{{userId: "u", noSentAt: true}},
+		},
+		{
+			name:         "request without a nonce when one is required",
+			requireNonce: true,
+			checks:       []replayCheck{{userId: "u", noSentAt: true, wantError: ErrReplayProtectionMissing}},
+		},
+		{
+			name:   "nonce without a sent time",
+			checks: []replayCheck{{userId: "u", nonce: "n", noSentAt: true, wantError: ErrReplayProtectionInvalid}},
+		},
+		{
+			name:   "sent at the edge of the window",
+			checks: []replayCheck{{userId: "u", nonce: "a", sentAgo: testReplayWindow}, {userId: "u", nonce: "b", sentAgo: -testReplayWindow}},
+		},
+		{
+			name:   "sent before the window",
+			checks: []replayCheck{{userId: "u", nonce: "n", sentAgo: testReplayWindow + time.Second, wantError: ErrReplayWindowExceeded}},
+		},
+		{
+			name:   "sent after the window",
+			checks: []replayCheck{{userId: "u", nonce: "n", sentAgo: -testReplayWindow - time.Second, wantError: ErrReplayWindowExceeded}},
+		},
+		{
+			name:   "replayed nonce",
+			checks: []replayCheck{{userId: "u", nonce: "n"}, {advance: testReplayWindow, userId: "u", nonce: "n", sentAgo: testReplayWindow, wantError: ErrReplayDetected}},
+		},
+		{
+			name:   "same nonce from another user",
+			checks: []replayCheck{{userId: "u", nonce: "n"}, {userId: "v", nonce: "n"}},
+		},
+		{
+			name:   "nonce is forgotten after twice the window",
+			checks: []replayCheck{{userId: "u", nonce: "n"}, {advance: 2 * testReplayWindow, userId: "u", nonce: "n"}},
+		},
+		{
+			name:   "nonce is remembered until twice the window",
+			checks: []replayCheck{{userId: "u", nonce: "n"}, {advance: 2*testReplayWindow - time.Millisecond, userId: "u", nonce: "n", wantError: ErrReplayDetected}},
+		},
+		{
+			name:   "rejected request does not record its nonce",
+			checks: []replayCheck{{userId: "u", nonce: "n", sentAgo: 2 * testReplayWindow, wantError: ErrReplayWindowExceeded}, {userId: "u", nonce: "n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			options := []ReplayGuardOption{ReplayGuardOption_Window(testReplayWindow), ReplayGuardOption_Clock(func() time.Time { return now })}
+
+			if tt.requireNonce {
+				options = append(options, ReplayGuardOption_RequireNonce())
+			}
+
+			guard := NewReplayGuard(NewMemoryNonceStore(), options...)
+
+			for i, check := range tt.checks {
+				now = now.Add(check.advance)
+				protection := chat.ReplayProtection{Nonce: check.nonce}
+
+				if !check.noSentAt {
+					sentAt := now.Add(-check.sentAgo)
+					protection.SentAtUtc = &sentAt
+				}
+
+				if err := guard.Check(context.Background(), check.userId, protection); !errors.Is(err, check.wantError) {
+					t.Errorf("check %d: Check() error = %v, want %v", i, err, check.wantError)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+// Package server contains reusable components for servers implementing the BroChat API. The components enforce the
+// business rules documented on the chat.BroChatClient methods against pluggable storage.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// A FriendChecker determines whether two users are friends. Used to enforce the friends membership model.
+type FriendChecker interface {
+	AreFriends(ctx context.Context, userId string, otherUserId string) (bool, error)
+}
+
+// FriendCheckerFunc adapts a function to the FriendChecker interface.
+type FriendCheckerFunc func(ctx context.Context, userId string, otherUserId string) (bool, error)
+
+// AreFriends implements the FriendChecker interface.
+func (f FriendCheckerFunc) AreFriends(ctx context.Context, userId string, otherUserId string) (bool, error) {
+	return f(ctx, userId, otherUserId)
+}
+
+// RoomManager implements the room and membership rules of the BroChat API. Rule violations are returned as
+// *chat.BroChatError values which can be written directly with serverutil.WriteError; any other error comes from the store.
+//
+// Mutations are serialized by the manager so capacity and room limits cannot be exceeded by concurrent requests to
+// the same manager. Deployments running several managers against a shared store must enforce the limits in the store.
+type RoomManager struct {
+	mu      sync.Mutex
+	store   RoomStore
+	friends FriendChecker
+	newId   func() string
+	now     func() time.Time
+}
+
+// RoomManagerOption is a type for the options that can be passed to NewRoomManager.
+type RoomManagerOption func(*RoomManager)
+
+// Sets the function used to generate room and channel IDs. Defaults to random 128 bit hex strings.
+func RoomManagerOption_IdGenerator(newId func() string) RoomManagerOption {
+	return func(m *RoomManager) {
+		m.newId = newId
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func RoomManagerOption_Clock(now func() time.Time) RoomManagerOption {
+	return func(m *RoomManager) {
+		m.now = now
+	}
+}
+
+// NewRoomManager creates a room manager using the store and friend checker.
+// If the friend checker is nil, rooms using the friends membership model cannot be joined.
+func NewRoomManager(store RoomStore, friends FriendChecker, options ...RoomManagerOption) *RoomManager {
+	m := &RoomManager{
+		store:   store,
+		friends: friends,
+		newId:   newId,
+		now:     time.Now,
+	}
+
+	for _, opt := range options {
+		opt(m)
+	}
+
+	return m
+}
+
+// CreateRoom creates a room owned by the user. A user cannot own more than MAX_ROOMS_PER_USER rooms.
+func (m *RoomManager) CreateRoom(ctx context.Context, owner chat.UserInfo, request chat.CreateRoomRequest) (chat.Room, error) {
+	if errs := request.Validate(); len(errs) > 0 {
+		return chat.Room{}, chat.NewValidationErrorFromFields(errs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	owned, err := m.store.CountRoomsOwnedBy(ctx, owner.Id)
+
+	if err != nil {
+		return chat.Room{}, err
+	}
+
+	if owned >= chat.MAX_ROOMS_PER_USER {
+		return chat.Room{}, chat.NewInvalidOperationError(fmt.Sprintf("a user cannot own more than %d rooms", chat.MAX_ROOMS_PER_USER))
+	}
+
+	stored := StoredRoom{
+		Room: chat.Room{
+			Id:                   m.newId(),
+			Name:                 request.Name,
+			ChannelId:            m.newId(),
+			Owner:                owner,
+			Moderators:           make([]chat.UserInfo, 0),
+			MembershipModel:      chat.RoomMembershipModel(request.MembershipModel),
+			CreatedAtUtc:         m.now().UTC(),
+			CustomEmojis:         make([]chat.CustomEmoji, 0),
+			ContentFilterEnabled: request.ContentFilterEnabled,
+			MaxMembers:           request.MaxMembers,
+			MemberCount:          1,
+			Tags:                 normalizeTags(request.Tags),
+		},
+		ChannelMode: chat.CHANNEL_MODE_DEFAULT,
+	}
+
+	if request.JoinPassword != "" {
+		hash, err := chat.HashRoomPassword(request.JoinPassword)
+
+		if err != nil {
+			return chat.Room{}, err
+		}
+
+		stored.PasswordHash = hash
+		stored.Room.IsPasswordProtected = true
+	}
+
+	if err := m.store.CreateRoom(ctx, stored); err != nil {
+		return chat.Room{}, err
+	}
+
+	return stored.Room, nil
+}
+
+// GetRoom returns a room. Only members may view a room.
+func (m *RoomManager) GetRoom(ctx context.Context, roomId string, userId string) (chat.Room, error) {
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return chat.Room{}, err
+	}
+
+	if err := m.requireMember(ctx, roomId, userId); err != nil {
+		return chat.Room{}, err
+	}
+
+	return stored.Room, nil
+}
+
+// GetRoomsForUser returns the rooms the user is a member of.
+func (m *RoomManager) GetRoomsForUser(ctx context.Context, userId string) ([]chat.Room, error) {
+	return m.store.GetRoomsForUser(ctx, userId)
+}
+
+// UpdateRoom applies a partial update to a room. Only the room owner may update a room and the capacity cannot be
+// lowered below the current member count.
+func (m *RoomManager) UpdateRoom(ctx context.Context, roomId string, userId string, request chat.UpdateRoomRequest) (chat.Room, error) {
+	if errs := request.Validate(); len(errs) > 0 {
+		return chat.Room{}, chat.NewValidationErrorFromFields(errs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return chat.Room{}, err
+	}
+
+	if stored.Room.Owner.Id != userId {
+		return chat.Room{}, chat.NewForbiddenError("only the room owner may update the room")
+	}
+
+	room := &stored.Room
+
+	if request.Name != nil {
+		room.Name = *request.Name
+	}
+
+	if request.MembershipModel != nil {
+		room.MembershipModel = chat.RoomMembershipModel(*request.MembershipModel)
+	}
+
+	if request.ContentFilterEnabled != nil {
+		room.ContentFilterEnabled = *request.ContentFilterEnabled
+	}
+
+	if request.MaxMembers != nil {
+		if *request.MaxMembers != 0 && *request.MaxMembers < room.MemberCount {
+			return chat.Room{}, chat.NewInvalidOperationError("the room capacity cannot be lowered below the current member count")
+		}
+
+		room.MaxMembers = *request.MaxMembers
+	}
+
+	if request.Tags != nil {
+		room.Tags = normalizeTags(*request.Tags)
+	}
+
+	if request.ChannelMode != nil {
+		stored.ChannelMode = *request.ChannelMode
+	}
+
+	if request.JoinPassword != nil {
+		stored.PasswordHash = ""
+		room.IsPasswordProtected = false
+
+		if *request.JoinPassword != "" {
+			hash, err := chat.HashRoomPassword(*request.JoinPassword)
+
+			if err != nil {
+				return chat.Room{}, err
+			}
+
+			stored.PasswordHash = hash
+			room.IsPasswordProtected = true
+		}
+	}
+
+	if err := m.store.UpdateRoom(ctx, stored); err != nil {
+		return chat.Room{}, err
+	}
+
+	return stored.Room, nil
+}
+
+// DeleteRoom deletes a room. Only the room owner may delete a room.
+func (m *RoomManager) DeleteRoom(ctx context.Context, roomId string, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return err
+	}
+
+	if stored.Room.Owner.Id != userId {
+		return chat.NewForbiddenError("only the room owner may delete the room")
+	}
+
+	return m.store.DeleteRoom(ctx, roomId)
+}
+
+// JoinRoom adds the user to a room. Public rooms may be joined by anyone and friends rooms by the owner's friends.
+// Password protected rooms require the correct password and full rooms cannot be joined.
+func (m *RoomManager) JoinRoom(ctx context.Context, roomId string, user chat.UserInfo, request chat.JoinRoomRequest) (chat.Room, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return chat.Room{}, err
+	}
+
+	isMember, err := m.store.IsMember(ctx, roomId, user.Id)
+
+	if err != nil {
+		return chat.Room{}, err
+	}
+
+	if isMember {
+		return chat.Room{}, chat.NewDataConflictError("the user is already a member of the room")
+	}
+
+	if stored.Room.MembershipModel == chat.FRIENDS_MEMBERSHIP_MODEL {
+		areFriends := false
+
+		if m.friends != nil {
+			areFriends, err = m.friends.AreFriends(ctx, stored.Room.Owner.Id, user.Id)
+
+			if err != nil {
+				return chat.Room{}, err
+			}
+		}
+
+		if !areFriends {
+			return chat.Room{}, chat.NewForbiddenError("only friends of the room owner may join the room")
+		}
+	}
+
+	if stored.PasswordHash != "" {
+		ok, err := chat.VerifyRoomPassword(request.Password, stored.PasswordHash)
+
+		if err != nil {
+			return chat.Room{}, err
+		}
+
+		if !ok {
+			return chat.Room{}, chat.NewInvalidRoomPasswordError()
+		}
+	}
+
+	if stored.Room.IsFull() {
+		return chat.Room{}, chat.NewRoomFullError()
+	}
+
+	if err := m.store.AddMember(ctx, roomId, user); err != nil {
+		return chat.Room{}, err
+	}
+
+	stored.Room.MemberCount++
+
+	if err := m.store.UpdateRoom(ctx, stored); err != nil {
+		return chat.Room{}, err
+	}
+
+	return stored.Room, nil
+}
+
+// LeaveRoom removes the user from a room. The owner cannot leave their own room and must delete it instead.
+func (m *RoomManager) LeaveRoom(ctx context.Context, roomId string, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return err
+	}
+
+	if stored.Room.Owner.Id == userId {
+		return chat.NewInvalidOperationError("the room owner cannot leave the room")
+	}
+
+	return m.removeMember(ctx, stored, userId)
+}
+
+// KickUser removes a member from a room. Only the owner and moderators may kick users, the owner cannot be kicked
+// and only the owner may kick a moderator.
+func (m *RoomManager) KickUser(ctx context.Context, roomId string, actorUserId string, targetUserId string, request chat.KickUserFromRoomRequest) error {
+	if errs := request.Validate(); len(errs) > 0 {
+		return chat.NewValidationErrorFromFields(errs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.getRoom(ctx, roomId)
+
+	if err != nil {
+		return err
+	}
+
+	actorRole := stored.Room.RoleOf(actorUserId)
+
+	if !actorRole.IsModerator() {
+		return chat.NewForbiddenError("only the room owner and moderators may kick users")
+	}
+
+	switch stored.Room.RoleOf(targetUserId) {
+	case chat.ROOM_ROLE_OWNER:
+		return chat.NewInvalidOperationError("the room owner cannot be kicked")
+	case chat.ROOM_ROLE_MODERATOR:
+		if actorRole != chat.ROOM_ROLE_OWNER {
+			return chat.NewForbiddenError("only the room owner may kick a moderator")
+		}
+	}
+
+	return m.removeMember(ctx, stored, targetUserId)
+}
+
+// GetMembers returns the members of a room. Only members may list the members of a room.
+func (m *RoomManager) GetMembers(ctx context.Context, roomId string, userId string) ([]chat.UserInfo, error) {
+	if _, err := m.getRoom(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	if err := m.requireMember(ctx, roomId, userId); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetMembers(ctx, roomId)
+}
+
+// removeMember removes a member from a room, including from its moderators. Must be called with the lock held.
+func (m *RoomManager) removeMember(ctx context.Context, stored StoredRoom, userId string) error {
+	isMember, err := m.store.IsMember(ctx, stored.Room.Id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if !isMember {
+		return chat.NewNotFoundError("the user is not a member of the room")
+	}
+
+	if err := m.store.RemoveMember(ctx, stored.Room.Id, userId); err != nil {
+		return err
+	}
+
+	moderators := make([]chat.UserInfo, 0, len(stored.Room.Moderators))
+
+	for _, moderator := range stored.Room.Moderators {
+		if moderator.Id != userId {
+			moderators = append(moderators, moderator)
+		}
+	}
+
+	stored.Room.Moderators = moderators
+
+	if stored.Room.MemberCount > 0 {
+		stored.Room.MemberCount--
+	}
+
+	return m.store.UpdateRoom(ctx, stored)
+}
+
+// getRoom returns the room, translating ErrRoomNotFound into the not found error response.
+func (m *RoomManager) getRoom(ctx context.Context, roomId string) (StoredRoom, error) {
+	stored, err := m.store.GetRoom(ctx, roomId)
+
+	if errors.Is(err, ErrRoomNotFound) {
+		return StoredRoom{}, chat.NewNotFoundError("room not found")
+	}
+
+	return stored, err
+}
+
+// requireMember returns a forbidden error response if the user is not a member of the room.
+func (m *RoomManager) requireMember(ctx context.Context, roomId string, userId string) error {
+	isMember, err := m.store.IsMember(ctx, roomId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if !isMember {
+		return chat.NewForbiddenError("the user is not a member of the room")
+	}
+
+	return nil
+}
+
+// normalizeTags normalizes the tags and removes duplicates.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = chat.NormalizeRoomTag(tag)
+
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
+
+// newId generates a random 128 bit hex ID.
+func newId() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	testRoomOwner  = chat.UserInfo{Id: "owner", Username: "owner"}
+	testRoomJoiner = chat.UserInfo{Id: "joiner", Username: "joiner"}
+)
+
+// errorCode returns the response code of a rule violation returned by a manager, or fails the test for any other error.
+func errorCode(t *testing.T, err error) chat.BroChatResponseCode {
+	t.Helper()
+
+	if err == nil {
+		return chat.BROCHAT_RESPONSE_CODE_SUCCESS
+	}
+
+	var brochatErr *chat.BroChatError
+
+	if !errors.As(err, &brochatErr) {
+		t.Fatalf("error = %v, want a *chat.BroChatError", err)
+	}
+
+	return brochatErr.Code
+}
+
+// onlyFriendsWith returns a friend checker under which the user is only friends with the given user.
+func onlyFriendsWith(userId string, friendId string) FriendChecker {
+	return FriendCheckerFunc(func(_ context.Context, a string, b string) (bool, error) {
+		return (a == userId && b == friendId) || (a == friendId && b == userId), nil
+	})
+}
+
+func TestRoomManager_CreateRoomOwnerCap(t *testing.T) {
+	tests := []struct {
+		name  string
+		owned int
+		want  chat.BroChatResponseCode
+	}{
+		{"below the cap", chat.MAX_ROOMS_PER_USER - 1, chat.BROCHAT_RESPONSE_CODE_SUCCESS},
+		{"at the cap", chat.MAX_ROOMS_PER_USER, chat.BROCHAT_RESPONSE_CODE_INVALID_OPERATION},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewRoomManager(NewMemoryRoomStore(), nil)
+
+			for i := 0; i < tt.owned; i++ {
+				if _, err := m.CreateRoom(ctx, testRoomOwner, chat.CreateRoomRequest{Name: fmt.Sprintf("room %d", i), MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)}); err != nil {
+					t.Fatalf("CreateRoom(%d) error = %v", i, err)
+				}
+			}
+
+			_, err := m.CreateRoom(ctx, testRoomOwner, chat.CreateRoomRequest{Name: "one more", MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)})
+
+			if got := errorCode(t, err); got != tt.want {
+				t.Errorf("CreateRoom() code = %v, want %v", got, tt.want)
+			}
+
+			// Other users are not limited by the rooms of the owner
+			if _, err := m.CreateRoom(ctx, testRoomJoiner, chat.CreateRoomRequest{Name: "other", MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)}); err != nil {
+				t.Errorf("CreateRoom() by another user error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRoomManager_JoinRoom(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  chat.CreateRoomRequest
+		friends  FriendChecker
+		members  int
+		password string
+		want     chat.BroChatResponseCode
+	}{
+		{
+			name:    "public room",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)},
+			want:    chat.BROCHAT_RESPONSE_CODE_SUCCESS,
+		},
+		{
+			name:    "friends room joined by a friend of the owner",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.FRIENDS_MEMBERSHIP_MODEL)},
+			friends: onlyFriendsWith(testRoomOwner.Id, testRoomJoiner.Id),
+			want:    chat.BROCHAT_RESPONSE_CODE_SUCCESS,
+		},
+		{
+			name:    "friends room joined by a stranger",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.FRIENDS_MEMBERSHIP_MODEL)},
+			friends: onlyFriendsWith(testRoomOwner.Id, "someone else"),
+			want:    chat.BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR,
+		},
+		{
+			name:    "friends room without a friend checker",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.FRIENDS_MEMBERSHIP_MODEL)},
+			want:    chat.BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR,
+		},
+		{
+			name:     "correct password",
+			request:  chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), JoinPassword: "letmein"},
+			password: "letmein",
+			want:     chat.BROCHAT_RESPONSE_CODE_SUCCESS,
+		},
+		{
+			name:     "wrong password",
+			request:  chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), JoinPassword: "letmein"},
+			password: "LETMEIN",
+			want:     chat.BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR,
+		},
+		{
+			name:    "missing password",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), JoinPassword: "letmein"},
+			want:    chat.BROCHAT_RESPONSE_CODE_INVALID_ROOM_PASSWORD_ERROR,
+		},
+		{
+			name:    "room with a free place",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), MaxMembers: 3},
+			members: 1,
+			want:    chat.BROCHAT_RESPONSE_CODE_SUCCESS,
+		},
+		{
+			name:    "full room",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), MaxMembers: 3},
+			members: 2,
+			want:    chat.BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR,
+		},
+		{
+			name:    "full room with the default capacity",
+			request: chat.CreateRoomRequest{MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)},
+			members: chat.MAX_ROOM_MEMBERS - 1,
+			want:    chat.BROCHAT_RESPONSE_CODE_ROOM_FULL_ERROR,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryRoomStore()
+			m := NewRoomManager(store, tt.friends)
+
+			tt.request.Name = "room"
+			room, err := m.CreateRoom(ctx, testRoomOwner, tt.request)
+
+			if err != nil {
+				t.Fatalf("CreateRoom() error = %v", err)
+			}
+
+			// Fill the room besides the owner
+			for i := 0; i < tt.members; i++ {
+				if _, err := m.JoinRoom(ctx, room.Id, chat.UserInfo{Id: fmt.Sprintf("member %d", i)}, chat.JoinRoomRequest{}); err != nil {
+					t.Fatalf("JoinRoom(member %d) error = %v", i, err)
+				}
+			}
+
+			joined, err := m.JoinRoom(ctx, room.Id, testRoomJoiner, chat.JoinRoomRequest{Password: tt.password})
+
+			if got := errorCode(t, err); got != tt.want {
+				t.Fatalf("JoinRoom() code = %v, want %v", got, tt.want)
+			}
+
+			isMember, err := store.IsMember(ctx, room.Id, testRoomJoiner.Id)
+
+			if err != nil {
+				t.Fatalf("IsMember() error = %v", err)
+			}
+
+			if wantMember := tt.want == chat.BROCHAT_RESPONSE_CODE_SUCCESS; isMember != wantMember {
+				t.Errorf("IsMember() = %v, want %v", isMember, wantMember)
+			}
+
+			if tt.want == chat.BROCHAT_RESPONSE_CODE_SUCCESS && joined.MemberCount != uint64(tt.members)+2 {
+				t.Errorf("MemberCount = %d, want %d", joined.MemberCount, tt.members+2)
+			}
+		})
+	}
+}
+
+func TestRoomManager_JoinRoomTwice(t *testing.T) {
+	ctx := context.Background()
+	m := NewRoomManager(NewMemoryRoomStore(), nil)
+
+	room, err := m.CreateRoom(ctx, testRoomOwner, chat.CreateRoomRequest{Name: "room", MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL)})
+
+	if err != nil {
+		t.Fatalf("CreateRoom() error = %v", err)
+	}
+
+	if _, err := m.JoinRoom(ctx, room.Id, testRoomJoiner, chat.JoinRoomRequest{}); err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+
+	_, err = m.JoinRoom(ctx, room.Id, testRoomJoiner, chat.JoinRoomRequest{})
+
+	if got := errorCode(t, err); got != chat.BROCHAT_RESPONSE_CODE_DATA_CONFLICT_ERROR {
+		t.Errorf("JoinRoom() again code = %v, want %v", got, chat.BROCHAT_RESPONSE_CODE_DATA_CONFLICT_ERROR)
+	}
+
+	_, err = m.JoinRoom(ctx, "missing", testRoomJoiner, chat.JoinRoomRequest{})
+
+	if got := errorCode(t, err); got != chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR {
+		t.Errorf("JoinRoom() of a missing room code = %v, want %v", got, chat.BROCHAT_RESPONSE_CODE_NOT_FOUND_ERROR)
+	}
+}
+
+func TestRoomManager_UpdateRoom(t *testing.T) {
+	tests := []struct {
+		name    string
+		userId  string
+		request chat.UpdateRoomRequest
+		want    chat.BroChatResponseCode
+	}{
+		{"owner raises the capacity", testRoomOwner.Id, chat.UpdateRoomRequest{MaxMembers: ptr(uint64(10))}, chat.BROCHAT_RESPONSE_CODE_SUCCESS},
+		{"owner lowers the capacity to the member count", testRoomOwner.Id, chat.UpdateRoomRequest{MaxMembers: ptr(uint64(3))}, chat.BROCHAT_RESPONSE_CODE_SUCCESS},
+		{"owner lowers the capacity below the member count", testRoomOwner.Id, chat.UpdateRoomRequest{MaxMembers: ptr(uint64(2))}, chat.BROCHAT_RESPONSE_CODE_INVALID_OPERATION},
+		{"owner sets a capacity of zero", testRoomOwner.Id, chat.UpdateRoomRequest{MaxMembers: ptr(uint64(0))}, chat.BROCHAT_RESPONSE_CODE_VALIDATION_ERROR},
+		{"member updates the room", testRoomJoiner.Id, chat.UpdateRoomRequest{MaxMembers: ptr(uint64(10))}, chat.BROCHAT_RESPONSE_CODE_FORBIDDEN_ERROR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewRoomManager(NewMemoryRoomStore(), nil)
+
+			room, err := m.CreateRoom(ctx, testRoomOwner, chat.CreateRoomRequest{Name: "room", MembershipModel: string(chat.PUBLIC_MEMBERSHIP_MODEL), MaxMembers: 5})
+
+			if err != nil {
+				t.Fatalf("CreateRoom() error = %v", err)
+			}
+
+			for _, user := range []chat.UserInfo{testRoomJoiner, {Id: "third"}} {
+				if _, err := m.JoinRoom(ctx, room.Id, user, chat.JoinRoomRequest{}); err != nil {
+					t.Fatalf("JoinRoom(%s) error = %v", user.Id, err)
+				}
+			}
+
+			updated, err := m.UpdateRoom(ctx, room.Id, tt.userId, tt.request)
+
+			if got := errorCode(t, err); got != tt.want {
+				t.Fatalf("UpdateRoom() code = %v, want %v", got, tt.want)
+			}
+
+			if tt.want == chat.BROCHAT_RESPONSE_CODE_SUCCESS && updated.MaxMembers != *tt.request.MaxMembers {
+				t.Errorf("MaxMembers = %d, want %d", updated.MaxMembers, *tt.request.MaxMembers)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
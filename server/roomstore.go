@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrRoomNotFound is returned by a RoomStore when the room does not exist.
+	ErrRoomNotFound = errors.New("room not found")
+)
+
+// A StoredRoom is a room as persisted by a RoomStore, including the state which is never sent to clients.
+type StoredRoom struct {
+	// The room.
+	Room chat.Room
+	// The hash of the join password created with chat.HashRoomPassword. Empty if the room is not password protected.
+	PasswordHash string
+	// The posting mode of the room's channel.
+	ChannelMode chat.ChannelMode
+}
+
+// A RoomStore persists rooms and their members for a RoomManager. Implementations must be safe for concurrent use.
+type RoomStore interface {
+	// CreateRoom stores a new room with the owner as its only member.
+	CreateRoom(ctx context.Context, room StoredRoom) error
+	// GetRoom returns the room or ErrRoomNotFound.
+	GetRoom(ctx context.Context, roomId string) (StoredRoom, error)
+	// UpdateRoom replaces a room or returns ErrRoomNotFound.
+	UpdateRoom(ctx context.Context, room StoredRoom) error
+	// DeleteRoom removes a room and its members or returns ErrRoomNotFound.
+	DeleteRoom(ctx context.Context, roomId string) error
+	// CountRoomsOwnedBy returns the number of rooms the user owns.
+	CountRoomsOwnedBy(ctx context.Context, userId string) (int, error)
+	// GetRoomsForUser returns the rooms the user is a member of.
+	GetRoomsForUser(ctx context.Context, userId string) ([]chat.Room, error)
+	// GetMembers returns the members of the room or ErrRoomNotFound.
+	GetMembers(ctx context.Context, roomId string) ([]chat.UserInfo, error)
+	// IsMember returns true if the user is a member of the room.
+	IsMember(ctx context.Context, roomId string, userId string) (bool, error)
+	// AddMember adds a user to the members of the room. Adding an existing member is not an error.
+	AddMember(ctx context.Context, roomId string, user chat.UserInfo) error
+	// RemoveMember removes a user from the members of the room. Removing a user who is not a member is not an error.
+	RemoveMember(ctx context.Context, roomId string, userId string) error
+}
+
+// MemoryRoomStore is a RoomStore which keeps rooms in memory.
+type MemoryRoomStore struct {
+	mu      sync.RWMutex
+	rooms   map[string]StoredRoom
+	members map[string]map[string]chat.UserInfo
+}
+
+// NewMemoryRoomStore creates an empty in memory room store.
+func NewMemoryRoomStore() *MemoryRoomStore {
+	return &MemoryRoomStore{
+		rooms:   make(map[string]StoredRoom),
+		members: make(map[string]map[string]chat.UserInfo),
+	}
+}
+
+func (s *MemoryRoomStore) CreateRoom(_ context.Context, room StoredRoom) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rooms[room.Room.Id] = room
+	s.members[room.Room.Id] = map[string]chat.UserInfo{room.Room.Owner.Id: room.Room.Owner}
+
+	return nil
+}
+
+func (s *MemoryRoomStore) GetRoom(_ context.Context, roomId string) (StoredRoom, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.rooms[roomId]
+
+	if !ok {
+		return StoredRoom{}, ErrRoomNotFound
+	}
+
+	return room, nil
+}
+
+func (s *MemoryRoomStore) UpdateRoom(_ context.Context, room StoredRoom) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rooms[room.Room.Id]; !ok {
+		return ErrRoomNotFound
+	}
+
+	s.rooms[room.Room.Id] = room
+
+	return nil
+}
+
+func (s *MemoryRoomStore) DeleteRoom(_ context.Context, roomId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rooms[roomId]; !ok {
+		return ErrRoomNotFound
+	}
+
+	delete(s.rooms, roomId)
+	delete(s.members, roomId)
+
+	return nil
+}
+
+func (s *MemoryRoomStore) CountRoomsOwnedBy(_ context.Context, userId string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+
+	for _, room := range s.rooms {
+		if room.Room.Owner.Id == userId {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *MemoryRoomStore) GetRoomsForUser(_ context.Context, userId string) ([]chat.Room, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rooms := make([]chat.Room, 0)
+
+	for roomId, members := range s.members {
+		if _, ok := members[userId]; ok {
+			rooms = append(rooms, s.rooms[roomId].Room)
+		}
+	}
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].CreatedAtUtc.Before(rooms[j].CreatedAtUtc)
+	})
+
+	return rooms, nil
+}
+
+func (s *MemoryRoomStore) GetMembers(_ context.Context, roomId string) ([]chat.UserInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members, ok := s.members[roomId]
+
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+
+	users := make([]chat.UserInfo, 0, len(members))
+
+	for _, user := range members {
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+func (s *MemoryRoomStore) IsMember(_ context.Context, roomId string, userId string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.members[roomId][userId]
+
+	return ok, nil
+}
+
+func (s *MemoryRoomStore) AddMember(_ context.Context, roomId string, user chat.UserInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, ok := s.members[roomId]
+
+	if !ok {
+		return ErrRoomNotFound
+	}
+
+	members[user.Id] = user
+
+	return nil
+}
+
+func (s *MemoryRoomStore) RemoveMember(_ context.Context, roomId string, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, ok := s.members[roomId]
+
+	if !ok {
+		return ErrRoomNotFound
+	}
+
+	delete(members, userId)
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// sqliteMigrations are applied in order to bring the database up to date, tracked with PRAGMA user_version. The index
+// of a migration plus one is the schema version it produces. Each migration is a list of single statements, as most
+// drivers only execute the first statement of a multi-statement string. Never edit a released migration, append a new
+// one instead.
+var sqliteMigrations = [][]string{
+	{
+		`CREATE TABLE messages (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	id TEXT NOT NULL,
+	channel_id TEXT NOT NULL,
+	sender_user_id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	received_at INTEGER NOT NULL,
+	data BLOB NOT NULL,
+	UNIQUE (channel_id, id)
+)`,
+		`CREATE INDEX messages_channel_received ON messages (channel_id, received_at, seq)`,
+	},
+}
+
+// SQLiteMessageStore is a MessageStore backed by a SQLite database. Suitable for self-hosted single binary servers.
+//
+// The store only uses database/sql so any SQLite driver can be used. Search matching is performed by LIKE, which
+// SQLite only treats case-insensitively for ASCII characters.
+type SQLiteMessageStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteMessageStore creates a message store using the database, applying any pending migrations.
+// The caller remains responsible for closing the database.
+func NewSQLiteMessageStore(ctx context.Context, db *sql.DB) (*SQLiteMessageStore, error) {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var version int
+
+	if err := tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return nil, err
+	}
+
+	if version > len(sqliteMigrations) {
+		return nil, fmt.Errorf("message store schema version %d is newer than the supported version %d", version, len(sqliteMigrations))
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		for _, statement := range sqliteMigrations[version] {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return nil, fmt.Errorf("applying message store migration %d: %w", version+1, err)
+			}
+		}
+	}
+
+	// PRAGMA statements do not accept parameters.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteMessageStore{db: db}, nil
+}
+
+func (s *SQLiteMessageStore) Append(ctx context.Context, message chat.ChatMessage) error {
+	data, err := json.Marshal(message)
+
+	if err != nil {
+		return err
+	}
+
+	// The conflict clause reports a duplicate without relying on the error types of a particular driver.
+	res, err := s.db.ExecContext(ctx, `INSERT INTO messages (id, channel_id, sender_user_id, content, received_at, data)
+VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (channel_id, id) DO NOTHING`,
+		message.Id, message.ChannelId, message.SenderUserId, message.Content, message.ReceivedAt().UnixNano(), data)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrMessageExists
+	}
+
+	return nil
+}
+
+func (s *SQLiteMessageStore) GetPage(ctx context.Context, channelId string, page uint64, pageSize uint64) ([]chat.ChatMessage, error) {
+	pageSize = normalizePageSize(pageSize)
+
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM messages WHERE channel_id = ?
+ORDER BY received_at DESC, seq DESC LIMIT ? OFFSET ?`,
+		channelId, pageSize, (normalizePage(page)-1)*pageSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSQLiteMessages(rows)
+}
+
+func (s *SQLiteMessageStore) GetBefore(ctx context.Context, channelId string, messageId string, limit uint64) ([]chat.ChatMessage, error) {
+	var receivedAt, seq int64
+
+	err := s.db.QueryRowContext(ctx, "SELECT received_at, seq FROM messages WHERE channel_id = ? AND id = ?",
+		channelId, messageId).Scan(&receivedAt, &seq)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMessageNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM messages
+WHERE channel_id = ? AND (received_at < ? OR (received_at = ? AND seq < ?))
+ORDER BY received_at DESC, seq DESC LIMIT ?`,
+		channelId, receivedAt, receivedAt, seq, normalizePageSize(limit))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSQLiteMessages(rows)
+}
+
+func (s *SQLiteMessageStore) Delete(ctx context.Context, channelId string, messageId string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM messages WHERE channel_id = ? AND id = ?", channelId, messageId)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrMessageNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteMessageStore) Search(ctx context.Context, query MessageSearchQuery) ([]chat.MessageSearchResult, error) {
+	results := make([]chat.MessageSearchResult, 0)
+
+	if query.Text == "" || len(query.ChannelIds) == 0 {
+		return results, nil
+	}
+
+	var where strings.Builder
+	args := make([]any, 0, len(query.ChannelIds)+6)
+
+	where.WriteString("channel_id IN (?" + strings.Repeat(", ?", len(query.ChannelIds)-1) + ")")
+
+	for _, channelId := range query.ChannelIds {
+		args = append(args, channelId)
+	}
+
+	where.WriteString(` AND content LIKE ? ESCAPE '\'`)
+	args = append(args, "%"+escapeLike(query.Text)+"%")
+
+	if query.SenderUserId != "" {
+		where.WriteString(" AND sender_user_id = ?")
+		args = append(args, query.SenderUserId)
+	}
+
+	if !query.After.IsZero() {
+		where.WriteString(" AND received_at >= ?")
+		args = append(args, query.After.UnixNano())
+	}
+
+	if !query.Before.IsZero() {
+		where.WriteString(" AND received_at < ?")
+		args = append(args, query.Before.UnixNano())
+	}
+
+	pageSize := normalizePageSize(query.PageSize)
+	args = append(args, pageSize, (normalizePage(query.Page)-1)*pageSize)
+
+	rows, err := s.db.QueryContext(ctx, "SELECT data FROM messages WHERE "+where.String()+
+		" ORDER BY received_at DESC, seq DESC LIMIT ? OFFSET ?", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := scanSQLiteMessages(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range messages {
+		results = append(results, chat.MessageSearchResult{Message: m, Highlights: MatchHighlights(m.Content, query.Text)})
+	}
+
+	return results, nil
+}
+
+// scanSQLiteMessages decodes the data column of each row and closes the rows.
+func scanSQLiteMessages(rows *sql.Rows) ([]chat.ChatMessage, error) {
+	defer rows.Close()
+
+	messages := make([]chat.ChatMessage, 0)
+
+	for rows.Next() {
+		var data []byte
+
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var m chat.ChatMessage
+
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern using a backslash as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+	_ "modernc.org/sqlite"
+)
+
+// openTestSQLiteDB opens a database in a temporary directory which is closed when the test ends.
+func openTestSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "messages.db"))
+
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestNewSQLiteMessageStore_Migrations(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLiteDB(t)
+
+	store, err := NewSQLiteMessageStore(ctx, db)
+
+	if err != nil {
+		t.Fatalf("NewSQLiteMessageStore() error = %v", err)
+	}
+
+	var version int
+
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("reading user_version: %v", err)
+	}
+
+	if version != len(sqliteMigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(sqliteMigrations))
+	}
+
+	// Every statement of every migration ran, including those after the first of a migration
+	var index string
+
+	if err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND name = 'messages_channel_received'").Scan(&index); err != nil {
+		t.Errorf("the index created by the second statement of the first migration is missing: %v", err)
+	}
+
+	message := chat.ChatMessage{Id: "m", ChannelId: "c", Content: "hello", ReceivedAtUtc: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	if err := store.Append(ctx, message); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// Opening an up to date database applies nothing and keeps the messages
+	reopened, err := NewSQLiteMessageStore(ctx, db)
+
+	if err != nil {
+		t.Fatalf("NewSQLiteMessageStore() on an up to date database error = %v", err)
+	}
+
+	if page, err := reopened.GetPage(ctx, "c", 1, 10); err != nil || len(page) != 1 || page[0].Id != "m" {
+		t.Errorf("GetPage() = %+v, %v, want the stored message", page, err)
+	}
+
+	if _, err := db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatalf("setting user_version: %v", err)
+	}
+
+	if _, err := NewSQLiteMessageStore(ctx, db); err == nil {
+		t.Errorf("NewSQLiteMessageStore() on a newer schema error = nil, want an error")
+	}
+}
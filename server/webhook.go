@@ -0,0 +1,517 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// Webhook delivery defaults.
+const (
+	// The default number of times a delivery is attempted before it is abandoned.
+	DEFAULT_WEBHOOK_MAX_ATTEMPTS = 6
+	// The default number of deliveries made concurrently.
+	DEFAULT_WEBHOOK_WORKERS = 4
+	// The default number of deliveries which can be waiting for a worker.
+	DEFAULT_WEBHOOK_QUEUE_SIZE = 1000
+	// The default timeout of a single delivery attempt.
+	DEFAULT_WEBHOOK_TIMEOUT = 10 * time.Second
+)
+
+// The longest the default backoff waits between delivery attempts.
+const maxWebhookBackoff = 10 * time.Minute
+
+var (
+	// ErrWebhookQueueFull is returned by Dispatch when deliveries were dropped because the queue was full.
+	ErrWebhookQueueFull = errors.New("webhook delivery queue is full")
+	// ErrWebhookAddressNotAllowed is the error of a delivery to a receiver at an address which is not allowed, such as a
+	// loopback or private address. The delivery is not retried.
+	ErrWebhookAddressNotAllowed = errors.New("webhook receiver address is not allowed")
+)
+
+// A WebhookDeliveryError is reported when a delivery is abandoned.
+type WebhookDeliveryError struct {
+	// The ID of the webhook.
+	WebhookId string
+	// The ID of the event that was not delivered.
+	EventId string
+	// The number of attempts made.
+	Attempts int
+	// The error of the last attempt.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *WebhookDeliveryError) Error() string {
+	return fmt.Sprintf("delivering event %s to webhook %s failed after %d attempts: %v", e.EventId, e.WebhookId, e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *WebhookDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// WebhookDispatcher manages the webhooks of rooms and delivers room events to them. Each delivery is a POST of a
+// chat.WebhookEvent signed with the webhook's secret (see chat.SignWebhookPayload). Failed deliveries are retried with
+// exponential backoff; a delivery is only retried if it failed to connect, timed out, or the receiver responded with 408,
+// 429 or a 5xx status code. Deliveries only connect to public addresses, checked as each connection is dialed so a
+// host name cannot be pointed at a service inside the network after the webhook was created.
+//
+// Management rule violations are returned as *chat.BroChatError values which can be written directly with
+// serverutil.WriteError; any other error comes from the stores.
+type WebhookDispatcher struct {
+	mu          sync.Mutex
+	store       WebhookStore
+	rooms       RoomStore
+	httpClient  *http.Client
+	allowAddr   func(addr netip.Addr) bool
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	workers     int
+	queueSize   int
+	queue       chan webhookDelivery
+	newId       func() string
+	now         func() time.Time
+}
+
+// webhookDelivery is an event waiting to be delivered to a webhook.
+type webhookDelivery struct {
+	webhook StoredWebhook
+	event   chat.WebhookEvent
+	body    []byte
+}
+
+// WebhookDispatcherOption is a type for the options that can be passed to NewWebhookDispatcher.
+type WebhookDispatcherOption func(*WebhookDispatcher)
+
+// Sets the HTTP client used to make deliveries. Defaults to a client with a DEFAULT_WEBHOOK_TIMEOUT timeout which only
+// connects to the addresses allowed by WebhookDispatcherOption_AllowAddress. A client set with this option must guard
+// against connecting to internal services itself.
+func WebhookDispatcherOption_HttpClient(httpClient *http.Client) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.httpClient = httpClient
+	}
+}
+
+// Sets the function deciding whether deliveries may connect to an address. Defaults to chat.IsPublicWebhookAddress.
+// Allowing loopback addresses is useful for tests. Has no effect when an HTTP client is set with WebhookDispatcherOption_HttpClient.
+func WebhookDispatcherOption_AllowAddress(allow func(addr netip.Addr) bool) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.allowAddr = allow
+	}
+}
+
+// Sets the number of times a delivery is attempted before it is abandoned. Defaults to DEFAULT_WEBHOOK_MAX_ATTEMPTS.
+func WebhookDispatcherOption_MaxAttempts(maxAttempts int) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.maxAttempts = maxAttempts
+	}
+}
+
+// Sets the function returning how long to wait after the given failed attempt, starting at 1. Defaults to an exponential
+// backoff starting at one second with jitter, capped at ten minutes. A longer Retry-After from the receiver takes
+// precedence, up to ten minutes.
+func WebhookDispatcherOption_Backoff(backoff func(attempt int) time.Duration) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.backoff = backoff
+	}
+}
+
+// Sets the number of deliveries made concurrently by Run. Defaults to DEFAULT_WEBHOOK_WORKERS.
+func WebhookDispatcherOption_Workers(workers int) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.workers = workers
+	}
+}
+
+// Sets the number of deliveries which can be waiting for a worker. Defaults to DEFAULT_WEBHOOK_QUEUE_SIZE.
+func WebhookDispatcherOption_QueueSize(queueSize int) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.queueSize = queueSize
+	}
+}
+
+// Sets the function used to generate webhook and event IDs. Defaults to random 128 bit hex strings.
+func WebhookDispatcherOption_IdGenerator(newId func() string) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.newId = newId
+	}
+}
+
+// Sets the function used to get the current time. Defaults to time.Now.
+func WebhookDispatcherOption_Clock(now func() time.Time) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.now = now
+	}
+}
+
+// NewWebhookDispatcher creates a webhook dispatcher using the webhook store. The room store is used to check that only
+// room owners manage webhooks. Deliveries are only made while Run is running.
+func NewWebhookDispatcher(store WebhookStore, rooms RoomStore, options ...WebhookDispatcherOption) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		store:       store,
+		rooms:       rooms,
+		allowAddr:   chat.IsPublicWebhookAddress,
+		maxAttempts: DEFAULT_WEBHOOK_MAX_ATTEMPTS,
+		backoff:     defaultWebhookBackoff,
+		workers:     DEFAULT_WEBHOOK_WORKERS,
+		queueSize:   DEFAULT_WEBHOOK_QUEUE_SIZE,
+		newId:       newId,
+		now:         time.Now,
+	}
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	if d.httpClient == nil {
+		d.httpClient = newWebhookHttpClient(d.allowAddr)
+	}
+
+	d.queue = make(chan webhookDelivery, d.queueSize)
+
+	return d
+}
+
+// CreateWebhook registers a webhook for the room. Only the room owner may create webhooks and a room cannot have more
+// than MAX_WEBHOOKS_PER_ROOM webhooks. The returned webhook includes its secret, which is not returned again.
+func (d *WebhookDispatcher) CreateWebhook(ctx context.Context, roomId string, userId string, request chat.CreateWebhookRequest) (chat.Webhook, error) {
+	if errs := request.Validate(); len(errs) > 0 {
+		return chat.Webhook{}, chat.NewValidationErrorFromFields(errs)
+	}
+
+	if err := d.requireOwner(ctx, roomId, userId); err != nil {
+		return chat.Webhook{}, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existing, err := d.store.GetWebhooks(ctx, roomId)
+
+	if err != nil {
+		return chat.Webhook{}, err
+	}
+
+	if len(existing) >= chat.MAX_WEBHOOKS_PER_ROOM {
+		return chat.Webhook{}, chat.NewInvalidOperationError(fmt.Sprintf("a room cannot have more than %d webhooks", chat.MAX_WEBHOOKS_PER_ROOM))
+	}
+
+	stored := StoredWebhook{
+		Webhook: chat.Webhook{
+			Id:              d.newId(),
+			RoomId:          roomId,
+			Url:             request.Url,
+			EventTypes:      uniqueEventTypes(request.EventTypes),
+			CreatedByUserId: userId,
+			CreatedAtUtc:    d.now().UTC(),
+		},
+		Secret: newWebhookSecret(),
+	}
+
+	if err := d.store.CreateWebhook(ctx, stored); err != nil {
+		return chat.Webhook{}, err
+	}
+
+	webhook := stored.Webhook
+	webhook.Secret = stored.Secret
+
+	return webhook, nil
+}
+
+// GetWebhooks returns the webhooks of the room without their secrets. Only the room owner may list webhooks.
+func (d *WebhookDispatcher) GetWebhooks(ctx context.Context, roomId string, userId string) ([]chat.Webhook, error) {
+	if err := d.requireOwner(ctx, roomId, userId); err != nil {
+		return nil, err
+	}
+
+	stored, err := d.store.GetWebhooks(ctx, roomId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]chat.Webhook, 0, len(stored))
+
+	for _, s := range stored {
+		webhooks = append(webhooks, s.Webhook)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook from the room. Only the room owner may delete webhooks.
+func (d *WebhookDispatcher) DeleteWebhook(ctx context.Context, roomId string, userId string, webhookId string) error {
+	if err := d.requireOwner(ctx, roomId, userId); err != nil {
+		return err
+	}
+
+	err := d.store.DeleteWebhook(ctx, roomId, webhookId)
+
+	if errors.Is(err, ErrWebhookNotFound) {
+		return chat.NewNotFoundError("webhook not found")
+	}
+
+	return err
+}
+
+// Dispatch queues a delivery of the feed message to each webhook of the room which subscribes to its type. Call wherever
+// the server publishes a room event to the feed. Returns ErrWebhookQueueFull if any delivery was dropped.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, roomId string, message *chat.FeedMessage) error {
+	webhooks, err := d.store.GetWebhooks(ctx, roomId)
+
+	if err != nil {
+		return err
+	}
+
+	content := json.RawMessage(message.Content)
+
+	// Content which is not JSON is delivered as a base64 string.
+	if !json.Valid(content) {
+		if content, err = json.Marshal(message.Content); err != nil {
+			return err
+		}
+	}
+
+	createdAt := d.now().UTC()
+	var dropped bool
+
+	for _, webhook := range webhooks {
+		if !webhook.Webhook.Delivers(message.Type) {
+			continue
+		}
+
+		event := chat.WebhookEvent{
+			Id:           d.newId(),
+			WebhookId:    webhook.Webhook.Id,
+			RoomId:       roomId,
+			Type:         message.Type,
+			Content:      content,
+			CreatedAtUtc: createdAt,
+		}
+
+		body, err := json.Marshal(event)
+
+		if err != nil {
+			return err
+		}
+
+		select {
+		case d.queue <- webhookDelivery{webhook: webhook, event: event, body: body}:
+		default:
+			dropped = true
+		}
+	}
+
+	if dropped {
+		return ErrWebhookQueueFull
+	}
+
+	return nil
+}
+
+// Run makes queued deliveries until the context is cancelled. Abandoned deliveries are passed to onError as
+// *WebhookDeliveryError values; onError may be nil and must be safe for concurrent use.
+func (d *WebhookDispatcher) Run(ctx context.Context, onError func(error)) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-d.queue:
+					if err := d.deliver(ctx, delivery); err != nil && onError != nil {
+						onError(err)
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// deliver attempts the delivery until it succeeds, fails permanently, runs out of attempts or the context is cancelled.
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery webhookDelivery) error {
+	for attempt := 1; ; attempt++ {
+		retry, retryAfter, err := d.attempt(ctx, delivery)
+
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= d.maxAttempts {
+			return &WebhookDeliveryError{WebhookId: delivery.webhook.Webhook.Id, EventId: delivery.event.Id, Attempts: attempt, Err: err}
+		}
+
+		wait := max(d.backoff(attempt), retryAfter)
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &WebhookDeliveryError{WebhookId: delivery.webhook.Webhook.Id, EventId: delivery.event.Id, Attempts: attempt, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt makes a single delivery attempt. Returns whether a failed attempt should be retried and the delay requested
+// by the receiver with a Retry-After header.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery webhookDelivery) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.webhook.Webhook.Url, bytes.NewReader(delivery.body))
+
+	if err != nil {
+		return false, 0, err
+	}
+
+	timestamp := d.now()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BroChat-Webhooks")
+	req.Header.Set(chat.WEBHOOK_EVENT_ID_HEADER, delivery.event.Id)
+	req.Header.Set(chat.WEBHOOK_EVENT_TYPE_HEADER, string(delivery.event.Type))
+	req.Header.Set(chat.WEBHOOK_TIMESTAMP_HEADER, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(chat.WEBHOOK_SIGNATURE_HEADER, chat.SignWebhookPayload(delivery.webhook.Secret, timestamp, delivery.body))
+
+	res, err := d.httpClient.Do(req)
+
+	if err != nil {
+		return !errors.Is(err, ErrWebhookAddressNotAllowed), 0, err
+	}
+
+	// Drain some of the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, 0, nil
+	}
+
+	err = fmt.Errorf("receiver responded with status %d", res.StatusCode)
+
+	switch {
+	case res.StatusCode == http.StatusRequestTimeout, res.StatusCode == http.StatusTooManyRequests, res.StatusCode >= 500:
+		var retryAfter time.Duration
+
+		// The receiver cannot hold the delivery for longer than the backoff would
+		if seconds, parseErr := strconv.Atoi(res.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			retryAfter = time.Duration(min(seconds, int(maxWebhookBackoff/time.Second))) * time.Second
+		}
+
+		return true, retryAfter, err
+	default:
+		return false, 0, err
+	}
+}
+
+// newWebhookHttpClient creates the default client for deliveries. Every connection, including those of redirects, is
+// checked with allow once the host has been resolved. Proxies are not used, as the check would only apply to the proxy.
+func newWebhookHttpClient(allow func(addr netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   DEFAULT_WEBHOOK_TIMEOUT,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			if !allow(addrPort.Addr().Unmap()) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, addrPort.Addr())
+			}
+
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{Timeout: DEFAULT_WEBHOOK_TIMEOUT, Transport: transport}
+}
+
+// requireOwner returns an error response if the room does not exist or the user is not its owner.
+func (d *WebhookDispatcher) requireOwner(ctx context.Context, roomId string, userId string) error {
+	stored, err := d.rooms.GetRoom(ctx, roomId)
+
+	if errors.Is(err, ErrRoomNotFound) {
+		return chat.NewNotFoundError("room not found")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if stored.Room.Owner.Id != userId {
+		return chat.NewForbiddenError("only the room owner may manage webhooks")
+	}
+
+	return nil
+}
+
+// defaultWebhookBackoff doubles the wait after each attempt starting at one second, capped at maxWebhookBackoff. Half of
+// the wait is randomized so receivers recovering from an outage are not hit by every retry at once.
+func defaultWebhookBackoff(attempt int) time.Duration {
+	wait := maxWebhookBackoff
+
+	if attempt <= 20 {
+		wait = min(time.Second<<(attempt-1), maxWebhookBackoff)
+	}
+
+	return wait/2 + mathrand.N(wait/2+1)
+}
+
+// uniqueEventTypes removes duplicate event types, keeping the first occurrence.
+func uniqueEventTypes(eventTypes []chat.FeedMessageType) []chat.FeedMessageType {
+	unique := make([]chat.FeedMessageType, 0, len(eventTypes))
+	seen := make(map[chat.FeedMessageType]bool, len(eventTypes))
+
+	for _, t := range eventTypes {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	return unique
+}
+
+// newWebhookSecret generates a random 256 bit hex signing secret.
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
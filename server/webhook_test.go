@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+const testWebhookSecret = "secret"
+
+// newTestWebhookDispatcher creates a dispatcher with a webhook of room r delivering chat messages to the url, and runs
+// it until the test ends. Abandoned deliveries are sent to the returned channel.
+func newTestWebhookDispatcher(t *testing.T, url string, options ...WebhookDispatcherOption) (*WebhookDispatcher, <-chan error) {
+	t.Helper()
+
+	store := NewMemoryWebhookStore()
+
+	err := store.CreateWebhook(context.Background(), StoredWebhook{
+		Webhook: chat.Webhook{Id: "w", RoomId: "r", Url: url, EventTypes: []chat.FeedMessageType{chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE}},
+		Secret:  testWebhookSecret,
+	})
+
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+
+	options = append([]WebhookDispatcherOption{WebhookDispatcherOption_Backoff(func(int) time.Duration { return 0 })}, options...)
+	d := NewWebhookDispatcher(store, NewMemoryRoomStore(), options...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.Run(ctx, func(err error) { errs <- err })
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	return d, errs
+}
+
+func allowAnyAddress(netip.Addr) bool { return true }
+
+func dispatchChatMessage(t *testing.T, d *WebhookDispatcher) {
+	t.Helper()
+
+	if err := d.Dispatch(context.Background(), "r", &chat.FeedMessage{Type: chat.FEED_MESSAGE_TYPE_CHAT_MESSAGE, Content: []byte(`{"id":"m"}`)}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+}
+
+func TestWebhookDispatcher_DeliversSignedEvent(t *testing.T) {
+	events := make(chan chat.WebhookEvent, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := chat.ParseWebhookEvent(r, testWebhookSecret)
+
+		if err != nil {
+			t.Errorf("ParseWebhookEvent() error = %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.Header.Get(chat.WEBHOOK_EVENT_ID_HEADER) != event.Id || r.Header.Get(chat.WEBHOOK_EVENT_TYPE_HEADER) != string(event.Type) {
+			t.Errorf("event headers do not match the event %+v", event)
+		}
+
+		events <- event
+	}))
+	defer receiver.Close()
+
+	d, _ := newTestWebhookDispatcher(t, receiver.URL, WebhookDispatcherOption_AllowAddress(allowAnyAddress))
+	dispatchChatMessage(t, d)
+
+	select {
+	case event := <-events:
+		if event.WebhookId != "w" || event.RoomId != "r" || string(event.Content) != `{"id":"m"}` {
+			t.Errorf("delivered event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the delivery")
+	}
+}
+
+func TestWebhookDispatcher_SignatureRejectedWithOtherSecret(t *testing.T) {
+	rejected := make(chan error, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := chat.ParseWebhookEvent(r, "other secret")
+		rejected <- err
+	}))
+	defer receiver.Close()
+
+	d, _ := newTestWebhookDispatcher(t, receiver.URL, WebhookDispatcherOption_AllowAddress(allowAnyAddress))
+	dispatchChatMessage(t, d)
+
+	select {
+	case err := <-rejected:
+		if !errors.Is(err, chat.ErrWebhookSignatureInvalid) {
+			t.Errorf("ParseWebhookEvent() error = %v, want ErrWebhookSignatureInvalid", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the delivery")
+	}
+}
+
+func TestWebhookDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{name: "server error then success", statuses: []int{500, 503, 200}, wantAttempts: 3},
+		{name: "too many requests then success", statuses: []int{429, 204}, wantAttempts: 2},
+		{name: "client error is not retried", statuses: []int{400}, wantAttempts: 1, wantErr: true},
+		{name: "abandoned after max attempts", statuses: []int{500, 500, 500, 500}, wantAttempts: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			delivered := make(chan struct{}, 1)
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				status := tt.statuses[min(int(attempts.Add(1)), len(tt.statuses))-1]
+				w.WriteHeader(status)
+
+				if status < 300 {
+					delivered <- struct{}{}
+				}
+			}))
+			defer receiver.Close()
+
+			d, errs := newTestWebhookDispatcher(t, receiver.URL, WebhookDispatcherOption_AllowAddress(allowAnyAddress), WebhookDispatcherOption_MaxAttempts(3))
+			dispatchChatMessage(t, d)
+
+			select {
+			case <-delivered:
+				if tt.wantErr {
+					t.Fatalf("delivered, want the delivery abandoned")
+				}
+			case err := <-errs:
+				var deliveryErr *WebhookDeliveryError
+
+				if !tt.wantErr || !errors.As(err, &deliveryErr) {
+					t.Fatalf("delivery error = %v", err)
+				}
+
+				if deliveryErr.Attempts != int(tt.wantAttempts) {
+					t.Errorf("WebhookDeliveryError.Attempts = %d, want %d", deliveryErr.Attempts, tt.wantAttempts)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for the delivery")
+			}
+
+			if n := attempts.Load(); n != tt.wantAttempts {
+				t.Errorf("receiver got %d attempts, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher_RetryAfterClamped(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "999999999999")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	d := NewWebhookDispatcher(NewMemoryWebhookStore(), NewMemoryRoomStore(), WebhookDispatcherOption_AllowAddress(allowAnyAddress))
+	body, _ := json.Marshal(chat.WebhookEvent{Id: "e"})
+
+	retry, retryAfter, err := d.attempt(context.Background(), webhookDelivery{
+		webhook: StoredWebhook{Webhook: chat.Webhook{Id: "w", Url: receiver.URL}, Secret: testWebhookSecret},
+		event:   chat.WebhookEvent{Id: "e"},
+		body:    body,
+	})
+
+	if err == nil || !retry {
+		t.Fatalf("attempt() = %v, %v, want a retried failure", retry, err)
+	}
+
+	if retryAfter != maxWebhookBackoff {
+		t.Errorf("attempt() retry after = %v, want it clamped to %v", retryAfter, maxWebhookBackoff)
+	}
+}
+
+func TestWebhookDispatcher_RejectsLoopbackReceiver(t *testing.T) {
+	var attempts atomic.Int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+	}))
+	defer receiver.Close()
+
+	// The default address check applies, so the receiver on the loopback address is never reached
+	d, errs := newTestWebhookDispatcher(t, receiver.URL)
+	dispatchChatMessage(t, d)
+
+	select {
+	case err := <-errs:
+		var deliveryErr *WebhookDeliveryError
+
+		if !errors.Is(err, ErrWebhookAddressNotAllowed) || !errors.As(err, &deliveryErr) || deliveryErr.Attempts != 1 {
+			t.Errorf("delivery error = %v, want ErrWebhookAddressNotAllowed after one attempt", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the delivery to be abandoned")
+	}
+
+	if n := attempts.Load(); n != 0 {
+		t.Errorf("receiver got %d attempts, want none", n)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrWebhookNotFound is returned by a WebhookStore when the webhook does not exist.
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// A StoredWebhook is a webhook as persisted by a WebhookStore, including the signing secret.
+type StoredWebhook struct {
+	// The webhook. The Secret field is never set.
+	Webhook chat.Webhook
+	// The secret used to sign deliveries to the webhook.
+	Secret string
+}
+
+// A WebhookStore persists the webhooks of rooms for a WebhookDispatcher. Implementations must be safe for concurrent use.
+type WebhookStore interface {
+	// CreateWebhook stores a new webhook.
+	CreateWebhook(ctx context.Context, webhook StoredWebhook) error
+	// GetWebhooks returns the webhooks of the room, oldest first.
+	GetWebhooks(ctx context.Context, roomId string) ([]StoredWebhook, error)
+	// DeleteWebhook removes a webhook from the room or returns ErrWebhookNotFound.
+	DeleteWebhook(ctx context.Context, roomId string, webhookId string) error
+}
+
+// MemoryWebhookStore is a WebhookStore which keeps webhooks in memory.
+type MemoryWebhookStore struct {
+	mu       sync.RWMutex
+	webhooks map[string]map[string]StoredWebhook
+}
+
+// NewMemoryWebhookStore creates an empty in memory webhook store.
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		webhooks: make(map[string]map[string]StoredWebhook),
+	}
+}
+
+func (s *MemoryWebhookStore) CreateWebhook(_ context.Context, webhook StoredWebhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roomId := webhook.Webhook.RoomId
+
+	if s.webhooks[roomId] == nil {
+		s.webhooks[roomId] = make(map[string]StoredWebhook)
+	}
+
+	s.webhooks[roomId][webhook.Webhook.Id] = webhook
+
+	return nil
+}
+
+func (s *MemoryWebhookStore) GetWebhooks(_ context.Context, roomId string) ([]StoredWebhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := make([]StoredWebhook, 0, len(s.webhooks[roomId]))
+
+	for _, webhook := range s.webhooks[roomId] {
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].Webhook.CreatedAtUtc.Before(webhooks[j].Webhook.CreatedAtUtc)
+	})
+
+	return webhooks, nil
+}
+
+func (s *MemoryWebhookStore) DeleteWebhook(_ context.Context, roomId string, webhookId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[roomId][webhookId]; !ok {
+		return ErrWebhookNotFound
+	}
+
+	delete(s.webhooks[roomId], webhookId)
+
+	if len(s.webhooks[roomId]) == 0 {
+		delete(s.webhooks, roomId)
+	}
+
+	return nil
+}
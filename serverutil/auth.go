@@ -0,0 +1,106 @@
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+var (
+	// ErrTokenInvalid should be returned by a TokenVerifier when the token is malformed, expired or revoked.
+	// Results in a 401 Unauthorized response.
+	ErrTokenInvalid = errors.New("the access token is invalid")
+	// ErrTokenForbidden should be returned by a TokenVerifier when the token is valid but the user may not use the API.
+	// Example: a suspended account. Results in a 403 Forbidden response.
+	ErrTokenForbidden = errors.New("the user is not permitted to access this resource")
+)
+
+// A TokenVerifier verifies a bearer access token and returns the ID of the user it was issued to.
+// Returning a *chat.BroChatError writes that error as the response.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (string, error)
+}
+
+// TokenVerifierFunc adapts a function to the TokenVerifier interface.
+type TokenVerifierFunc func(ctx context.Context, token string) (string, error)
+
+// VerifyToken implements the TokenVerifier interface.
+func (f TokenVerifierFunc) VerifyToken(ctx context.Context, token string) (string, error) {
+	return f(ctx, token)
+}
+
+// userIdContextKey is the context key of the authenticated user ID.
+type userIdContextKey struct{}
+
+// ContextWithUserId returns a copy of the context carrying the authenticated user ID.
+func ContextWithUserId(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userIdContextKey{}, userId)
+}
+
+// UserIdFromContext returns the authenticated user ID stored by the BearerAuth middleware.
+// The second return value will be false if the request was not authenticated.
+func UserIdFromContext(ctx context.Context) (string, bool) {
+	userId, ok := ctx.Value(userIdContextKey{}).(string)
+	return userId, ok && userId != ""
+}
+
+// BearerAuth returns middleware which authenticates requests using the bearer token in the Authorization header.
+// The authenticated user ID is available to the next handler via UserIdFromContext.
+// Requests without a valid token are rejected with a 401 response and forbidden users with a 403 response.
+func BearerAuth(verifier TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r.Header.Get("Authorization"))
+
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				WriteBroChatError(w, chat.NewUnauthorizedError("a bearer token is required"))
+				return
+			}
+
+			userId, err := verifier.VerifyToken(r.Context(), token)
+
+			if err == nil && userId == "" {
+				err = ErrTokenInvalid
+			}
+
+			if err != nil {
+				writeAuthError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithUserId(r.Context(), userId)))
+		})
+	}
+}
+
+// bearerToken extracts the token from an Authorization header value. The scheme is case insensitive.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// writeAuthError writes the response for a token verification failure.
+func writeAuthError(w http.ResponseWriter, err error) {
+	var brochatErr *chat.BroChatError
+
+	switch {
+	case errors.As(err, &brochatErr):
+		WriteBroChatError(w, brochatErr)
+	case errors.Is(err, ErrTokenForbidden):
+		WriteBroChatError(w, chat.NewForbiddenError(err.Error()))
+	default:
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		WriteBroChatError(w, chat.NewUnauthorizedError(ErrTokenInvalid.Error()))
+	}
+}
@@ -0,0 +1,317 @@
+package serverutil
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// Rate limit response headers, read by clients with chat.ParseRateLimit and chat.ParseRetryAfter.
+const (
+	RATE_LIMIT_LIMIT_HEADER     = chat.RATE_LIMIT_LIMIT_HEADER
+	RATE_LIMIT_REMAINING_HEADER = chat.RATE_LIMIT_REMAINING_HEADER
+	RATE_LIMIT_RESET_HEADER     = chat.RATE_LIMIT_RESET_HEADER
+	RETRY_AFTER_HEADER          = chat.RETRY_AFTER_HEADER
+)
+
+// A RateLimitPolicy describes a token bucket which holds up to Requests tokens and refills completely over Per.
+// Example: RateLimitPolicy{Requests: 60, Per: time.Minute} allows bursts of 60 requests and a sustained rate of one per second.
+type RateLimitPolicy struct {
+	// The capacity of the bucket.
+	Requests int
+	// The time taken to refill an empty bucket.
+	Per time.Duration
+}
+
+// rate returns the number of tokens added to the bucket per second.
+func (p RateLimitPolicy) rate() float64 {
+	return float64(p.Requests) / p.Per.Seconds()
+}
+
+// A RateLimitDecision is the outcome of taking a token from a bucket.
+type RateLimitDecision struct {
+	// True if a token was available and the request may proceed.
+	Allowed bool
+	// The number of whole tokens left in the bucket.
+	Remaining int
+	// How long until a token will be available. Zero if Allowed is true.
+	RetryAfter time.Duration
+	// How long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// makeRateLimitDecision creates the decision for a bucket holding the given number of tokens after a take.
+func makeRateLimitDecision(policy RateLimitPolicy, allowed bool, tokens float64) RateLimitDecision {
+	rate := policy.rate()
+	decision := RateLimitDecision{
+		Allowed:    allowed,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(policy.Requests) - tokens) / rate * float64(time.Second)),
+	}
+
+	if !allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+
+	return decision
+}
+
+// A RateLimitStore holds the token buckets of a rate limiter. Implementations must be safe for concurrent use.
+type RateLimitStore interface {
+	// Take attempts to take a token from the bucket identified by the key.
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitDecision, error)
+}
+
+// MemoryRateLimitStore is a RateLimitStore which keeps buckets in memory. Suitable for a single server instance.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is the state of a single bucket.
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in memory rate limit store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// Take implements the RateLimitStore interface.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(policy, now)
+
+	capacity := float64(policy.Requests)
+	bucket, ok := s.buckets[key]
+
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+
+	if elapsed > 0 {
+		bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*policy.rate())
+		bucket.updatedAt = now
+	}
+
+	allowed := bucket.tokens >= 1
+
+	if allowed {
+		bucket.tokens--
+	}
+
+	decision := makeRateLimitDecision(policy, allowed, bucket.tokens)
+	bucket.fullAt = now.Add(decision.ResetAfter)
+
+	return decision, nil
+}
+
+// sweep removes buckets which have refilled completely, as they are indistinguishable from new buckets.
+// Runs at most once per policy period. Must be called with the lock held.
+func (s *MemoryRateLimitStore) sweep(policy RateLimitPolicy, now time.Time) {
+	if now.Sub(s.lastSweep) < policy.Per {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// A RedisEvaluator runs a Lua script on a Redis server. It decouples the RedisRateLimitStore from any particular Redis client.
+// Example adapter for go-redis:
+//
+//	RedisEvaluatorFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	})
+type RedisEvaluator interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// RedisEvaluatorFunc adapts a function to the RedisEvaluator interface.
+type RedisEvaluatorFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// Eval implements the RedisEvaluator interface.
+func (f RedisEvaluatorFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// redisTokenBucketScript atomically refills and takes from a bucket stored as a hash. The token count is returned as a
+// string because Redis truncates Lua numbers to integers.
+const redisTokenBucketScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`
+
+// RedisRateLimitStore is a RateLimitStore which keeps buckets in Redis, allowing a limit to be shared by several server instances.
+type RedisRateLimitStore struct {
+	evaluator RedisEvaluator
+	prefix    string
+}
+
+// NewRedisRateLimitStore creates a rate limit store using the evaluator. Bucket keys are prefixed with the given prefix.
+func NewRedisRateLimitStore(evaluator RedisEvaluator, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{evaluator: evaluator, prefix: prefix}
+}
+
+// Take implements the RateLimitStore interface.
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitDecision, error) {
+	// The script works in milliseconds
+	rate := policy.rate() / 1000
+	reply, err := s.evaluator.Eval(ctx, redisTokenBucketScript, []string{s.prefix + key}, policy.Requests, rate, now.UnixMilli())
+
+	if err != nil {
+		return RateLimitDecision{}, err
+	}
+
+	values, ok := reply.([]any)
+
+	if !ok || len(values) != 2 {
+		return RateLimitDecision{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	allowed, ok := values[0].(int64)
+
+	if !ok {
+		return RateLimitDecision{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	tokensText, ok := values[1].(string)
+
+	if !ok {
+		return RateLimitDecision{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+
+	if err != nil {
+		return RateLimitDecision{}, err
+	}
+
+	return makeRateLimitDecision(policy, allowed == 1, tokens), nil
+}
+
+// rateLimitOptions holds the options for the RateLimit middleware.
+type rateLimitOptions struct {
+	store   RateLimitStore
+	keyFunc func(r *http.Request) string
+}
+
+// RateLimitOption is a type for the options that can be passed to RateLimit.
+type RateLimitOption func(*rateLimitOptions)
+
+// Sets the store used to hold the token buckets. Defaults to a new MemoryRateLimitStore.
+func RateLimitOption_Store(store RateLimitStore) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.store = store
+	}
+}
+
+// Sets the function used to identify the bucket of a request. Defaults to RateLimitKeyByUserOrIP.
+func RateLimitOption_KeyFunc(keyFunc func(r *http.Request) string) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.keyFunc = keyFunc
+	}
+}
+
+// RateLimitKeyByUserOrIP identifies a request by its authenticated user ID, falling back to the client IP address.
+// Place the RateLimit middleware after BearerAuth for requests to be limited per user.
+func RateLimitKeyByUserOrIP(r *http.Request) string {
+	if userId, ok := UserIdFromContext(r.Context()); ok {
+		return "user:" + userId
+	}
+
+	return "ip:" + clientIP(r)
+}
+
+// RateLimit returns middleware which limits requests using a token bucket per key. Every response carries the
+// X-RateLimit-* headers and rejected requests receive a 429 response with a Retry-After header.
+// If the store fails the request is allowed, so an unavailable store does not take the API down with it. The response
+// then only carries X-RateLimit-Limit, as the state of the bucket is unknown.
+func RateLimit(policy RateLimitPolicy, options ...RateLimitOption) func(http.Handler) http.Handler {
+	opts := &rateLimitOptions{
+		keyFunc: RateLimitKeyByUserOrIP,
+	}
+
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	if opts.store == nil {
+		opts.store = NewMemoryRateLimitStore()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, err := opts.store.Take(r.Context(), opts.keyFunc(r), policy, time.Now())
+
+			w.Header().Set(RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(policy.Requests))
+
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(decision.Remaining))
+			w.Header().Set(RATE_LIMIT_RESET_HEADER, strconv.Itoa(ceilSeconds(decision.ResetAfter)))
+
+			if !decision.Allowed {
+				retryAfter := ceilSeconds(decision.RetryAfter)
+				w.Header().Set(RETRY_AFTER_HEADER, strconv.Itoa(retryAfter))
+				WriteBroChatError(w, chat.NewRateLimitedError(time.Duration(retryAfter)*time.Second))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds the duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// clientIP returns the IP address of the client that made the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,66 @@
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// failingRateLimitStore is a RateLimitStore which is unavailable.
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, RateLimitPolicy, time.Time) (RateLimitDecision, error) {
+	return RateLimitDecision{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	policy := RateLimitPolicy{Requests: 2, Per: time.Minute}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name          string
+		store         RateLimitStore
+		requests      int
+		wantStatus    int
+		wantRateLimit chat.RateLimit
+		wantRetry     bool
+	}{
+		{name: "allowed", store: NewMemoryRateLimitStore(), requests: 1, wantStatus: http.StatusOK,
+			wantRateLimit: chat.RateLimit{Limit: 2, Remaining: 1, Reset: 30 * time.Second}},
+		{name: "rejected", store: NewMemoryRateLimitStore(), requests: 3, wantStatus: http.StatusTooManyRequests,
+			wantRateLimit: chat.RateLimit{Limit: 2, Remaining: 0, Reset: time.Minute}, wantRetry: true},
+		{name: "store failure", store: failingRateLimitStore{}, requests: 3, wantStatus: http.StatusOK,
+			wantRateLimit: chat.RateLimit{Limit: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RateLimit(policy, RateLimitOption_Store(tt.store))(ok)
+
+			var res *http.Response
+
+			for i := 0; i < tt.requests; i++ {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				res = rec.Result()
+			}
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+
+			if got := chat.ParseRateLimit(res.Header); got != tt.wantRateLimit {
+				t.Errorf("rate limit headers = %+v, want %+v", got, tt.wantRateLimit)
+			}
+
+			if retryAfter := chat.ParseRetryAfter(res.Header, time.Now()); (retryAfter > 0) != tt.wantRetry {
+				t.Errorf("Retry-After = %v, want one only when rejected", retryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package serverutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// The header used to carry the request ID.
+const REQUEST_ID_HEADER = chat.REQUEST_ID_HEADER
+
+// The maximum length of a request ID accepted from a client. Longer IDs are replaced.
+const maxRequestIdLength = 128
+
+// requestIdContextKey is the context key of the request ID.
+type requestIdContextKey struct{}
+
+// ContextWithRequestId returns a copy of the context carrying the request ID.
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns the request ID stored by the RequestId middleware. Will return an empty string if there is none.
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+// PropagateRequestId sets the request ID from the context on an outbound request, so calls to other services can be correlated.
+func PropagateRequestId(ctx context.Context, req *http.Request) {
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		req.Header.Set(REQUEST_ID_HEADER, requestId)
+	}
+}
+
+// Logger returns the logger with the request ID from the context attached. If the logger is nil slog.Default() is used.
+func Logger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		return logger.With(slog.String("request_id", requestId))
+	}
+
+	return logger
+}
+
+// NewRequestId generates a random request ID.
+func NewRequestId() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// RequestId returns middleware which assigns every request an ID. A well formed X-Request-ID header sent by the
+// client is reused, otherwise a new ID is generated. The ID is stored in the request context, echoed in the
+// X-Request-ID response header and included in error responses written with WriteBroChatError.
+func RequestId() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId := r.Header.Get(REQUEST_ID_HEADER)
+
+			if !isValidRequestId(requestId) {
+				requestId = NewRequestId()
+			}
+
+			w.Header().Set(REQUEST_ID_HEADER, requestId)
+
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestId(r.Context(), requestId)))
+		})
+	}
+}
+
+// isValidRequestId reports whether a client supplied request ID is safe to reuse in headers and logs.
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	for i := 0; i < len(requestId); i++ {
+		c := requestId[i]
+
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,65 @@
+// Package serverutil contains helpers for servers implementing the BroChat API, keeping their responses consistent
+// with what the chat.BroChatClient expects.
+package serverutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// WriteBroChatError writes the error as a JSON response with the HTTP status code that corresponds to its code.
+// A nil error is written as an unhandled error. The request ID is included if the RequestId middleware is in use.
+func WriteBroChatError(w http.ResponseWriter, err *chat.BroChatError) {
+	if err == nil {
+		err = chat.NewErrorResponse(chat.BROCHAT_RESPONSE_CODE_UNHANDLED_ERROR, "An unexpected error occurred")
+	}
+
+	// Attach the request ID assigned by the RequestId middleware without modifying the caller's error
+	if requestId := w.Header().Get(REQUEST_ID_HEADER); requestId != "" && err.RequestId == "" {
+		withId := *err
+		withId.RequestId = requestId
+		err = &withId
+	}
+
+	WriteJSON(w, err.Code.HttpStatusCode(), err)
+}
+
+// WriteJSON writes the value as a JSON response with the given status code.
+func WriteJSON(w http.ResponseWriter, status int, value any) {
+	body, err := json.Marshal(value)
+
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// StatusCode returns the HTTP status code that corresponds to the response code.
+func StatusCode(code chat.BroChatResponseCode) int {
+	return code.HttpStatusCode()
+}
+
+// ResponseCode returns the response code that corresponds to the HTTP status code.
+func ResponseCode(status int) chat.BroChatResponseCode {
+	return chat.ResponseCodeFromHttpStatus(status)
+}
+
+// WriteError writes the error as a JSON response. A *chat.BroChatError anywhere in the error chain is written as is,
+// any other error is written as an unhandled error without exposing its message.
+func WriteError(w http.ResponseWriter, err error) {
+	var brochatErr *chat.BroChatError
+
+	if errors.As(err, &brochatErr) {
+		WriteBroChatError(w, brochatErr)
+		return
+	}
+
+	WriteBroChatError(w, nil)
+}
@@ -0,0 +1,62 @@
+// Package validation applies the shared request validation rules. Clients use it to check requests before sending
+// them and servers use it to reject invalid requests with the standard BroChatError response.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/dmars8047/brolib/chat"
+)
+
+// A Validator is a request which can check itself against the shared limits. All request types in the chat package
+// with validation rules implement this interface.
+type Validator interface {
+	Validate() chat.ValidationErrors
+}
+
+// Validate checks the request and returns the field errors. Requests which do not implement Validator have no
+// validation rules and are always valid.
+func Validate(request any) chat.ValidationErrors {
+	if v, ok := request.(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// Check validates the request and returns the field errors as an error. Will return nil if the request is valid.
+// Intended for client side pre-flight checks.
+func Check(request any) error {
+	return Validate(request).Err()
+}
+
+// ErrorResponse validates the request and returns the error response a server should send if it is invalid.
+// Will return nil if the request is valid.
+func ErrorResponse(request any) *chat.BroChatError {
+	errs := Validate(request)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return chat.NewValidationErrorFromFields(errs)
+}
+
+// Decode reads a JSON request body into a T and validates it. A body that cannot be parsed results in a request
+// parse error response and an invalid request results in a validation error response.
+// Usage: request, errResponse := validation.Decode[chat.CreateRoomRequest](r.Body)
+func Decode[T any](body io.Reader) (T, *chat.BroChatError) {
+	var request T
+
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		if errors.Is(err, io.EOF) {
+			return request, chat.NewRequestParseError("the request body is empty")
+		}
+
+		return request, chat.NewRequestParseError("the request body is not valid JSON")
+	}
+
+	return request, ErrorResponse(request)
+}